@@ -0,0 +1,101 @@
+// Package apiversion tells the versions of the HTTP API apart. v2 is served under /v2, every other
+// route of the application is v1, which is kept as it is for the clients depending on it. The
+// requests of every version are counted, and the responses of a deprecated version tell its clients
+// so in the Deprecation (RFC 9745) and Sunset (RFC 8594) headers.
+package apiversion
+
+import (
+	"gofr.dev/pkg/gofr/container"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Versions of the API.
+const (
+	V1 = "v1"
+	V2 = "v2"
+)
+
+// MetricRequests counts the requests of the API by version, in the label version.
+const MetricRequests = "app_api_requests_total"
+
+// Of returns the version of the API path belongs to, or "" for GoFr's /.well-known/ routes, which
+// are not part of it.
+func Of(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/.well-known/"):
+		return ""
+	case path == "/v2" || strings.HasPrefix(path, "/v2/"):
+		return V2
+	default:
+		return V1
+	}
+}
+
+// RegisterMetrics registers the metrics the middleware records.
+func RegisterMetrics(metrics container.Metrics) {
+	metrics.NewCounter(MetricRequests, "Number of requests of the HTTP API, by version.")
+}
+
+// Deprecation describes a deprecated version of the API.
+type Deprecation struct {
+	// Since is when the version was deprecated.
+	Since time.Time
+	// Sunset is when the version stops being served, zero if that is not decided yet.
+	Sunset time.Time
+	// Successor is the path of the resource replacing the ones of the version, if any.
+	Successor string
+}
+
+// header sets the headers telling the clients of the version about its deprecation.
+func (d Deprecation) header(h http.Header) {
+	h.Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+
+	if !d.Sunset.IsZero() {
+		h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+
+	if d.Successor != "" {
+		h.Add("Link", "<"+d.Successor+`>; rel="successor-version"`)
+	}
+}
+
+// Option configures the middleware.
+type Option func(deprecations map[string]Deprecation)
+
+// Deprecate marks version as deprecated.
+func Deprecate(version string, deprecation Deprecation) Option {
+	return func(deprecations map[string]Deprecation) {
+		deprecations[version] = deprecation
+	}
+}
+
+// Middleware counts the requests of every version of the API in metrics and adds the deprecation
+// headers to the responses of the deprecated versions.
+func Middleware(metrics container.Metrics, opts ...Option) func(http.Handler) http.Handler {
+	deprecations := make(map[string]Deprecation)
+
+	for _, opt := range opts {
+		opt(deprecations)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			version := Of(r.URL.Path)
+			if version == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			metrics.IncrementCounter(r.Context(), MetricRequests, "version", version)
+
+			if deprecation, ok := deprecations[version]; ok {
+				deprecation.header(w.Header())
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package apiversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gofr.dev/pkg/gofr/container"
+)
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		path            string
+		expectedVersion string
+	}{
+		{path: "/user", expectedVersion: V1},
+		{path: "/users/0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", expectedVersion: V1},
+		{path: "/audit", expectedVersion: V1},
+		{path: "/v2/users", expectedVersion: V2},
+		{path: "/v2", expectedVersion: V2},
+		{path: "/v2users", expectedVersion: V1},
+		{path: "/.well-known/openapi.json", expectedVersion: ""},
+	}
+
+	for i, tt := range tests {
+		assert.Equal(t, tt.expectedVersion, Of(tt.path), "TEST[%d] failed: %s", i, tt.path)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	since := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		path            string
+		deprecation     Deprecation
+		expectedVersion string
+		expectedHeader  http.Header
+	}{
+		{name: "deprecated version", path: "/user/waheed",
+			deprecation:     Deprecation{Since: since, Sunset: sunset, Successor: "/v2/users"},
+			expectedVersion: V1,
+			expectedHeader: http.Header{
+				"Deprecation": {"@1737331200"},
+				"Sunset":      {"Thu, 31 Jul 2025 00:00:00 GMT"},
+				"Link":        {`</v2/users>; rel="successor-version"`},
+			}},
+		{name: "deprecated version without sunset", path: "/user", deprecation: Deprecation{Since: since},
+			expectedVersion: V1, expectedHeader: http.Header{"Deprecation": {"@1737331200"}}},
+		{name: "current version", path: "/v2/users", deprecation: Deprecation{Since: since},
+			expectedVersion: V2, expectedHeader: http.Header{}},
+		{name: "outside of the API", path: "/.well-known/health", deprecation: Deprecation{Since: since},
+			expectedHeader: http.Header{}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mocks := container.NewMockContainer(t)
+
+			if tt.expectedVersion != "" {
+				mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), MetricRequests, "version", tt.expectedVersion)
+			}
+
+			handler := Middleware(mocks.Metrics, Deprecate(V1, tt.deprecation))(http.HandlerFunc(
+				func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+				}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+
+			assert.Equal(t, tt.expectedHeader, w.Header(), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
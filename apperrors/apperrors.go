@@ -0,0 +1,122 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies domain errors independently of the layer that produced them.
+type Kind string
+
+const (
+	KindNotFound      Kind = "not_found"
+	KindAlreadyExists Kind = "already_exists"
+	KindValidation    Kind = "validation"
+	KindConflict      Kind = "conflict"
+	KindUnavailable   Kind = "unavailable"
+	KindAborted       Kind = "aborted"
+
+	KindNotAcceptable        Kind = "not_acceptable"
+	KindUnsupportedMediaType Kind = "unsupported_media_type"
+
+	KindPreconditionFailed   Kind = "precondition_failed"
+	KindPreconditionRequired Kind = "precondition_required"
+)
+
+// Error is a domain error. The store translates driver errors into it, the service adds
+// the entity being worked on and the transports translate its Kind into a status code.
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Message != "":
+		return e.Message
+	case e.Err != nil:
+		return e.Err.Error()
+	default:
+		return string(e.Kind)
+	}
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error of the same Kind, so errors.Is(err, &Error{Kind: KindNotFound})
+// matches every not-found error.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+
+	return ok && t.Message == "" && t.Err == nil && t.Kind == e.Kind
+}
+
+// NotFound returns an error for an entity that does not exist.
+func NotFound(entity, key, value string) *Error {
+	return &Error{Kind: KindNotFound, Message: fmt.Sprintf("%s with %s '%s' not found", entity, key, value)}
+}
+
+// AlreadyExists returns an error for an entity that cannot be created twice.
+func AlreadyExists(entity, key, value string) *Error {
+	return &Error{Kind: KindAlreadyExists, Message: fmt.Sprintf("%s with %s '%s' already exists", entity, key, value)}
+}
+
+// Validation wraps err, which describes invalid input, e.g. validation.Errors.
+func Validation(err error) *Error {
+	return &Error{Kind: KindValidation, Err: err}
+}
+
+// Conflict returns an error for a change that clashes with the current state.
+func Conflict(message string, err error) *Error {
+	return &Error{Kind: KindConflict, Message: message, Err: err}
+}
+
+// PreconditionFailed returns an error for a conditional change of an entity that was modified
+// since the client read it.
+func PreconditionFailed(entity, key, value string) *Error {
+	return &Error{Kind: KindPreconditionFailed, Message: fmt.Sprintf("%s with %s '%s' has been modified", entity, key, value)}
+}
+
+// PreconditionRequired returns an error for a change that must be made conditional but is not.
+func PreconditionRequired(message string) *Error {
+	return &Error{Kind: KindPreconditionRequired, Message: message}
+}
+
+// Unavailable wraps err, raised because a dependency could not be reached.
+func Unavailable(err error) *Error {
+	return &Error{Kind: KindUnavailable, Message: "service temporarily unavailable", Err: err}
+}
+
+// Aborted returns an error for a change that was not made because another change it had to be
+// made together with failed.
+func Aborted(message string) *Error {
+	return &Error{Kind: KindAborted, Message: message}
+}
+
+// NotAcceptable returns an error for a request accepting none of the formats a response can be sent in.
+func NotAcceptable(message string) *Error {
+	return &Error{Kind: KindNotAcceptable, Message: message}
+}
+
+// UnsupportedMediaType returns an error for a request body in a format that cannot be read.
+func UnsupportedMediaType(message string) *Error {
+	return &Error{Kind: KindUnsupportedMediaType, Message: message}
+}
+
+// KindOf returns the Kind of the first *Error in err's chain, or "" if there is none.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	return ""
+}
+
+// IsNotFound reports whether err is a not-found error.
+func IsNotFound(err error) bool {
+	return KindOf(err) == KindNotFound
+}
@@ -0,0 +1,61 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	cause := errors.New("dial tcp: connection refused")
+
+	tests := []struct {
+		name            string
+		err             error
+		expectedKind    Kind
+		expectedMessage string
+	}{
+		{name: "not found", err: NotFound("user", "name", "waheed"),
+			expectedKind: KindNotFound, expectedMessage: "user with name 'waheed' not found"},
+		{name: "already exists", err: AlreadyExists("user", "name", "waheed"),
+			expectedKind: KindAlreadyExists, expectedMessage: "user with name 'waheed' already exists"},
+		{name: "validation", err: Validation(errors.New("user_age: out of range")),
+			expectedKind: KindValidation, expectedMessage: "user_age: out of range"},
+		{name: "conflict", err: Conflict("user was modified concurrently", cause),
+			expectedKind: KindConflict, expectedMessage: "user was modified concurrently"},
+		{name: "precondition failed", err: PreconditionFailed("user", "name", "waheed"),
+			expectedKind: KindPreconditionFailed, expectedMessage: "user with name 'waheed' has been modified"},
+		{name: "precondition required", err: PreconditionRequired("If-Match header is required"),
+			expectedKind: KindPreconditionRequired, expectedMessage: "If-Match header is required"},
+		{name: "unavailable", err: Unavailable(cause),
+			expectedKind: KindUnavailable, expectedMessage: "service temporarily unavailable"},
+		{name: "aborted", err: Aborted("another operation of the batch failed"),
+			expectedKind: KindAborted, expectedMessage: "another operation of the batch failed"},
+		{name: "not acceptable", err: NotAcceptable("Accept must allow application/json"),
+			expectedKind: KindNotAcceptable, expectedMessage: "Accept must allow application/json"},
+		{name: "unsupported media type", err: UnsupportedMediaType("Content-Type must be text/csv"),
+			expectedKind: KindUnsupportedMediaType, expectedMessage: "Content-Type must be text/csv"},
+		{name: "wrapped", err: fmt.Errorf("deleting: %w", NotFound("user", "name", "waheed")),
+			expectedKind: KindNotFound, expectedMessage: "deleting: user with name 'waheed' not found"},
+		{name: "plain error", err: cause, expectedKind: "", expectedMessage: "dial tcp: connection refused"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedKind, KindOf(tt.err), "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedMessage, tt.err.Error(), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := fmt.Errorf("reading user: %w", Unavailable(cause))
+
+	assert.True(t, errors.Is(err, &Error{Kind: KindUnavailable}))
+	assert.False(t, errors.Is(err, &Error{Kind: KindNotFound}))
+	assert.True(t, errors.Is(err, cause))
+	assert.True(t, IsNotFound(NotFound("user", "name", "waheed")))
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIKeyHeader is the request header carrying the API key. Requests without it may send the key as
+// the whole value of the Authorization header, as clients did before API keys were configurable.
+const APIKeyHeader = "X-API-Key"
+
+type apiKey struct {
+	name      string
+	hash      []byte
+	expiresAt time.Time
+}
+
+// APIKeyAuthenticator authenticates requests carrying one of the configured API keys.
+// Only the SHA-256 hashes of the keys are kept in configuration.
+type APIKeyAuthenticator struct {
+	keys []apiKey
+	now  func() time.Time
+}
+
+// NewAPIKeyAuthenticator parses a comma separated list of keys in the form
+// name:sha256-hex[:RFC3339 expiry], e.g. "ci:ba7816bf...:2026-12-31T00:00:00Z".
+func NewAPIKeyAuthenticator(keys string, now func() time.Time) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{now: now}
+
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) < 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid api key entry %q", entry)
+		}
+
+		hash, err := hex.DecodeString(parts[1])
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid sha256 hash for api key %q", parts[0])
+		}
+
+		key := apiKey{name: parts[0], hash: hash}
+
+		if len(parts) == 3 && parts[2] != "" {
+			if key.expiresAt, err = time.Parse(time.RFC3339, parts[2]); err != nil {
+				return nil, fmt.Errorf("invalid expiry for api key %q: %w", parts[0], err)
+			}
+		}
+
+		a.keys = append(a.keys, key)
+	}
+
+	return a, nil
+}
+
+// Authenticate implements Authenticator.
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		key = r.Header.Get("Authorization")
+	}
+
+	if key == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	hash := sha256.Sum256([]byte(key))
+
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], k.hash) != 1 {
+			continue
+		}
+
+		if !k.expiresAt.IsZero() && !a.now().Before(k.expiresAt) {
+			return nil, ErrExpiredCredentials
+		}
+
+		return &Principal{Name: k.name, Method: MethodAPIKey}, nil
+	}
+
+	return nil, ErrInvalidCredentials
+}
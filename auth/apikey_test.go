@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sha256 of "abc" and "expired".
+const (
+	abcHash     = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	expiredHash = "fa64ea1e82e1206f828ab2a02917c7e92accb98e3b95881a1b4ad52b914b66e3"
+)
+
+func TestNewAPIKeyAuthenticator(t *testing.T) {
+	tests := []struct {
+		name        string
+		keys        string
+		expectedErr bool
+	}{
+		{name: "empty configuration", keys: "", expectedErr: false},
+		{name: "keys with and without expiry", keys: "ci:" + abcHash + ", ops:" + abcHash + ":2026-01-01T00:00:00Z", expectedErr: false},
+		{name: "missing hash", keys: "ci", expectedErr: true},
+		{name: "hash is not sha256", keys: "ci:abcd", expectedErr: true},
+		{name: "invalid expiry", keys: "ci:" + abcHash + ":tomorrow", expectedErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAPIKeyAuthenticator(tt.keys, time.Now)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestAPIKeyAuthenticator_Authenticate(t *testing.T) {
+	now := func() time.Time { return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	a, err := NewAPIKeyAuthenticator("ci:"+abcHash+":2026-01-01T00:00:00Z,old:"+expiredHash+":2025-01-01T00:00:00Z", now)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name              string
+		key               string
+		authorization     string
+		expectedPrincipal *Principal
+		expectedErr       error
+	}{
+		{name: "valid key", key: "abc", expectedPrincipal: &Principal{Name: "ci", Method: MethodAPIKey}},
+		{name: "missing key", key: "", expectedErr: ErrMissingCredentials},
+		{name: "unknown key", key: "xyz", expectedErr: ErrInvalidCredentials},
+		{name: "expired key", key: "expired", expectedErr: ErrExpiredCredentials},
+		{name: "key in the authorization header", authorization: "abc",
+			expectedPrincipal: &Principal{Name: "ci", Method: MethodAPIKey}},
+		{name: "api key header takes precedence", key: "xyz", authorization: "abc", expectedErr: ErrInvalidCredentials},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user", http.NoBody)
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			principal, err := a.Authenticate(req)
+
+			assert.Equal(t, tt.expectedPrincipal, principal, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
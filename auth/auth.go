@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Authentication methods selectable through AUTH_METHOD.
+const (
+	MethodAPIKey = "api_key"
+	MethodBasic  = "basic"
+	MethodJWT    = "jwt"
+)
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrExpiredCredentials = errors.New("expired credentials")
+	ErrUnknownMethod      = errors.New("unknown authentication method")
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Name   string
+	Method string
+	Roles  []string
+}
+
+// Authenticator identifies the caller of a request from its credentials.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Config is the part of GoFr's config.Config needed to build an Authenticator.
+type Config interface {
+	Get(key string) string
+	GetOrDefault(key, defaultValue string) string
+}
+
+// New returns the Authenticator selected by AUTH_METHOD:
+//   - api_key reads hashed keys from AUTH_API_KEYS,
+//   - basic reads bcrypt hashed credentials from the file in AUTH_BASIC_CREDENTIALS_FILE,
+//   - jwt validates bearer tokens against the JWKS file in AUTH_JWKS_FILE, optionally
+//     checking AUTH_JWT_ISSUER and AUTH_JWT_AUDIENCE.
+func New(c Config) (Authenticator, error) {
+	switch method := c.GetOrDefault("AUTH_METHOD", MethodAPIKey); method {
+	case MethodAPIKey:
+		return NewAPIKeyAuthenticator(c.Get("AUTH_API_KEYS"), time.Now)
+	case MethodBasic:
+		return NewBasicAuthenticatorFromFile(c.Get("AUTH_BASIC_CREDENTIALS_FILE"))
+	case MethodJWT:
+		return NewJWTAuthenticatorFromFile(c.Get("AUTH_JWKS_FILE"), c.Get("AUTH_JWT_ISSUER"), c.Get("AUTH_JWT_AUDIENCE"))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMethod, method)
+	}
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx. Since gofr.Context wraps the
+// request context, handlers and services can pass their *gofr.Context directly.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+
+	return principal, ok && principal != nil
+}
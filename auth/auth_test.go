@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockConfig map[string]string
+
+func (c mockConfig) Get(key string) string {
+	return c[key]
+}
+
+func (c mockConfig) GetOrDefault(key, defaultValue string) string {
+	if value, ok := c[key]; ok {
+		return value
+	}
+
+	return defaultValue
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       mockConfig
+		expectedType Authenticator
+		expectedErr  bool
+	}{
+		{name: "api key by default", config: mockConfig{"AUTH_API_KEYS": "ci:" + abcHash}, expectedType: &APIKeyAuthenticator{}},
+		{name: "missing credentials file", config: mockConfig{"AUTH_METHOD": MethodBasic}, expectedErr: true},
+		{name: "missing jwks file", config: mockConfig{"AUTH_METHOD": MethodJWT}, expectedErr: true},
+		{name: "unknown method", config: mockConfig{"AUTH_METHOD": "oauth"}, expectedErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := New(tt.config)
+
+			assert.Equal(t, tt.expectedErr, err != nil, "TEST[%d] failed: %s", i, tt.name)
+
+			if !tt.expectedErr {
+				assert.IsType(t, tt.expectedType, a, "TEST[%d] failed: %s", i, tt.name)
+			}
+		})
+	}
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	_, ok := PrincipalFromContext(context.Background())
+	assert.False(t, ok)
+
+	principal := &Principal{Name: "waheed", Method: MethodAPIKey}
+
+	got, ok := PrincipalFromContext(WithPrincipal(context.Background(), principal))
+	assert.True(t, ok)
+	assert.Equal(t, principal, got)
+}
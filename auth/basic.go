@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicAuthenticator authenticates requests using HTTP Basic credentials
+// checked against bcrypt password hashes.
+type BasicAuthenticator struct {
+	users map[string][]byte
+}
+
+// NewBasicAuthenticatorFromFile loads the credentials file at path.
+func NewBasicAuthenticatorFromFile(path string) (*BasicAuthenticator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening basic auth credentials: %w", err)
+	}
+	defer f.Close()
+
+	return NewBasicAuthenticator(f)
+}
+
+// NewBasicAuthenticator reads htpasswd style credentials, one username:bcrypt-hash per line.
+// Empty lines and lines starting with # are ignored.
+func NewBasicAuthenticator(r io.Reader) (*BasicAuthenticator, error) {
+	a := &BasicAuthenticator{users: make(map[string][]byte)}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		user, hash, ok := strings.Cut(entry, ":")
+		if !ok || user == "" || hash == "" {
+			return nil, fmt.Errorf("invalid basic auth credentials on line %d", line)
+		}
+
+		a.users[user] = []byte(hash)
+	}
+
+	return a, scanner.Err()
+}
+
+// Authenticate implements Authenticator.
+func (a *BasicAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrMissingCredentials
+	}
+
+	hash, ok := a.users[user]
+	if !ok || bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Principal{Name: user, Method: MethodBasic}, nil
+}
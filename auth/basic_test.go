@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBasicAuthenticator_Authenticate(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	a, err := NewBasicAuthenticator(strings.NewReader("# ops team\n\nwaheed:" + string(hash) + "\n"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name              string
+		setAuth           func(r *http.Request)
+		expectedPrincipal *Principal
+		expectedErr       error
+	}{
+		{
+			name:              "valid credentials",
+			setAuth:           func(r *http.Request) { r.SetBasicAuth("waheed", "secret") },
+			expectedPrincipal: &Principal{Name: "waheed", Method: MethodBasic},
+		},
+		{
+			name:        "missing credentials",
+			setAuth:     func(*http.Request) {},
+			expectedErr: ErrMissingCredentials,
+		},
+		{
+			name:        "wrong password",
+			setAuth:     func(r *http.Request) { r.SetBasicAuth("waheed", "guess") },
+			expectedErr: ErrInvalidCredentials,
+		},
+		{
+			name:        "unknown user",
+			setAuth:     func(r *http.Request) { r.SetBasicAuth("albert", "secret") },
+			expectedErr: ErrInvalidCredentials,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user", http.NoBody)
+			tt.setAuth(req)
+
+			principal, err := a.Authenticate(req)
+
+			assert.Equal(t, tt.expectedPrincipal, principal, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestNewBasicAuthenticator_InvalidLine(t *testing.T) {
+	_, err := NewBasicAuthenticator(strings.NewReader("waheed\n"))
+
+	assert.EqualError(t, err, "invalid basic auth credentials on line 1")
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JSONWebKey is a single key of a JWKS document. Only symmetric (oct) keys for HS256
+// and RSA public keys for RS256 are supported.
+type JSONWebKey struct {
+	KeyID     string `json:"kid"`
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg"`
+	K         string `json:"k,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JSONWebKeySet is a JWKS document as described by RFC 7517.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type verificationKey struct {
+	id        string
+	algorithm string
+	key       any
+}
+
+// tokenClaims are the claims read from a bearer token.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+// JWTAuthenticator authenticates requests carrying an HS256 or RS256 signed bearer token.
+// The subject claim of the token becomes the principal name and the roles claim its roles.
+type JWTAuthenticator struct {
+	keys   []verificationKey
+	parser *jwt.Parser
+}
+
+// NewJWTAuthenticatorFromFile loads the JWKS document at path. Issuer and audience are
+// only checked when non-empty.
+func NewJWTAuthenticatorFromFile(path, issuer, audience string) (*JWTAuthenticator, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading jwks: %w", err)
+	}
+
+	var set JSONWebKeySet
+	if err = json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("parsing jwks: %w", err)
+	}
+
+	return NewJWTAuthenticator(set, issuer, audience)
+}
+
+// NewJWTAuthenticator returns a JWTAuthenticator trusting the keys of set.
+func NewJWTAuthenticator(set JSONWebKeySet, issuer, audience string) (*JWTAuthenticator, error) {
+	a := &JWTAuthenticator{}
+
+	for _, k := range set.Keys {
+		key, err := k.verificationKey()
+		if err != nil {
+			return nil, err
+		}
+
+		a.keys = append(a.keys, key)
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
+	a.parser = jwt.NewParser(options...)
+
+	return a, nil
+}
+
+func (k JSONWebKey) verificationKey() (verificationKey, error) {
+	switch k.KeyType {
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return verificationKey{}, fmt.Errorf("invalid oct key %q", k.KeyID)
+		}
+
+		return verificationKey{id: k.KeyID, algorithm: jwt.SigningMethodHS256.Alg(), key: secret}, nil
+	case "RSA":
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 {
+			return verificationKey{}, fmt.Errorf("invalid RSA key %q", k.KeyID)
+		}
+
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+		return verificationKey{id: k.KeyID, algorithm: jwt.SigningMethodRS256.Alg(), key: key}, nil
+	default:
+		return verificationKey{}, fmt.Errorf("unsupported key type %q for key %q", k.KeyType, k.KeyID)
+	}
+}
+
+// Authenticate implements Authenticator.
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	var claims tokenClaims
+
+	if _, err := a.parser.ParseWithClaims(token, &claims, a.keyFunc); err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredCredentials
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidCredentials)
+	}
+
+	return &Principal{Name: claims.Subject, Method: MethodJWT, Roles: claims.Roles}, nil
+}
+
+// keyFunc picks the key matching the token's kid header. The key must also match the
+// signing algorithm, so an RSA public key can never be used as an HMAC secret.
+func (a *JWTAuthenticator) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	for _, k := range a.keys {
+		if k.algorithm == token.Method.Alg() && (kid == "" || k.id == kid) {
+			return k.key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no key found for kid %q", kid)
+}
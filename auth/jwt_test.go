@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTAuthenticator_Authenticate(t *testing.T) {
+	secret := []byte("hmac-secret")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	a, err := NewJWTAuthenticator(JSONWebKeySet{Keys: []JSONWebKey{
+		{KeyID: "hs", KeyType: "oct", K: base64.RawURLEncoding.EncodeToString(secret)},
+		{KeyID: "rs", KeyType: "RSA",
+			N: base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+	}}, "gofrProject", "")
+	require.NoError(t, err)
+
+	sign := func(method jwt.SigningMethod, kid string, key any, claims jwt.RegisteredClaims) string {
+		token := jwt.NewWithClaims(method, tokenClaims{RegisteredClaims: claims, Roles: []string{"editor"}})
+		token.Header["kid"] = kid
+
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+
+		return signed
+	}
+
+	valid := jwt.RegisteredClaims{
+		Subject:   "waheed",
+		Issuer:    "gofrProject",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	otherIssuer := valid
+	otherIssuer.Issuer = "someone-else"
+
+	tests := []struct {
+		name              string
+		authorization     string
+		expectedPrincipal *Principal
+		expectedErr       error
+	}{
+		{
+			name:              "valid HS256 token",
+			authorization:     "Bearer " + sign(jwt.SigningMethodHS256, "hs", secret, valid),
+			expectedPrincipal: &Principal{Name: "waheed", Method: MethodJWT, Roles: []string{"editor"}},
+		},
+		{
+			name:              "valid RS256 token",
+			authorization:     "Bearer " + sign(jwt.SigningMethodRS256, "rs", rsaKey, valid),
+			expectedPrincipal: &Principal{Name: "waheed", Method: MethodJWT, Roles: []string{"editor"}},
+		},
+		{
+			name:          "missing token",
+			authorization: "",
+			expectedErr:   ErrMissingCredentials,
+		},
+		{
+			name:          "expired token",
+			authorization: "Bearer " + sign(jwt.SigningMethodHS256, "hs", secret, expired),
+			expectedErr:   ErrExpiredCredentials,
+		},
+		{
+			name:          "wrong issuer",
+			authorization: "Bearer " + sign(jwt.SigningMethodHS256, "hs", secret, otherIssuer),
+			expectedErr:   ErrInvalidCredentials,
+		},
+		{
+			name:          "HS256 token signed with unknown secret",
+			authorization: "Bearer " + sign(jwt.SigningMethodHS256, "hs", []byte("guess"), valid),
+			expectedErr:   ErrInvalidCredentials,
+		},
+		{
+			name:          "HS256 token pointing at the RSA key",
+			authorization: "Bearer " + sign(jwt.SigningMethodHS256, "rs", secret, valid),
+			expectedErr:   ErrInvalidCredentials,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user", http.NoBody)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			principal, err := a.Authenticate(req)
+
+			assert.Equal(t, tt.expectedPrincipal, principal, "TEST[%d] failed: %s", i, tt.name)
+			assert.ErrorIs(t, err, tt.expectedErr, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package authz
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+
+	"gofrProject/auth"
+	"gofrProject/entities"
+	"gofrProject/problem"
+)
+
+// Role groups the permissions granted to a principal.
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// Permission is an action a route requires.
+type Permission string
+
+const (
+	ReadUsers        Permission = "users:read"
+	ReadDeletedUsers Permission = "users:read_deleted"
+	CreateUsers      Permission = "users:create"
+	UpdateUsers      Permission = "users:update"
+	DeleteUsers      Permission = "users:delete"
+	RestoreUsers     Permission = "users:restore"
+	PurgeUsers       Permission = "users:purge"
+	ReadAudit        Permission = "audit:read"
+	BatchUsers       Permission = "users:batch"
+	ExportUsers      Permission = "users:export"
+	ImportUsers      Permission = "users:import"
+	ReindexUsers     Permission = "users:reindex"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleReader: {ReadUsers},
+	RoleEditor: {ReadUsers, CreateUsers, UpdateUsers, BatchUsers, ExportUsers, ImportUsers},
+	RoleAdmin: {ReadUsers, ReadDeletedUsers, CreateUsers, UpdateUsers, DeleteUsers, RestoreUsers, PurgeUsers, ReadAudit,
+		BatchUsers, ExportUsers, ImportUsers, ReindexUsers},
+}
+
+// Rule grants access to a route independently of the principal's roles.
+type Rule func(ctx *gofr.Context, principal *auth.Principal) bool
+
+// Owner grants access when the path parameter param names the principal itself. User names are
+// unique regardless of case, so they are compared that way.
+func Owner(param string) Rule {
+	return func(ctx *gofr.Context, principal *auth.Principal) bool {
+		return strings.EqualFold(ctx.PathParam(param), principal.Name)
+	}
+}
+
+// UserLookup returns the user identified by a path parameter.
+type UserLookup func(id string, ctx *gofr.Context) (entities.Users, error)
+
+// OwnerByID grants access when the path parameter param holds the id of the principal's own user.
+func OwnerByID(param string, lookup UserLookup) Rule {
+	return func(ctx *gofr.Context, principal *auth.Principal) bool {
+		user, err := lookup(ctx.PathParam(param), ctx)
+
+		return err == nil && strings.EqualFold(user.UserName, principal.Name)
+	}
+}
+
+// ErrForbidden is returned when the principal may not perform the requested action.
+type ErrForbidden struct {
+	Principal  string
+	Permission Permission
+}
+
+func (e ErrForbidden) Error() string {
+	return fmt.Sprintf("principal '%s' lacks permission '%s'", e.Principal, e.Permission)
+}
+
+func (ErrForbidden) StatusCode() int {
+	return http.StatusForbidden
+}
+
+// ErrUnauthenticated is returned when no principal is attached to the request.
+type ErrUnauthenticated struct{}
+
+func (ErrUnauthenticated) Error() string {
+	return "request is not authenticated"
+}
+
+func (ErrUnauthenticated) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+// Config is the part of GoFr's config.Config needed to build a Policy.
+type Config interface {
+	Get(key string) string
+}
+
+// Policy decides which principals may call which routes.
+type Policy struct {
+	roles map[string][]Role
+}
+
+// NewPolicy returns a Policy assigning roles to principals from AUTHZ_ROLES, a comma separated
+// list of principal:role pairs such as "local:admin,ci:reader". Roles carried by the principal
+// itself, e.g. from a JWT roles claim, are honoured as well.
+func NewPolicy(c Config) (*Policy, error) {
+	p := &Policy{roles: make(map[string][]Role)}
+
+	for _, entry := range strings.Split(c.Get("AUTHZ_ROLES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, role, ok := strings.Cut(entry, ":")
+		if _, known := rolePermissions[Role(role)]; !ok || name == "" || !known {
+			return nil, fmt.Errorf("invalid role assignment %q", entry)
+		}
+
+		p.roles[name] = append(p.roles[name], Role(role))
+	}
+
+	return p, nil
+}
+
+// Require wraps handler so that it only runs for principals holding permission through one of
+// their roles, or satisfying one of the rules.
+func (p *Policy) Require(permission Permission, handler gofr.Handler, rules ...Rule) gofr.Handler {
+	return func(ctx *gofr.Context) (any, error) {
+		principal, ok := auth.PrincipalFromContext(ctx)
+		if !ok {
+			return problem.Respond(ErrUnauthenticated{})
+		}
+
+		if p.Allowed(principal, permission) {
+			return handler(ctx)
+		}
+
+		for _, rule := range rules {
+			if rule(ctx, principal) {
+				return handler(ctx)
+			}
+		}
+
+		return problem.Respond(ErrForbidden{Principal: principal.Name, Permission: permission})
+	}
+}
+
+// batchPermissions are the permissions of the single-user routes of the operations of a batch request.
+var batchPermissions = map[string]Permission{
+	entities.BatchCreate: CreateUsers,
+	entities.BatchUpdate: UpdateUsers,
+	entities.BatchDelete: DeleteUsers,
+}
+
+// Authorize returns the error to respond with unless the principal of the request holds permission
+// through one of its roles.
+func (p *Policy) Authorize(ctx *gofr.Context, permission Permission) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated{}
+	}
+
+	if !p.Allowed(principal, permission) {
+		return ErrForbidden{Principal: principal.Name, Permission: permission}
+	}
+
+	return nil
+}
+
+// AuthorizeBatch authorizes an operation of a batch request like the single-user route it stands
+// for. Rules such as Owner do not apply to batches. Unknown operations are left to the service to reject.
+func (p *Policy) AuthorizeBatch(op string, ctx *gofr.Context) error {
+	permission, ok := batchPermissions[op]
+	if !ok {
+		return nil
+	}
+
+	return p.Authorize(ctx, permission)
+}
+
+// Allowed reports whether any role of the principal grants the permission.
+func (p *Policy) Allowed(principal *auth.Principal, permission Permission) bool {
+	roles := slices.Clone(p.roles[principal.Name])
+	for _, role := range principal.Roles {
+		roles = append(roles, Role(role))
+	}
+
+	for _, role := range roles {
+		if slices.Contains(rolePermissions[role], permission) {
+			return true
+		}
+	}
+
+	return false
+}
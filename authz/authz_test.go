@@ -0,0 +1,241 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gofr.dev/pkg/gofr"
+	gofrHttp "gofr.dev/pkg/gofr/http"
+	"gofr.dev/pkg/gofr/http/response"
+
+	"gofrProject/auth"
+	"gofrProject/entities"
+	"gofrProject/problem"
+)
+
+type mockConfig map[string]string
+
+func (c mockConfig) Get(key string) string {
+	return c[key]
+}
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		roles       string
+		expectedErr bool
+	}{
+		{name: "no assignments", roles: "", expectedErr: false},
+		{name: "valid assignments", roles: "local:admin, ci:reader,ci:editor", expectedErr: false},
+		{name: "unknown role", roles: "local:root", expectedErr: true},
+		{name: "missing role", roles: "local", expectedErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPolicy(mockConfig{"AUTHZ_ROLES": tt.roles})
+
+			assert.Equal(t, tt.expectedErr, err != nil, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_Require(t *testing.T) {
+	policy, err := NewPolicy(mockConfig{"AUTHZ_ROLES": "alice:admin,bob:editor,carol:reader"})
+	assert.NoError(t, err)
+
+	handler := func(*gofr.Context) (any, error) {
+		return "ok", nil
+	}
+
+	tests := []struct {
+		name             string
+		principal        *auth.Principal
+		permission       Permission
+		rules            []Rule
+		pathParam        string
+		expectedResponse interface{}
+		expectedErr      error
+	}{
+		{
+			name:             "admin may delete",
+			principal:        &auth.Principal{Name: "alice"},
+			permission:       DeleteUsers,
+			pathParam:        "waheed",
+			expectedResponse: "ok",
+		},
+		{
+			name:             "editor may update others",
+			principal:        &auth.Principal{Name: "bob"},
+			permission:       UpdateUsers,
+			rules:            []Rule{Owner("name")},
+			pathParam:        "waheed",
+			expectedResponse: "ok",
+		},
+		{
+			name:             "reader may read",
+			principal:        &auth.Principal{Name: "carol"},
+			permission:       ReadUsers,
+			pathParam:        "waheed",
+			expectedResponse: "ok",
+		},
+		{
+			name:             "roles from the principal are honoured",
+			principal:        &auth.Principal{Name: "dave", Roles: []string{"editor"}},
+			permission:       CreateUsers,
+			expectedResponse: "ok",
+		},
+		{
+			name:             "owner may update own record",
+			principal:        &auth.Principal{Name: "carol"},
+			permission:       UpdateUsers,
+			rules:            []Rule{Owner("name")},
+			pathParam:        "carol",
+			expectedResponse: "ok",
+		},
+		{
+			name:             "owner may update own record named in another case",
+			principal:        &auth.Principal{Name: "carol"},
+			permission:       UpdateUsers,
+			rules:            []Rule{Owner("name")},
+			pathParam:        "Carol",
+			expectedResponse: "ok",
+		},
+		{
+			name:       "reader may not update others",
+			principal:  &auth.Principal{Name: "carol"},
+			permission: UpdateUsers,
+			rules:      []Rule{Owner("name")},
+			pathParam:  "waheed",
+			expectedResponse: response.File{ContentType: problem.ContentType, Content: []byte(`{"type":"about:blank",` +
+				`"title":"Forbidden","status":403,"detail":"principal 'carol' lacks permission 'users:update'"}`)},
+			expectedErr: ErrForbidden{Principal: "carol", Permission: UpdateUsers},
+		},
+		{
+			name:       "editor may not delete",
+			principal:  &auth.Principal{Name: "bob"},
+			permission: DeleteUsers,
+			pathParam:  "waheed",
+			expectedResponse: response.File{ContentType: problem.ContentType, Content: []byte(`{"type":"about:blank",` +
+				`"title":"Forbidden","status":403,"detail":"principal 'bob' lacks permission 'users:delete'"}`)},
+			expectedErr: ErrForbidden{Principal: "bob", Permission: DeleteUsers},
+		},
+		{
+			name:       "unauthenticated request",
+			principal:  nil,
+			permission: ReadUsers,
+			expectedResponse: response.File{ContentType: problem.ContentType, Content: []byte(`{"type":"about:blank",` +
+				`"title":"Unauthorized","status":401,"detail":"request is not authenticated"}`)},
+			expectedErr: ErrUnauthenticated{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/user/{name}", nil)
+			req.Header.Set("Content-Type", "application/json")
+
+			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{"name": test.pathParam}))
+
+			ctx := context.Background()
+			if test.principal != nil {
+				ctx = auth.WithPrincipal(ctx, test.principal)
+			}
+
+			c := &gofr.Context{
+				Context: ctx,
+				Request: gofrR,
+			}
+
+			res, err := policy.Require(test.permission, handler, test.rules...)(c)
+
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedResponse, res)
+		})
+	}
+}
+
+func TestOwnerByID(t *testing.T) {
+	lookup := func(id string, _ *gofr.Context) (entities.Users, error) {
+		if id != "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f" {
+			return entities.Users{}, errors.New("user not found")
+		}
+
+		return entities.Users{ID: id, UserName: "carol"}, nil
+	}
+
+	tests := []struct {
+		name      string
+		principal string
+		id        string
+		expected  bool
+	}{
+		{name: "own record", principal: "carol", id: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", expected: true},
+		{name: "own record named in another case", principal: "Carol", id: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", expected: true},
+		{name: "someone else's record", principal: "bob", id: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", expected: false},
+		{name: "unknown id", principal: "carol", id: "0193c6b8-7d2a-7c3e-9f41-000000000000", expected: false},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/users/{id}", nil)
+			c := &gofr.Context{
+				Context: context.Background(),
+				Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{"id": test.id})),
+			}
+
+			allowed := OwnerByID("id", lookup)(c, &auth.Principal{Name: test.principal})
+
+			assert.Equal(t, test.expected, allowed, "TEST[%d] failed: %s", i, test.name)
+		})
+	}
+}
+
+func TestAuthorizeBatch(t *testing.T) {
+	policy, err := NewPolicy(mockConfig{"AUTHZ_ROLES": "alice:admin,bob:editor,carol:reader"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		principal   *auth.Principal
+		op          string
+		expectedErr error
+	}{
+		{name: "admin deletes", principal: &auth.Principal{Name: "alice"}, op: entities.BatchDelete, expectedErr: nil},
+		{name: "editor creates", principal: &auth.Principal{Name: "bob"}, op: entities.BatchCreate, expectedErr: nil},
+		{name: "editor updates", principal: &auth.Principal{Name: "bob"}, op: entities.BatchUpdate, expectedErr: nil},
+		{name: "editor deletes", principal: &auth.Principal{Name: "bob"}, op: entities.BatchDelete,
+			expectedErr: ErrForbidden{Principal: "bob", Permission: DeleteUsers}},
+		{name: "reader creates", principal: &auth.Principal{Name: "carol"}, op: entities.BatchCreate,
+			expectedErr: ErrForbidden{Principal: "carol", Permission: CreateUsers}},
+		{name: "unknown operation", principal: &auth.Principal{Name: "carol"}, op: "upsert", expectedErr: nil},
+		{name: "no principal", principal: nil, op: entities.BatchCreate, expectedErr: ErrUnauthenticated{}},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &gofr.Context{Context: context.Background()}
+			if test.principal != nil {
+				c.Context = auth.WithPrincipal(c.Context, test.principal)
+			}
+
+			err := policy.AuthorizeBatch(test.op, c)
+
+			assert.Equal(t, test.expectedErr, err, "TEST[%d] failed: %s", i, test.name)
+		})
+	}
+}
+
+func TestErrForbidden_StatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusForbidden, ErrForbidden{}.StatusCode())
+	assert.Equal(t, http.StatusUnauthorized, ErrUnauthenticated{}.StatusCode())
+}
@@ -0,0 +1,275 @@
+// Package cache keeps users read from the store in GoFr's Redis datasource.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"gofr.dev/pkg/gofr"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"gofrProject/service"
+	"golang.org/x/sync/singleflight"
+	"strings"
+	"time"
+)
+
+// Default lifetimes of cached users and of cached misses.
+const (
+	DefaultTTL         = 5 * time.Minute
+	DefaultNegativeTTL = 30 * time.Second
+)
+
+// Users is a read-through cache in front of a service.UserStore. GetUsersByName is served from
+// ctx.Redis when possible, misses are cached for a shorter time so that lookups of unknown names
+// do not reach the store either. Writes go to the store and evict the users they changed.
+//
+// Concurrent misses for the same name in one process share a single store query. Every eviction
+// of a name increments its generation, a user read from the store is only cached if the
+// generation of its name did not change meanwhile, so that a write evicting the name while it is
+// read from the store is not undone by the stale read. Redis errors are logged and the store is
+// used instead, the cache never fails a request.
+type Users struct {
+	service.UserStore
+
+	ttl         time.Duration
+	negativeTTL time.Duration
+	group       singleflight.Group
+}
+
+// Option configures Users.
+type Option func(u *Users)
+
+// WithTTL sets how long a user is cached.
+func WithTTL(ttl time.Duration) Option {
+	return func(u *Users) {
+		u.ttl = ttl
+	}
+}
+
+// WithNegativeTTL sets how long the absence of a user is cached.
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(u *Users) {
+		u.negativeTTL = ttl
+	}
+}
+
+// New returns a cache in front of store.
+func New(store service.UserStore, opts ...Option) *Users {
+	u := &Users{UserStore: store, ttl: DefaultTTL, negativeTTL: DefaultNegativeTTL}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
+}
+
+// entry is the cached value of a user name. Version and the timestamps are stored separately as
+// entities.Users does not marshal them.
+type entry struct {
+	Missing   bool           `json:"missing,omitempty"`
+	User      entities.Users `json:"user"`
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// nameKey returns the Redis key of a user name. Names are unique regardless of case.
+func nameKey(name string) string {
+	return "user:name:" + strings.ToLower(name)
+}
+
+// generationKey returns the Redis key of the generation of a user name.
+func generationKey(name string) string {
+	return "user:generation:" + strings.ToLower(name)
+}
+
+// setIfCurrent sets KEYS[1] to ARGV[2] for ARGV[3] milliseconds unless the generation KEYS[2] is
+// no longer ARGV[1], an empty string standing for a generation that does not exist.
+var setIfCurrent = redis.NewScript(`
+if (redis.call("GET", KEYS[2]) or "") ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// GetUsersByName returns the cached user with the given name, reading it from the store on a miss.
+func (u *Users) GetUsersByName(name string, ctx *gofr.Context) (entities.Users, error) {
+	key := nameKey(name)
+
+	if cached, ok := u.get(key, ctx); ok {
+		if cached.Missing {
+			return entities.Users{}, apperrors.NotFound("user", "name", name)
+		}
+
+		cached.User.Version, cached.User.CreatedAt, cached.User.UpdatedAt = cached.Version, cached.CreatedAt, cached.UpdatedAt
+
+		return cached.User, nil
+	}
+
+	user, err, _ := u.group.Do(key, func() (any, error) {
+		return u.load(name, detached(ctx))
+	})
+
+	return user.(entities.Users), err
+}
+
+// load reads the user with the given name from the store and caches it, or its absence, unless
+// the name is evicted meanwhile.
+func (u *Users) load(name string, ctx *gofr.Context) (entities.Users, error) {
+	generation, ok := u.generation(name, ctx)
+
+	user, err := u.UserStore.GetUsersByName(name, ctx)
+	if !ok {
+		return user, err
+	}
+
+	switch {
+	case err == nil:
+		u.set(name, generation, entry{User: user, Version: user.Version, CreatedAt: user.CreatedAt, UpdatedAt: user.UpdatedAt},
+			u.ttl, ctx)
+	case apperrors.IsNotFound(err):
+		u.set(name, generation, entry{Missing: true}, u.negativeTTL, ctx)
+	}
+
+	return user, err
+}
+
+// detached returns a copy of ctx that is not canceled with it. The store query of a miss is
+// shared by every reader of the name, it must not fail for all of them when the first one is gone.
+func detached(ctx *gofr.Context) *gofr.Context {
+	c := *ctx
+	c.Context = context.WithoutCancel(ctx.Context)
+
+	return &c
+}
+
+// AddUsers adds the user and evicts the cached miss of its name.
+func (u *Users) AddUsers(user *entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	defer u.evict(ctx, user.UserName)
+
+	return u.UserStore.AddUsers(user, change, ctx)
+}
+
+// UpdateUsers updates the user and evicts it.
+func (u *Users) UpdateUsers(updateUser *entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	defer u.evict(ctx, updateUser.UserName)
+
+	return u.UserStore.UpdateUsers(updateUser, change, ctx)
+}
+
+// DeleteUsers deletes the user and evicts it.
+func (u *Users) DeleteUsers(user entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	defer u.evict(ctx, user.UserName)
+
+	return u.UserStore.DeleteUsers(user, change, ctx)
+}
+
+// RestoreUsers restores the user and evicts its cached miss.
+func (u *Users) RestoreUsers(user entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	defer u.evict(ctx, user.UserName)
+
+	return u.UserStore.RestoreUsers(user, change, ctx)
+}
+
+// RenameUsers renames the user and evicts both its old and its new name.
+func (u *Users) RenameUsers(id, name string, version int, change *entities.Change, ctx *gofr.Context) error {
+	names := []string{name}
+
+	if user, err := u.UserStore.GetUsersByID(id, ctx); err == nil {
+		names = append(names, user.UserName)
+	}
+
+	defer u.evict(ctx, names...)
+
+	return u.UserStore.RenameUsers(id, name, version, change, ctx)
+}
+
+// WriteBatch applies the writes and evicts every user they named.
+func (u *Users) WriteBatch(writes []entities.BatchWrite, atomic bool, ctx *gofr.Context) []error {
+	names := make([]string, len(writes))
+	for i := range writes {
+		names[i] = writes[i].User.UserName
+	}
+
+	defer u.evict(ctx, names...)
+
+	return u.UserStore.WriteBatch(writes, atomic, ctx)
+}
+
+func (u *Users) get(key string, ctx *gofr.Context) (entry, bool) {
+	b, err := ctx.Redis.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			ctx.Errorf("reading %s from cache: %v", key, err)
+		}
+
+		return entry{}, false
+	}
+
+	var cached entry
+	if err := json.Unmarshal(b, &cached); err != nil {
+		ctx.Errorf("decoding %s from cache: %v", key, err)
+
+		return entry{}, false
+	}
+
+	return cached, true
+}
+
+// generation returns the generation of a user name, false if it cannot be read.
+func (u *Users) generation(name string, ctx *gofr.Context) (string, bool) {
+	key := generationKey(name)
+
+	generation, err := ctx.Redis.Get(ctx, key).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		ctx.Errorf("reading %s from cache: %v", key, err)
+
+		return "", false
+	}
+
+	return generation, true
+}
+
+// set caches the entry of a user name unless its generation is no longer generation.
+func (u *Users) set(name, generation string, cached entry, ttl time.Duration, ctx *gofr.Context) {
+	key := nameKey(name)
+
+	b, err := json.Marshal(cached)
+	if err != nil {
+		ctx.Errorf("encoding %s for cache: %v", key, err)
+
+		return
+	}
+
+	err = setIfCurrent.Run(ctx, ctx.Redis, []string{key, generationKey(name)}, generation, b, ttl.Milliseconds()).Err()
+	if err != nil {
+		ctx.Errorf("writing %s to cache: %v", key, err)
+	}
+}
+
+// evict removes the cached entries of the names and increments their generations. A generation
+// lives as long as a cached user, far longer than it takes to read one from the store.
+func (u *Users) evict(ctx *gofr.Context, names ...string) {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, nameKey(name))
+	}
+
+	_, err := ctx.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+
+		for _, name := range names {
+			pipe.Incr(ctx, generationKey(name))
+			pipe.Expire(ctx, generationKey(name), u.ttl)
+		}
+
+		return nil
+	})
+	if err != nil {
+		ctx.Errorf("evicting %v from cache: %v", keys, err)
+	}
+}
@@ -0,0 +1,289 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"gofrProject/service"
+)
+
+const testUserID = "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f"
+
+// testRedis adapts a go-redis client to GoFr's Redis datasource.
+type testRedis struct {
+	*redis.Client
+}
+
+func (testRedis) HealthCheck(context.Context) (any, error) {
+	return nil, nil
+}
+
+func newTestContext(t *testing.T) (*gofr.Context, *miniredis.Miniredis) {
+	mockContainer, _ := container.NewMockContainer(t)
+	server := miniredis.RunT(t)
+	mockContainer.Redis = testRedis{redis.NewClient(&redis.Options{Addr: server.Addr()})}
+
+	return &gofr.Context{Context: context.Background(), Container: mockContainer}, server
+}
+
+func Test_GetUsersByName(t *testing.T) {
+	user := entities.Users{ID: testUserID, UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "waheed@example.com", CreatedAt: time.Date(2025, 1, 20, 12, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2025, 1, 21, 12, 0, 0, 0, time.UTC), Version: 3}
+
+	tests := []struct {
+		name          string
+		mockExpect    func(mockStore *service.MockUserStore)
+		prepare       func(server *miniredis.Miniredis)
+		expectedUser  entities.Users
+		expectedError error
+	}{
+		{
+			name: "user is read from the store once",
+			mockExpect: func(mockStore *service.MockUserStore) {
+				mockStore.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(user, nil).Times(1)
+			},
+			prepare:      func(*miniredis.Miniredis) {},
+			expectedUser: user,
+		},
+		{
+			name: "miss is read from the store once",
+			mockExpect: func(mockStore *service.MockUserStore) {
+				mockStore.EXPECT().GetUsersByName("waheed", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "name", "waheed")).Times(1)
+			},
+			prepare:       func(*miniredis.Miniredis) {},
+			expectedError: apperrors.NotFound("user", "name", "waheed"),
+		},
+		{
+			name: "store errors are not cached",
+			mockExpect: func(mockStore *service.MockUserStore) {
+				mockStore.EXPECT().GetUsersByName("waheed", gomock.Any()).
+					Return(entities.Users{}, apperrors.Unavailable(nil)).Times(2)
+			},
+			prepare:       func(*miniredis.Miniredis) {},
+			expectedError: apperrors.Unavailable(nil),
+		},
+		{
+			name: "redis unavailable",
+			mockExpect: func(mockStore *service.MockUserStore) {
+				mockStore.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(user, nil).Times(2)
+			},
+			prepare:      func(server *miniredis.Miniredis) { server.Close() },
+			expectedUser: user,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := service.NewMockUserStore(gomock.NewController(t))
+			ctx, server := newTestContext(t)
+			users := New(mockStore)
+
+			tt.mockExpect(mockStore)
+			tt.prepare(server)
+
+			for range 2 {
+				got, err := users.GetUsersByName("waheed", ctx)
+
+				assert.Equal(t, tt.expectedUser, got, "TEST[%d] failed: %s", i, tt.name)
+				assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
+			}
+		})
+	}
+}
+
+func Test_GetUsersByName_Expiry(t *testing.T) {
+	mockStore := service.NewMockUserStore(gomock.NewController(t))
+	ctx, server := newTestContext(t)
+	users := New(mockStore, WithTTL(time.Minute), WithNegativeTTL(time.Second))
+
+	gomock.InOrder(
+		mockStore.EXPECT().GetUsersByName("waheed", gomock.Any()).
+			Return(entities.Users{}, apperrors.NotFound("user", "name", "waheed")),
+		mockStore.EXPECT().GetUsersByName("WAHEED", gomock.Any()).
+			Return(entities.Users{ID: testUserID, UserName: "waheed"}, nil),
+	)
+
+	_, err := users.GetUsersByName("waheed", ctx)
+	assert.True(t, apperrors.IsNotFound(err))
+
+	server.FastForward(time.Second)
+
+	user, err := users.GetUsersByName("WAHEED", ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, testUserID, user.ID)
+	assert.Equal(t, time.Minute, server.TTL("user:name:waheed"))
+}
+
+func Test_GetUsersByName_Stampede(t *testing.T) {
+	mockStore := service.NewMockUserStore(gomock.NewController(t))
+	ctx, _ := newTestContext(t)
+	users := New(mockStore)
+	release := make(chan struct{})
+
+	mockStore.EXPECT().GetUsersByName("waheed", gomock.Any()).
+		DoAndReturn(func(string, *gofr.Context) (entities.Users, error) {
+			<-release
+
+			return entities.Users{ID: testUserID, UserName: "waheed"}, nil
+		}).Times(1)
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			user, err := users.GetUsersByName("waheed", ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, testUserID, user.ID)
+		}()
+	}
+
+	// Give every reader the time to miss the cache and join the query in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+}
+
+func Test_GetUsersByName_EvictedWhileLoading(t *testing.T) {
+	mockStore := service.NewMockUserStore(gomock.NewController(t))
+	ctx, server := newTestContext(t)
+	users := New(mockStore)
+
+	gomock.InOrder(
+		mockStore.EXPECT().GetUsersByName("waheed", gomock.Any()).
+			DoAndReturn(func(string, *gofr.Context) (entities.Users, error) {
+				// A write of another instance evicts the name after the stale user was read.
+				users.evict(ctx, "waheed")
+
+				return entities.Users{ID: testUserID, UserName: "waheed", Version: 1}, nil
+			}),
+		mockStore.EXPECT().GetUsersByName("waheed", gomock.Any()).
+			Return(entities.Users{ID: testUserID, UserName: "waheed", Version: 2}, nil),
+	)
+
+	user, err := users.GetUsersByName("waheed", ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, user.Version)
+	assert.False(t, server.Exists("user:name:waheed"))
+
+	for range 2 {
+		user, err = users.GetUsersByName("waheed", ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, user.Version)
+	}
+}
+
+func Test_GetUsersByName_Canceled(t *testing.T) {
+	mockStore := service.NewMockUserStore(gomock.NewController(t))
+	ctx, server := newTestContext(t)
+	users := New(mockStore)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx.Context = canceled
+
+	mockStore.EXPECT().GetUsersByName("waheed", gomock.Any()).
+		DoAndReturn(func(_ string, ctx *gofr.Context) (entities.Users, error) {
+			assert.NoError(t, ctx.Err())
+
+			return entities.Users{ID: testUserID, UserName: "waheed"}, nil
+		})
+
+	user, err := users.GetUsersByName("waheed", ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, testUserID, user.ID)
+	assert.True(t, server.Exists("user:name:waheed"))
+}
+
+func Test_Eviction(t *testing.T) {
+	user := entities.Users{ID: testUserID, UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "waheed@example.com", Version: 1}
+
+	tests := []struct {
+		name       string
+		mockExpect func(mockStore *service.MockUserStore)
+		write      func(users *Users, ctx *gofr.Context) error
+	}{
+		{
+			name: "add",
+			mockExpect: func(mockStore *service.MockUserStore) {
+				mockStore.EXPECT().AddUsers(&user, nil, gomock.Any()).Return(nil)
+			},
+			write: func(users *Users, ctx *gofr.Context) error { return users.AddUsers(&user, nil, ctx) },
+		},
+		{
+			name: "update",
+			mockExpect: func(mockStore *service.MockUserStore) {
+				mockStore.EXPECT().UpdateUsers(&user, nil, gomock.Any()).Return(nil)
+			},
+			write: func(users *Users, ctx *gofr.Context) error { return users.UpdateUsers(&user, nil, ctx) },
+		},
+		{
+			name: "delete",
+			mockExpect: func(mockStore *service.MockUserStore) {
+				mockStore.EXPECT().DeleteUsers(user, nil, gomock.Any()).Return(nil)
+			},
+			write: func(users *Users, ctx *gofr.Context) error { return users.DeleteUsers(user, nil, ctx) },
+		},
+		{
+			name: "restore",
+			mockExpect: func(mockStore *service.MockUserStore) {
+				mockStore.EXPECT().RestoreUsers(user, nil, gomock.Any()).Return(nil)
+			},
+			write: func(users *Users, ctx *gofr.Context) error { return users.RestoreUsers(user, nil, ctx) },
+		},
+		{
+			name: "rename",
+			mockExpect: func(mockStore *service.MockUserStore) {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+				mockStore.EXPECT().RenameUsers(testUserID, "abdul", 1, nil, gomock.Any()).Return(nil)
+			},
+			write: func(users *Users, ctx *gofr.Context) error {
+				return users.RenameUsers(testUserID, "abdul", 1, nil, ctx)
+			},
+		},
+		{
+			name: "batch",
+			mockExpect: func(mockStore *service.MockUserStore) {
+				mockStore.EXPECT().WriteBatch([]entities.BatchWrite{{Op: entities.BatchDelete, User: user}}, true, gomock.Any()).
+					Return([]error{nil})
+			},
+			write: func(users *Users, ctx *gofr.Context) error {
+				return users.WriteBatch([]entities.BatchWrite{{Op: entities.BatchDelete, User: user}}, true, ctx)[0]
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := service.NewMockUserStore(gomock.NewController(t))
+			ctx, server := newTestContext(t)
+			users := New(mockStore)
+
+			mockStore.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(user, nil).Times(1)
+			_, err := users.GetUsersByName("waheed", ctx)
+			assert.NoError(t, err, "TEST[%d] failed: %s", i, tt.name)
+			assert.True(t, server.Exists("user:name:waheed"), "TEST[%d] failed: %s", i, tt.name)
+
+			tt.mockExpect(mockStore)
+
+			assert.NoError(t, tt.write(users, ctx), "TEST[%d] failed: %s", i, tt.name)
+			assert.False(t, server.Exists("user:name:waheed"), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
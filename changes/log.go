@@ -0,0 +1,172 @@
+// Package changes keeps the feed of the changes of users that clients follow live, see
+// handler.StreamChanges. Changes are numbered as they are published and the last ones are kept in a
+// bounded log, so that a client reconnecting with the id of the last change it received is sent the
+// changes it missed meanwhile.
+//
+// The log lives in the memory of the process: it only sees the changes made through it and starts
+// empty when the application starts. Clients resuming from an id it does not know are told so and
+// have to read the users again.
+package changes
+
+import (
+	"gofrProject/entities"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of the size of the log and of the buffer of every subscriber.
+const (
+	DefaultSize   = 1000
+	DefaultBuffer = 64
+)
+
+// Log numbers the changes published to it, keeps the last ones and sends them to its subscribers.
+//
+// Publishing never waits for a subscriber. Every subscriber has a buffer of changes that were not
+// sent to it yet, one falling so far behind that its buffer is full is unsubscribed, and can resume
+// from the log like after a lost connection.
+type Log struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	changes     []entities.UserChange
+	size        int
+	buffer      int
+	subscribers map[*Subscription]struct{}
+}
+
+// Option configures a Log.
+type Option func(l *Log)
+
+// WithSize sets how many of the last changes are kept for the subscribers resuming.
+func WithSize(size int) Option {
+	return func(l *Log) {
+		l.size = size
+	}
+}
+
+// WithBuffer sets how many changes can wait to be sent to a subscriber before it is unsubscribed.
+func WithBuffer(buffer int) Option {
+	return func(l *Log) {
+		l.buffer = buffer
+	}
+}
+
+// NewLog returns an empty log. The ids of its changes are unique to it, so ids of the changes of
+// an earlier run of the application are not mistaken for its own.
+func NewLog(opts ...Option) *Log {
+	l := &Log{epoch: strconv.FormatInt(time.Now().UnixNano(), 36), size: DefaultSize, buffer: DefaultBuffer,
+		subscribers: make(map[*Subscription]struct{})}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Publish gives change the next id, keeps it and sends it to every subscriber.
+func (l *Log) Publish(change entities.UserChange) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+	change.ID = l.epoch + "-" + strconv.FormatUint(l.seq, 10)
+
+	l.changes = append(l.changes, change)
+	if len(l.changes) > l.size {
+		l.changes = l.changes[len(l.changes)-l.size:]
+	}
+
+	for s := range l.subscribers {
+		select {
+		case s.changes <- change:
+		default:
+			s.dropped = true
+			l.unsubscribe(s)
+		}
+	}
+}
+
+// Subscribe returns a subscription to the changes published from now on. A non-empty lastEventID is
+// the id of the last change the subscriber received, the changes published after it are in the
+// Backlog of the subscription unless they are no longer in the log.
+func (l *Log) Subscribe(lastEventID string) *Subscription {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := &Subscription{changes: make(chan entities.UserChange, l.buffer), log: l}
+
+	if lastEventID != "" {
+		s.Backlog, s.Expired = l.since(lastEventID)
+	}
+
+	l.subscribers[s] = struct{}{}
+
+	return s
+}
+
+// since returns the changes published after the change with the given id, or false if the log
+// does not have all of them.
+func (l *Log) since(id string) ([]entities.UserChange, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != l.epoch {
+		return nil, true
+	}
+
+	last, err := strconv.ParseUint(seq, 10, 64)
+	first := l.seq - uint64(len(l.changes)) + 1
+
+	if err != nil || last > l.seq || last+1 < first {
+		return nil, true
+	}
+
+	return append([]entities.UserChange(nil), l.changes[last+1-first:]...), false
+}
+
+// unsubscribe removes s from the subscribers and closes its channel, unless it was removed already.
+func (l *Log) unsubscribe(s *Subscription) {
+	if _, ok := l.subscribers[s]; !ok {
+		return
+	}
+
+	delete(l.subscribers, s)
+	close(s.changes)
+}
+
+// Subscription receives the changes published to a Log.
+type Subscription struct {
+	// Backlog holds the changes published after the one the subscriber resumed from, oldest first.
+	Backlog []entities.UserChange
+	// Expired reports that the subscriber could not resume as the log does not have all the changes
+	// after the one it resumed from. It may have missed changes and should read the users again.
+	Expired bool
+
+	changes chan entities.UserChange
+	log     *Log
+	dropped bool
+}
+
+// Changes returns the channel the changes published after the subscription are sent on. It is
+// closed when the subscription is closed or dropped.
+func (s *Subscription) Changes() <-chan entities.UserChange {
+	return s.changes
+}
+
+// Dropped reports that the subscriber was unsubscribed because it fell behind.
+func (s *Subscription) Dropped() bool {
+	s.log.mu.Lock()
+	defer s.log.mu.Unlock()
+
+	return s.dropped
+}
+
+// Close unsubscribes the subscriber.
+func (s *Subscription) Close() {
+	s.log.mu.Lock()
+	defer s.log.mu.Unlock()
+
+	s.log.unsubscribe(s)
+}
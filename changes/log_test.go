@@ -0,0 +1,135 @@
+package changes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gofrProject/entities"
+)
+
+func publish(l *Log, names ...string) {
+	for _, name := range names {
+		l.Publish(entities.UserChange{Type: "user.updated", UserName: name})
+	}
+}
+
+func userNames(changes []entities.UserChange) []string {
+	names := make([]string, len(changes))
+	for i, change := range changes {
+		names[i] = change.UserName
+	}
+
+	return names
+}
+
+// receive returns the names of the changes waiting on the channel of s.
+func receive(s *Subscription) []string {
+	var names []string
+
+	for {
+		select {
+		case change, ok := <-s.Changes():
+			if !ok {
+				return names
+			}
+
+			names = append(names, change.UserName)
+		default:
+			return names
+		}
+	}
+}
+
+func TestLog_Subscribe(t *testing.T) {
+	l := NewLog(WithSize(3))
+	other := NewLog()
+
+	publish(l, "adam", "bob")
+
+	first := l.Subscribe("")
+	defer first.Close()
+
+	publish(l, "carol", "dave", "erin")
+
+	assert.Equal(t, []string{"carol", "dave", "erin"}, receive(first))
+
+	ids := make([]string, len(l.changes))
+	for i, change := range l.changes {
+		ids[i] = change.ID
+	}
+
+	publish(other, "adam")
+
+	tests := []struct {
+		name            string
+		lastEventID     string
+		expectedBacklog []string
+		expectedExpired bool
+	}{
+		{name: "from now on", lastEventID: "", expectedBacklog: nil},
+		{name: "oldest change kept", lastEventID: ids[0], expectedBacklog: []string{"dave", "erin"}},
+		{name: "last change", lastEventID: ids[2], expectedBacklog: []string{}},
+		{name: "change dropped from the log", lastEventID: l.epoch + "-1", expectedExpired: true},
+		{name: "change before the oldest kept", lastEventID: l.epoch + "-2", expectedBacklog: []string{"carol", "dave", "erin"}},
+		{name: "change not published yet", lastEventID: l.epoch + "-6", expectedExpired: true},
+		{name: "change of another log", lastEventID: other.changes[0].ID, expectedExpired: true},
+		{name: "not an id", lastEventID: "carol", expectedExpired: true},
+	}
+
+	for i, tt := range tests {
+		s := l.Subscribe(tt.lastEventID)
+
+		assert.Equal(t, tt.expectedExpired, s.Expired, "TEST[%d] failed: %s", i, tt.name)
+
+		if tt.expectedBacklog != nil {
+			assert.Equal(t, tt.expectedBacklog, userNames(s.Backlog), "TEST[%d] failed: %s", i, tt.name)
+		} else {
+			assert.Empty(t, s.Backlog, "TEST[%d] failed: %s", i, tt.name)
+		}
+
+		s.Close()
+	}
+}
+
+func TestLog_SlowSubscriber(t *testing.T) {
+	l := NewLog(WithBuffer(2))
+
+	slow := l.Subscribe("")
+	fast := l.Subscribe("")
+	defer fast.Close()
+
+	publish(l, "adam", "bob")
+	assert.Equal(t, []string{"adam", "bob"}, receive(fast))
+
+	publish(l, "carol")
+
+	assert.Equal(t, []string{"carol"}, receive(fast), "other subscribers are not held back")
+	assert.Equal(t, []string{"adam", "bob"}, receive(slow), "changes buffered before the subscriber was dropped")
+	assert.True(t, slow.Dropped())
+	assert.False(t, fast.Dropped())
+
+	resumed := l.Subscribe(l.changes[1].ID)
+	defer resumed.Close()
+
+	assert.False(t, resumed.Expired)
+	assert.Equal(t, []string{"carol"}, userNames(resumed.Backlog), "dropped subscriber resumes from the log")
+
+	slow.Close()
+}
+
+func TestSubscription_Close(t *testing.T) {
+	l := NewLog()
+
+	s := l.Subscribe("")
+	s.Close()
+	s.Close()
+
+	publish(l, "adam")
+
+	_, ok := <-s.Changes()
+	require.False(t, ok)
+	assert.False(t, s.Dropped())
+	assert.Empty(t, l.subscribers)
+}
@@ -0,0 +1,278 @@
+// Package client is a Go client of version 2 of the users API, served under /v2/users.
+//
+// A Client authenticates its requests like the server expects, retries the requests that failed
+// for a reason that may go away, with a randomised exponential backoff, and returns the problem
+// details the API responds with as an *Error:
+//
+//	c := client.New("http://localhost:9000", client.WithAPIKey(key))
+//
+//	user, err := c.Get(ctx, id)
+//	if errors.Is(err, &apperrors.Error{Kind: apperrors.KindNotFound}) {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of the retries of a Client.
+const (
+	DefaultRetries    = 3
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// apiKeyHeader is the header a Client sends its API key in.
+const apiKeyHeader = "X-API-Key"
+
+// Client calls the users API at a base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	authorize  func(r *http.Request)
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client.
+type Option func(c *Client)
+
+// WithHTTPClient sends the requests with httpClient instead of http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates the requests with an API key, for servers with AUTH_METHOD api_key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.authorize = func(r *http.Request) {
+			r.Header.Set(apiKeyHeader, key)
+		}
+	}
+}
+
+// WithBasicAuth authenticates the requests with a user name and password, for servers with
+// AUTH_METHOD basic.
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) {
+		c.authorize = func(r *http.Request) {
+			r.SetBasicAuth(username, password)
+		}
+	}
+}
+
+// WithBearerToken authenticates the requests with a JWT, for servers with AUTH_METHOD jwt.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.authorize = func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+}
+
+// WithRetries sets how many times a failed request is retried, 0 to never retry.
+func WithRetries(retries int) Option {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+// WithBackoff sets the bounds of the wait before a retry. The n-th retry waits a random duration up
+// to min·2ⁿ⁻¹, and never more than max.
+func WithBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.minBackoff, c.maxBackoff = minBackoff, maxBackoff
+	}
+}
+
+// New returns a client of the API served at baseURL, e.g. http://localhost:9000.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		authorize:  func(*http.Request) {},
+		retries:    DefaultRetries,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// request is a call of the API.
+type request struct {
+	method string
+	path   string
+	header http.Header
+	body   any
+}
+
+// do sends req, retrying it while it may succeed, and decodes the data member of the response into
+// v unless v is nil. It returns the headers of the response.
+func (c *Client) do(ctx context.Context, req request, v any) (http.Header, error) {
+	var body []byte
+
+	if req.body != nil {
+		var err error
+
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("encoding the request body: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req, body)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// The outcome of the last attempt is returned when the deadline of ctx would pass before the
+		// retry.
+		if attempt < c.retries && retryable(req.method, resp, err) {
+			if wait := c.backoff(attempt, resp); !exceedsDeadline(ctx, wait) {
+				if resp != nil {
+					_, _ = io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+
+				if err := sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return resp.Header, decode(resp, v)
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request, body []byte) (*http.Response, error) {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+req.path, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range req.header {
+		r.Header[key] = values
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	c.authorize(r)
+
+	return c.httpClient.Do(r)
+}
+
+// retryable tells whether a request may succeed if it is sent again. Requests the server turned
+// away with 429 are retried, as are requests whose sending failed and requests the server failed
+// to answer with 5xx, unless they create a user, which might have been created nonetheless.
+func retryable(method string, resp *http.Response, err error) bool {
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	if method == http.MethodPost {
+		return false
+	}
+
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// backoff returns how long to wait before the retry following attempt: the time the server asked
+// for in Retry-After if any, or else a random duration up to the exponential backoff of attempt.
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	ceiling := c.maxBackoff
+	if exp := c.minBackoff << attempt; attempt < 32 && exp > 0 && exp < ceiling {
+		ceiling = exp
+	}
+
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return rand.N(ceiling + 1)
+}
+
+// exceedsDeadline tells whether the deadline of ctx passes within d.
+func exceedsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+
+	return ok && time.Until(deadline) < d
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// decode reads resp, the data member of a successful response into v, and the problem details
+// of an unsuccessful one into an *Error.
+func decode(resp *http.Response, v any) error {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading the response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return newError(resp, body)
+	}
+
+	if v == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, &struct {
+		Data any `json:"data"`
+	}{Data: v}); err != nil {
+		return fmt.Errorf("decoding the response: %w", err)
+	}
+
+	return nil
+}
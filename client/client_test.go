@@ -0,0 +1,292 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gofr.dev/pkg/gofr"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+	"gofrProject/apperrors"
+	"gofrProject/auth"
+	"gofrProject/authz"
+	"gofrProject/client"
+	"gofrProject/entities"
+	"gofrProject/handler"
+	"gofrProject/headers"
+	"gofrProject/problem"
+	"gofrProject/service"
+	"gofrProject/store"
+	"gofrProject/stream"
+	"gofrProject/validation"
+)
+
+// apiKey is the key the test server accepts, whose SHA-256 hash is in apiKeys.
+const (
+	apiKey  = "abc"
+	apiKeys = "test:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+)
+
+// handle runs h like the handlers GoFr registers: its result is written by the GoFr responder.
+func handle(h gofr.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := h(&gofr.Context{Context: r.Context(), Request: gofrHttp.NewRequest(r)})
+
+		gofrHttp.NewResponder(w, r.Method).Respond(result, err)
+	}
+}
+
+// newServer returns a server of the v2 routes of main.go on the GoFr router, backed by the memory
+// store.
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	authenticator, err := auth.NewAPIKeyAuthenticator(apiKeys, time.Now)
+	require.NoError(t, err)
+
+	h := handler.NewUserHandler(service.NewUserService(store.NewMemory()))
+
+	router := gofrHttp.NewRouter()
+	router.Add(http.MethodGet, "/v2/users", handle(h.GetUsersV2))
+	router.Add(http.MethodPost, "/v2/users", handle(h.AddUserV2))
+	router.Add(http.MethodGet, "/v2/users/{id}", handle(h.GetUserV2))
+	router.Add(http.MethodPut, "/v2/users/{id}", handle(h.UpdateUserV2))
+	router.Add(http.MethodPatch, "/v2/users/{id}", handle(h.PatchUserV2))
+	router.Add(http.MethodDelete, "/v2/users/{id}", handle(h.DeleteUserByID))
+
+	authenticate := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authenticator.Authenticate(r)
+			if err != nil {
+				problem.Write(w, authz.ErrUnauthenticated{})
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+
+	router.UseMiddleware(authenticate, headers.Middleware(), stream.Middleware())
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestClient_Users(t *testing.T) {
+	server := newServer(t)
+	c := client.New(server.URL, client.WithAPIKey(apiKey))
+	ctx := context.Background()
+
+	waheed, err := c.Create(ctx, entities.UserV2{UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "waheed@example.com"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, waheed.ID)
+	assert.False(t, waheed.CreatedAt.IsZero())
+	assert.NotEmpty(t, waheed.ETag)
+
+	for i, name := range []string{"amal", "zara"} {
+		_, err := c.Create(ctx, entities.UserV2{UserName: name, UserAge: 30, PhoneNumber: "+1415555267" + strconv.Itoa(i+2),
+			Email: name + "@example.com"})
+		require.NoError(t, err)
+	}
+
+	got, err := c.Get(ctx, waheed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, waheed, got)
+
+	page, err := c.List(ctx, client.ListOptions{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.TotalCount)
+	assert.Len(t, page.Users, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	var names []string
+
+	for user, err := range c.All(ctx, client.ListOptions{Limit: 1, Descending: true}) {
+		require.NoError(t, err)
+
+		names = append(names, user.UserName)
+	}
+
+	assert.Equal(t, []string{"zara", "waheed", "amal"}, names)
+
+	updated, err := c.Update(ctx, waheed.ID, entities.UserV2{UserName: "waheed", UserAge: 20,
+		PhoneNumber: "+14155552671", Email: "waheed@example.com"}, client.IfMatch(waheed.ETag))
+	require.NoError(t, err)
+	assert.Equal(t, 20, updated.UserAge)
+	assert.NotEqual(t, waheed.ETag, updated.ETag)
+
+	patched, err := c.Patch(ctx, waheed.ID, map[string]any{"phone_number": "+14155552679"}, client.IfMatch(updated.ETag))
+	require.NoError(t, err)
+	assert.Equal(t, "+14155552679", patched.PhoneNumber)
+	assert.Equal(t, 20, patched.UserAge)
+
+	require.NoError(t, c.Delete(ctx, waheed.ID, client.IfMatch(patched.ETag)))
+
+	_, err = c.Get(ctx, waheed.ID)
+	assert.ErrorIs(t, err, &apperrors.Error{Kind: apperrors.KindNotFound})
+}
+
+func TestClient_Errors(t *testing.T) {
+	server := newServer(t)
+	c := client.New(server.URL, client.WithAPIKey(apiKey))
+	ctx := context.Background()
+
+	user, err := c.Create(ctx, entities.UserV2{UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "waheed@example.com"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		call           func() error
+		expectedStatus int
+		expectedKind   apperrors.Kind
+	}{
+		{name: "not found", call: func() error {
+			_, err := c.Get(ctx, "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f")
+			return err
+		}, expectedStatus: http.StatusNotFound, expectedKind: apperrors.KindNotFound},
+		{name: "name taken", call: func() error {
+			_, err := c.Create(ctx, entities.UserV2{UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+				Email: "other@example.com"})
+			return err
+		}, expectedStatus: http.StatusConflict, expectedKind: apperrors.KindAlreadyExists},
+		{name: "stale version", call: func() error {
+			_, err := c.Patch(ctx, user.ID, map[string]any{"user_age": 20}, client.IfMatch(`"41"`))
+			return err
+		}, expectedStatus: http.StatusPreconditionFailed, expectedKind: apperrors.KindPreconditionFailed},
+		{name: "unauthenticated", call: func() error {
+			_, err := client.New(server.URL, client.WithAPIKey("wrong")).Get(ctx, user.ID)
+			return err
+		}, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for i, tt := range tests {
+		err := tt.call()
+
+		var apiErr *client.Error
+
+		require.ErrorAs(t, err, &apiErr, "TEST[%d] failed: %s", i, tt.name)
+		assert.Equal(t, tt.expectedStatus, apiErr.StatusCode, "TEST[%d] failed: %s", i, tt.name)
+		assert.Equal(t, tt.expectedKind, apiErr.Kind, "TEST[%d] failed: %s", i, tt.name)
+
+		if tt.expectedKind != "" {
+			assert.ErrorIs(t, err, &apperrors.Error{Kind: tt.expectedKind}, "TEST[%d] failed: %s", i, tt.name)
+		}
+	}
+}
+
+func TestClient_ValidationErrors(t *testing.T) {
+	server := newServer(t)
+	c := client.New(server.URL, client.WithAPIKey(apiKey))
+
+	_, err := c.Create(context.Background(), entities.UserV2{UserName: "waheed", UserAge: 19, PhoneNumber: "not a phone",
+		Email: "waheed@example.com"})
+
+	var fields validation.Errors
+
+	require.ErrorAs(t, err, &fields)
+	require.Len(t, fields, 1)
+	assert.Equal(t, "phone_number", fields[0].Field)
+	assert.ErrorIs(t, err, &apperrors.Error{Kind: apperrors.KindValidation})
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		statuses         []int
+		retryAfter       string
+		expectedAttempts int
+		expectedStatus   int
+	}{
+		{name: "server error", method: http.MethodGet, statuses: []int{http.StatusServiceUnavailable,
+			http.StatusBadGateway, http.StatusOK}, expectedAttempts: 3, expectedStatus: http.StatusOK},
+		{name: "too many requests", method: http.MethodPost, statuses: []int{http.StatusTooManyRequests,
+			http.StatusCreated}, retryAfter: "0", expectedAttempts: 2, expectedStatus: http.StatusCreated},
+		{name: "creation not retried", method: http.MethodPost, statuses: []int{http.StatusInternalServerError},
+			expectedAttempts: 1, expectedStatus: http.StatusInternalServerError},
+		{name: "client error not retried", method: http.MethodGet, statuses: []int{http.StatusNotFound},
+			expectedAttempts: 1, expectedStatus: http.StatusNotFound},
+		{name: "retries exhausted", method: http.MethodDelete, statuses: []int{http.StatusServiceUnavailable,
+			http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			expectedAttempts: 3, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for i, tt := range tests {
+		var attempts atomic.Int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status := tt.statuses[attempts.Add(1)-1]
+
+			assert.Equal(t, apiKey, r.Header.Get(auth.APIKeyHeader), "TEST[%d] failed: %s", i, tt.name)
+
+			if tt.retryAfter != "" {
+				w.Header().Set("Retry-After", tt.retryAfter)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"data":{"id":"0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f"}}`))
+		}))
+
+		c := client.New(server.URL, client.WithAPIKey(apiKey), client.WithRetries(2),
+			client.WithBackoff(time.Millisecond, 5*time.Millisecond))
+
+		var err error
+
+		switch tt.method {
+		case http.MethodGet:
+			_, err = c.Get(context.Background(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f")
+		case http.MethodPost:
+			_, err = c.Create(context.Background(), entities.UserV2{UserName: "waheed"})
+		case http.MethodDelete:
+			err = c.Delete(context.Background(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f")
+		}
+
+		var apiErr *client.Error
+
+		if tt.expectedStatus < http.StatusBadRequest {
+			assert.NoError(t, err, "TEST[%d] failed: %s", i, tt.name)
+		} else if assert.ErrorAs(t, err, &apiErr, "TEST[%d] failed: %s", i, tt.name) {
+			assert.Equal(t, tt.expectedStatus, apiErr.StatusCode, "TEST[%d] failed: %s", i, tt.name)
+		}
+
+		assert.Equal(t, tt.expectedAttempts, int(attempts.Load()), "TEST[%d] failed: %s", i, tt.name)
+
+		server.Close()
+	}
+}
+
+func TestClient_Deadline(t *testing.T) {
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.New(server.URL).Get(ctx, "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f")
+
+	var apiErr *client.Error
+
+	require.ErrorAs(t, err, &apiErr, "the response is returned when the retry would be after the deadline")
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, int32(1), attempts.Load())
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"gofrProject/apperrors"
+	"gofrProject/validation"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// problemContentType is the media type of the problem details the API describes its errors with.
+const problemContentType = "application/problem+json"
+
+// problemDetails is the body of an unsuccessful response, the RFC 7807 problem details of the
+// server with its code and errors extension members.
+type problemDetails struct {
+	Title  string            `json:"title"`
+	Detail string            `json:"detail"`
+	Code   string            `json:"code"`
+	Errors validation.Errors `json:"errors"`
+}
+
+// Error is an unsuccessful response of the API, described by the problem details in its body.
+//
+// It unwraps to the *apperrors.Error of its Kind, so errors.Is(err, &apperrors.Error{Kind: k})
+// tells the kind of the error apart like on the server, and errors.As into a validation.Errors
+// returns the invalid fields of a validation error.
+type Error struct {
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// Title is the status text of the status code.
+	Title string
+	// Detail is the message of the error.
+	Detail string
+	// Kind is the kind of the error, empty for errors that are not domain errors.
+	Kind apperrors.Kind
+	// Fields are the invalid fields of a validation error, named like the fields of UserV2.
+	Fields validation.Errors
+}
+
+func (e *Error) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("users api: %d %s", e.StatusCode, e.Title)
+	}
+
+	return fmt.Sprintf("users api: %d %s: %s", e.StatusCode, e.Title, e.Detail)
+}
+
+func (e *Error) Unwrap() error {
+	if e.Kind == "" {
+		return nil
+	}
+
+	err := &apperrors.Error{Kind: e.Kind, Message: e.Detail}
+	if len(e.Fields) > 0 {
+		err.Err = e.Fields
+	}
+
+	return err
+}
+
+// newError returns the error of resp, whose body is body. Bodies that are not problem details, e.g.
+// those of a proxy in front of the API, are kept as the detail.
+func newError(resp *http.Response, body []byte) *Error {
+	e := &Error{StatusCode: resp.StatusCode, Title: http.StatusText(resp.StatusCode)}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	var details problemDetails
+
+	if mediaType != problemContentType || json.Unmarshal(body, &details) != nil {
+		e.Detail = strings.TrimSpace(string(body))
+
+		return e
+	}
+
+	if details.Title != "" {
+		e.Title = details.Title
+	}
+
+	e.Detail, e.Kind, e.Fields = details.Detail, apperrors.Kind(details.Code), details.Errors
+
+	return e
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"gofrProject/entities"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// User is a user together with its ETag, which makes a write conditional with IfMatch.
+type User struct {
+	entities.UserV2
+	ETag string
+}
+
+// ListOptions filters and sorts the users of List and All. The zero value lists every user that is
+// not deleted by name, a page of the server's default size at a time.
+type ListOptions struct {
+	// Limit is the size of a page.
+	Limit int
+	// Cursor is the next_cursor of the previous page, empty for the first page.
+	Cursor string
+	// SortBy is entities.SortByUserName or entities.SortByUserAge.
+	SortBy         string
+	Descending     bool
+	MinAge         *int
+	MaxAge         *int
+	EmailDomain    string
+	IncludeDeleted bool
+}
+
+func (o ListOptions) query() string {
+	q := url.Values{}
+
+	if o.Limit != 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+
+	if o.Cursor != "" {
+		q.Set("cursor", o.Cursor)
+	}
+
+	if o.SortBy != "" {
+		q.Set("sort", o.SortBy)
+	}
+
+	if o.Descending {
+		q.Set("order", "desc")
+	}
+
+	if o.MinAge != nil {
+		q.Set("min_age", strconv.Itoa(*o.MinAge))
+	}
+
+	if o.MaxAge != nil {
+		q.Set("max_age", strconv.Itoa(*o.MaxAge))
+	}
+
+	if o.EmailDomain != "" {
+		q.Set("email_domain", o.EmailDomain)
+	}
+
+	if o.IncludeDeleted {
+		q.Set("include_deleted", "true")
+	}
+
+	if len(q) == 0 {
+		return ""
+	}
+
+	return "?" + q.Encode()
+}
+
+// WriteOption configures a write of a user.
+type WriteOption func(h http.Header)
+
+// IfMatch makes a write fail with precondition_failed if the user is no longer at the version of
+// etag, the ETag of User. Servers with USER_REQUIRE_IF_MATCH set require it, "*" writes any version.
+func IfMatch(etag string) WriteOption {
+	return func(h http.Header) {
+		h.Set("If-Match", etag)
+	}
+}
+
+func writeHeader(opts []WriteOption) http.Header {
+	h := http.Header{}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// List returns the page of users opts selects.
+func (c *Client) List(ctx context.Context, opts ListOptions) (entities.UsersPageV2, error) {
+	var page entities.UsersPageV2
+
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/v2/users" + opts.query()}, &page)
+
+	return page, err
+}
+
+// All iterates over the users opts selects, from the page of opts.Cursor on, fetching the pages as
+// they are needed. It stops after yielding the error of a page.
+func (c *Client) All(ctx context.Context, opts ListOptions) iter.Seq2[entities.UserV2, error] {
+	return func(yield func(entities.UserV2, error) bool) {
+		for {
+			page, err := c.List(ctx, opts)
+			if err != nil {
+				yield(entities.UserV2{}, err)
+				return
+			}
+
+			for _, user := range page.Users {
+				if !yield(user, nil) {
+					return
+				}
+			}
+
+			if page.NextCursor == "" {
+				return
+			}
+
+			opts.Cursor = page.NextCursor
+		}
+	}
+}
+
+// Get returns the user with the given id.
+func (c *Client) Get(ctx context.Context, id string) (User, error) {
+	return c.user(ctx, request{method: http.MethodGet, path: userPath(id)})
+}
+
+// Create creates user and returns it as created, with its id and timestamps. The fields set by the
+// server are ignored.
+func (c *Client) Create(ctx context.Context, user entities.UserV2) (User, error) {
+	return c.user(ctx, request{method: http.MethodPost, path: "/v2/users", body: user})
+}
+
+// Update replaces the user with the given id by user and returns it as updated.
+func (c *Client) Update(ctx context.Context, id string, user entities.UserV2, opts ...WriteOption) (User, error) {
+	return c.user(ctx, request{method: http.MethodPut, path: userPath(id), header: writeHeader(opts), body: user})
+}
+
+// Patch applies patch, a JSON Merge Patch (RFC 7396) of the fields of UserV2, to the user with the
+// given id and returns it as updated. A nil value removes the field.
+func (c *Client) Patch(ctx context.Context, id string, patch map[string]any, opts ...WriteOption) (User, error) {
+	return c.user(ctx, request{method: http.MethodPatch, path: userPath(id), header: writeHeader(opts), body: patch})
+}
+
+// Delete soft-deletes the user with the given id.
+func (c *Client) Delete(ctx context.Context, id string, opts ...WriteOption) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: userPath(id), header: writeHeader(opts)}, nil)
+
+	return err
+}
+
+func (c *Client) user(ctx context.Context, req request) (User, error) {
+	var user User
+
+	header, err := c.do(ctx, req, &user.UserV2)
+	if err != nil {
+		return User{}, err
+	}
+
+	user.ETag = header.Get("ETag")
+
+	return user, nil
+}
+
+func userPath(id string) string {
+	return "/v2/users/" + url.PathEscape(id)
+}
@@ -0,0 +1,118 @@
+// Package dialect rewrites the SQL of the store and the migrations for the database configured
+// in DB_DIALECT. Queries are written once with ANSI double-quoted identifiers and ? placeholders,
+// which SQL converts to the quoting and placeholder style of the dialect. LIMIT ? and OFFSET ?
+// are understood by every supported dialect and need no rewriting.
+package dialect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect is the name of a SQL dialect, as returned by the Dialect method of GoFr's SQL datasource.
+// Names other than postgres and sqlite are treated as MySQL, GoFr's default.
+type Dialect string
+
+// Supported dialects.
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// Parse returns the dialect with the given DB_DIALECT value. An empty value is MySQL.
+func Parse(name string) (Dialect, error) {
+	switch d := Dialect(name); d {
+	case "":
+		return MySQL, nil
+	case MySQL, Postgres, SQLite:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unsupported dialect %q, must be mysql, postgres or sqlite", name)
+	}
+}
+
+// SQL rewrites query for the dialect. MySQL quotes identifiers with backticks and PostgreSQL
+// numbers its placeholders $1, $2 and so on. Queries must not contain string literals, values
+// are always passed as arguments.
+func (d Dialect) SQL(query string) string {
+	if d == SQLite {
+		return query
+	}
+
+	var (
+		b strings.Builder
+		n int
+	)
+
+	b.Grow(len(query))
+
+	for _, r := range query {
+		switch {
+		case r == '"' && d != Postgres:
+			b.WriteByte('`')
+		case r == '?' && d == Postgres:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// Upsert returns the clause that turns an INSERT into an update of columns when a row with the
+// same unique key already exists.
+func (d Dialect) Upsert(key string, columns ...string) string {
+	assignments := make([]string, 0, len(columns))
+
+	if d == Postgres || d == SQLite {
+		for _, column := range columns {
+			assignments = append(assignments, column+" = excluded."+column)
+		}
+
+		return " ON CONFLICT (" + key + ") DO UPDATE SET " + strings.Join(assignments, ", ")
+	}
+
+	for _, column := range columns {
+		assignments = append(assignments, column+" = VALUES("+column+")")
+	}
+
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+}
+
+// Timestamp returns the column type holding a point in time.
+func (d Dialect) Timestamp() string {
+	if d == Postgres {
+		return "TIMESTAMP"
+	}
+
+	return "DATETIME"
+}
+
+// AutoIncrement returns the definition of an integer primary key column numbered by the database.
+func (d Dialect) AutoIncrement(column string) string {
+	switch d {
+	case Postgres:
+		return column + " BIGSERIAL PRIMARY KEY"
+	case SQLite:
+		return column + " INTEGER PRIMARY KEY AUTOINCREMENT"
+	default:
+		return column + " BIGINT AUTO_INCREMENT PRIMARY KEY"
+	}
+}
+
+// Text returns the type of a text column of up to size characters that compares case-insensitively,
+// as MySQL's default collation does. PostgreSQL needs the citext extension for it.
+func (d Dialect) Text(size int) string {
+	switch d {
+	case Postgres:
+		return "CITEXT"
+	case SQLite:
+		return "VARCHAR(" + strconv.Itoa(size) + ") COLLATE NOCASE"
+	default:
+		return "VARCHAR(" + strconv.Itoa(size) + ")"
+	}
+}
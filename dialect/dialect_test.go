@@ -0,0 +1,95 @@
+package dialect
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name            string
+		value           string
+		expectedDialect Dialect
+		expectedErr     error
+	}{
+		{name: "default", value: "", expectedDialect: MySQL},
+		{name: "mysql", value: "mysql", expectedDialect: MySQL},
+		{name: "postgres", value: "postgres", expectedDialect: Postgres},
+		{name: "sqlite", value: "sqlite", expectedDialect: SQLite},
+		{name: "unsupported", value: "oracle",
+			expectedErr: errors.New(`unsupported dialect "oracle", must be mysql, postgres or sqlite`)},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Parse(tt.value)
+
+			assert.Equal(t, tt.expectedDialect, d, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestDialect(t *testing.T) {
+	query := `SELECT ID FROM "User" WHERE UserName = ? AND Version = ? LIMIT ?`
+
+	tests := []struct {
+		name              string
+		dialect           Dialect
+		expectedSQL       string
+		expectedUpsert    string
+		expectedTimestamp string
+		expectedText      string
+		expectedID        string
+	}{
+		{
+			name:              "mysql",
+			dialect:           MySQL,
+			expectedSQL:       "SELECT ID FROM `User` WHERE UserName = ? AND Version = ? LIMIT ?",
+			expectedUpsert:    " ON DUPLICATE KEY UPDATE UserAge = VALUES(UserAge), Email = VALUES(Email)",
+			expectedTimestamp: "DATETIME",
+			expectedText:      "VARCHAR(255)",
+			expectedID:        "ID BIGINT AUTO_INCREMENT PRIMARY KEY",
+		},
+		{
+			name:              "postgres",
+			dialect:           Postgres,
+			expectedSQL:       `SELECT ID FROM "User" WHERE UserName = $1 AND Version = $2 LIMIT $3`,
+			expectedUpsert:    " ON CONFLICT (UserName) DO UPDATE SET UserAge = excluded.UserAge, Email = excluded.Email",
+			expectedTimestamp: "TIMESTAMP",
+			expectedText:      "CITEXT",
+			expectedID:        "ID BIGSERIAL PRIMARY KEY",
+		},
+		{
+			name:              "sqlite",
+			dialect:           SQLite,
+			expectedSQL:       query,
+			expectedUpsert:    " ON CONFLICT (UserName) DO UPDATE SET UserAge = excluded.UserAge, Email = excluded.Email",
+			expectedTimestamp: "DATETIME",
+			expectedText:      "VARCHAR(255) COLLATE NOCASE",
+			expectedID:        "ID INTEGER PRIMARY KEY AUTOINCREMENT",
+		},
+		{
+			name:              "unknown is mysql",
+			dialect:           "",
+			expectedSQL:       "SELECT ID FROM `User` WHERE UserName = ? AND Version = ? LIMIT ?",
+			expectedUpsert:    " ON DUPLICATE KEY UPDATE UserAge = VALUES(UserAge), Email = VALUES(Email)",
+			expectedTimestamp: "DATETIME",
+			expectedText:      "VARCHAR(255)",
+			expectedID:        "ID BIGINT AUTO_INCREMENT PRIMARY KEY",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedSQL, tt.dialect.SQL(query), "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedUpsert, tt.dialect.Upsert("UserName", "UserAge", "Email"),
+				"TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedTimestamp, tt.dialect.Timestamp(), "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedText, tt.dialect.Text(255), "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedID, tt.dialect.AutoIncrement("ID"), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
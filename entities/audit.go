@@ -0,0 +1,82 @@
+package entities
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditRename  = "rename"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+// AuditEntry records one change of a user. Actor is the principal that made the change, empty for
+// changes made by the application itself. Changes holds the before and after values of the fields
+// that changed, keyed by their JSON name. Entries are never changed once written.
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	UserID    string          `json:"user_id"`
+	UserName  string          `json:"user_name"`
+	Changes   json.RawMessage `json:"changes"`
+	RequestID string          `json:"request_id,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Change is what the store records together with a write of a user: its audit entry and, when
+// events are enabled, the event reporting it.
+type Change struct {
+	Audit AuditEntry
+	Event *OutboxMessage
+}
+
+// AuditQuery describes which page of the audit log to list, newest entry first. Empty filters
+// match every entry, From and To bound the timestamp when they are not zero.
+type AuditQuery struct {
+	Limit  int
+	Before *AuditCursor
+	UserID string
+	Actor  string
+	From   time.Time
+	To     time.Time
+}
+
+// AuditCursor is the position of the last entry returned on a page of the audit log.
+type AuditCursor struct {
+	ID int64 `json:"i"`
+}
+
+// AuditPage is the envelope returned when listing the audit log.
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// Encode returns the opaque representation of the cursor handed out to clients.
+func (c AuditCursor) Encode() string {
+	b, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeAuditCursor parses a cursor previously produced by AuditCursor.Encode.
+func DecodeAuditCursor(cursor string) (AuditCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return AuditCursor{}, ErrInvalidCursor
+	}
+
+	var c AuditCursor
+	if err = json.Unmarshal(b, &c); err != nil || c.ID <= 0 {
+		return AuditCursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
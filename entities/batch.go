@@ -0,0 +1,53 @@
+package entities
+
+// Operations of a batch request.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchRequest is the body of a batch request. If Atomic is set, either every operation is applied
+// or none is, otherwise each operation succeeds or fails on its own.
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation is one operation of a batch request. User is the user to create or the new values
+// of the user named UserName to update. A non-zero Version makes an update or delete conditional,
+// like an If-Match header does for a single user.
+type BatchOperation struct {
+	Op       string `json:"op"`
+	UserName string `json:"user_name,omitempty"`
+	Version  int    `json:"version,omitempty"`
+	User     *Users `json:"user,omitempty"`
+}
+
+// BatchResponse reports the outcome of every operation of a batch request, in request order.
+type BatchResponse struct {
+	Atomic    bool          `json:"atomic"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// BatchResult is the outcome of one operation of a batch request. Status is the status code the
+// operation would have had as a single request, Error describes why it failed. User and ETag are
+// the created or updated user and its new version.
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Status int    `json:"status"`
+	User   *Users `json:"user,omitempty"`
+	ETag   string `json:"etag,omitempty"`
+	Error  error  `json:"error,omitempty"`
+}
+
+// BatchWrite is one write of a batch as the store applies it. User is the user to create, the new
+// values of the user to update or the user to delete, at the version the write expects it to be at.
+type BatchWrite struct {
+	Op     string
+	User   Users
+	Change *Change
+}
@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// UserChange is a notification of the change feed: a user was created, updated or deleted. ID is
+// given by the feed and identifies the change to resume from after reconnecting. Type is one of the
+// event types of package events, Changes holds the before and after values of the fields that
+// changed, keyed by their JSON name. Who made the change is left to the audit log.
+type UserChange struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	UserID    string          `json:"user_id"`
+	UserName  string          `json:"user_name"`
+	Changes   json.RawMessage `json:"changes"`
+	Timestamp time.Time       `json:"timestamp"`
+}
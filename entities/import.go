@@ -0,0 +1,34 @@
+package entities
+
+// ImportOptions choose how users are imported. With DryRun the rows are checked but nothing is
+// written, with Upsert rows naming an existing user update it instead of failing.
+type ImportOptions struct {
+	DryRun bool
+	Upsert bool
+}
+
+// ImportRow is a user read from line Line of an import file. Err is set if the line could not be
+// read as a user.
+type ImportRow struct {
+	Line int
+	User Users
+	Err  error
+}
+
+// ImportResult reports the outcome of an import. With DryRun, Created and Updated count the users
+// that would have been created and updated.
+type ImportResult struct {
+	DryRun  bool          `json:"dry_run"`
+	Upsert  bool          `json:"upsert"`
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Failed  int           `json:"failed"`
+	Errors  []ImportError `json:"errors"`
+}
+
+// ImportError describes why the row read from line Line of an import file was not imported.
+type ImportError struct {
+	Line     int    `json:"line"`
+	UserName string `json:"user_name,omitempty"`
+	Error    error  `json:"error"`
+}
@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// OutboxMessage is an event stored in the outbox together with the user change it reports,
+// waiting to be published to Topic. ID orders the messages in the order of the changes.
+type OutboxMessage struct {
+	ID        int64
+	Topic     string
+	Payload   []byte
+	CreatedAt time.Time
+}
@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// Columns the user list can be sorted by.
+const (
+	SortByUserName = "user_name"
+	SortByUserAge  = "user_age"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// UsersQuery describes which page of users to list and how to filter and order them.
+// Soft-deleted users are only listed when IncludeDeleted is set.
+type UsersQuery struct {
+	Limit          int
+	After          *UserCursor
+	SortBy         string
+	Descending     bool
+	MinAge         *int
+	MaxAge         *int
+	EmailDomain    string
+	IncludeDeleted bool
+}
+
+// UserCursor is the keyset position of the last user returned on a page.
+type UserCursor struct {
+	UserName string `json:"n"`
+	UserAge  int    `json:"a"`
+}
+
+// UsersPage is the envelope returned when listing users.
+type UsersPage struct {
+	Users      []Users `json:"users" xml:"users>user"`
+	NextCursor string  `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+	TotalCount int     `json:"total_count" xml:"total_count"`
+}
+
+// Encode returns the opaque representation of the cursor handed out to clients.
+func (c UserCursor) Encode() string {
+	b, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeUserCursor parses a cursor previously produced by UserCursor.Encode.
+func DecodeUserCursor(cursor string) (UserCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return UserCursor{}, ErrInvalidCursor
+	}
+
+	var c UserCursor
+	if err = json.Unmarshal(b, &c); err != nil || c.UserName == "" {
+		return UserCursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
@@ -0,0 +1,26 @@
+package entities
+
+// SearchQuery describes a search over the user names, emails and phone numbers of the users that
+// are not deleted. Every word of Text has to match, Limit is the number of results returned.
+type SearchQuery struct {
+	Text  string
+	Limit int
+}
+
+// SearchResult is a user matching a search. Users matching better have a higher Score.
+type SearchResult struct {
+	User  Users   `json:"user" xml:"user"`
+	Score float64 `json:"score" xml:"score"`
+}
+
+// SearchPage is the envelope returned when searching users, best match first. TotalCount is the
+// number of users matching, of which only the best are returned.
+type SearchPage struct {
+	Results    []SearchResult `json:"results" xml:"results>result"`
+	TotalCount int            `json:"total_count" xml:"total_count"`
+}
+
+// RebuildResult reports how many users a rebuild of the search index indexed.
+type RebuildResult struct {
+	Indexed int `json:"indexed"`
+}
@@ -2,13 +2,35 @@ package entities
 
 import (
 	"errors"
+	"time"
 )
 
 var ErrInvalidPhoneNumber = errors.New("invalid phone number")
 
+// Users is a user record. ID is a UUIDv7 assigned by the server on creation; it never changes,
+// even when the user is renamed. DeletedAt is set by the server when the user is soft-deleted.
+// Version is incremented by every write and is sent to clients as the ETag of the user.
+//
+// Users encodes to the representation of the v1 API, which clients depend on as it is. CreatedAt
+// and UpdatedAt, set by the store, are only part of the v2 representation, see UserV2.
 type Users struct {
-	UserName    string `json:"user_name"`
-	UserAge     int    `json:"user_age"`
-	PhoneNumber string `json:"phone_Number"`
-	Email       string `json:"email"`
+	ID          string     `json:"id" xml:"id"`
+	UserName    string     `json:"user_name" xml:"user_name"`
+	UserAge     int        `json:"user_age" xml:"user_age"`
+	PhoneNumber string     `json:"phone_Number" xml:"phone_Number"`
+	Email       string     `json:"email" xml:"email"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" xml:"deleted_at,omitempty"`
+	CreatedAt   time.Time  `json:"-" xml:"-"`
+	UpdatedAt   time.Time  `json:"-" xml:"-"`
+	Version     int        `json:"-" xml:"-"`
+}
+
+// Rename is the body of a rename request.
+type Rename struct {
+	UserName string `json:"user_name"`
+}
+
+// PurgeResult reports how many soft-deleted users a purge removed permanently.
+type PurgeResult struct {
+	Purged int64 `json:"purged"`
 }
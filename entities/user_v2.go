@@ -0,0 +1,45 @@
+package entities
+
+import "time"
+
+// UserV2 is a user in the v2 API. It differs from the v1 representation, Users, in the name of
+// phone_number and in the timestamps of the creation and last change of the user.
+type UserV2 struct {
+	ID          string     `json:"id" xml:"id"`
+	UserName    string     `json:"user_name" xml:"user_name"`
+	UserAge     int        `json:"user_age" xml:"user_age"`
+	PhoneNumber string     `json:"phone_number" xml:"phone_number"`
+	Email       string     `json:"email" xml:"email"`
+	CreatedAt   time.Time  `json:"created_at" xml:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" xml:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" xml:"deleted_at,omitempty"`
+}
+
+// NewUserV2 returns the v2 representation of user.
+func NewUserV2(user Users) UserV2 {
+	return UserV2{ID: user.ID, UserName: user.UserName, UserAge: user.UserAge, PhoneNumber: user.PhoneNumber,
+		Email: user.Email, CreatedAt: user.CreatedAt, UpdatedAt: user.UpdatedAt, DeletedAt: user.DeletedAt}
+}
+
+// User returns the user u represents. The fields set by the server, the timestamps, are left out
+// like they are of the bodies of v1 requests.
+func (u UserV2) User() Users {
+	return Users{ID: u.ID, UserName: u.UserName, UserAge: u.UserAge, PhoneNumber: u.PhoneNumber, Email: u.Email}
+}
+
+// UsersPageV2 is a page of users in the v2 API, see UsersPage.
+type UsersPageV2 struct {
+	Users      []UserV2 `json:"users" xml:"users>user"`
+	NextCursor string   `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+	TotalCount int      `json:"total_count" xml:"total_count"`
+}
+
+// NewUsersPageV2 returns the v2 representation of page.
+func NewUsersPageV2(page UsersPage) UsersPageV2 {
+	users := make([]UserV2, len(page.Users))
+	for i, user := range page.Users {
+		users[i] = NewUserV2(user)
+	}
+
+	return UsersPageV2{Users: users, NextCursor: page.NextCursor, TotalCount: page.TotalCount}
+}
@@ -0,0 +1,61 @@
+// Package etag implements the entity tags of users, derived from their version, and the
+// comparisons used by the If-Match and If-None-Match preconditions (RFC 9110 section 13.1).
+package etag
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Format returns the strong entity tag of version.
+func Format(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Matches reports whether an If-Match header value lists the entity tag of version, using the
+// strong comparison. "*" matches every version.
+func Matches(header string, version int) bool {
+	return matches(header, version, false)
+}
+
+// MatchesWeak reports whether an If-None-Match header value lists the entity tag of version,
+// using the weak comparison. "*" matches every version.
+func MatchesWeak(header string, version int) bool {
+	return matches(header, version, true)
+}
+
+func matches(header string, version int, weak bool) bool {
+	tag := Format(version)
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+
+		if candidate == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ErrNotModified is returned instead of a representation the client already holds.
+var ErrNotModified = notModified{}
+
+type notModified struct{}
+
+func (notModified) Error() string {
+	return "not modified"
+}
+
+// StatusCode is used by GoFr as the status of the response.
+func (notModified) StatusCode() int {
+	return http.StatusNotModified
+}
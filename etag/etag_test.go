@@ -0,0 +1,40 @@
+package etag
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, `"7"`, Format(7))
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       string
+		expected     bool
+		expectedWeak bool
+	}{
+		{name: "same tag", header: `"3"`, expected: true, expectedWeak: true},
+		{name: "other tag", header: `"2"`, expected: false, expectedWeak: false},
+		{name: "tag in list", header: `"1", "3"`, expected: true, expectedWeak: true},
+		{name: "any", header: "*", expected: true, expectedWeak: true},
+		{name: "weak tag", header: `W/"3"`, expected: false, expectedWeak: true},
+		{name: "unquoted tag", header: "3", expected: false, expectedWeak: false},
+		{name: "empty", header: "", expected: false, expectedWeak: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Matches(tt.header, 3), "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedWeak, MatchesWeak(tt.header, 3), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestErrNotModified(t *testing.T) {
+	assert.Equal(t, http.StatusNotModified, ErrNotModified.StatusCode())
+}
@@ -0,0 +1,120 @@
+// Package events defines the user lifecycle events published to GoFr pub/sub.
+//
+// Every message is a JSON envelope carrying the event metadata and a payload that depends on
+// the event type:
+//
+//	{
+//	  "id": "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
+//	  "type": "user.updated",
+//	  "version": 1,
+//	  "timestamp": "2024-12-30T12:00:00Z",
+//	  "actor": "local",
+//	  "payload": {
+//	    "user": {"id": "...", "user_name": "waheed", "user_age": 20, "phone_Number": "+14155552671", "email": "..."},
+//	    "changes": {"user_age": {"before": 19, "after": 20}}
+//	  }
+//	}
+//
+// id is a UUIDv7 unique to the event, consumers can use it to drop duplicates. version is the
+// version of the payload schema of the type, it is incremented on incompatible changes only.
+// actor is the authenticated principal that made the change, it is empty for changes made by
+// the application itself. The payload is UserCreated, UserUpdated, UserDeleted or UserPurged.
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"gofrProject/entities"
+)
+
+// Event types.
+const (
+	TypeUserCreated = "user.created"
+	TypeUserUpdated = "user.updated"
+	TypeUserDeleted = "user.deleted"
+	TypeUserPurged  = "user.purged"
+)
+
+// Version is the payload schema version of every event type.
+const Version = 1
+
+// Envelope is the message published for every event.
+type Envelope struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Version   int       `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor,omitempty"`
+	Payload   any       `json:"payload"`
+}
+
+// UserCreated is the payload of user.created.
+type UserCreated struct {
+	User entities.Users `json:"user"`
+}
+
+// UserUpdated is the payload of user.updated. User is the user after the update, Changes holds
+// the before and after values of the fields that changed, keyed by their JSON name.
+type UserUpdated struct {
+	User    entities.Users         `json:"user"`
+	Changes map[string]FieldChange `json:"changes"`
+}
+
+// UserDeleted is the payload of user.deleted. User is the user as it was before it was deleted.
+type UserDeleted struct {
+	User entities.Users `json:"user"`
+}
+
+// UserPurged is the payload of user.purged, sent when a deleted user is removed for good. User is
+// the user as it was when it was purged.
+type UserPurged struct {
+	User entities.Users `json:"user"`
+}
+
+// FieldChange is the value of a field before and after an update.
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Diff returns the fields of the JSON representation of a user that differ between before and after.
+func Diff(before, after entities.Users) (map[string]FieldChange, error) {
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]FieldChange{}
+
+	for name, value := range afterFields {
+		if !reflect.DeepEqual(beforeFields[name], value) {
+			changes[name] = FieldChange{Before: beforeFields[name], After: value}
+		}
+	}
+
+	for name, value := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			changes[name] = FieldChange{Before: value}
+		}
+	}
+
+	return changes, nil
+}
+
+func fields(user entities.Users) (map[string]any, error) {
+	b, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]any
+
+	return m, json.Unmarshal(b, &m)
+}
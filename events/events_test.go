@@ -0,0 +1,46 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gofrProject/entities"
+)
+
+func TestDiff(t *testing.T) {
+	deletedAt := time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC)
+	user := entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", UserName: "waheed", UserAge: 19,
+		PhoneNumber: "+14155552671", Email: "waheed@example.com", Version: 1}
+
+	aged := user
+	aged.UserAge, aged.Version = 20, 2
+
+	deleted := user
+	deleted.DeletedAt = &deletedAt
+
+	tests := []struct {
+		name            string
+		before          entities.Users
+		after           entities.Users
+		expectedChanges map[string]FieldChange
+	}{
+		{name: "unchanged", before: user, after: user, expectedChanges: map[string]FieldChange{}},
+		{name: "changed field, version is not a field", before: user, after: aged,
+			expectedChanges: map[string]FieldChange{"user_age": {Before: float64(19), After: float64(20)}}},
+		{name: "added field", before: user, after: deleted,
+			expectedChanges: map[string]FieldChange{"deleted_at": {Before: nil, After: "2024-12-30T12:00:00Z"}}},
+		{name: "removed field", before: deleted, after: user,
+			expectedChanges: map[string]FieldChange{"deleted_at": {Before: "2024-12-30T12:00:00Z", After: nil}}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := Diff(tt.before, tt.after)
+
+			assert.NoError(t, err, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedChanges, changes, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
@@ -1,73 +1,1006 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/http/response"
+	"gofrProject/apperrors"
+	"gofrProject/changes"
 	"gofrProject/entities"
+	"gofrProject/etag"
+	"gofrProject/headers"
+	"gofrProject/negotiate"
+	"gofrProject/problem"
+	"gofrProject/stream"
+	"gofrProject/userio"
+	"gofrProject/validation"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
 )
 
 type Handler struct {
-	UserService UserService
+	UserService    UserService
+	requireIfMatch bool
+	authorizeBatch func(op string, ctx *gofr.Context) error
+	authorizeRead  func(ctx *gofr.Context) error
+	changes        ChangeFeed
+	heartbeat      time.Duration
 }
 
-func NewUserHandler(userService UserService) *Handler {
-	return &Handler{UserService: userService}
+// Option configures a Handler.
+type Option func(h *Handler)
+
+// RequireIfMatch makes writes of a user that do not carry an If-Match header fail with
+// 428 Precondition Required, so that clients cannot overwrite changes they have not seen.
+func RequireIfMatch(required bool) Option {
+	return func(h *Handler) {
+		h.requireIfMatch = required
+	}
+}
+
+// AuthorizeBatch makes Batch call authorize for every kind of operation of a request and reject the
+// whole request with the first error it returns.
+func AuthorizeBatch(authorize func(op string, ctx *gofr.Context) error) Option {
+	return func(h *Handler) {
+		h.authorizeBatch = authorize
+	}
+}
+
+// AuthorizeReadDeleted makes the lists of users call authorize when they include deleted users,
+// and fail with the error it returns. The parameter include_deleted is parsed before, so that
+// every value it accepts for true is authorized.
+func AuthorizeReadDeleted(authorize func(ctx *gofr.Context) error) Option {
+	return func(h *Handler) {
+		h.authorizeRead = authorize
+	}
+}
+
+// FollowChanges makes StreamChanges and StreamChangesWebSocket send the changes of feed, with a
+// heartbeat every so often while no change is made.
+func FollowChanges(feed ChangeFeed, heartbeat time.Duration) Option {
+	return func(h *Handler) {
+		h.changes, h.heartbeat = feed, heartbeat
+	}
+}
+
+func NewUserHandler(userService UserService, opts ...Option) *Handler {
+	h := &Handler{UserService: userService}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Media types users are sent in, chosen by the Accept header. Lists of users can also be sent as CSV.
+var (
+	userFormats = []string{negotiate.JSON, negotiate.XML, negotiate.MessagePack}
+	listFormats = []string{negotiate.JSON, negotiate.XML, negotiate.CSV, negotiate.MessagePack}
+)
+
+// bodyFormats are the media types of the user in the body of AddUser and UpdateUser, as told by the
+// Content-Type header. A CSV body holds a header and a single user.
+var bodyFormats = []string{negotiate.JSON, negotiate.XML, negotiate.CSV, negotiate.MessagePack}
+
+// accept returns the media type of offers the response is sent in according to the Accept header.
+func accept(offers []string, ctx *gofr.Context) (string, error) {
+	headers.Set(ctx.Request.Context(), "Vary", "Accept")
+
+	return negotiate.Accept(headers.Get(ctx.Request.Context(), "Accept"), offers...)
 }
 
+// render returns data as the response in the media type format. JSON is left to GoFr, the other
+// formats are sent as they are encoded by negotiate.Marshal.
+func render(format string, data any) (any, error) {
+	if format == negotiate.JSON {
+		return data, nil
+	}
+
+	body, err := negotiate.Marshal(format, data)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	return response.File{Content: body, ContentType: format}, nil
+}
+
+// renderCSV returns the users of page as a CSV file. The total count and the cursor of the next page
+// have no place in the file and are sent in the X-Total-Count and X-Next-Cursor headers instead.
+func renderCSV(page entities.UsersPage, ctx *gofr.Context) (any, error) {
+	var b bytes.Buffer
+
+	writer, err := userio.NewWriter(userio.CSV, &b)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	for _, user := range page.Users {
+		if err := writer.Write(user); err != nil {
+			return problem.Respond(err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return problem.Respond(err)
+	}
+
+	headers.Set(ctx.Request.Context(), "X-Total-Count", strconv.Itoa(page.TotalCount))
+
+	if page.NextCursor != "" {
+		headers.Set(ctx.Request.Context(), "X-Next-Cursor", page.NextCursor)
+	}
+
+	return response.File{Content: b.Bytes(), ContentType: negotiate.CSV}, nil
+}
+
+// GetUsers lists users. It supports the query parameters limit, cursor, sort (user_name or user_age),
+// order (asc or desc), min_age, max_age, email_domain and include_deleted.
 func (h *Handler) GetUsers(ctx *gofr.Context) (any, error) {
-	resp, err := h.UserService.GetUsers(ctx)
+	format, err := accept(listFormats, ctx)
 	if err != nil {
-		fmt.Sprintf("error while getting user: %s", err)
-		return nil, err
+		return problem.Respond(err)
 	}
-	return resp, nil
+
+	query, err := h.listQuery(ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	resp, err := h.UserService.GetUsers(query, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	if format == negotiate.CSV {
+		return renderCSV(resp, ctx)
+	}
+
+	return render(format, resp)
+}
+
+// listQuery parses the query parameters of a list request, and authorizes the request if they
+// include deleted users.
+func (h *Handler) listQuery(ctx *gofr.Context) (entities.UsersQuery, error) {
+	query, err := usersQuery(ctx)
+	if err != nil || !query.IncludeDeleted || h.authorizeRead == nil {
+		return query, err
+	}
+
+	if err := h.authorizeRead(ctx); err != nil {
+		return entities.UsersQuery{}, err
+	}
+
+	return query, nil
+}
+
+// usersQuery parses the query parameters of a list request.
+func usersQuery(ctx *gofr.Context) (entities.UsersQuery, error) {
+	var (
+		query entities.UsersQuery
+		errs  validation.Errors
+		err   error
+	)
+
+	if limit := ctx.Param("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil {
+			errs = append(errs, validation.FieldError{Field: "limit", Code: validation.CodeInvalidType,
+				Message: "limit must be an integer"})
+		}
+	}
+
+	if cursor := ctx.Param("cursor"); cursor != "" {
+		after, err := entities.DecodeUserCursor(cursor)
+		if err != nil {
+			errs = append(errs, validation.FieldError{Field: "cursor", Code: validation.CodeInvalidValue,
+				Message: "cursor is not valid"})
+		}
+
+		query.After = &after
+	}
+
+	query.SortBy = ctx.Param("sort")
+
+	switch ctx.Param("order") {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		errs = append(errs, validation.FieldError{Field: "order", Code: validation.CodeInvalidValue,
+			Message: "order must be asc or desc"})
+	}
+
+	for _, age := range []struct {
+		param string
+		value **int
+	}{{"min_age", &query.MinAge}, {"max_age", &query.MaxAge}} {
+		value := ctx.Param(age.param)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, validation.FieldError{Field: age.param, Code: validation.CodeInvalidType,
+				Message: age.param + " must be an integer"})
+			continue
+		}
+
+		*age.value = &parsed
+	}
+
+	query.EmailDomain = ctx.Param("email_domain")
+
+	if includeDeleted := ctx.Param("include_deleted"); includeDeleted != "" {
+		if query.IncludeDeleted, err = strconv.ParseBool(includeDeleted); err != nil {
+			errs = append(errs, validation.FieldError{Field: "include_deleted", Code: validation.CodeInvalidType,
+				Message: "include_deleted must be true or false"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return entities.UsersQuery{}, apperrors.Validation(errs)
+	}
+
+	return query, nil
 }
 
 func (h *Handler) GetUserByName(ctx *gofr.Context) (interface{}, error) {
 	name := ctx.Request.PathParam("name")
 
+	format, err := accept(userFormats, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
 	resp, err := h.UserService.GetUsersByName(name, ctx)
 	if err != nil {
-		fmt.Sprintf("error while getting user by name: %s", err)
-		return resp, err
+		return problem.Respond(err)
 	}
-	return resp, nil
+	return userResponse(format, resp.Version, resp, ctx)
+}
+
+// userResponse returns body, the representation of a user at version, in format with its ETag, or
+// 304 Not Modified if the client already has this version according to If-None-Match.
+func userResponse(format string, version int, body any, ctx *gofr.Context) (interface{}, error) {
+	headers.Set(ctx.Request.Context(), "ETag", etag.Format(version))
+
+	if etag.MatchesWeak(headers.Get(ctx.Request.Context(), "If-None-Match"), version) {
+		return nil, etag.ErrNotModified
+	}
+
+	return render(format, body)
+}
+
+// userAddress is how the path of a request addresses the user it writes: by the path parameter
+// param, "name" or "id", together with the methods of the service reading and writing a user
+// addressed that way.
+type userAddress struct {
+	param  string
+	get    func(key string, ctx *gofr.Context) (entities.Users, error)
+	update func(key string, version int, updateUser *entities.Users, ctx *gofr.Context) error
+	patch  func(key string, version int, patch map[string]any, ctx *gofr.Context) error
+	delete func(key string, version int, ctx *gofr.Context) error
+}
+
+func (h *Handler) byName() userAddress {
+	return userAddress{param: "name", get: h.UserService.GetUsersByName, update: h.UserService.UpdateUsers,
+		patch: h.UserService.PatchUsers, delete: h.UserService.DeleteUsers}
+}
+
+func (h *Handler) byID() userAddress {
+	return userAddress{param: "id", get: h.UserService.GetUsersByID, update: h.UserService.UpdateUsersByID,
+		patch: h.UserService.PatchUsersByID, delete: h.UserService.DeleteUsersByID}
+}
+
+// expectedVersion returns the version of the user with the given key, addressed by address, the
+// If-Match header of the request refers to, 0 if the write is unconditional.
+func (h *Handler) expectedVersion(address userAddress, key string, ctx *gofr.Context) (int, error) {
+	ifMatch := headers.Get(ctx.Request.Context(), "If-Match")
+
+	switch {
+	case ifMatch == "" && h.requireIfMatch:
+		return 0, apperrors.PreconditionRequired("If-Match header is required")
+	case ifMatch == "" || ifMatch == "*":
+		return 0, nil
+	}
+
+	user, err := address.get(key, ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if !etag.Matches(ifMatch, user.Version) {
+		return 0, apperrors.PreconditionFailed("user", address.param, key)
+	}
+
+	return user.Version, nil
+}
+
+// bindUser reads the user in the body of the request into user, in the format the Content-Type
+// header names. action tells what is being done to the user in the error of a body that cannot be read.
+func bindUser(user *entities.Users, action string, ctx *gofr.Context) error {
+	format, err := negotiate.ContentType(headers.Get(ctx.Request.Context(), "Content-Type"), bodyFormats...)
+	if err != nil {
+		return err
+	}
+
+	if format == negotiate.JSON {
+		err = ctx.Bind(user)
+	} else {
+		err = readUser(format, user, ctx)
+	}
+
+	if err != nil {
+		return apperrors.Validation(fmt.Errorf("error while %s user: %v", action, err))
+	}
+
+	return nil
+}
+
+// mergePatch is the media type of JSON Merge Patch (RFC 7396) documents.
+const mergePatch = "application/merge-patch+json"
+
+// bindPatch reads the JSON Merge Patch in the body of the request into patch. The body is decoded
+// here rather than with ctx.Bind, which leaves patch empty for any Content-Type but JSON.
+func bindPatch(patch *map[string]any, ctx *gofr.Context) error {
+	if _, err := negotiate.ContentType(headers.Get(ctx.Request.Context(), "Content-Type"), mergePatch, negotiate.JSON); err != nil {
+		return err
+	}
+
+	body := stream.Body(ctx.Request.Context())
+	if body == nil {
+		return apperrors.Validation(errors.New("error while patching user: request body cannot be read"))
+	}
+
+	if err := json.NewDecoder(body).Decode(patch); err != nil {
+		return apperrors.Validation(fmt.Errorf("error while patching user: %v", err))
+	}
+
+	return nil
+}
+
+// readUser reads the user in the body of the request in a format other than JSON.
+func readUser(format string, user *entities.Users, ctx *gofr.Context) error {
+	body := stream.Body(ctx.Request.Context())
+	if body == nil {
+		return errors.New("request body cannot be read")
+	}
+
+	if format != negotiate.CSV {
+		return negotiate.Unmarshal(format, body, user)
+	}
+
+	rows := 0
+
+	for row := range userio.Read(userio.CSV, body) {
+		if row.Err != nil {
+			return row.Err
+		}
+
+		*user = row.User
+		rows++
+	}
+
+	if rows != 1 {
+		return fmt.Errorf("body has %d users, it must have 1", rows)
+	}
+
+	return nil
 }
 
 func (h *Handler) AddUser(ctx *gofr.Context) (interface{}, error) {
 	var newUser entities.Users
 
-	if err := ctx.Bind(&newUser); err != nil {
-		return nil, fmt.Errorf("error while adding user: %v", err)
+	if err := bindUser(&newUser, "adding", ctx); err != nil {
+		return problem.Respond(err)
 	}
 
 	if err := h.UserService.AddUsers(&newUser, ctx); err != nil {
-		return nil, err
+		return problem.Respond(err)
 	}
 
 	return nil, nil
 }
 
 func (h *Handler) UpdateUser(ctx *gofr.Context) (interface{}, error) {
+	return h.updateUser(h.byName(), ctx)
+}
 
-	name := ctx.Request.PathParam("name")
-
+func (h *Handler) updateUser(address userAddress, ctx *gofr.Context) (interface{}, error) {
 	var updateUser entities.Users
 
-	if err := h.UserService.UpdateUsers(name, &updateUser, ctx); err != nil {
-		fmt.Sprintf("error while updating user: %s", err)
-		return nil, err
+	if err := bindUser(&updateUser, "updating", ctx); err != nil {
+		return problem.Respond(err)
+	}
+
+	key := ctx.Request.PathParam(address.param)
+
+	version, err := h.expectedVersion(address, key, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	if err := address.update(key, version, &updateUser, ctx); err != nil {
+		return problem.Respond(err)
+	}
+	return nil, nil
+}
+
+// PatchUser applies a JSON Merge Patch (RFC 7396) to the user, only touching the supplied fields.
+func (h *Handler) PatchUser(ctx *gofr.Context) (interface{}, error) {
+	return h.patchUser(h.byName(), ctx)
+}
+
+func (h *Handler) patchUser(address userAddress, ctx *gofr.Context) (interface{}, error) {
+	var patch map[string]any
+
+	if err := bindPatch(&patch, ctx); err != nil {
+		return problem.Respond(err)
+	}
+
+	key := ctx.Request.PathParam(address.param)
+
+	version, err := h.expectedVersion(address, key, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	if err := address.patch(key, version, patch, ctx); err != nil {
+		return problem.Respond(err)
 	}
 	return nil, nil
 }
 
 func (h *Handler) DeleteUser(ctx *gofr.Context) (interface{}, error) {
+	return h.deleteUser(h.byName(), ctx)
+}
 
-	name := ctx.Request.PathParam("name")
+func (h *Handler) deleteUser(address userAddress, ctx *gofr.Context) (interface{}, error) {
+	key := ctx.Request.PathParam(address.param)
+
+	version, err := h.expectedVersion(address, key, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
 
-	if err := h.UserService.DeleteUsers(name, ctx); err != nil {
-		fmt.Sprintf("error while deleting user: %s", err)
-		return nil, err
+	if err := address.delete(key, version, ctx); err != nil {
+		return problem.Respond(err)
 	}
 	return nil, nil
 }
+
+// RestoreUser undoes the soft delete of the user named in the path and returns it.
+func (h *Handler) RestoreUser(ctx *gofr.Context) (interface{}, error) {
+	name := ctx.Request.PathParam("name")
+
+	format, err := accept(userFormats, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	resp, err := h.UserService.RestoreUsers(name, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+	return render(format, resp)
+}
+
+// PurgeUsers permanently removes the users soft-deleted longer than the configured retention ago.
+func (h *Handler) PurgeUsers(ctx *gofr.Context) (interface{}, error) {
+	resp, err := h.UserService.PurgeUsers(ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+	return resp, nil
+}
+
+// GetUserByID returns the user with the id in the path.
+func (h *Handler) GetUserByID(ctx *gofr.Context) (interface{}, error) {
+	id := ctx.Request.PathParam("id")
+
+	format, err := accept(userFormats, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	resp, err := h.UserService.GetUsersByID(id, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+	return userResponse(format, resp.Version, resp, ctx)
+}
+
+// UpdateUserByID is UpdateUser for the user with the id in the path.
+func (h *Handler) UpdateUserByID(ctx *gofr.Context) (interface{}, error) {
+	return h.updateUser(h.byID(), ctx)
+}
+
+// PatchUserByID is PatchUser for the user with the id in the path.
+func (h *Handler) PatchUserByID(ctx *gofr.Context) (interface{}, error) {
+	return h.patchUser(h.byID(), ctx)
+}
+
+// DeleteUserByID is DeleteUser for the user with the id in the path.
+func (h *Handler) DeleteUserByID(ctx *gofr.Context) (interface{}, error) {
+	return h.deleteUser(h.byID(), ctx)
+}
+
+// RenameUser changes the name of the user with the id in the path. The id stays the same.
+func (h *Handler) RenameUser(ctx *gofr.Context) (interface{}, error) {
+	id := ctx.Request.PathParam("id")
+
+	format, err := accept(userFormats, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	var rename entities.Rename
+
+	if err := ctx.Bind(&rename); err != nil {
+		return problem.Respond(apperrors.Validation(fmt.Errorf("error while renaming user: %v", err)))
+	}
+
+	resp, err := h.UserService.RenameUsers(id, rename.UserName, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+	return render(format, resp)
+}
+
+// GetUserHistory lists the audit log entries of the user named in the path, newest first. The history
+// follows the user across renames. It supports the same query parameters as GetAuditLog.
+func (h *Handler) GetUserHistory(ctx *gofr.Context) (any, error) {
+	query, err := auditQuery(ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	resp, err := h.UserService.GetUserHistory(ctx.Request.PathParam("name"), query, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+	return resp, nil
+}
+
+// GetAuditLog lists the audit log of all users, newest entry first. It supports the query parameters
+// limit, cursor, actor, and from and to as RFC 3339 timestamps.
+func (h *Handler) GetAuditLog(ctx *gofr.Context) (any, error) {
+	query, err := auditQuery(ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	resp, err := h.UserService.GetAuditLog(query, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+	return resp, nil
+}
+
+// auditQuery parses the query parameters of an audit log request.
+func auditQuery(ctx *gofr.Context) (entities.AuditQuery, error) {
+	var (
+		query entities.AuditQuery
+		errs  validation.Errors
+		err   error
+	)
+
+	if limit := ctx.Param("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil {
+			errs = append(errs, validation.FieldError{Field: "limit", Code: validation.CodeInvalidType,
+				Message: "limit must be an integer"})
+		}
+	}
+
+	if cursor := ctx.Param("cursor"); cursor != "" {
+		before, err := entities.DecodeAuditCursor(cursor)
+		if err != nil {
+			errs = append(errs, validation.FieldError{Field: "cursor", Code: validation.CodeInvalidValue,
+				Message: "cursor is not valid"})
+		}
+
+		query.Before = &before
+	}
+
+	query.Actor = ctx.Param("actor")
+
+	for _, bound := range []struct {
+		param string
+		value *time.Time
+	}{{"from", &query.From}, {"to", &query.To}} {
+		value := ctx.Param(bound.param)
+		if value == "" {
+			continue
+		}
+
+		if *bound.value, err = time.Parse(time.RFC3339, value); err != nil {
+			errs = append(errs, validation.FieldError{Field: bound.param, Code: validation.CodeInvalidType,
+				Message: bound.param + " must be an RFC 3339 timestamp"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return entities.AuditQuery{}, apperrors.Validation(errs)
+	}
+
+	return query, nil
+}
+
+// Batch creates, updates and deletes the users listed in the body, see entities.BatchRequest. Every
+// operation gets a result with the status code it would have had as a single request.
+func (h *Handler) Batch(ctx *gofr.Context) (any, error) {
+	var request entities.BatchRequest
+
+	if err := ctx.Bind(&request); err != nil {
+		return problem.Respond(apperrors.Validation(fmt.Errorf("error while reading batch: %v", err)))
+	}
+
+	if h.authorizeBatch != nil {
+		authorized := make(map[string]bool)
+
+		for _, op := range request.Operations {
+			if authorized[op.Op] {
+				continue
+			}
+
+			if err := h.authorizeBatch(op.Op, ctx); err != nil {
+				return problem.Respond(err)
+			}
+
+			authorized[op.Op] = true
+		}
+	}
+
+	resp, err := h.UserService.Batch(request, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	for i := range resp.Results {
+		batchResult(&resp.Results[i])
+	}
+
+	return resp, nil
+}
+
+// batchStatus is the status code of a successful operation of a batch request.
+var batchStatus = map[string]int{
+	entities.BatchCreate: http.StatusCreated,
+	entities.BatchUpdate: http.StatusOK,
+	entities.BatchDelete: http.StatusNoContent,
+}
+
+// batchResult sets the status code of result and describes its error as problem details.
+func batchResult(result *entities.BatchResult) {
+	if result.Error != nil {
+		details := problem.New(result.Error)
+		result.Status, result.Error = details.Status, details
+
+		return
+	}
+
+	result.Status = batchStatus[result.Op]
+
+	if result.User != nil {
+		result.ETag = etag.Format(result.User.Version)
+	}
+}
+
+// ExportUsers streams the users matching the filters of GetUsers as a file in the format given by the
+// format query parameter, csv (the default) or ndjson. The users are written as they are read from
+// the store, an export of all users is never held in memory.
+func (h *Handler) ExportUsers(ctx *gofr.Context) (any, error) {
+	format := ctx.Param("format")
+	if format == "" {
+		format = userio.CSV
+	}
+
+	if userio.ContentType(format) == "" {
+		return problem.Respond(apperrors.Validation(validation.Errors{{Field: "format", Code: validation.CodeInvalidValue,
+			Message: "format must be csv or ndjson"}}))
+	}
+
+	query, err := h.listQuery(ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	w, ok := stream.Writer(ctx.Request.Context())
+	if !ok {
+		return problem.Respond(errors.New("response cannot be streamed"))
+	}
+
+	writer, err := userio.NewWriter(format, &fileWriter{w: w, format: format, ctx: ctx})
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	if err := h.UserService.ExportUsers(query, writer.Write, ctx); err != nil {
+		return problem.Respond(err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return problem.Respond(err)
+	}
+
+	return response.File{ContentType: userio.ContentType(format)}, nil
+}
+
+// fileWriter sets the headers of an exported file on its first write, so that they are not sent
+// along with the problem details of an export failing before any user was written.
+type fileWriter struct {
+	w       io.Writer
+	format  string
+	ctx     *gofr.Context
+	started bool
+}
+
+func (f *fileWriter) Write(b []byte) (int, error) {
+	if !f.started {
+		headers.Set(f.ctx.Request.Context(), "Content-Type", userio.ContentType(f.format))
+		headers.Set(f.ctx.Request.Context(), "Content-Disposition", `attachment; filename="users.`+f.format+`"`)
+		f.started = true
+	}
+
+	return f.w.Write(b)
+}
+
+// ImportUsers creates the users of the CSV or NDJSON file in the body, as told by its Content-Type.
+// With the query parameter upsert=true users that exist are updated instead, with dry_run=true the
+// file is only checked. The response counts the users imported and describes the error of every
+// line that was not.
+func (h *Handler) ImportUsers(ctx *gofr.Context) (any, error) {
+	format, ok := userio.FormatOf(headers.Get(ctx.Request.Context(), "Content-Type"))
+	if !ok {
+		return problem.Respond(apperrors.UnsupportedMediaType("Content-Type must be text/csv or application/x-ndjson"))
+	}
+
+	options, err := importOptions(ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	body := stream.Body(ctx.Request.Context())
+	if body == nil {
+		return problem.Respond(errors.New("request body cannot be streamed"))
+	}
+
+	result := h.UserService.ImportUsers(userio.Read(format, body), options, ctx)
+
+	for i := range result.Errors {
+		result.Errors[i].Error = problem.New(result.Errors[i].Error)
+	}
+
+	return result, nil
+}
+
+// importOptions parses the query parameters of an import request.
+func importOptions(ctx *gofr.Context) (entities.ImportOptions, error) {
+	var (
+		options entities.ImportOptions
+		errs    validation.Errors
+	)
+
+	for _, option := range []struct {
+		param string
+		value *bool
+	}{{"dry_run", &options.DryRun}, {"upsert", &options.Upsert}} {
+		value := ctx.Param(option.param)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, validation.FieldError{Field: option.param, Code: validation.CodeInvalidType,
+				Message: option.param + " must be true or false"})
+			continue
+		}
+
+		*option.value = parsed
+	}
+
+	if len(errs) > 0 {
+		return entities.ImportOptions{}, apperrors.Validation(errs)
+	}
+
+	return options, nil
+}
+
+// SearchUsers returns the users best matching the query parameter q by name, email or phone number,
+// best match first. It supports the query parameter limit.
+func (h *Handler) SearchUsers(ctx *gofr.Context) (any, error) {
+	format, err := accept(userFormats, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	query := entities.SearchQuery{Text: ctx.Param("q")}
+
+	if limit := ctx.Param("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil {
+			return problem.Respond(apperrors.Validation(validation.Errors{{Field: "limit", Code: validation.CodeInvalidType,
+				Message: "limit must be an integer"}}))
+		}
+	}
+
+	resp, err := h.UserService.SearchUsers(query, ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	return render(format, resp)
+}
+
+// RebuildSearchIndex repopulates the search index from the users of the store.
+func (h *Handler) RebuildSearchIndex(ctx *gofr.Context) (any, error) {
+	resp, err := h.UserService.RebuildSearchIndex(ctx)
+	if err != nil {
+		return problem.Respond(err)
+	}
+	return resp, nil
+}
+
+var errChangesDisabled = errors.New("change feed is disabled")
+
+// StreamChanges sends the changes of users as server-sent events while the client stays connected:
+// an event per change, named by its type, with the change as JSON data and its id as event id. A
+// client reconnecting with the Last-Event-ID header, or the last_event_id query parameter, is sent
+// the changes it missed first. If they are no longer known it is sent a reset event instead and
+// should read the users again. A client too slow to keep up is disconnected, it can resume the same
+// way.
+func (h *Handler) StreamChanges(ctx *gofr.Context) (any, error) {
+	if h.changes == nil {
+		return problem.Respond(apperrors.Unavailable(errChangesDisabled))
+	}
+
+	w, ok := stream.Writer(ctx.Request.Context())
+	if !ok {
+		return problem.Respond(errors.New("response cannot be streamed"))
+	}
+
+	subscription := h.changes.Subscribe(lastEventID(ctx))
+	defer subscription.Close()
+
+	headers.Set(ctx.Request.Context(), "Content-Type", eventStream)
+	headers.Set(ctx.Request.Context(), "Cache-Control", "no-cache")
+
+	// The headers are sent right away, clients know they are connected before the first change.
+	if err := stream.Flush(w); err != nil {
+		return problem.Respond(err)
+	}
+
+	if err := h.followChanges(ctx.Request.Context(), subscription, &eventWriter{w: w}); err != nil {
+		ctx.Errorf("streaming user changes: %v", err)
+	}
+
+	return response.File{ContentType: eventStream}, nil
+}
+
+// StreamChangesWebSocket sends the changes of users over a WebSocket, as JSON messages holding a
+// change, a {"type":"reset"} message where StreamChanges sends a reset event, or a
+// {"type":"heartbeat"} message. The connection is resumed with the last_event_id query parameter.
+func (h *Handler) StreamChangesWebSocket(ctx *gofr.Context) (any, error) {
+	if h.changes == nil {
+		return nil, apperrors.Unavailable(errChangesDisabled)
+	}
+
+	subscription := h.changes.Subscribe(lastEventID(ctx))
+	defer subscription.Close()
+
+	return nil, h.followChanges(ctx.Request.Context(), subscription, socketWriter{ctx: ctx})
+}
+
+// eventStream is the media type of server-sent events.
+const eventStream = "text/event-stream"
+
+// lastEventID returns the id of the last change the client received before reconnecting.
+func lastEventID(ctx *gofr.Context) string {
+	if id := headers.Get(ctx.Request.Context(), "Last-Event-ID"); id != "" {
+		return id
+	}
+
+	return ctx.Param("last_event_id")
+}
+
+// changeWriter sends the change feed to a client.
+type changeWriter interface {
+	change(change entities.UserChange) error
+	reset() error
+	heartbeat() error
+}
+
+// followChanges writes the changes of subscription to w until ctx is done, w fails or the subscriber
+// is dropped for falling behind.
+func (h *Handler) followChanges(ctx context.Context, subscription *changes.Subscription, w changeWriter) error {
+	if subscription.Expired {
+		if err := w.reset(); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range subscription.Backlog {
+		if err := w.change(change); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case change, ok := <-subscription.Changes():
+			if !ok {
+				return nil
+			}
+
+			err = w.change(change)
+		case <-ticker.C:
+			err = w.heartbeat()
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// eventWriter writes the change feed as server-sent events.
+type eventWriter struct {
+	w io.Writer
+}
+
+func (e *eventWriter) change(change entities.UserChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	return e.write(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", change.ID, change.Type, data))
+}
+
+func (e *eventWriter) reset() error {
+	return e.write("event: reset\ndata: {}\n\n")
+}
+
+// heartbeat writes a comment, which clients ignore.
+func (e *eventWriter) heartbeat() error {
+	return e.write(": heartbeat\n\n")
+}
+
+func (e *eventWriter) write(event string) error {
+	if _, err := io.WriteString(e.w, event); err != nil {
+		return err
+	}
+
+	return stream.Flush(e.w)
+}
+
+// socketWriter writes the change feed as WebSocket messages.
+type socketWriter struct {
+	ctx *gofr.Context
+}
+
+func (s socketWriter) change(change entities.UserChange) error {
+	return s.ctx.WriteMessageToSocket(change)
+}
+
+func (s socketWriter) reset() error {
+	return s.ctx.WriteMessageToSocket(map[string]string{"type": "reset"})
+}
+
+func (s socketWriter) heartbeat() error {
+	return s.ctx.WriteMessageToSocket(map[string]string{"type": "heartbeat"})
+}
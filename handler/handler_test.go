@@ -1,71 +1,126 @@
 package handler_test
 
 import (
+	"context"
 	"fmt"
 	"github.com/pkg/errors"
+	"iter"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
 	"go.uber.org/mock/gomock"
 	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/datasource"
 
 	gofrHttp "gofr.dev/pkg/gofr/http"
+	"gofr.dev/pkg/gofr/http/response"
+	"gofrProject/apperrors"
+	"gofrProject/auth"
+	"gofrProject/authz"
+	"gofrProject/changes"
 	"gofrProject/entities"
+	"gofrProject/etag"
 	"gofrProject/handler"
+	"gofrProject/headers"
+	"gofrProject/problem"
+	"gofrProject/stream"
+	"gofrProject/validation"
 )
 
+// problemResponse is the problem details body the handlers return alongside err.
+func problemResponse(err error) interface{} {
+	res, _ := problem.Respond(err)
+	return res
+}
+
 func Test_GetUsers(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockService := handler.NewMockUserService(ctrl)
 	h := handler.NewUserHandler(mockService)
 
+	minAge := 18
+	cursor := entities.UserCursor{UserName: "adam", UserAge: 25}
+	invalidQueryErr := apperrors.Validation(validation.Errors{
+		{Field: "limit", Code: validation.CodeInvalidType, Message: "limit must be an integer"},
+		{Field: "cursor", Code: validation.CodeInvalidValue, Message: "cursor is not valid"},
+		{Field: "order", Code: validation.CodeInvalidValue, Message: "order must be asc or desc"},
+		{Field: "max_age", Code: validation.CodeInvalidType, Message: "max_age must be an integer"},
+		{Field: "include_deleted", Code: validation.CodeInvalidType, Message: "include_deleted must be true or false"},
+	})
+	page := entities.UsersPage{
+		Users: []entities.Users{
+			{UserName: "waheed",
+				UserAge:     19,
+				PhoneNumber: "12345354",
+				Email:       "waheed@example.com"},
+		},
+		TotalCount: 1,
+	}
+
 	tests := []struct {
 		name             string
-		pathParam        string
+		queryParams      string
 		mockExpect       func()
 		expectedResponse interface{}
 		expectedErr      error
 	}{
 		{
-			name:      "successful getuser",
-			pathParam: "waheed",
+			name:        "successful getuser",
+			queryParams: "",
 			mockExpect: func() {
-				mockService.EXPECT().GetUsers(gomock.Any()).Return([]entities.Users{
-					{UserName: "waheed",
-						UserAge:     19,
-						PhoneNumber: "12345354",
-						Email:       "waheed@example.com"},
-				}, nil)
+				mockService.EXPECT().GetUsers(entities.UsersQuery{}, gomock.Any()).Return(page, nil)
 			},
-			expectedResponse: []entities.Users{
-				{UserName: "waheed",
-					UserAge:     19,
-					PhoneNumber: "12345354",
-					Email:       "waheed@example.com"},
+			expectedResponse: page,
+			expectedErr:      nil,
+		},
+		{
+			name: "pagination, sorting and filters",
+			queryParams: "?limit=10&cursor=" + cursor.Encode() +
+				"&sort=user_age&order=desc&min_age=18&email_domain=example.com&include_deleted=true",
+			mockExpect: func() {
+				mockService.EXPECT().GetUsers(entities.UsersQuery{
+					Limit:          10,
+					After:          &cursor,
+					SortBy:         entities.SortByUserAge,
+					Descending:     true,
+					MinAge:         &minAge,
+					EmailDomain:    "example.com",
+					IncludeDeleted: true,
+				}, gomock.Any()).Return(page, nil)
 			},
-			expectedErr: nil,
+			expectedResponse: page,
+			expectedErr:      nil,
 		},
-
 		{
-			name:      "user not found",
-			pathParam: "waheed",
+			name:             "invalid query parameters",
+			queryParams:      "?limit=ten&cursor=bogus&order=up&max_age=old&include_deleted=maybe",
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(invalidQueryErr),
+			expectedErr:      invalidQueryErr,
+		},
+		{
+			name:        "user not found",
+			queryParams: "",
 			mockExpect: func() {
-				mockService.EXPECT().GetUsers(gomock.Any()).Return(nil, errors.New("user not found"))
+				mockService.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(entities.UsersPage{}, errors.New("user not found"))
 			},
-			expectedResponse: nil,
+			expectedResponse: problemResponse(errors.New("user not found")),
 			expectedErr:      errors.New("user not found"),
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			req := httptest.NewRequest(http.MethodGet, "/user", nil)
+			req := httptest.NewRequest(http.MethodGet, "/user"+test.queryParams, nil)
 			req.Header.Set("Content-Type", "application/json")
 
-			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{"name": test.pathParam}))
+			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{}))
 
 			c := &gofr.Context{
 				Context: nil,
@@ -86,6 +141,94 @@ func Test_GetUsers(t *testing.T) {
 	}
 }
 
+// mockConfig is the configuration of an authz.Policy.
+type mockConfig map[string]string
+
+func (c mockConfig) Get(key string) string {
+	return c[key]
+}
+
+func Test_GetUsers_IncludeDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+
+	policy, err := authz.NewPolicy(mockConfig{"AUTHZ_ROLES": "alice:admin,carol:reader"})
+	require.NoError(t, err)
+
+	h := handler.NewUserHandler(mockService, handler.AuthorizeReadDeleted(func(ctx *gofr.Context) error {
+		return policy.Authorize(ctx, authz.ReadDeletedUsers)
+	}))
+
+	forbidden := authz.ErrForbidden{Principal: "carol", Permission: authz.ReadDeletedUsers}
+
+	tests := []struct {
+		name             string
+		principal        string
+		queryParams      string
+		mockExpect       func()
+		expectedResponse interface{}
+		expectedErr      error
+	}{
+		{
+			name:        "deleted users listed with the permission",
+			principal:   "alice",
+			queryParams: "?include_deleted=1",
+			mockExpect: func() {
+				mockService.EXPECT().GetUsers(entities.UsersQuery{IncludeDeleted: true}, gomock.Any()).
+					Return(entities.UsersPage{}, nil)
+			},
+			expectedResponse: entities.UsersPage{},
+		},
+		{
+			name:             "deleted users listed without the permission",
+			principal:        "carol",
+			queryParams:      "?include_deleted=1",
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(forbidden),
+			expectedErr:      forbidden,
+		},
+		{
+			name:             "other spelling of true",
+			principal:        "carol",
+			queryParams:      "?include_deleted=True",
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(forbidden),
+			expectedErr:      forbidden,
+		},
+		{
+			name:        "deleted users left out",
+			principal:   "carol",
+			queryParams: "?include_deleted=0",
+			mockExpect: func() {
+				mockService.EXPECT().GetUsers(entities.UsersQuery{}, gomock.Any()).Return(entities.UsersPage{}, nil)
+			},
+			expectedResponse: entities.UsersPage{},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user"+test.queryParams, nil)
+
+			c := &gofr.Context{
+				Context: auth.WithPrincipal(context.Background(), &auth.Principal{Name: test.principal}),
+				Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{})),
+			}
+			test.mockExpect()
+
+			res, err := h.GetUsers(c)
+
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr, "TEST[%d] failed: %s", i, test.name)
+			} else {
+				assert.NoError(t, err, "TEST[%d] failed: %s", i, test.name)
+			}
+
+			assert.Equal(t, test.expectedResponse, res, "TEST[%d] failed: %s", i, test.name)
+		})
+	}
+}
+
 func Test_GetUserByName(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockService := handler.NewMockUserService(ctrl)
@@ -121,10 +264,22 @@ func Test_GetUserByName(t *testing.T) {
 			name:      "user not found",
 			pathParam: "waheed",
 			mockExpect: func() {
-				mockService.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(entities.Users{}, errors.New("user not found"))
+				mockService.EXPECT().GetUsersByName("waheed", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "name", "waheed"))
 			},
-			expectedResponse: entities.Users{},
-			expectedErr:      errors.New("user not found"),
+			expectedResponse: problemResponse(apperrors.NotFound("user", "name", "waheed")),
+			expectedErr:      apperrors.NotFound("user", "name", "waheed"),
+		},
+		{
+			name:      "store error is not disclosed",
+			pathParam: "waheed",
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(entities.Users{},
+					datasource.ErrorDB{Err: errors.New("Error 1146: Table 'users.users' doesn't exist"), Message: "error from sql db"})
+			},
+			expectedResponse: response.File{ContentType: problem.ContentType, Content: []byte(`{"type":"about:blank",` +
+				`"title":"Internal Server Error","status":500,"detail":"internal server error"}`)},
+			expectedErr: errors.New("error from sql db: Error 1146: Table 'users.users' doesn't exist"),
 		},
 	}
 
@@ -181,9 +336,24 @@ func Test_AddUser(t *testing.T) {
 			mockExpect: func() {
 				mockService.EXPECT().AddUsers(gomock.Any(), gomock.Any()).Return(fmt.Errorf("error while adding user"))
 			},
-			expectedResponse: nil,
+			expectedResponse: problemResponse(fmt.Errorf("error while adding user")),
 			expectedErr:      fmt.Errorf("error while adding user"),
 		},
+		{
+			name:      "invalid user",
+			inputBody: `{"user_name": "waheed", "user_age": 19, "phone_Number": "12345354"}`,
+			mockExpect: func() {
+				mockService.EXPECT().AddUsers(gomock.Any(), gomock.Any()).Return(validation.Errors{
+					{Field: "email", Code: validation.CodeRequired, Message: "email is required"},
+				})
+			},
+			expectedResponse: problemResponse(validation.Errors{
+				{Field: "email", Code: validation.CodeRequired, Message: "email is required"},
+			}),
+			expectedErr: validation.Errors{
+				{Field: "email", Code: validation.CodeRequired, Message: "email is required"},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -216,9 +386,13 @@ func Test_UpdateUser(t *testing.T) {
 	mockService := handler.NewMockUserService(ctrl)
 	h := handler.NewUserHandler(mockService)
 
+	invalidBodyErr := fmt.Errorf("error while updating user: json: cannot unmarshal string into " +
+		"Go struct field Users.user_age of type int")
+
 	tests := []struct {
 		name             string
 		pathParam        string
+		inputBody        string
 		mockExpect       func()
 		expectedResponse interface{}
 		expectedErr      error
@@ -226,8 +400,13 @@ func Test_UpdateUser(t *testing.T) {
 		{
 			name:      "successful update user",
 			pathParam: "waheed",
+			inputBody: `{"user_age": 20, "phone_Number": "12345354", "email": "waheed@example.com"}`,
 			mockExpect: func() {
-				mockService.EXPECT().UpdateUsers("waheed", gomock.Any(), gomock.Any()).Return(nil)
+				mockService.EXPECT().UpdateUsers("waheed", 0, &entities.Users{
+					UserAge:     20,
+					PhoneNumber: "12345354",
+					Email:       "waheed@example.com",
+				}, gomock.Any()).Return(nil)
 			},
 			expectedResponse: nil,
 			expectedErr:      nil,
@@ -235,17 +414,26 @@ func Test_UpdateUser(t *testing.T) {
 		{
 			name:      "error while updating user",
 			pathParam: "waheed",
+			inputBody: `{"user_age": 20, "phone_Number": "12345354", "email": "waheed@example.com"}`,
 			mockExpect: func() {
-				mockService.EXPECT().UpdateUsers("waheed", gomock.Any(), gomock.Any()).Return(fmt.Errorf("error while updating user"))
+				mockService.EXPECT().UpdateUsers("waheed", 0, gomock.Any(), gomock.Any()).Return(fmt.Errorf("error while updating user"))
 			},
-			expectedResponse: nil,
+			expectedResponse: problemResponse(fmt.Errorf("error while updating user")),
 			expectedErr:      fmt.Errorf("error while updating user"),
 		},
+		{
+			name:             "invalid request body",
+			pathParam:        "waheed",
+			inputBody:        `{"user_age": "twenty"}`,
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(apperrors.Validation(invalidBodyErr)),
+			expectedErr:      invalidBodyErr,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			req := httptest.NewRequest(http.MethodPut, "/user/{name}", nil)
+			req := httptest.NewRequest(http.MethodPut, "/user/{name}", strings.NewReader(test.inputBody))
 			req.Header.Set("Content-Type", "application/json")
 
 			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{"name": test.pathParam}))
@@ -268,6 +456,111 @@ func Test_UpdateUser(t *testing.T) {
 	}
 }
 
+func Test_PatchUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	immutableErr := apperrors.Validation(validation.Errors{
+		{Field: "user_name", Code: validation.CodeImmutable, Message: "user_name cannot be changed"},
+	})
+	unsupported := apperrors.UnsupportedMediaType("Content-Type must be one of application/merge-patch+json, " +
+		"application/json")
+	malformedErr := apperrors.Validation(errors.New("error while patching user: unexpected EOF"))
+
+	tests := []struct {
+		name             string
+		pathParam        string
+		contentType      string
+		inputBody        string
+		mockExpect       func()
+		expectedResponse interface{}
+		expectedErr      error
+	}{
+		{
+			name:        "successful patch user",
+			pathParam:   "waheed",
+			contentType: "application/json",
+			inputBody:   `{"email": "waheed@example.org", "phone_Number": null}`,
+			mockExpect: func() {
+				mockService.EXPECT().PatchUsers("waheed", 0, map[string]any{
+					"email":        "waheed@example.org",
+					"phone_Number": nil,
+				}, gomock.Any()).Return(nil)
+			},
+			expectedResponse: nil,
+			expectedErr:      nil,
+		},
+		{
+			name:        "merge patch",
+			pathParam:   "waheed",
+			contentType: "application/merge-patch+json; charset=utf-8",
+			inputBody:   `{"email": "waheed@example.org"}`,
+			mockExpect: func() {
+				mockService.EXPECT().PatchUsers("waheed", 0, map[string]any{"email": "waheed@example.org"},
+					gomock.Any()).Return(nil)
+			},
+			expectedResponse: nil,
+			expectedErr:      nil,
+		},
+		{
+			name:             "unsupported content type",
+			pathParam:        "waheed",
+			contentType:      "application/xml",
+			inputBody:        `<user><email>waheed@example.org</email></user>`,
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(unsupported),
+			expectedErr:      unsupported,
+		},
+		{
+			name:             "malformed patch",
+			pathParam:        "waheed",
+			contentType:      "application/merge-patch+json",
+			inputBody:        `{"email": `,
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(malformedErr),
+			expectedErr:      malformedErr,
+		},
+		{
+			name:        "error while patching user",
+			pathParam:   "waheed",
+			contentType: "application/json",
+			inputBody:   `{"user_name": "albert"}`,
+			mockExpect: func() {
+				mockService.EXPECT().PatchUsers("waheed", 0, gomock.Any(), gomock.Any()).
+					Return(immutableErr)
+			},
+			expectedResponse: problemResponse(immutableErr),
+			expectedErr:      immutableErr,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/user/{name}", strings.NewReader(test.inputBody))
+			req.Header.Set("Content-Type", test.contentType)
+			w := httptest.NewRecorder()
+
+			ctx, _ := stream.With(headers.With(req.Context(), req.Header, w.Header()), req.Body, w)
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req.WithContext(ctx), map[string]string{"name": test.pathParam})),
+			}
+			test.mockExpect()
+
+			res, err := h.PatchUser(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedResponse, res)
+		})
+	}
+}
+
 func Test_DeleteUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockService := handler.NewMockUserService(ctrl)
@@ -284,7 +577,7 @@ func Test_DeleteUser(t *testing.T) {
 			name:      "successful delete user",
 			pathParam: "waheed",
 			mockExpect: func() {
-				mockService.EXPECT().DeleteUsers("waheed", gomock.Any()).Return(nil)
+				mockService.EXPECT().DeleteUsers("waheed", 0, gomock.Any()).Return(nil)
 			},
 			expectedResponse: nil,
 			expectedErr:      nil,
@@ -293,9 +586,9 @@ func Test_DeleteUser(t *testing.T) {
 			name:      "error while deleting user",
 			pathParam: "waheed",
 			mockExpect: func() {
-				mockService.EXPECT().DeleteUsers("waheed", gomock.Any()).Return(fmt.Errorf("error while deleting user"))
+				mockService.EXPECT().DeleteUsers("waheed", 0, gomock.Any()).Return(fmt.Errorf("error while deleting user"))
 			},
-			expectedResponse: nil,
+			expectedResponse: problemResponse(fmt.Errorf("error while deleting user")),
 			expectedErr:      fmt.Errorf("error while deleting user"),
 		},
 	}
@@ -324,3 +617,1288 @@ func Test_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+const testUserID = "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f"
+
+func Test_GetUserByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse interface{}
+		expectedErr      error
+	}{
+		{
+			name: "user found",
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByID(testUserID, gomock.Any()).
+					Return(entities.Users{ID: testUserID, UserName: "waheed"}, nil)
+			},
+			expectedResponse: entities.Users{ID: testUserID, UserName: "waheed"},
+			expectedErr:      nil,
+		},
+		{
+			name: "user not found",
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByID(testUserID, gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "id", testUserID))
+			},
+			expectedResponse: problemResponse(apperrors.NotFound("user", "id", testUserID)),
+			expectedErr:      apperrors.NotFound("user", "id", testUserID),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/{id}", nil)
+
+			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{"id": testUserID}))
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrR,
+			}
+			test.mockExpect()
+
+			res, err := h.GetUserByID(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedResponse, res)
+		})
+	}
+}
+
+func Test_UpdateUserByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse interface{}
+		expectedErr      error
+	}{
+		{
+			name: "update addressed by id",
+			mockExpect: func() {
+				mockService.EXPECT().UpdateUsersByID(testUserID, 0, &entities.Users{UserAge: 20, PhoneNumber: "+14155552671",
+					Email: "waheed@example.com"}, gomock.Any()).Return(nil)
+			},
+			expectedResponse: nil,
+			expectedErr:      nil,
+		},
+		{
+			name: "user not found",
+			mockExpect: func() {
+				mockService.EXPECT().UpdateUsersByID(testUserID, 0, gomock.Any(), gomock.Any()).
+					Return(apperrors.NotFound("user", "id", testUserID))
+			},
+			expectedResponse: problemResponse(apperrors.NotFound("user", "id", testUserID)),
+			expectedErr:      apperrors.NotFound("user", "id", testUserID),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/users/{id}",
+				strings.NewReader(`{"user_age":20,"phone_Number":"+14155552671","email":"waheed@example.com"}`))
+			req.Header.Set("Content-Type", "application/json")
+
+			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{"id": testUserID}))
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrR,
+			}
+			test.mockExpect()
+
+			res, err := h.UpdateUserByID(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedResponse, res)
+		})
+	}
+}
+
+func Test_RenameUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	tests := []struct {
+		name             string
+		body             string
+		mockExpect       func()
+		expectedResponse interface{}
+		expectedErr      error
+	}{
+		{
+			name: "successful rename",
+			body: `{"user_name":"waheed2"}`,
+			mockExpect: func() {
+				mockService.EXPECT().RenameUsers(testUserID, "waheed2", gomock.Any()).
+					Return(entities.Users{ID: testUserID, UserName: "waheed2"}, nil)
+			},
+			expectedResponse: entities.Users{ID: testUserID, UserName: "waheed2"},
+			expectedErr:      nil,
+		},
+		{
+			name: "name already taken",
+			body: `{"user_name":"alice"}`,
+			mockExpect: func() {
+				mockService.EXPECT().RenameUsers(testUserID, "alice", gomock.Any()).
+					Return(entities.Users{}, apperrors.AlreadyExists("user", "name", "alice"))
+			},
+			expectedResponse: problemResponse(apperrors.AlreadyExists("user", "name", "alice")),
+			expectedErr:      apperrors.AlreadyExists("user", "name", "alice"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/users/{id}/rename", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{"id": testUserID}))
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrR,
+			}
+			test.mockExpect()
+
+			res, err := h.RenameUser(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedResponse, res)
+		})
+	}
+}
+
+func Test_RestoreUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse interface{}
+		expectedErr      error
+	}{
+		{
+			name: "successful restore",
+			mockExpect: func() {
+				mockService.EXPECT().RestoreUsers("waheed", gomock.Any()).
+					Return(entities.Users{ID: testUserID, UserName: "waheed"}, nil)
+			},
+			expectedResponse: entities.Users{ID: testUserID, UserName: "waheed"},
+			expectedErr:      nil,
+		},
+		{
+			name: "user is not deleted",
+			mockExpect: func() {
+				mockService.EXPECT().RestoreUsers("waheed", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("deleted user", "name", "waheed"))
+			},
+			expectedResponse: problemResponse(apperrors.NotFound("deleted user", "name", "waheed")),
+			expectedErr:      apperrors.NotFound("deleted user", "name", "waheed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/user/{name}/restore", nil)
+
+			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{"name": "waheed"}))
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrR,
+			}
+			test.mockExpect()
+
+			res, err := h.RestoreUser(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedResponse, res)
+		})
+	}
+}
+
+func Test_GetUserHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	cursor := entities.AuditCursor{ID: 7}
+	page := entities.AuditPage{
+		Entries: []entities.AuditEntry{{ID: 6, Actor: "waheed", Action: entities.AuditCreate, UserID: testUserID,
+			UserName: "waheed", Changes: []byte(`{}`)}},
+	}
+
+	tests := []struct {
+		name             string
+		queryParams      string
+		mockExpect       func()
+		expectedResponse interface{}
+		expectedErr      error
+	}{
+		{
+			name:        "history of the user",
+			queryParams: "?limit=10&cursor=" + cursor.Encode(),
+			mockExpect: func() {
+				mockService.EXPECT().GetUserHistory("waheed", entities.AuditQuery{Limit: 10, Before: &cursor}, gomock.Any()).
+					Return(page, nil)
+			},
+			expectedResponse: page,
+			expectedErr:      nil,
+		},
+		{
+			name:        "user not found",
+			queryParams: "",
+			mockExpect: func() {
+				mockService.EXPECT().GetUserHistory("waheed", entities.AuditQuery{}, gomock.Any()).
+					Return(entities.AuditPage{}, apperrors.NotFound("user", "name", "waheed"))
+			},
+			expectedResponse: problemResponse(apperrors.NotFound("user", "name", "waheed")),
+			expectedErr:      apperrors.NotFound("user", "name", "waheed"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user/waheed/history"+test.queryParams, nil)
+
+			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{"name": "waheed"}))
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrR,
+			}
+			test.mockExpect()
+
+			res, err := h.GetUserHistory(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedResponse, res)
+		})
+	}
+}
+
+func Test_GetAuditLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	invalidQueryErr := apperrors.Validation(validation.Errors{
+		{Field: "limit", Code: validation.CodeInvalidType, Message: "limit must be an integer"},
+		{Field: "cursor", Code: validation.CodeInvalidValue, Message: "cursor is not valid"},
+		{Field: "from", Code: validation.CodeInvalidType, Message: "from must be an RFC 3339 timestamp"},
+		{Field: "to", Code: validation.CodeInvalidType, Message: "to must be an RFC 3339 timestamp"},
+	})
+	page := entities.AuditPage{
+		Entries: []entities.AuditEntry{{ID: 6, Actor: "admin", Action: entities.AuditDelete, UserID: testUserID,
+			UserName: "waheed", Changes: []byte(`{}`)}},
+	}
+
+	tests := []struct {
+		name             string
+		queryParams      string
+		mockExpect       func()
+		expectedResponse interface{}
+		expectedErr      error
+	}{
+		{
+			name:        "filters by actor and time range",
+			queryParams: "?actor=admin&from=2025-01-01T00:00:00Z&to=2025-02-01T00:00:00Z",
+			mockExpect: func() {
+				mockService.EXPECT().GetAuditLog(entities.AuditQuery{Actor: "admin", From: from, To: to}, gomock.Any()).
+					Return(page, nil)
+			},
+			expectedResponse: page,
+			expectedErr:      nil,
+		},
+		{
+			name:             "invalid query parameters",
+			queryParams:      "?limit=ten&cursor=bogus&from=yesterday&to=2025-02-01",
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(invalidQueryErr),
+			expectedErr:      invalidQueryErr,
+		},
+		{
+			name:        "error from service",
+			queryParams: "",
+			mockExpect: func() {
+				mockService.EXPECT().GetAuditLog(entities.AuditQuery{}, gomock.Any()).
+					Return(entities.AuditPage{}, errors.New("db error"))
+			},
+			expectedResponse: problemResponse(errors.New("db error")),
+			expectedErr:      errors.New("db error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/audit"+test.queryParams, nil)
+
+			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{}))
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrR,
+			}
+			test.mockExpect()
+
+			res, err := h.GetAuditLog(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedResponse, res)
+		})
+	}
+}
+
+func Test_PurgeUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse interface{}
+		expectedErr      error
+	}{
+		{
+			name: "successful purge",
+			mockExpect: func() {
+				mockService.EXPECT().PurgeUsers(gomock.Any()).Return(entities.PurgeResult{Purged: 2}, nil)
+			},
+			expectedResponse: entities.PurgeResult{Purged: 2},
+			expectedErr:      nil,
+		},
+		{
+			name: "error while purging",
+			mockExpect: func() {
+				mockService.EXPECT().PurgeUsers(gomock.Any()).Return(entities.PurgeResult{}, errors.New("db error"))
+			},
+			expectedResponse: problemResponse(errors.New("db error")),
+			expectedErr:      errors.New("db error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/user/purge", nil)
+
+			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{}))
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrR,
+			}
+			test.mockExpect()
+
+			res, err := h.PurgeUsers(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedResponse, res)
+		})
+	}
+}
+
+func Test_ConditionalRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+
+	user := entities.Users{ID: testUserID, UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "waheed@example.com", Version: 3}
+	body := `{"user_age":20,"phone_Number":"+14155552671","email":"waheed@example.com"}`
+	updateUser := &entities.Users{UserAge: 20, PhoneNumber: "+14155552671", Email: "waheed@example.com"}
+
+	tests := []struct {
+		name             string
+		method           string
+		requireIfMatch   bool
+		requestHeaders   map[string]string
+		mockExpect       func()
+		expectedResponse interface{}
+		expectedErr      error
+		expectedETag     string
+	}{
+		{
+			name:   "read sends the etag",
+			method: http.MethodGet,
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(user, nil)
+			},
+			expectedResponse: user,
+			expectedETag:     `"3"`,
+		},
+		{
+			name:           "read of an unchanged user is not modified",
+			method:         http.MethodGet,
+			requestHeaders: map[string]string{"If-None-Match": `W/"3"`},
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(user, nil)
+			},
+			expectedResponse: nil,
+			expectedErr:      etag.ErrNotModified,
+			expectedETag:     `"3"`,
+		},
+		{
+			name:           "read of a changed user",
+			method:         http.MethodGet,
+			requestHeaders: map[string]string{"If-None-Match": `"2"`},
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(user, nil)
+			},
+			expectedResponse: user,
+			expectedETag:     `"3"`,
+		},
+		{
+			name:           "update of the current version",
+			method:         http.MethodPut,
+			requestHeaders: map[string]string{"If-Match": `"3"`},
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(user, nil)
+				mockService.EXPECT().UpdateUsers("waheed", 3, updateUser, gomock.Any()).Return(nil)
+			},
+			expectedResponse: nil,
+		},
+		{
+			name:           "update of a stale version",
+			method:         http.MethodPut,
+			requestHeaders: map[string]string{"If-Match": `"2"`},
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(user, nil)
+			},
+			expectedResponse: problemResponse(apperrors.PreconditionFailed("user", "name", "waheed")),
+			expectedErr:      apperrors.PreconditionFailed("user", "name", "waheed"),
+		},
+		{
+			name:             "update without required if-match",
+			method:           http.MethodPut,
+			requireIfMatch:   true,
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(apperrors.PreconditionRequired("If-Match header is required")),
+			expectedErr:      apperrors.PreconditionRequired("If-Match header is required"),
+		},
+		{
+			name:           "delete of any version",
+			method:         http.MethodDelete,
+			requireIfMatch: true,
+			requestHeaders: map[string]string{"If-Match": "*"},
+			mockExpect: func() {
+				mockService.EXPECT().DeleteUsers("waheed", 0, gomock.Any()).Return(nil)
+			},
+			expectedResponse: nil,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := handler.NewUserHandler(mockService, handler.RequireIfMatch(test.requireIfMatch))
+
+			req := httptest.NewRequest(test.method, "/user/{name}", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			for key, value := range test.requestHeaders {
+				req.Header.Set(key, value)
+			}
+
+			responseHeaders := http.Header{}
+			req = req.WithContext(headers.With(req.Context(), req.Header, responseHeaders))
+
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{"name": "waheed"})),
+			}
+			test.mockExpect()
+
+			handlers := map[string]gofr.Handler{http.MethodGet: h.GetUserByName, http.MethodPut: h.UpdateUser,
+				http.MethodDelete: h.DeleteUser}
+
+			res, err := handlers[test.method](c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error(), "TEST[%d] failed: %s", i, test.name)
+			} else {
+				assert.NoError(t, err, "TEST[%d] failed: %s", i, test.name)
+			}
+
+			assert.Equal(t, test.expectedResponse, res, "TEST[%d] failed: %s", i, test.name)
+			assert.Equal(t, test.expectedETag, responseHeaders.Get("ETag"), "TEST[%d] failed: %s", i, test.name)
+		})
+	}
+}
+
+func Test_Batch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+
+	var authorized []string
+
+	h := handler.NewUserHandler(mockService, handler.AuthorizeBatch(func(op string, _ *gofr.Context) error {
+		authorized = append(authorized, op)
+
+		if op == entities.BatchDelete {
+			return apperrors.Validation(fmt.Errorf("delete is not allowed"))
+		}
+
+		return nil
+	}))
+
+	request := entities.BatchRequest{Atomic: true, Operations: []entities.BatchOperation{
+		{Op: entities.BatchCreate, User: &entities.Users{UserName: "adam"}},
+		{Op: entities.BatchUpdate, UserName: "waheed", User: &entities.Users{UserAge: 20}},
+		{Op: entities.BatchCreate, User: &entities.Users{UserName: "eve"}},
+	}}
+	notFound := apperrors.NotFound("user", "name", "waheed")
+
+	tests := []struct {
+		name               string
+		inputBody          string
+		mockExpect         func()
+		expectedResponse   interface{}
+		expectedErr        error
+		expectedAuthorized []string
+	}{
+		{
+			name: "results carry status codes and problem details",
+			inputBody: `{"atomic": true, "operations": [{"op": "create", "user": {"user_name": "adam"}},` +
+				`{"op": "update", "user_name": "waheed", "user": {"user_age": 20}},` +
+				`{"op": "create", "user": {"user_name": "eve"}}]}`,
+			mockExpect: func() {
+				mockService.EXPECT().Batch(request, gomock.Any()).Return(entities.BatchResponse{Atomic: true, Succeeded: 1,
+					Failed: 2, Results: []entities.BatchResult{
+						{Index: 0, Op: "create", User: &entities.Users{ID: testUserID, UserName: "adam", Version: 1}},
+						{Index: 1, Op: "update", Error: notFound},
+						{Index: 2, Op: "create", Error: apperrors.Aborted("another operation of the batch failed")},
+					}}, nil)
+			},
+			expectedResponse: entities.BatchResponse{Atomic: true, Succeeded: 1, Failed: 2, Results: []entities.BatchResult{
+				{Index: 0, Op: "create", Status: http.StatusCreated,
+					User: &entities.Users{ID: testUserID, UserName: "adam", Version: 1}, ETag: etag.Format(1)},
+				{Index: 1, Op: "update", Status: http.StatusNotFound, Error: problem.New(notFound)},
+				{Index: 2, Op: "create", Status: http.StatusFailedDependency,
+					Error: problem.New(apperrors.Aborted("another operation of the batch failed"))},
+			}},
+			expectedAuthorized: []string{"create", "update"},
+		},
+		{
+			name:               "operation the principal may not perform",
+			inputBody:          `{"operations": [{"op": "create", "user": {"user_name": "adam"}}, {"op": "delete", "user_name": "waheed"}]}`,
+			mockExpect:         func() {},
+			expectedResponse:   problemResponse(apperrors.Validation(fmt.Errorf("delete is not allowed"))),
+			expectedErr:        apperrors.Validation(fmt.Errorf("delete is not allowed")),
+			expectedAuthorized: []string{"create", "delete"},
+		},
+		{
+			name:      "invalid batch",
+			inputBody: `{"operations": "all of them"}`,
+			mockExpect: func() {
+				mockService.EXPECT().Batch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedErr: apperrors.Validation(fmt.Errorf("error while reading batch")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authorized = nil
+
+			req := httptest.NewRequest(http.MethodPost, "/users:batch", strings.NewReader(test.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			gofrR := gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{}))
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrR,
+			}
+			test.mockExpect()
+
+			res, err := h.Batch(c)
+
+			if test.expectedErr != nil {
+				assert.ErrorContains(t, err, test.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if test.expectedResponse != nil {
+				assert.Equal(t, test.expectedResponse, res)
+			}
+
+			assert.Equal(t, test.expectedAuthorized, authorized)
+		})
+	}
+}
+
+func Test_ExportUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	minAge := 18
+	users := []entities.Users{
+		{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+			Email: "waheed@example.com"},
+		{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", UserName: "adam", UserAge: 40, PhoneNumber: "+14155552672",
+			Email: "adam@example.com"},
+	}
+	export := func(_ entities.UsersQuery, fn func(entities.Users) error, _ *gofr.Context) error {
+		for _, user := range users {
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+	invalidFormat := apperrors.Validation(validation.Errors{{Field: "format", Code: validation.CodeInvalidValue,
+		Message: "format must be csv or ndjson"}})
+
+	tests := []struct {
+		name                string
+		queryParams         string
+		mockExpect          func()
+		expectedResponse    any
+		expectedErr         error
+		expectedBody        string
+		expectedDisposition string
+	}{
+		{
+			name:        "csv by default",
+			queryParams: "",
+			mockExpect: func() {
+				mockService.EXPECT().ExportUsers(entities.UsersQuery{}, gomock.Any(), gomock.Any()).DoAndReturn(export)
+			},
+			expectedResponse: response.File{ContentType: "text/csv"},
+			expectedBody: "id,user_name,user_age,phone_Number,email\n" +
+				"0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f,waheed,19,+14155552671,waheed@example.com\n" +
+				"0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002,adam,40,+14155552672,adam@example.com\n",
+			expectedDisposition: `attachment; filename="users.csv"`,
+		},
+		{
+			name:        "filtered ndjson",
+			queryParams: "?format=ndjson&min_age=18",
+			mockExpect: func() {
+				mockService.EXPECT().ExportUsers(entities.UsersQuery{MinAge: &minAge}, gomock.Any(), gomock.Any()).
+					DoAndReturn(export)
+			},
+			expectedResponse: response.File{ContentType: "application/x-ndjson"},
+			expectedBody: `{"id":"0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f","user_name":"waheed","user_age":19,` +
+				`"phone_Number":"+14155552671","email":"waheed@example.com"}` + "\n" +
+				`{"id":"0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002","user_name":"adam","user_age":40,` +
+				`"phone_Number":"+14155552672","email":"adam@example.com"}` + "\n",
+			expectedDisposition: `attachment; filename="users.ndjson"`,
+		},
+		{
+			name:             "unknown format",
+			queryParams:      "?format=xlsx",
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(invalidFormat),
+			expectedErr:      invalidFormat,
+		},
+		{
+			name:        "export failing before any user was written",
+			queryParams: "",
+			mockExpect: func() {
+				mockService.EXPECT().ExportUsers(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(apperrors.Unavailable(errors.New("connection refused")))
+			},
+			expectedResponse: problemResponse(apperrors.Unavailable(errors.New("connection refused"))),
+			expectedErr:      apperrors.Unavailable(errors.New("connection refused")),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user/export"+test.queryParams, http.NoBody)
+			w := httptest.NewRecorder()
+
+			ctx, _ := stream.With(headers.With(req.Context(), req.Header, w.Header()), req.Body, w)
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req.WithContext(ctx), map[string]string{})),
+			}
+			test.mockExpect()
+
+			res, err := h.ExportUsers(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error(), "TEST[%d] failed: %s", i, test.name)
+			} else {
+				assert.NoError(t, err, "TEST[%d] failed: %s", i, test.name)
+			}
+
+			assert.Equal(t, test.expectedResponse, res, "TEST[%d] failed: %s", i, test.name)
+			assert.Equal(t, test.expectedBody, w.Body.String(), "TEST[%d] failed: %s", i, test.name)
+			assert.Equal(t, test.expectedDisposition, w.Header().Get("Content-Disposition"), "TEST[%d] failed: %s", i, test.name)
+		})
+	}
+}
+
+func Test_ExportUsers_IncludeDeleted(t *testing.T) {
+	mockService := handler.NewMockUserService(gomock.NewController(t))
+	forbidden := authz.ErrForbidden{Principal: "carol", Permission: authz.ReadDeletedUsers}
+
+	h := handler.NewUserHandler(mockService, handler.AuthorizeReadDeleted(func(*gofr.Context) error {
+		return forbidden
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/user/export?include_deleted=1", nil)
+	c := &gofr.Context{Context: context.Background(), Request: gofrHttp.NewRequest(req)}
+
+	res, err := h.ExportUsers(c)
+
+	assert.ErrorIs(t, err, forbidden)
+	assert.Equal(t, problemResponse(forbidden), res)
+}
+
+func Test_ImportUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	file := "user_name,user_age,phone_Number,email\n" +
+		"waheed,19,+14155552671,waheed@example.com\n" +
+		"adam,40,+14155552672,adam@example.com\n"
+	exists := apperrors.AlreadyExists("user", "name", "adam")
+	unsupported := apperrors.UnsupportedMediaType("Content-Type must be text/csv or application/x-ndjson")
+	invalidOption := apperrors.Validation(validation.Errors{{Field: "upsert", Code: validation.CodeInvalidType,
+		Message: "upsert must be true or false"}})
+
+	tests := []struct {
+		name             string
+		queryParams      string
+		contentType      string
+		mockExpect       func()
+		expectedResponse any
+		expectedErr      error
+	}{
+		{
+			name:        "dry run of a csv file",
+			queryParams: "?dry_run=true",
+			contentType: "text/csv; charset=utf-8",
+			mockExpect: func() {
+				mockService.EXPECT().ImportUsers(gomock.Any(), entities.ImportOptions{DryRun: true}, gomock.Any()).
+					DoAndReturn(func(rows iter.Seq[entities.ImportRow], options entities.ImportOptions,
+						_ *gofr.Context) entities.ImportResult {
+						var names []string
+						for row := range rows {
+							names = append(names, row.User.UserName)
+						}
+
+						assert.Equal(t, []string{"waheed", "adam"}, names)
+
+						return entities.ImportResult{DryRun: true, Created: 1, Failed: 1, Errors: []entities.ImportError{
+							{Line: 3, UserName: "adam", Error: exists}}}
+					})
+			},
+			expectedResponse: entities.ImportResult{DryRun: true, Created: 1, Failed: 1, Errors: []entities.ImportError{
+				{Line: 3, UserName: "adam", Error: problem.New(exists)}}},
+		},
+		{
+			name:             "unsupported content type",
+			contentType:      "application/json",
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(unsupported),
+			expectedErr:      unsupported,
+		},
+		{
+			name:             "invalid option",
+			queryParams:      "?upsert=always",
+			contentType:      "application/x-ndjson",
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(invalidOption),
+			expectedErr:      invalidOption,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/user/import"+test.queryParams, strings.NewReader(file))
+			req.Header.Set("Content-Type", test.contentType)
+			w := httptest.NewRecorder()
+
+			ctx, _ := stream.With(headers.With(req.Context(), req.Header, w.Header()), req.Body, w)
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req.WithContext(ctx), map[string]string{})),
+			}
+			test.mockExpect()
+
+			res, err := h.ImportUsers(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error(), "TEST[%d] failed: %s", i, test.name)
+			} else {
+				assert.NoError(t, err, "TEST[%d] failed: %s", i, test.name)
+			}
+
+			assert.Equal(t, test.expectedResponse, res, "TEST[%d] failed: %s", i, test.name)
+		})
+	}
+}
+
+func Test_ResponseFormats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	waheed := entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", UserName: "waheed", UserAge: 19,
+		PhoneNumber: "+14155552671", Email: "waheed@example.com", Version: 2}
+	page := entities.UsersPage{Users: []entities.Users{waheed}, NextCursor: "next", TotalCount: 3}
+	notAcceptable := apperrors.NotAcceptable("Accept must allow one of application/json, application/xml, " +
+		"application/msgpack")
+
+	tests := []struct {
+		name             string
+		handler          func(ctx *gofr.Context) (any, error)
+		accept           string
+		mockExpect       func()
+		expectedResponse any
+		expectedErr      error
+		expectedHeaders  map[string]string
+	}{
+		{
+			name:    "list as json by default",
+			handler: h.GetUsers,
+			accept:  "",
+			mockExpect: func() {
+				mockService.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(page, nil)
+			},
+			expectedResponse: page,
+			expectedHeaders:  map[string]string{"Vary": "Accept"},
+		},
+		{
+			name:    "list as csv",
+			handler: h.GetUsers,
+			accept:  "text/csv",
+			mockExpect: func() {
+				mockService.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(page, nil)
+			},
+			expectedResponse: response.File{ContentType: "text/csv", Content: []byte(
+				"id,user_name,user_age,phone_Number,email\n" +
+					"0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f,waheed,19,+14155552671,waheed@example.com\n")},
+			expectedHeaders: map[string]string{"Vary": "Accept", "X-Total-Count": "3", "X-Next-Cursor": "next"},
+		},
+		{
+			name:    "list as xml",
+			handler: h.GetUsers,
+			accept:  "application/xml",
+			mockExpect: func() {
+				mockService.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(page, nil)
+			},
+			expectedResponse: response.File{ContentType: "application/xml", Content: []byte(
+				`<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><data><users><user>` +
+					`<id>0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f</id><user_name>waheed</user_name><user_age>19</user_age>` +
+					`<phone_Number>+14155552671</phone_Number><email>waheed@example.com</email></user></users>` +
+					`<next_cursor>next</next_cursor><total_count>3</total_count></data></response>`)},
+			expectedHeaders: map[string]string{"Vary": "Accept"},
+		},
+		{
+			name:    "user as xml",
+			handler: h.GetUserByName,
+			accept:  "text/xml",
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(waheed, nil)
+			},
+			expectedResponse: response.File{ContentType: "application/xml", Content: []byte(
+				`<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><data>` +
+					`<id>0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f</id><user_name>waheed</user_name><user_age>19</user_age>` +
+					`<phone_Number>+14155552671</phone_Number><email>waheed@example.com</email></data></response>`)},
+			expectedHeaders: map[string]string{"Vary": "Accept", "ETag": `"2"`},
+		},
+		{
+			name:             "user cannot be csv",
+			handler:          h.GetUserByName,
+			accept:           "text/csv",
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(notAcceptable),
+			expectedErr:      notAcceptable,
+			expectedHeaders:  map[string]string{"Vary": "Accept"},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user/waheed", http.NoBody)
+			req.Header.Set("Accept", test.accept)
+
+			responseHeaders := http.Header{}
+			req = req.WithContext(headers.With(req.Context(), req.Header, responseHeaders))
+
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{"name": "waheed"})),
+			}
+			test.mockExpect()
+
+			res, err := test.handler(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error(), "TEST[%d] failed: %s", i, test.name)
+			} else {
+				assert.NoError(t, err, "TEST[%d] failed: %s", i, test.name)
+			}
+
+			assert.Equal(t, test.expectedResponse, res, "TEST[%d] failed: %s", i, test.name)
+
+			for key, value := range test.expectedHeaders {
+				assert.Equal(t, value, responseHeaders.Get(key), "TEST[%d] failed: %s", i, test.name)
+			}
+		})
+	}
+}
+
+func Test_RequestFormats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	waheed := entities.Users{UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671", Email: "waheed@example.com"}
+	packed, _ := msgpack.Marshal(map[string]any{"user_name": "waheed", "user_age": 19, "phone_Number": "+14155552671",
+		"email": "waheed@example.com"})
+	unsupported := apperrors.UnsupportedMediaType("Content-Type must be one of application/json, application/xml, " +
+		"text/csv, application/msgpack")
+	twoUsers := apperrors.Validation(errors.New("error while adding user: body has 2 users, it must have 1"))
+
+	tests := []struct {
+		name             string
+		contentType      string
+		body             string
+		mockExpect       func()
+		expectedResponse any
+		expectedErr      error
+	}{
+		{
+			name:        "xml",
+			contentType: "application/xml; charset=utf-8",
+			body: `<user><user_name>waheed</user_name><user_age>19</user_age><phone_Number>+14155552671</phone_Number>` +
+				`<email>waheed@example.com</email></user>`,
+			mockExpect: func() {
+				mockService.EXPECT().AddUsers(&waheed, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:        "msgpack",
+			contentType: "application/msgpack",
+			body:        string(packed),
+			mockExpect: func() {
+				mockService.EXPECT().AddUsers(&waheed, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:        "csv",
+			contentType: "text/csv",
+			body:        "user_name,user_age,phone_Number,email\nwaheed,19,+14155552671,waheed@example.com\n",
+			mockExpect: func() {
+				mockService.EXPECT().AddUsers(&waheed, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:        "csv with more than one user",
+			contentType: "text/csv",
+			body: "user_name,user_age,phone_Number,email\nwaheed,19,+14155552671,waheed@example.com\n" +
+				"adam,40,+14155552672,adam@example.com\n",
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(twoUsers),
+			expectedErr:      twoUsers,
+		},
+		{
+			name:             "unsupported content type",
+			contentType:      "text/plain",
+			body:             "waheed",
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(unsupported),
+			expectedErr:      unsupported,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", test.contentType)
+			w := httptest.NewRecorder()
+
+			ctx, _ := stream.With(headers.With(req.Context(), req.Header, w.Header()), req.Body, w)
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req.WithContext(ctx), map[string]string{})),
+			}
+			test.mockExpect()
+
+			res, err := h.AddUser(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error(), "TEST[%d] failed: %s", i, test.name)
+			} else {
+				assert.NoError(t, err, "TEST[%d] failed: %s", i, test.name)
+			}
+
+			assert.Equal(t, test.expectedResponse, res, "TEST[%d] failed: %s", i, test.name)
+		})
+	}
+}
+
+func Test_SearchUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	page := entities.SearchPage{Results: []entities.SearchResult{{User: entities.Users{UserName: "waheed"}, Score: 10}},
+		TotalCount: 1}
+	invalidLimit := apperrors.Validation(validation.Errors{{Field: "limit", Code: validation.CodeInvalidType,
+		Message: "limit must be an integer"}})
+
+	tests := []struct {
+		name             string
+		queryParams      string
+		mockExpect       func()
+		expectedResponse any
+		expectedErr      error
+	}{
+		{
+			name:        "search",
+			queryParams: "?q=wah&limit=5",
+			mockExpect: func() {
+				mockService.EXPECT().SearchUsers(entities.SearchQuery{Text: "wah", Limit: 5}, gomock.Any()).Return(page, nil)
+			},
+			expectedResponse: page,
+		},
+		{
+			name:             "invalid limit",
+			queryParams:      "?q=wah&limit=five",
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(invalidLimit),
+			expectedErr:      invalidLimit,
+		},
+		{
+			name:        "search error",
+			queryParams: "?q=wah",
+			mockExpect: func() {
+				mockService.EXPECT().SearchUsers(gomock.Any(), gomock.Any()).
+					Return(entities.SearchPage{}, apperrors.Unavailable(errors.New("connection refused")))
+			},
+			expectedResponse: problemResponse(apperrors.Unavailable(errors.New("connection refused"))),
+			expectedErr:      apperrors.Unavailable(errors.New("connection refused")),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user/search"+test.queryParams, http.NoBody)
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{})),
+			}
+			test.mockExpect()
+
+			res, err := h.SearchUsers(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error(), "TEST[%d] failed: %s", i, test.name)
+			} else {
+				assert.NoError(t, err, "TEST[%d] failed: %s", i, test.name)
+			}
+
+			assert.Equal(t, test.expectedResponse, res, "TEST[%d] failed: %s", i, test.name)
+		})
+	}
+}
+
+func Test_RebuildSearchIndex(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/user/search/rebuild", http.NoBody)
+	c := &gofr.Context{
+		Context: nil,
+		Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{})),
+	}
+
+	mockService.EXPECT().RebuildSearchIndex(gomock.Any()).Return(entities.RebuildResult{Indexed: 42}, nil)
+
+	res, err := h.RebuildSearchIndex(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.RebuildResult{Indexed: 42}, res)
+}
+
+// liveFeed is a change feed publishing changes right after every subscription. With a buffer of a
+// single change the subscriber is sent the first of them and dropped, which ends the stream.
+type liveFeed struct {
+	log  *changes.Log
+	live []entities.UserChange
+}
+
+func (f liveFeed) Subscribe(lastEventID string) *changes.Subscription {
+	s := f.log.Subscribe(lastEventID)
+
+	for _, change := range f.live {
+		f.log.Publish(change)
+	}
+
+	return s
+}
+
+// publish publishes change to l and returns the id it was given.
+func publish(l *changes.Log, change entities.UserChange) string {
+	s := l.Subscribe("")
+	defer s.Close()
+
+	l.Publish(change)
+
+	return (<-s.Changes()).ID
+}
+
+func Test_StreamChanges(t *testing.T) {
+	timestamp := time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC)
+	created := entities.UserChange{Type: "user.created", UserID: testUserID, UserName: "waheed",
+		Changes: []byte(`{"user_name":{"before":null,"after":"waheed"}}`), Timestamp: timestamp}
+	deleted := entities.UserChange{Type: "user.deleted", UserID: testUserID, UserName: "waheed",
+		Changes: []byte(`{"deleted_at":{"before":null,"after":"2024-12-30T12:00:00Z"}}`), Timestamp: timestamp}
+
+	createdEvent := func(id string) string {
+		return "id: " + id + "\nevent: user.created\ndata: {\"id\":\"" + id + "\",\"type\":\"user.created\"," +
+			"\"user_id\":\"" + testUserID + "\",\"user_name\":\"waheed\"," +
+			"\"changes\":{\"user_name\":{\"before\":null,\"after\":\"waheed\"}},\"timestamp\":\"2024-12-30T12:00:00Z\"}\n\n"
+	}
+	deletedEvent := func(id string) string {
+		return "id: " + id + "\nevent: user.deleted\ndata: {\"id\":\"" + id + "\",\"type\":\"user.deleted\"," +
+			"\"user_id\":\"" + testUserID + "\",\"user_name\":\"waheed\"," +
+			"\"changes\":{\"deleted_at\":{\"before\":null,\"after\":\"2024-12-30T12:00:00Z\"}},\"timestamp\":\"2024-12-30T12:00:00Z\"}\n\n"
+	}
+
+	tests := []struct {
+		name         string
+		lastEventID  func(first string) (header, queryParam string)
+		live         []entities.UserChange
+		expectedBody func(epoch string) string
+	}{
+		{
+			name:         "new subscriber",
+			lastEventID:  func(string) (string, string) { return "", "" },
+			live:         []entities.UserChange{deleted, created},
+			expectedBody: func(epoch string) string { return deletedEvent(epoch + "-3") },
+		},
+		{
+			name:        "subscriber resuming with Last-Event-ID",
+			lastEventID: func(first string) (string, string) { return first, "" },
+			live:        []entities.UserChange{created, deleted},
+			expectedBody: func(epoch string) string {
+				return deletedEvent(epoch+"-2") + createdEvent(epoch+"-3")
+			},
+		},
+		{
+			name:        "subscriber resuming with last_event_id",
+			lastEventID: func(first string) (string, string) { return "", first },
+			live:        []entities.UserChange{created, deleted},
+			expectedBody: func(epoch string) string {
+				return deletedEvent(epoch+"-2") + createdEvent(epoch+"-3")
+			},
+		},
+		{
+			name:        "subscriber resuming from an unknown change",
+			lastEventID: func(string) (string, string) { return "1a2b3c-1", "" },
+			live:        []entities.UserChange{created, deleted},
+			expectedBody: func(epoch string) string {
+				return "event: reset\ndata: {}\n\n" + createdEvent(epoch+"-3")
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			log := changes.NewLog(changes.WithBuffer(1))
+			first := publish(log, created)
+			publish(log, deleted)
+
+			epoch, _, _ := strings.Cut(first, "-")
+			header, queryParam := test.lastEventID(first)
+
+			h := handler.NewUserHandler(handler.NewMockUserService(gomock.NewController(t)),
+				handler.FollowChanges(liveFeed{log: log, live: test.live}, time.Hour))
+
+			req := httptest.NewRequest(http.MethodGet, "/user/changes?last_event_id="+queryParam, http.NoBody)
+			if header != "" {
+				req.Header.Set("Last-Event-ID", header)
+			}
+
+			w := httptest.NewRecorder()
+
+			ctx, _ := stream.With(headers.With(req.Context(), req.Header, w.Header()), req.Body, w)
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req.WithContext(ctx), map[string]string{})),
+			}
+
+			res, err := h.StreamChanges(c)
+
+			assert.NoError(t, err, "TEST[%d] failed: %s", i, test.name)
+			assert.Equal(t, response.File{ContentType: "text/event-stream"}, res, "TEST[%d] failed: %s", i, test.name)
+			assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"), "TEST[%d] failed: %s", i, test.name)
+			assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"), "TEST[%d] failed: %s", i, test.name)
+			assert.Equal(t, test.expectedBody(epoch), w.Body.String(), "TEST[%d] failed: %s", i, test.name)
+		})
+	}
+}
+
+func Test_StreamChanges_Disabled(t *testing.T) {
+	h := handler.NewUserHandler(handler.NewMockUserService(gomock.NewController(t)))
+
+	req := httptest.NewRequest(http.MethodGet, "/user/changes", http.NoBody)
+	c := &gofr.Context{
+		Context: nil,
+		Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req, map[string]string{})),
+	}
+
+	disabled := apperrors.Unavailable(errors.New("change feed is disabled"))
+
+	res, err := h.StreamChanges(c)
+
+	assert.Equal(t, disabled.Error(), err.Error())
+	assert.Equal(t, problemResponse(disabled), res)
+
+	_, err = h.StreamChangesWebSocket(c)
+
+	assert.Equal(t, disabled.Error(), err.Error())
+}
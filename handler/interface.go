@@ -2,13 +2,35 @@ package handler
 
 import (
 	"gofr.dev/pkg/gofr"
+	"gofrProject/changes"
 	"gofrProject/entities"
+	"iter"
 )
 
 type UserService interface {
-	GetUsers(ctx *gofr.Context) ([]entities.Users, error)
+	GetUsers(query entities.UsersQuery, ctx *gofr.Context) (entities.UsersPage, error)
 	GetUsersByName(name string, ctx *gofr.Context) (entities.Users, error)
+	GetUsersByID(id string, ctx *gofr.Context) (entities.Users, error)
 	AddUsers(user *entities.Users, ctx *gofr.Context) error
-	DeleteUsers(name string, ctx *gofr.Context) error
-	UpdateUsers(name string, updateUser *entities.Users, ctx *gofr.Context) error
+	DeleteUsers(name string, version int, ctx *gofr.Context) error
+	DeleteUsersByID(id string, version int, ctx *gofr.Context) error
+	RestoreUsers(name string, ctx *gofr.Context) (entities.Users, error)
+	PurgeUsers(ctx *gofr.Context) (entities.PurgeResult, error)
+	UpdateUsers(name string, version int, updateUser *entities.Users, ctx *gofr.Context) error
+	UpdateUsersByID(id string, version int, updateUser *entities.Users, ctx *gofr.Context) error
+	PatchUsers(name string, version int, patch map[string]any, ctx *gofr.Context) error
+	PatchUsersByID(id string, version int, patch map[string]any, ctx *gofr.Context) error
+	RenameUsers(id, name string, ctx *gofr.Context) (entities.Users, error)
+	GetAuditLog(query entities.AuditQuery, ctx *gofr.Context) (entities.AuditPage, error)
+	GetUserHistory(name string, query entities.AuditQuery, ctx *gofr.Context) (entities.AuditPage, error)
+	Batch(request entities.BatchRequest, ctx *gofr.Context) (entities.BatchResponse, error)
+	ExportUsers(query entities.UsersQuery, fn func(user entities.Users) error, ctx *gofr.Context) error
+	ImportUsers(rows iter.Seq[entities.ImportRow], options entities.ImportOptions, ctx *gofr.Context) entities.ImportResult
+	SearchUsers(query entities.SearchQuery, ctx *gofr.Context) (entities.SearchPage, error)
+	RebuildSearchIndex(ctx *gofr.Context) (entities.RebuildResult, error)
+}
+
+// ChangeFeed is the feed of the changes of users, see package changes.
+type ChangeFeed interface {
+	Subscribe(lastEventID string) *changes.Subscription
 }
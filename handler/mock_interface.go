@@ -10,7 +10,9 @@
 package handler
 
 import (
+	changes "gofrProject/changes"
 	entities "gofrProject/entities"
+	iter "iter"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -55,33 +57,121 @@ func (mr *MockUserServiceMockRecorder) AddUsers(user, ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUsers", reflect.TypeOf((*MockUserService)(nil).AddUsers), user, ctx)
 }
 
+// Batch mocks base method.
+func (m *MockUserService) Batch(request entities.BatchRequest, ctx *gofr.Context) (entities.BatchResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Batch", request, ctx)
+	ret0, _ := ret[0].(entities.BatchResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Batch indicates an expected call of Batch.
+func (mr *MockUserServiceMockRecorder) Batch(request, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Batch", reflect.TypeOf((*MockUserService)(nil).Batch), request, ctx)
+}
+
 // DeleteUsers mocks base method.
-func (m *MockUserService) DeleteUsers(name string, ctx *gofr.Context) error {
+func (m *MockUserService) DeleteUsers(name string, version int, ctx *gofr.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUsers", name, ctx)
+	ret := m.ctrl.Call(m, "DeleteUsers", name, version, ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteUsers indicates an expected call of DeleteUsers.
-func (mr *MockUserServiceMockRecorder) DeleteUsers(name, ctx any) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) DeleteUsers(name, version, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUsers", reflect.TypeOf((*MockUserService)(nil).DeleteUsers), name, version, ctx)
+}
+
+// DeleteUsersByID mocks base method.
+func (m *MockUserService) DeleteUsersByID(id string, version int, ctx *gofr.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUsersByID", id, version, ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUsersByID indicates an expected call of DeleteUsersByID.
+func (mr *MockUserServiceMockRecorder) DeleteUsersByID(id, version, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUsersByID", reflect.TypeOf((*MockUserService)(nil).DeleteUsersByID), id, version, ctx)
+}
+
+// ExportUsers mocks base method.
+func (m *MockUserService) ExportUsers(query entities.UsersQuery, fn func(user entities.Users) error, ctx *gofr.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportUsers", query, fn, ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportUsers indicates an expected call of ExportUsers.
+func (mr *MockUserServiceMockRecorder) ExportUsers(query, fn, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportUsers", reflect.TypeOf((*MockUserService)(nil).ExportUsers), query, fn, ctx)
+}
+
+// GetAuditLog mocks base method.
+func (m *MockUserService) GetAuditLog(query entities.AuditQuery, ctx *gofr.Context) (entities.AuditPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLog", query, ctx)
+	ret0, _ := ret[0].(entities.AuditPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockUserServiceMockRecorder) GetAuditLog(query, ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUsers", reflect.TypeOf((*MockUserService)(nil).DeleteUsers), name, ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockUserService)(nil).GetAuditLog), query, ctx)
+}
+
+// GetUserHistory mocks base method.
+func (m *MockUserService) GetUserHistory(name string, query entities.AuditQuery, ctx *gofr.Context) (entities.AuditPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserHistory", name, query, ctx)
+	ret0, _ := ret[0].(entities.AuditPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserHistory indicates an expected call of GetUserHistory.
+func (mr *MockUserServiceMockRecorder) GetUserHistory(name, query, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserHistory", reflect.TypeOf((*MockUserService)(nil).GetUserHistory), name, query, ctx)
 }
 
 // GetUsers mocks base method.
-func (m *MockUserService) GetUsers(ctx *gofr.Context) ([]entities.Users, error) {
+func (m *MockUserService) GetUsers(query entities.UsersQuery, ctx *gofr.Context) (entities.UsersPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUsers", ctx)
-	ret0, _ := ret[0].([]entities.Users)
+	ret := m.ctrl.Call(m, "GetUsers", query, ctx)
+	ret0, _ := ret[0].(entities.UsersPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUsers indicates an expected call of GetUsers.
-func (mr *MockUserServiceMockRecorder) GetUsers(ctx any) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) GetUsers(query, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserService)(nil).GetUsers), query, ctx)
+}
+
+// GetUsersByID mocks base method.
+func (m *MockUserService) GetUsersByID(id string, ctx *gofr.Context) (entities.Users, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByID", id, ctx)
+	ret0, _ := ret[0].(entities.Users)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByID indicates an expected call of GetUsersByID.
+func (mr *MockUserServiceMockRecorder) GetUsersByID(id, ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserService)(nil).GetUsers), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByID", reflect.TypeOf((*MockUserService)(nil).GetUsersByID), id, ctx)
 }
 
 // GetUsersByName mocks base method.
@@ -99,16 +189,185 @@ func (mr *MockUserServiceMockRecorder) GetUsersByName(name, ctx any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByName", reflect.TypeOf((*MockUserService)(nil).GetUsersByName), name, ctx)
 }
 
+// ImportUsers mocks base method.
+func (m *MockUserService) ImportUsers(rows iter.Seq[entities.ImportRow], options entities.ImportOptions, ctx *gofr.Context) entities.ImportResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportUsers", rows, options, ctx)
+	ret0, _ := ret[0].(entities.ImportResult)
+	return ret0
+}
+
+// ImportUsers indicates an expected call of ImportUsers.
+func (mr *MockUserServiceMockRecorder) ImportUsers(rows, options, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportUsers", reflect.TypeOf((*MockUserService)(nil).ImportUsers), rows, options, ctx)
+}
+
+// PatchUsers mocks base method.
+func (m *MockUserService) PatchUsers(name string, version int, patch map[string]any, ctx *gofr.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchUsers", name, version, patch, ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchUsers indicates an expected call of PatchUsers.
+func (mr *MockUserServiceMockRecorder) PatchUsers(name, version, patch, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchUsers", reflect.TypeOf((*MockUserService)(nil).PatchUsers), name, version, patch, ctx)
+}
+
+// PatchUsersByID mocks base method.
+func (m *MockUserService) PatchUsersByID(id string, version int, patch map[string]any, ctx *gofr.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchUsersByID", id, version, patch, ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchUsersByID indicates an expected call of PatchUsersByID.
+func (mr *MockUserServiceMockRecorder) PatchUsersByID(id, version, patch, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchUsersByID", reflect.TypeOf((*MockUserService)(nil).PatchUsersByID), id, version, patch, ctx)
+}
+
+// PurgeUsers mocks base method.
+func (m *MockUserService) PurgeUsers(ctx *gofr.Context) (entities.PurgeResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUsers", ctx)
+	ret0, _ := ret[0].(entities.PurgeResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeUsers indicates an expected call of PurgeUsers.
+func (mr *MockUserServiceMockRecorder) PurgeUsers(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUsers", reflect.TypeOf((*MockUserService)(nil).PurgeUsers), ctx)
+}
+
+// RebuildSearchIndex mocks base method.
+func (m *MockUserService) RebuildSearchIndex(ctx *gofr.Context) (entities.RebuildResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebuildSearchIndex", ctx)
+	ret0, _ := ret[0].(entities.RebuildResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RebuildSearchIndex indicates an expected call of RebuildSearchIndex.
+func (mr *MockUserServiceMockRecorder) RebuildSearchIndex(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildSearchIndex", reflect.TypeOf((*MockUserService)(nil).RebuildSearchIndex), ctx)
+}
+
+// RenameUsers mocks base method.
+func (m *MockUserService) RenameUsers(id string, name string, ctx *gofr.Context) (entities.Users, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameUsers", id, name, ctx)
+	ret0, _ := ret[0].(entities.Users)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameUsers indicates an expected call of RenameUsers.
+func (mr *MockUserServiceMockRecorder) RenameUsers(id, name, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUsers", reflect.TypeOf((*MockUserService)(nil).RenameUsers), id, name, ctx)
+}
+
+// RestoreUsers mocks base method.
+func (m *MockUserService) RestoreUsers(name string, ctx *gofr.Context) (entities.Users, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUsers", name, ctx)
+	ret0, _ := ret[0].(entities.Users)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreUsers indicates an expected call of RestoreUsers.
+func (mr *MockUserServiceMockRecorder) RestoreUsers(name, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUsers", reflect.TypeOf((*MockUserService)(nil).RestoreUsers), name, ctx)
+}
+
+// SearchUsers mocks base method.
+func (m *MockUserService) SearchUsers(query entities.SearchQuery, ctx *gofr.Context) (entities.SearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", query, ctx)
+	ret0, _ := ret[0].(entities.SearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockUserServiceMockRecorder) SearchUsers(query, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockUserService)(nil).SearchUsers), query, ctx)
+}
+
 // UpdateUsers mocks base method.
-func (m *MockUserService) UpdateUsers(name string, updateUser *entities.Users, ctx *gofr.Context) error {
+func (m *MockUserService) UpdateUsers(name string, version int, updateUser *entities.Users, ctx *gofr.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUsers", name, updateUser, ctx)
+	ret := m.ctrl.Call(m, "UpdateUsers", name, version, updateUser, ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateUsers indicates an expected call of UpdateUsers.
-func (mr *MockUserServiceMockRecorder) UpdateUsers(name, updateUser, ctx any) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) UpdateUsers(name, version, updateUser, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUsers", reflect.TypeOf((*MockUserService)(nil).UpdateUsers), name, version, updateUser, ctx)
+}
+
+// UpdateUsersByID mocks base method.
+func (m *MockUserService) UpdateUsersByID(id string, version int, updateUser *entities.Users, ctx *gofr.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUsersByID", id, version, updateUser, ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUsersByID indicates an expected call of UpdateUsersByID.
+func (mr *MockUserServiceMockRecorder) UpdateUsersByID(id, version, updateUser, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUsersByID", reflect.TypeOf((*MockUserService)(nil).UpdateUsersByID), id, version, updateUser, ctx)
+}
+
+// MockChangeFeed is a mock of ChangeFeed interface.
+type MockChangeFeed struct {
+	ctrl     *gomock.Controller
+	recorder *MockChangeFeedMockRecorder
+	isgomock struct{}
+}
+
+// MockChangeFeedMockRecorder is the mock recorder for MockChangeFeed.
+type MockChangeFeedMockRecorder struct {
+	mock *MockChangeFeed
+}
+
+// NewMockChangeFeed creates a new mock instance.
+func NewMockChangeFeed(ctrl *gomock.Controller) *MockChangeFeed {
+	mock := &MockChangeFeed{ctrl: ctrl}
+	mock.recorder = &MockChangeFeedMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChangeFeed) EXPECT() *MockChangeFeedMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockChangeFeed) Subscribe(lastEventID string) *changes.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", lastEventID)
+	ret0, _ := ret[0].(*changes.Subscription)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockChangeFeedMockRecorder) Subscribe(lastEventID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUsers", reflect.TypeOf((*MockUserService)(nil).UpdateUsers), name, updateUser, ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockChangeFeed)(nil).Subscribe), lastEventID)
 }
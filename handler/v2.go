@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/http/response"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"gofrProject/etag"
+	"gofrProject/headers"
+	"gofrProject/negotiate"
+	"gofrProject/problem"
+	"gofrProject/stream"
+	"gofrProject/validation"
+	"slices"
+)
+
+// The v2 API serves the users of the same service as the v1 handlers, in the representation of
+// entities.UserV2. Its writes respond with the user they wrote, and the fields of its errors are
+// named like those of UserV2.
+
+// v2Fields maps the JSON names of the fields of entities.Users to those of entities.UserV2 where they
+// differ.
+var v2Fields = map[string]string{"phone_Number": "phone_number"}
+
+// readOnlyV2Fields are the fields of entities.UserV2 set by the server, which are ignored in bodies.
+var readOnlyV2Fields = []string{"created_at", "updated_at", "deleted_at"}
+
+// v2BodyFormats are the media types of the user in the body of the v2 writes.
+var v2BodyFormats = []string{negotiate.JSON, negotiate.XML, negotiate.MessagePack}
+
+// respondV2 is problem.Respond for the v2 API, it names the invalid fields of err like UserV2 does.
+func respondV2(err error) (any, error) {
+	d := problem.New(err)
+
+	d.Errors = slices.Clone(d.Errors)
+	for i := range d.Errors {
+		if field, ok := v2Fields[d.Errors[i].Field]; ok {
+			d.Errors[i].Field = field
+		}
+	}
+
+	body, _ := json.Marshal(d)
+
+	return response.File{Content: body, ContentType: problem.ContentType}, d
+}
+
+// bindUserV2 reads the user in the body of the request into user, in the format the Content-Type
+// header names.
+func bindUserV2(user *entities.UserV2, action string, ctx *gofr.Context) error {
+	format, err := negotiate.ContentType(headers.Get(ctx.Request.Context(), "Content-Type"), v2BodyFormats...)
+	if err != nil {
+		return err
+	}
+
+	switch body := stream.Body(ctx.Request.Context()); {
+	case format == negotiate.JSON:
+		err = ctx.Bind(user)
+	case body == nil:
+		err = errors.New("request body cannot be read")
+	default:
+		err = negotiate.Unmarshal(format, body, user)
+	}
+
+	if err != nil {
+		return apperrors.Validation(fmt.Errorf("error while %s user: %v", action, err))
+	}
+
+	return nil
+}
+
+// renderUserV2 reads the user with the given id and returns it in format with its ETag.
+func (h *Handler) renderUserV2(format, id string, ctx *gofr.Context) (any, error) {
+	user, err := h.UserService.GetUsersByID(id, ctx)
+	if err != nil {
+		return respondV2(err)
+	}
+
+	headers.Set(ctx.Request.Context(), "ETag", etag.Format(user.Version))
+
+	return render(format, entities.NewUserV2(user))
+}
+
+// GetUsersV2 lists users like GetUsers, with the same query parameters. Lists are not sent as CSV,
+// whose columns are those of v1.
+func (h *Handler) GetUsersV2(ctx *gofr.Context) (any, error) {
+	format, err := accept(userFormats, ctx)
+	if err != nil {
+		return respondV2(err)
+	}
+
+	query, err := h.listQuery(ctx)
+	if err != nil {
+		return respondV2(err)
+	}
+
+	page, err := h.UserService.GetUsers(query, ctx)
+	if err != nil {
+		return respondV2(err)
+	}
+
+	return render(format, entities.NewUsersPageV2(page))
+}
+
+// GetUserV2 returns the user with the id in the path like GetUserByID.
+func (h *Handler) GetUserV2(ctx *gofr.Context) (any, error) {
+	format, err := accept(userFormats, ctx)
+	if err != nil {
+		return respondV2(err)
+	}
+
+	user, err := h.UserService.GetUsersByID(ctx.Request.PathParam("id"), ctx)
+	if err != nil {
+		return respondV2(err)
+	}
+
+	return userResponse(format, user.Version, entities.NewUserV2(user), ctx)
+}
+
+// AddUserV2 creates the user in the body and returns it.
+func (h *Handler) AddUserV2(ctx *gofr.Context) (any, error) {
+	format, err := accept(userFormats, ctx)
+	if err != nil {
+		return respondV2(err)
+	}
+
+	var body entities.UserV2
+
+	if err := bindUserV2(&body, "adding", ctx); err != nil {
+		return respondV2(err)
+	}
+
+	user := body.User()
+
+	if err := h.UserService.AddUsers(&user, ctx); err != nil {
+		return respondV2(err)
+	}
+
+	return h.renderUserV2(format, user.ID, ctx)
+}
+
+// UpdateUserV2 replaces the user with the id in the path like UpdateUserByID and returns it.
+func (h *Handler) UpdateUserV2(ctx *gofr.Context) (any, error) {
+	format, err := accept(userFormats, ctx)
+	if err != nil {
+		return respondV2(err)
+	}
+
+	var body entities.UserV2
+
+	if err := bindUserV2(&body, "updating", ctx); err != nil {
+		return respondV2(err)
+	}
+
+	id := ctx.Request.PathParam("id")
+
+	version, err := h.expectedVersion(h.byID(), id, ctx)
+	if err != nil {
+		return respondV2(err)
+	}
+
+	user := body.User()
+
+	if err := h.UserService.UpdateUsersByID(id, version, &user, ctx); err != nil {
+		return respondV2(err)
+	}
+
+	return h.renderUserV2(format, id, ctx)
+}
+
+// PatchUserV2 applies a JSON Merge Patch (RFC 7396) of the v2 representation to the user with the id
+// in the path like PatchUserByID and returns it.
+func (h *Handler) PatchUserV2(ctx *gofr.Context) (any, error) {
+	format, err := accept(userFormats, ctx)
+	if err != nil {
+		return respondV2(err)
+	}
+
+	var patch map[string]any
+
+	if err := bindPatch(&patch, ctx); err != nil {
+		return respondV2(err)
+	}
+
+	// The fields of the errors of v1Patch are named as sent already.
+	patch, err = v1Patch(patch)
+	if err != nil {
+		return problem.Respond(err)
+	}
+
+	id := ctx.Request.PathParam("id")
+
+	version, err := h.expectedVersion(h.byID(), id, ctx)
+	if err != nil {
+		return respondV2(err)
+	}
+
+	if err := h.UserService.PatchUsersByID(id, version, patch, ctx); err != nil {
+		return respondV2(err)
+	}
+
+	return h.renderUserV2(format, id, ctx)
+}
+
+// v1Patch returns the patch of the v1 representation of a user doing what patch does to its v2
+// representation. The fields set by the server are left out, the v1 names of renamed fields are
+// unknown fields.
+func v1Patch(patch map[string]any) (map[string]any, error) {
+	translated := make(map[string]any, len(patch))
+
+	for field, value := range patch {
+		if slices.Contains(readOnlyV2Fields, field) {
+			continue
+		}
+
+		if _, ok := v2Fields[field]; ok {
+			return nil, apperrors.Validation(validation.Errors{{Field: field, Code: validation.CodeUnknownField,
+				Message: field + " is not a user field"}})
+		}
+
+		for v1Field, v2Field := range v2Fields {
+			if field == v2Field {
+				field = v1Field
+			}
+		}
+
+		translated[field] = value
+	}
+
+	return translated, nil
+}
@@ -0,0 +1,243 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gofr.dev/pkg/gofr"
+
+	gofrHttp "gofr.dev/pkg/gofr/http"
+	"gofr.dev/pkg/gofr/http/response"
+	"gofrProject/apperrors"
+	"gofrProject/authz"
+	"gofrProject/entities"
+	"gofrProject/handler"
+	"gofrProject/headers"
+	"gofrProject/problem"
+	"gofrProject/stream"
+	"gofrProject/validation"
+)
+
+func Test_UsersV2(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	createdAt := time.Date(2025, 1, 20, 12, 0, 0, 0, time.UTC)
+	user := entities.Users{ID: testUserID, UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "waheed@example.com", CreatedAt: createdAt, UpdatedAt: createdAt.Add(time.Hour), Version: 3}
+	userV2 := entities.UserV2{ID: testUserID, UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "waheed@example.com", CreatedAt: createdAt, UpdatedAt: createdAt.Add(time.Hour)}
+	body := `{"user_name":"waheed","user_age":19,"phone_number":"+14155552671","email":"waheed@example.com",` +
+		`"created_at":"2000-01-01T00:00:00Z"}`
+	written := &entities.Users{UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671", Email: "waheed@example.com"}
+	unsupported := apperrors.UnsupportedMediaType("Content-Type must be one of application/merge-patch+json, " +
+		"application/json")
+
+	tests := []struct {
+		name             string
+		method           string
+		handler          gofr.Handler
+		contentType      string
+		body             string
+		mockExpect       func()
+		expectedResponse any
+		expectedErr      error
+		expectedETag     string
+	}{
+		{
+			name:    "list",
+			method:  http.MethodGet,
+			handler: h.GetUsersV2,
+			mockExpect: func() {
+				mockService.EXPECT().GetUsers(entities.UsersQuery{}, gomock.Any()).
+					Return(entities.UsersPage{Users: []entities.Users{user}, NextCursor: "next", TotalCount: 2}, nil)
+			},
+			expectedResponse: entities.UsersPageV2{Users: []entities.UserV2{userV2}, NextCursor: "next", TotalCount: 2},
+		},
+		{
+			name:    "read",
+			method:  http.MethodGet,
+			handler: h.GetUserV2,
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+			},
+			expectedResponse: userV2,
+			expectedETag:     `"3"`,
+		},
+		{
+			name:    "create ignores the fields set by the server",
+			method:  http.MethodPost,
+			handler: h.AddUserV2,
+			body:    body,
+			mockExpect: func() {
+				mockService.EXPECT().AddUsers(written, gomock.Any()).DoAndReturn(func(u *entities.Users, _ *gofr.Context) error {
+					u.ID = testUserID
+
+					return nil
+				})
+				mockService.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+			},
+			expectedResponse: userV2,
+			expectedETag:     `"3"`,
+		},
+		{
+			name:    "update",
+			method:  http.MethodPut,
+			handler: h.UpdateUserV2,
+			body:    body,
+			mockExpect: func() {
+				mockService.EXPECT().UpdateUsersByID(testUserID, 0, written, gomock.Any()).Return(nil)
+				mockService.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+			},
+			expectedResponse: userV2,
+			expectedETag:     `"3"`,
+		},
+		{
+			name:    "patch of the v2 fields",
+			method:  http.MethodPatch,
+			handler: h.PatchUserV2,
+			body:    `{"phone_number":"+14155552671","updated_at":null,"email":null}`,
+			mockExpect: func() {
+				mockService.EXPECT().PatchUsersByID(testUserID, 0, map[string]any{"phone_Number": "+14155552671", "email": nil},
+					gomock.Any()).Return(nil)
+				mockService.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+			},
+			expectedResponse: userV2,
+			expectedETag:     `"3"`,
+		},
+		{
+			name:        "merge patch",
+			method:      http.MethodPatch,
+			handler:     h.PatchUserV2,
+			contentType: "application/merge-patch+json",
+			body:        `{"email":"waheed@example.org"}`,
+			mockExpect: func() {
+				mockService.EXPECT().PatchUsersByID(testUserID, 0, map[string]any{"email": "waheed@example.org"},
+					gomock.Any()).Return(nil)
+				mockService.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+			},
+			expectedResponse: userV2,
+			expectedETag:     `"3"`,
+		},
+		{
+			name:             "patch of an unsupported media type",
+			method:           http.MethodPatch,
+			handler:          h.PatchUserV2,
+			contentType:      "text/plain",
+			body:             `{"email":"waheed@example.org"}`,
+			mockExpect:       func() {},
+			expectedResponse: problemResponse(unsupported),
+			expectedErr:      unsupported,
+		},
+		{
+			name:       "patch of a v1 field",
+			method:     http.MethodPatch,
+			handler:    h.PatchUserV2,
+			body:       `{"phone_Number":"+14155552671"}`,
+			mockExpect: func() {},
+			expectedResponse: problemResponse(apperrors.Validation(validation.Errors{{Field: "phone_Number",
+				Code: validation.CodeUnknownField, Message: "phone_Number is not a user field"}})),
+			expectedErr: apperrors.Validation(validation.Errors{{Field: "phone_Number",
+				Code: validation.CodeUnknownField, Message: "phone_Number is not a user field"}}),
+		},
+		{
+			name:    "user not found",
+			method:  http.MethodGet,
+			handler: h.GetUserV2,
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByID(testUserID, gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "id", testUserID))
+			},
+			expectedResponse: problemResponse(apperrors.NotFound("user", "id", testUserID)),
+			expectedErr:      apperrors.NotFound("user", "id", testUserID),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/v2/users/{id}", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+
+			w := httptest.NewRecorder()
+			ctx, _ := stream.With(headers.With(req.Context(), req.Header, w.Header()), req.Body, w)
+
+			c := &gofr.Context{
+				Context: nil,
+				Request: gofrHttp.NewRequest(gofrHttp.SetPathParam(req.WithContext(ctx), map[string]string{"id": testUserID})),
+			}
+			test.mockExpect()
+
+			res, err := test.handler(c)
+
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr.Error(), err.Error(), "TEST[%d] failed: %s", i, test.name)
+			} else {
+				assert.NoError(t, err, "TEST[%d] failed: %s", i, test.name)
+			}
+
+			assert.Equal(t, test.expectedResponse, res, "TEST[%d] failed: %s", i, test.name)
+			assert.Equal(t, test.expectedETag, w.Header().Get("ETag"), "TEST[%d] failed: %s", i, test.name)
+		})
+	}
+}
+
+func Test_UsersV2_IncludeDeleted(t *testing.T) {
+	mockService := handler.NewMockUserService(gomock.NewController(t))
+	forbidden := authz.ErrForbidden{Principal: "carol", Permission: authz.ReadDeletedUsers}
+
+	h := handler.NewUserHandler(mockService, handler.AuthorizeReadDeleted(func(*gofr.Context) error {
+		return forbidden
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/users?include_deleted=1", nil)
+	req = req.WithContext(headers.With(req.Context(), req.Header, http.Header{}))
+
+	res, err := h.GetUsersV2(&gofr.Context{Context: req.Context(), Request: gofrHttp.NewRequest(req)})
+
+	assert.ErrorIs(t, err, forbidden)
+	assert.IsType(t, response.File{}, res)
+}
+
+func Test_UsersV2_ErrorFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := handler.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(mockService)
+
+	invalid := apperrors.Validation(validation.Errors{
+		{Field: "phone_Number", Code: validation.CodeInvalidFormat, Message: "phone number is not valid"},
+		{Field: "user_age", Code: validation.CodeOutOfRange, Message: "user age must be between 0 and 150"},
+	})
+
+	mockService.EXPECT().AddUsers(gomock.Any(), gomock.Any()).Return(invalid)
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/users", strings.NewReader(`{"phone_number":"x","user_age":200}`))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(headers.With(req.Context(), req.Header, http.Header{}))
+
+	res, err := h.AddUserV2(&gofr.Context{Request: gofrHttp.NewRequest(req)})
+	require.Error(t, err)
+
+	file, ok := res.(response.File)
+	require.True(t, ok)
+
+	var details problem.Details
+	require.NoError(t, json.Unmarshal(file.Content, &details))
+
+	assert.Equal(t, []validation.FieldError{
+		{Field: "phone_number", Code: validation.CodeInvalidFormat, Message: "phone number is not valid"},
+		{Field: "user_age", Code: validation.CodeOutOfRange, Message: "user age must be between 0 and 150"},
+	}, details.Errors)
+	assert.Equal(t, "phone_Number", invalid.Err.(validation.Errors)[0].Field, "the error of the service is left as it is")
+}
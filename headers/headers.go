@@ -0,0 +1,47 @@
+// Package headers gives GoFr handlers, which only see the request body and parameters,
+// access to the request and response headers of the HTTP exchange they serve.
+package headers
+
+import (
+	"context"
+	"net/http"
+)
+
+type exchangeKey struct{}
+
+type exchange struct {
+	request  http.Header
+	response http.Header
+}
+
+// Middleware makes the headers of every request and its response available through Get and Set.
+func Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(With(r.Context(), r.Header, w.Header())))
+		})
+	}
+}
+
+// With returns a copy of ctx carrying the request and response headers.
+func With(ctx context.Context, request, response http.Header) context.Context {
+	return context.WithValue(ctx, exchangeKey{}, exchange{request: request, response: response})
+}
+
+// Get returns the value of the request header key, or "" if ctx does not carry the headers.
+func Get(ctx context.Context, key string) string {
+	e, ok := ctx.Value(exchangeKey{}).(exchange)
+	if !ok {
+		return ""
+	}
+
+	return e.request.Get(key)
+}
+
+// Set sets the response header key. It does nothing if ctx does not carry the headers, and has
+// to be called before the handler returns for the header to be sent.
+func Set(ctx context.Context, key, value string) {
+	if e, ok := ctx.Value(exchangeKey{}).(exchange); ok {
+		e.response.Set(key, value)
+	}
+}
@@ -0,0 +1,32 @@
+package headers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	handler := Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Set(r.Context(), "ETag", `"`+Get(r.Context(), "If-None-Match")+`"`)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/user/waheed", nil)
+	req.Header.Set("If-None-Match", "3")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+}
+
+func TestWithoutHeaders(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Equal(t, "", Get(ctx, "If-Match"))
+	assert.NotPanics(t, func() { Set(ctx, "ETag", `"1"`) })
+}
@@ -2,23 +2,220 @@ package main
 
 import (
 	"gofr.dev/pkg/gofr"
+	"gofrProject/apiversion"
+	"gofrProject/auth"
+	"gofrProject/authz"
+	"gofrProject/cache"
+	"gofrProject/changes"
+	"gofrProject/dialect"
 	"gofrProject/handler"
+	"gofrProject/headers"
+	"gofrProject/migrations"
+	"gofrProject/outbox"
+	"gofrProject/rpc"
+	"gofrProject/search"
 	"gofrProject/service"
 	"gofrProject/store"
+	"gofrProject/stream"
+	"gofrProject/userpb"
+	"net/http"
+	"strconv"
+	"time"
 )
 
 func main() {
 	// Create a new application
 	a := gofr.New()
-	userstore := store.NewDetails()
-	userService := service.NewUserService(userstore)
-	userHandler := handler.NewUserHandler(userService)
-
-	a.GET("/user", userHandler.GetUsers)
-	a.POST("/user", userHandler.AddUser)
-	a.GET("/user/{name}", userHandler.GetUserByName)
-	a.PUT("/user/{name}", userHandler.UpdateUser)
-	a.DELETE("/user/{name}", userHandler.DeleteUser)
-	a.UseMiddleware(Authentication)
+
+	sqlDialect, err := dialect.Parse(a.Config.Get("DB_DIALECT"))
+	if err != nil {
+		a.Logger().Fatalf("invalid DB_DIALECT: %v", err)
+	}
+
+	a.Migrate(migrations.All(sqlDialect))
+
+	authenticator, err := auth.New(a.Config)
+	if err != nil {
+		a.Logger().Fatalf("unable to configure authentication: %v", err)
+	}
+
+	policy, err := authz.NewPolicy(a.Config)
+	if err != nil {
+		a.Logger().Fatalf("unable to configure authorization: %v", err)
+	}
+
+	retention, err := time.ParseDuration(a.Config.GetOrDefault("USER_DELETED_RETENTION", service.DefaultRetention.String()))
+	if err != nil {
+		a.Logger().Fatalf("invalid USER_DELETED_RETENTION: %v", err)
+	}
+
+	maxBatchSize, err := strconv.Atoi(a.Config.GetOrDefault("USER_BATCH_MAX_SIZE", strconv.Itoa(service.DefaultMaxBatchSize)))
+	if err != nil {
+		a.Logger().Fatalf("invalid USER_BATCH_MAX_SIZE: %v", err)
+	}
+
+	changeLog, heartbeat := newChangeLog(a)
+
+	var (
+		userstore  service.UserStore
+		userOutbox outbox.Store
+	)
+
+	switch backend := a.Config.GetOrDefault("STORE_BACKEND", "sql"); backend {
+	case "sql":
+		details := store.NewDetails()
+		userstore, userOutbox = details, details
+	case "memory":
+		memory := store.NewMemory()
+		userstore, userOutbox = memory, memory
+	default:
+		a.Logger().Fatalf("invalid STORE_BACKEND %q, must be sql or memory", backend)
+	}
+
+	// The search index wraps the store itself and reads back the users written to it, the cache goes
+	// in front of both.
+	userIndex := search.New(userstore)
+	userstore = userIndex
+
+	if ttl := a.Config.Get("USER_CACHE_TTL"); ttl != "" {
+		userstore = newUserCache(a, userstore, ttl)
+	}
+
+	serviceOptions := []service.Option{service.WithRetention(retention), service.WithMaxBatchSize(maxBatchSize),
+		service.WithSearch(userIndex), service.WithChangeFeed(changeLog)}
+	if topic := a.Config.Get("USER_EVENTS_TOPIC"); topic != "" {
+		serviceOptions = append(serviceOptions, service.WithEvents(topic))
+		addOutboxRelay(a, userOutbox)
+	}
+
+	requireVersion := a.Config.GetOrDefault("USER_REQUIRE_IF_MATCH", "false") == "true"
+
+	userService := service.NewUserService(userstore, serviceOptions...)
+	userHandler := handler.NewUserHandler(userService,
+		handler.RequireIfMatch(requireVersion),
+		handler.AuthorizeBatch(policy.AuthorizeBatch),
+		handler.AuthorizeReadDeleted(func(ctx *gofr.Context) error { return policy.Authorize(ctx, authz.ReadDeletedUsers) }),
+		handler.FollowChanges(changeLog, heartbeat))
+
+	a.RegisterService(&userpb.UserService_ServiceDesc, rpc.NewServer(userService,
+		rpc.Authenticate(authenticator, policy.Authorize), rpc.RequireVersion(requireVersion)))
+
+	a.GET("/user", policy.Require(authz.ReadUsers, userHandler.GetUsers))
+	a.POST("/user", policy.Require(authz.CreateUsers, userHandler.AddUser))
+	// Registered before /user/{name}, which would take export for a user name.
+	a.GET("/user/export", policy.Require(authz.ExportUsers, userHandler.ExportUsers))
+	a.POST("/user/import", policy.Require(authz.ImportUsers, userHandler.ImportUsers))
+	a.GET("/user/changes", policy.Require(authz.ReadUsers, userHandler.StreamChanges))
+	a.WebSocket("/user/changes/ws", policy.Require(authz.ReadUsers, userHandler.StreamChangesWebSocket))
+	a.GET("/user/search", policy.Require(authz.ReadUsers, userHandler.SearchUsers))
+	a.POST("/user/search/rebuild", policy.Require(authz.ReindexUsers, userHandler.RebuildSearchIndex))
+	a.GET("/user/{name}", policy.Require(authz.ReadUsers, userHandler.GetUserByName))
+	a.PUT("/user/{name}", policy.Require(authz.UpdateUsers, userHandler.UpdateUser, authz.Owner("name")))
+	a.PATCH("/user/{name}", policy.Require(authz.UpdateUsers, userHandler.PatchUser, authz.Owner("name")))
+	a.DELETE("/user/{name}", policy.Require(authz.DeleteUsers, userHandler.DeleteUser))
+	a.POST("/user/{name}/restore", policy.Require(authz.RestoreUsers, userHandler.RestoreUser))
+	a.POST("/user/purge", policy.Require(authz.PurgeUsers, userHandler.PurgeUsers))
+	a.GET("/user/{name}/history", policy.Require(authz.ReadAudit, userHandler.GetUserHistory, authz.Owner("name")))
+	a.GET("/audit", policy.Require(authz.ReadAudit, userHandler.GetAuditLog))
+
+	owner := authz.OwnerByID("id", userService.GetUsersByID)
+	a.GET("/users/{id}", policy.Require(authz.ReadUsers, userHandler.GetUserByID))
+	a.PUT("/users/{id}", policy.Require(authz.UpdateUsers, userHandler.UpdateUserByID, owner))
+	a.PATCH("/users/{id}", policy.Require(authz.UpdateUsers, userHandler.PatchUserByID, owner))
+	a.DELETE("/users/{id}", policy.Require(authz.DeleteUsers, userHandler.DeleteUserByID))
+	a.POST("/users/{id}/rename", policy.Require(authz.UpdateUsers, userHandler.RenameUser))
+	a.POST("/users:batch", policy.Require(authz.BatchUsers, userHandler.Batch))
+
+	a.GET("/v2/users", policy.Require(authz.ReadUsers, userHandler.GetUsersV2))
+	a.POST("/v2/users", policy.Require(authz.CreateUsers, userHandler.AddUserV2))
+	a.GET("/v2/users/{id}", policy.Require(authz.ReadUsers, userHandler.GetUserV2))
+	a.PUT("/v2/users/{id}", policy.Require(authz.UpdateUsers, userHandler.UpdateUserV2, owner))
+	a.PATCH("/v2/users/{id}", policy.Require(authz.UpdateUsers, userHandler.PatchUserV2, owner))
+	a.DELETE("/v2/users/{id}", policy.Require(authz.DeleteUsers, userHandler.DeleteUserByID))
+	a.AddCronJob(a.Config.GetOrDefault("USER_PURGE_SCHEDULE", "0 3 * * *"), "purge-deleted-users", func(ctx *gofr.Context) {
+		if _, err := userService.PurgeUsers(ctx); err != nil {
+			ctx.Errorf("unable to purge deleted users: %v", err)
+		}
+	})
+
+	if schedule := a.Config.Get("USER_SEARCH_REBUILD_SCHEDULE"); schedule != "" {
+		a.AddCronJob(schedule, "rebuild-user-search-index", func(ctx *gofr.Context) {
+			if _, err := userService.RebuildSearchIndex(ctx); err != nil {
+				ctx.Errorf("unable to rebuild the user search index: %v", err)
+			}
+		})
+	}
+
+	a.UseMiddleware(apiVersionMiddleware(a), Authentication(authenticator), headers.Middleware(), stream.Middleware())
 	a.Run()
 }
+
+// newUserCache puts the Redis cache in front of store, caching users for ttl.
+func newUserCache(a *gofr.App, store service.UserStore, ttl string) service.UserStore {
+	userTTL, err := time.ParseDuration(ttl)
+	if err != nil {
+		a.Logger().Fatalf("invalid USER_CACHE_TTL: %v", err)
+	}
+
+	negativeTTL, err := time.ParseDuration(a.Config.GetOrDefault("USER_CACHE_NEGATIVE_TTL", cache.DefaultNegativeTTL.String()))
+	if err != nil {
+		a.Logger().Fatalf("invalid USER_CACHE_NEGATIVE_TTL: %v", err)
+	}
+
+	return cache.New(store, cache.WithTTL(userTTL), cache.WithNegativeTTL(negativeTTL))
+}
+
+// newChangeLog returns the log of the change feed and how often its subscribers are sent a heartbeat.
+func newChangeLog(a *gofr.App) (*changes.Log, time.Duration) {
+	size, err := strconv.Atoi(a.Config.GetOrDefault("USER_CHANGES_LOG_SIZE", strconv.Itoa(changes.DefaultSize)))
+	if err != nil || size <= 0 {
+		a.Logger().Fatalf("invalid USER_CHANGES_LOG_SIZE: %v", a.Config.Get("USER_CHANGES_LOG_SIZE"))
+	}
+
+	buffer, err := strconv.Atoi(a.Config.GetOrDefault("USER_CHANGES_BUFFER", strconv.Itoa(changes.DefaultBuffer)))
+	if err != nil || buffer <= 0 {
+		a.Logger().Fatalf("invalid USER_CHANGES_BUFFER: %v", a.Config.Get("USER_CHANGES_BUFFER"))
+	}
+
+	heartbeat, err := time.ParseDuration(a.Config.GetOrDefault("USER_CHANGES_HEARTBEAT", "15s"))
+	if err != nil || heartbeat <= 0 {
+		a.Logger().Fatalf("invalid USER_CHANGES_HEARTBEAT: %v", a.Config.Get("USER_CHANGES_HEARTBEAT"))
+	}
+
+	return changes.NewLog(changes.WithSize(size), changes.WithBuffer(buffer)), heartbeat
+}
+
+// apiVersionMiddleware returns the middleware counting the requests of every version of the API, which
+// deprecates v1 once USER_V1_DEPRECATED_AT is set.
+func apiVersionMiddleware(a *gofr.App) func(http.Handler) http.Handler {
+	apiversion.RegisterMetrics(a.Metrics())
+
+	deprecatedAt := a.Config.Get("USER_V1_DEPRECATED_AT")
+	if deprecatedAt == "" {
+		return apiversion.Middleware(a.Metrics())
+	}
+
+	since, err := time.Parse(time.RFC3339, deprecatedAt)
+	if err != nil {
+		a.Logger().Fatalf("invalid USER_V1_DEPRECATED_AT: %v", err)
+	}
+
+	var sunset time.Time
+
+	if value := a.Config.Get("USER_V1_SUNSET"); value != "" {
+		if sunset, err = time.Parse(time.RFC3339, value); err != nil {
+			a.Logger().Fatalf("invalid USER_V1_SUNSET: %v", err)
+		}
+	}
+
+	return apiversion.Middleware(a.Metrics(), apiversion.Deprecate(apiversion.V1,
+		apiversion.Deprecation{Since: since, Sunset: sunset, Successor: "/v2/users"}))
+}
+
+// addOutboxRelay schedules the relay publishing the user events written to the outbox of store.
+func addOutboxRelay(a *gofr.App, store outbox.Store) {
+	outbox.RegisterMetrics(a.Metrics())
+
+	relay := outbox.New(store)
+	a.AddCronJob(a.Config.GetOrDefault("USER_OUTBOX_SCHEDULE", "*/5 * * * * *"), "user-outbox-relay", relay.Run)
+}
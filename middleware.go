@@ -2,14 +2,31 @@ package main
 
 import (
 	"net/http"
+	"strings"
+
+	"gofrProject/auth"
+	"gofrProject/authz"
+	"gofrProject/problem"
 )
 
-func Authentication(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Header.Get("Authorization") != "abc" {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
+// Authentication rejects requests the authenticator cannot identify and stores the
+// authenticated principal in the request context for the handlers and services. Like GoFr's own
+// authentication, it leaves the /.well-known/ paths open: the health checks, the OpenAPI document
+// and its Swagger UI.
+func Authentication(authenticator auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/.well-known/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := authenticator.Authenticate(r)
+			if err != nil {
+				problem.Write(w, authz.ErrUnauthenticated{})
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
 }
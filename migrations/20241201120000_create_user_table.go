@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"gofr.dev/pkg/gofr/migration"
+	"gofrProject/dialect"
+)
+
+// createUserTableQuery returns the statement creating the User table. The text columns compare
+// case-insensitively in every dialect, as they do with MySQL's default collation.
+func createUserTableQuery(d dialect.Dialect) string {
+	return d.SQL(`CREATE TABLE IF NOT EXISTS "User" (
+	UserName    ` + d.Text(255) + ` NOT NULL,
+	UserAge     INT NOT NULL DEFAULT 0,
+	PhoneNumber ` + d.Text(32) + ` NOT NULL,
+	Email       ` + d.Text(255) + ` NOT NULL,
+	PRIMARY KEY (UserName)
+)`)
+}
+
+const createCITextExtensionQuery = "CREATE EXTENSION IF NOT EXISTS citext"
+
+var (
+	phoneNumberIndex = index{name: "idx_user_phone_number", table: "User", columns: "PhoneNumber", unique: true}
+	emailIndex       = index{name: "idx_user_email", table: "User", columns: "Email", unique: true}
+)
+
+// createUserTable creates the User table with UserName as primary key and
+// unique indexes on PhoneNumber and Email.
+func createUserTable(d dialect.Dialect) migration.Migrate {
+	return migration.Migrate{
+		UP: func(ds migration.Datasource) error {
+			queries := []string{createUserTableQuery(d)}
+			if d == dialect.Postgres {
+				queries = append([]string{createCITextExtensionQuery}, queries...)
+			}
+
+			for _, query := range queries {
+				if _, err := ds.SQL.Exec(query); err != nil {
+					return err
+				}
+			}
+
+			return createIndexes(ds.SQL, d, phoneNumberIndex, emailIndex)
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"github.com/google/uuid"
+	"gofr.dev/pkg/gofr/migration"
+	"gofrProject/dialect"
+)
+
+const (
+	addUserIDColumnQuery      = `ALTER TABLE "User" ADD COLUMN ID CHAR(36) NULL`
+	selectUsersWithoutIDQuery = `SELECT UserName FROM "User" WHERE ID IS NULL`
+	setUserIDQuery            = `UPDATE "User" SET ID = ? WHERE UserName = ?`
+)
+
+var idIndex = index{name: "idx_user_id", table: "User", columns: "ID", unique: true}
+
+// requireUserIDQuery returns the statement making the ID column mandatory. SQLite cannot change
+// the constraints of an existing column, there the unique index is all that guards the ID.
+func requireUserIDQuery(d dialect.Dialect) string {
+	switch d {
+	case dialect.Postgres:
+		return `ALTER TABLE "User" ALTER COLUMN ID SET NOT NULL`
+	case dialect.SQLite:
+		return ""
+	default:
+		return d.SQL(`ALTER TABLE "User" MODIFY ID CHAR(36) NOT NULL`)
+	}
+}
+
+// addUserID adds the ID column holding a user's stable surrogate key. Existing users are
+// given a UUIDv7 before the column is made mandatory and unique.
+func addUserID(d dialect.Dialect) migration.Migrate {
+	return migration.Migrate{
+		UP: func(ds migration.Datasource) error {
+			if _, err := ds.SQL.Exec(d.SQL(addUserIDColumnQuery)); err != nil {
+				return err
+			}
+
+			if err := backfillUserIDs(ds.SQL, d); err != nil {
+				return err
+			}
+
+			if query := requireUserIDQuery(d); query != "" {
+				if _, err := ds.SQL.Exec(query); err != nil {
+					return err
+				}
+			}
+
+			return createIndexes(ds.SQL, d, idIndex)
+		},
+	}
+}
+
+func backfillUserIDs(db migration.SQL, d dialect.Dialect) error {
+	rows, err := db.Query(d.SQL(selectUsersWithoutIDQuery))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var names []string
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+
+		if _, err := db.Exec(d.SQL(setUserIDQuery), id.String(), name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"gofr.dev/pkg/gofr/migration"
+	"gofrProject/dialect"
+)
+
+var deletedAtIndex = index{name: "idx_user_deleted_at", table: "User", columns: "DeletedAt"}
+
+func addUserDeletedAtColumnQuery(d dialect.Dialect) string {
+	return d.SQL(`ALTER TABLE "User" ADD COLUMN DeletedAt ` + d.Timestamp() + " NULL")
+}
+
+// addUserDeletedAt adds the DeletedAt column marking soft-deleted users. It is indexed so that
+// purging users deleted before a point in time does not scan the whole table.
+func addUserDeletedAt(d dialect.Dialect) migration.Migrate {
+	return migration.Migrate{
+		UP: func(ds migration.Datasource) error {
+			if _, err := ds.SQL.Exec(addUserDeletedAtColumnQuery(d)); err != nil {
+				return err
+			}
+
+			return createIndexes(ds.SQL, d, deletedAtIndex)
+		},
+	}
+}
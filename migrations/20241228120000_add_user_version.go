@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"gofr.dev/pkg/gofr/migration"
+	"gofrProject/dialect"
+)
+
+const addUserVersionColumnQuery = `ALTER TABLE "User" ADD COLUMN Version INT NOT NULL DEFAULT 1`
+
+// addUserVersion adds the Version column that every write of a user increments. It backs the
+// ETag of the user and the optimistic concurrency checks of conditional writes.
+func addUserVersion(d dialect.Dialect) migration.Migrate {
+	return migration.Migrate{
+		UP: func(ds migration.Datasource) error {
+			_, err := ds.SQL.Exec(d.SQL(addUserVersionColumnQuery))
+
+			return err
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"gofr.dev/pkg/gofr/migration"
+	"gofrProject/dialect"
+)
+
+// createUserOutboxTableQuery returns the statement creating the UserOutbox table, holding the
+// events of user changes until the relay has published them.
+func createUserOutboxTableQuery(d dialect.Dialect) string {
+	return d.SQL(`CREATE TABLE IF NOT EXISTS "UserOutbox" (
+	` + d.AutoIncrement("ID") + `,
+	Topic     VARCHAR(255) NOT NULL,
+	Payload   TEXT NOT NULL,
+	CreatedAt ` + d.Timestamp() + ` NOT NULL,
+	SentAt    ` + d.Timestamp() + ` NULL
+)`)
+}
+
+var outboxPendingIndex = index{name: "idx_user_outbox_pending", table: "UserOutbox", columns: "SentAt, ID"}
+
+// createUserOutbox creates the transactional outbox of user events. The index lets the relay
+// find the oldest unsent events without scanning the events it already published.
+func createUserOutbox(d dialect.Dialect) migration.Migrate {
+	return migration.Migrate{
+		UP: func(ds migration.Datasource) error {
+			if _, err := ds.SQL.Exec(createUserOutboxTableQuery(d)); err != nil {
+				return err
+			}
+
+			return createIndexes(ds.SQL, d, outboxPendingIndex)
+		},
+	}
+}
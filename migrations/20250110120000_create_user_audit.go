@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"gofr.dev/pkg/gofr/migration"
+	"gofrProject/dialect"
+)
+
+// createUserAuditTableQuery returns the statement creating the UserAudit table, the append-only log
+// of user changes.
+func createUserAuditTableQuery(d dialect.Dialect) string {
+	return d.SQL(`CREATE TABLE IF NOT EXISTS "UserAudit" (
+	` + d.AutoIncrement("ID") + `,
+	Actor     VARCHAR(255) NOT NULL,
+	Action    VARCHAR(16) NOT NULL,
+	UserID    VARCHAR(36) NOT NULL,
+	UserName  VARCHAR(255) NOT NULL,
+	Changes   TEXT NOT NULL,
+	RequestID VARCHAR(255) NOT NULL,
+	CreatedAt ` + d.Timestamp() + ` NOT NULL
+)`)
+}
+
+var (
+	auditUserIndex  = index{name: "idx_user_audit_user", table: "UserAudit", columns: "UserID, ID"}
+	auditActorIndex = index{name: "idx_user_audit_actor", table: "UserAudit", columns: "Actor, CreatedAt"}
+	auditTimeIndex  = index{name: "idx_user_audit_created_at", table: "UserAudit", columns: "CreatedAt"}
+)
+
+// createUserAudit creates the audit log of user changes, indexed for the history of a user and for
+// queries by actor and time range. Rows are only ever inserted, never updated or deleted, and are
+// kept when the user they describe is purged.
+func createUserAudit(d dialect.Dialect) migration.Migrate {
+	return migration.Migrate{
+		UP: func(ds migration.Datasource) error {
+			if _, err := ds.SQL.Exec(createUserAuditTableQuery(d)); err != nil {
+				return err
+			}
+
+			return createIndexes(ds.SQL, d, auditUserIndex, auditActorIndex, auditTimeIndex)
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"gofr.dev/pkg/gofr/migration"
+	"gofrProject/dialect"
+	"time"
+)
+
+const backfillUserTimestampsQuery = `UPDATE "User" SET CreatedAt = ?, UpdatedAt = ? WHERE CreatedAt IS NULL`
+
+func addUserTimestampColumnQuery(d dialect.Dialect, column string) string {
+	return d.SQL(`ALTER TABLE "User" ADD COLUMN ` + column + " " + d.Timestamp() + " NULL")
+}
+
+// addUserTimestamps adds the CreatedAt and UpdatedAt columns of the v2 representation of users.
+// The store sets both on every write, the users that exist already are given the time of the
+// migration as their times are not known.
+func addUserTimestamps(d dialect.Dialect) migration.Migrate {
+	return migration.Migrate{
+		UP: func(ds migration.Datasource) error {
+			for _, column := range []string{"CreatedAt", "UpdatedAt"} {
+				if _, err := ds.SQL.Exec(addUserTimestampColumnQuery(d, column)); err != nil {
+					return err
+				}
+			}
+
+			now := time.Now().UTC()
+			_, err := ds.SQL.Exec(d.SQL(backfillUserTimestampsQuery), now, now)
+
+			return err
+		},
+	}
+}
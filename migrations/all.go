@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"gofr.dev/pkg/gofr/migration"
+	"gofrProject/dialect"
+)
+
+// All returns every migration known to the application keyed by its version.
+// GoFr records applied versions in its gofr_migrations table and only runs the
+// ones that are newer than the last applied version, so new columns are added by
+// appending a new, higher-numbered migration here instead of editing an old one.
+// The statements are written for the dialect of the configured database.
+func All(d dialect.Dialect) map[int64]migration.Migrate {
+	return map[int64]migration.Migrate{
+		20241201120000: createUserTable(d),
+		20241215120000: addUserID(d),
+		20241220120000: addUserDeletedAt(d),
+		20241228120000: addUserVersion(d),
+		20250105120000: createUserOutbox(d),
+		20250110120000: createUserAudit(d),
+		20250120120000: addUserTimestamps(d),
+	}
+}
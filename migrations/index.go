@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"gofr.dev/pkg/gofr/migration"
+	"gofrProject/dialect"
+)
+
+// index is an index a migration creates. MySQL has no CREATE INDEX IF NOT EXISTS, so the catalog
+// is checked first and an index that exists already, e.g. one created by hand before the
+// migrations were introduced, is left as it is.
+type index struct {
+	name    string
+	table   string
+	columns string
+	unique  bool
+}
+
+// createQuery returns the statement creating the index.
+func (i index) createQuery(d dialect.Dialect) string {
+	query := "CREATE INDEX "
+	if i.unique {
+		query = "CREATE UNIQUE INDEX "
+	}
+
+	return d.SQL(query + i.name + ` ON "` + i.table + `" (` + i.columns + ")")
+}
+
+// indexExistsQuery returns the query counting the indexes of a table, the first argument, with
+// the name given as the second argument.
+func indexExistsQuery(d dialect.Dialect) string {
+	switch d {
+	case dialect.Postgres:
+		return d.SQL("SELECT COUNT(*) FROM pg_indexes WHERE schemaname = current_schema() AND tablename = ? AND indexname = ?")
+	case dialect.SQLite:
+		return "SELECT COUNT(*) FROM sqlite_master WHERE tbl_name = ? AND name = ?"
+	default:
+		return "SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? " +
+			"AND index_name = ?"
+	}
+}
+
+// createIndexes creates the indexes that do not exist yet.
+func createIndexes(db migration.SQL, d dialect.Dialect, indexes ...index) error {
+	for _, i := range indexes {
+		var count int
+		if err := db.QueryRow(indexExistsQuery(d), i.table, i.name).Scan(&count); err != nil {
+			return err
+		}
+
+		if count > 0 {
+			continue
+		}
+
+		if _, err := db.Exec(i.createQuery(d)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
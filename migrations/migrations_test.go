@@ -0,0 +1,275 @@
+package migrations
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gofr.dev/pkg/gofr/container"
+	"gofr.dev/pkg/gofr/migration"
+	"gofrProject/dialect"
+)
+
+func TestAll(t *testing.T) {
+	all := All(dialect.MySQL)
+
+	assert.Contains(t, all, int64(20241201120000))
+	assert.Contains(t, all, int64(20241215120000))
+	assert.Contains(t, all, int64(20241220120000))
+	assert.Contains(t, all, int64(20241228120000))
+	assert.Contains(t, all, int64(20250105120000))
+	assert.Contains(t, all, int64(20250110120000))
+	assert.Contains(t, all, int64(20250120120000))
+
+	for version, m := range all {
+		assert.NotNil(t, m.UP, "migration %d has no UP function", version)
+	}
+}
+
+// expectIndex expects the lookup of the index name of table in the catalog, which finds count
+// indexes.
+func expectIndex(mock sqlmock.Sqlmock, d dialect.Dialect, table, name string, count int) {
+	mock.ExpectQuery(indexExistsQuery(d)).WithArgs(table, name).
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(count))
+}
+
+func TestCreateUserTable(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockExpect    func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "table and indexes created",
+			mockExpect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(createUserTableQuery(dialect.MySQL)).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectIndex(mock, dialect.MySQL, "User", "idx_user_phone_number", 0)
+				mock.ExpectExec("CREATE UNIQUE INDEX idx_user_phone_number ON `User` (PhoneNumber)").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				expectIndex(mock, dialect.MySQL, "User", "idx_user_email", 0)
+				mock.ExpectExec("CREATE UNIQUE INDEX idx_user_email ON `User` (Email)").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "existing indexes are left as they are",
+			mockExpect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(createUserTableQuery(dialect.MySQL)).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectIndex(mock, dialect.MySQL, "User", "idx_user_phone_number", 1)
+				expectIndex(mock, dialect.MySQL, "User", "idx_user_email", 1)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "error while looking up an index",
+			mockExpect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(createUserTableQuery(dialect.MySQL)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(indexExistsQuery(dialect.MySQL)).WithArgs("User", "idx_user_phone_number").
+					WillReturnError(fmt.Errorf("db error"))
+			},
+			expectedError: fmt.Errorf("db error"),
+		},
+		{
+			name: "error while creating table",
+			mockExpect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(createUserTableQuery(dialect.MySQL)).WillReturnError(fmt.Errorf("db error"))
+			},
+			expectedError: fmt.Errorf("db error"),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mock := container.NewMockContainer(t)
+			tt.mockExpect(mock.SQL)
+
+			err := createUserTable(dialect.MySQL).UP(migration.Datasource{SQL: mockContainer.SQL})
+
+			assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
+			assert.NoError(t, mock.SQL.ExpectationsWereMet(), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestAddUserID(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockExpect    func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "existing users are given an id",
+			mockExpect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(dialect.MySQL.SQL(addUserIDColumnQuery)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(dialect.MySQL.SQL(selectUsersWithoutIDQuery)).
+					WillReturnRows(sqlmock.NewRows([]string{"UserName"}).AddRow("waheed"))
+				mock.ExpectExec(dialect.MySQL.SQL(setUserIDQuery)).WithArgs(sqlmock.AnyArg(), "waheed").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(requireUserIDQuery(dialect.MySQL)).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectIndex(mock, dialect.MySQL, "User", "idx_user_id", 0)
+				mock.ExpectExec("CREATE UNIQUE INDEX idx_user_id ON `User` (ID)").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "error while adding column",
+			mockExpect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(dialect.MySQL.SQL(addUserIDColumnQuery)).WillReturnError(fmt.Errorf("db error"))
+			},
+			expectedError: fmt.Errorf("db error"),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mock := container.NewMockContainer(t)
+			tt.mockExpect(mock.SQL)
+
+			err := addUserID(dialect.MySQL).UP(migration.Datasource{SQL: mockContainer.SQL})
+
+			assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestAddUserDeletedAt(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockExpect    func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "column and index created",
+			mockExpect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(addUserDeletedAtColumnQuery(dialect.MySQL)).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectIndex(mock, dialect.MySQL, "User", "idx_user_deleted_at", 0)
+				mock.ExpectExec("CREATE INDEX idx_user_deleted_at ON `User` (DeletedAt)").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "error while creating index",
+			mockExpect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(addUserDeletedAtColumnQuery(dialect.MySQL)).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectIndex(mock, dialect.MySQL, "User", "idx_user_deleted_at", 0)
+				mock.ExpectExec("CREATE INDEX idx_user_deleted_at ON `User` (DeletedAt)").WillReturnError(fmt.Errorf("db error"))
+			},
+			expectedError: fmt.Errorf("db error"),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mock := container.NewMockContainer(t)
+			tt.mockExpect(mock.SQL)
+
+			err := addUserDeletedAt(dialect.MySQL).UP(migration.Datasource{SQL: mockContainer.SQL})
+
+			assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestAddUserVersion(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	mock.SQL.ExpectExec(dialect.MySQL.SQL(addUserVersionColumnQuery)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := addUserVersion(dialect.MySQL).UP(migration.Datasource{SQL: mockContainer.SQL})
+
+	assert.NoError(t, err)
+}
+
+func TestCreateUserTable_Postgres(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	mock.SQL.ExpectExec(createCITextExtensionQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.SQL.ExpectExec(`CREATE TABLE IF NOT EXISTS "User" (
+	UserName    CITEXT NOT NULL,
+	UserAge     INT NOT NULL DEFAULT 0,
+	PhoneNumber CITEXT NOT NULL,
+	Email       CITEXT NOT NULL,
+	PRIMARY KEY (UserName)
+)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.SQL.ExpectQuery("SELECT COUNT(*) FROM pg_indexes WHERE schemaname = current_schema() AND tablename = $1 AND indexname = $2").
+		WithArgs("User", "idx_user_phone_number").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.SQL.ExpectExec(`CREATE UNIQUE INDEX idx_user_phone_number ON "User" (PhoneNumber)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectIndex(mock.SQL, dialect.Postgres, "User", "idx_user_email", 1)
+
+	err := createUserTable(dialect.Postgres).UP(migration.Datasource{SQL: mockContainer.SQL})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.SQL.ExpectationsWereMet())
+}
+
+func TestAddUserID_SQLite(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	mock.SQL.ExpectExec(addUserIDColumnQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.SQL.ExpectQuery(selectUsersWithoutIDQuery).WillReturnRows(sqlmock.NewRows([]string{"UserName"}))
+	mock.SQL.ExpectQuery("SELECT COUNT(*) FROM sqlite_master WHERE tbl_name = ? AND name = ?").
+		WithArgs("User", "idx_user_id").WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(0))
+	mock.SQL.ExpectExec(`CREATE UNIQUE INDEX idx_user_id ON "User" (ID)`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := addUserID(dialect.SQLite).UP(migration.Datasource{SQL: mockContainer.SQL})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.SQL.ExpectationsWereMet())
+}
+
+func TestCreateUserOutbox(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	mock.SQL.ExpectExec("CREATE TABLE IF NOT EXISTS `UserOutbox` (\n" +
+		"\tID BIGINT AUTO_INCREMENT PRIMARY KEY,\n" +
+		"\tTopic     VARCHAR(255) NOT NULL,\n" +
+		"\tPayload   TEXT NOT NULL,\n" +
+		"\tCreatedAt DATETIME NOT NULL,\n" +
+		"\tSentAt    DATETIME NULL\n" +
+		")").WillReturnResult(sqlmock.NewResult(0, 0))
+	expectIndex(mock.SQL, dialect.MySQL, "UserOutbox", "idx_user_outbox_pending", 0)
+	mock.SQL.ExpectExec("CREATE INDEX idx_user_outbox_pending ON `UserOutbox` (SentAt, ID)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := createUserOutbox(dialect.MySQL).UP(migration.Datasource{SQL: mockContainer.SQL})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.SQL.ExpectationsWereMet())
+}
+
+func TestCreateUserAudit(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	mock.SQL.ExpectExec("CREATE TABLE IF NOT EXISTS \"UserAudit\" (\n" +
+		"\tID BIGSERIAL PRIMARY KEY,\n" +
+		"\tActor     VARCHAR(255) NOT NULL,\n" +
+		"\tAction    VARCHAR(16) NOT NULL,\n" +
+		"\tUserID    VARCHAR(36) NOT NULL,\n" +
+		"\tUserName  VARCHAR(255) NOT NULL,\n" +
+		"\tChanges   TEXT NOT NULL,\n" +
+		"\tRequestID VARCHAR(255) NOT NULL,\n" +
+		"\tCreatedAt TIMESTAMP NOT NULL\n" +
+		")").WillReturnResult(sqlmock.NewResult(0, 0))
+	expectIndex(mock.SQL, dialect.Postgres, "UserAudit", "idx_user_audit_user", 0)
+	mock.SQL.ExpectExec(`CREATE INDEX idx_user_audit_user ON "UserAudit" (UserID, ID)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectIndex(mock.SQL, dialect.Postgres, "UserAudit", "idx_user_audit_actor", 0)
+	mock.SQL.ExpectExec(`CREATE INDEX idx_user_audit_actor ON "UserAudit" (Actor, CreatedAt)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectIndex(mock.SQL, dialect.Postgres, "UserAudit", "idx_user_audit_created_at", 0)
+	mock.SQL.ExpectExec(`CREATE INDEX idx_user_audit_created_at ON "UserAudit" (CreatedAt)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := createUserAudit(dialect.Postgres).UP(migration.Datasource{SQL: mockContainer.SQL})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.SQL.ExpectationsWereMet())
+}
+
+func TestAddUserTimestamps(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	mock.SQL.ExpectExec(`ALTER TABLE "User" ADD COLUMN CreatedAt TIMESTAMP NULL`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.SQL.ExpectExec(`ALTER TABLE "User" ADD COLUMN UpdatedAt TIMESTAMP NULL`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.SQL.ExpectExec(`UPDATE "User" SET CreatedAt = $1, UpdatedAt = $2 WHERE CreatedAt IS NULL`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := addUserTimestamps(dialect.Postgres).UP(migration.Datasource{SQL: mockContainer.SQL})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.SQL.ExpectationsWereMet())
+}
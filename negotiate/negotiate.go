@@ -0,0 +1,174 @@
+// Package negotiate picks the format of a response from the Accept header of the request and reads
+// request bodies in the format named by their Content-Type. JSON is the default of both.
+package negotiate
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/vmihailenco/msgpack/v5"
+	"gofrProject/apperrors"
+	"io"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Media types of the formats, other names of the same formats are translated into them.
+const (
+	JSON        = "application/json"
+	XML         = "application/xml"
+	CSV         = "text/csv"
+	MessagePack = "application/msgpack"
+)
+
+var aliases = map[string]string{
+	"text/xml":                XML,
+	"application/x-msgpack":   MessagePack,
+	"application/vnd.msgpack": MessagePack,
+}
+
+// canonical returns the media type of the format named by mediaType.
+func canonical(mediaType string) string {
+	if alias, ok := aliases[mediaType]; ok {
+		return alias
+	}
+
+	return mediaType
+}
+
+// Accept returns the media type of offers the Accept header accept rates highest, the first of them
+// if the header is empty. Ties go to the offer that comes first.
+func Accept(accept string, offers ...string) (string, error) {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], nil
+	}
+
+	best, bestQuality := "", 0.0
+
+	for _, offer := range offers {
+		if q := quality(accept, offer); q > bestQuality {
+			best, bestQuality = offer, q
+		}
+	}
+
+	if best == "" {
+		return "", apperrors.NotAcceptable("Accept must allow one of " + strings.Join(offers, ", "))
+	}
+
+	return best, nil
+}
+
+// quality returns the quality value the most specific media range of accept that matches mediaType
+// gives it, 0 if none matches.
+func quality(accept, mediaType string) float64 {
+	q, specificity := 0.0, -1
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		rangeType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		s := matches(canonical(rangeType), mediaType)
+		if s <= specificity {
+			continue
+		}
+
+		q, specificity = 1, s
+
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				q = 0
+			}
+		}
+	}
+
+	return q
+}
+
+// matches returns how specific mediaRange is if it matches mediaType, -1 if it does not.
+func matches(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	default:
+		return -1
+	}
+}
+
+// ContentType returns the media type of the format named by the Content-Type header contentType,
+// JSON if it is empty, failing unless the format is one of supported.
+func ContentType(contentType string, supported ...string) (string, error) {
+	mediaType := JSON
+
+	if contentType != "" {
+		parsed, _, _ := mime.ParseMediaType(contentType)
+		mediaType = canonical(parsed)
+	}
+
+	if !slices.Contains(supported, mediaType) {
+		return "", apperrors.UnsupportedMediaType("Content-Type must be one of " + strings.Join(supported, ", "))
+	}
+
+	return mediaType, nil
+}
+
+// xmlResponse is the document of XML responses. Like the JSON responses of GoFr, it holds the
+// data of the response in a data element.
+type xmlResponse struct {
+	XMLName xml.Name `xml:"response"`
+	Data    any      `xml:"data"`
+}
+
+// Marshal returns the response holding data in the format of mediaType, which is XML or
+// MessagePack. Both hold data like the JSON responses of GoFr, the MessagePack document is the
+// JSON document in MessagePack encoding.
+func Marshal(mediaType string, data any) ([]byte, error) {
+	switch mediaType {
+	case XML:
+		body, err := xml.Marshal(xmlResponse{Data: data})
+		if err != nil {
+			return nil, err
+		}
+
+		return append([]byte(xml.Header), body...), nil
+	case MessagePack:
+		var b bytes.Buffer
+
+		enc := msgpack.NewEncoder(&b)
+		enc.SetCustomStructTag("json")
+
+		if err := enc.Encode(map[string]any{"data": data}); err != nil {
+			return nil, err
+		}
+
+		return b.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("cannot marshal %s", mediaType)
+	}
+}
+
+// Unmarshal reads the body r in the format of mediaType into v. Unlike responses, bodies are not
+// wrapped in a data element.
+func Unmarshal(mediaType string, r io.Reader, v any) error {
+	switch mediaType {
+	case JSON:
+		return json.NewDecoder(r).Decode(v)
+	case XML:
+		return xml.NewDecoder(r).Decode(v)
+	case MessagePack:
+		dec := msgpack.NewDecoder(r)
+		dec.SetCustomStructTag("json")
+
+		return dec.Decode(v)
+	default:
+		return fmt.Errorf("cannot unmarshal %s", mediaType)
+	}
+}
@@ -0,0 +1,113 @@
+package negotiate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"gofrProject/apperrors"
+)
+
+type user struct {
+	UserName string `json:"user_name" xml:"user_name"`
+	UserAge  int    `json:"user_age" xml:"user_age"`
+}
+
+func TestAccept(t *testing.T) {
+	offers := []string{JSON, XML, CSV, MessagePack}
+	notAcceptable := apperrors.NotAcceptable("Accept must allow one of application/json, application/xml, text/csv, " +
+		"application/msgpack")
+
+	tests := []struct {
+		name          string
+		accept        string
+		expectedType  string
+		expectedError error
+	}{
+		{name: "no header", accept: "", expectedType: JSON},
+		{name: "any type", accept: "*/*", expectedType: JSON},
+		{name: "exact type", accept: "text/csv", expectedType: CSV},
+		{name: "alias", accept: "application/x-msgpack", expectedType: MessagePack},
+		{name: "highest quality", accept: "application/json;q=0.5, application/xml", expectedType: XML},
+		{name: "specific range wins over wildcard", accept: "*/*;q=0.9, application/json;q=0.1, text/*", expectedType: CSV},
+		{name: "excluded type", accept: "application/json;q=0, */*;q=0.1", expectedType: XML},
+		{name: "browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", expectedType: XML},
+		{name: "nothing acceptable", accept: "text/html, image/*", expectedError: notAcceptable},
+	}
+
+	for i, tt := range tests {
+		mediaType, err := Accept(tt.accept, offers...)
+
+		assert.Equal(t, tt.expectedType, mediaType, "TEST[%d] failed: %s", i, tt.name)
+		assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	unsupported := apperrors.UnsupportedMediaType("Content-Type must be one of application/json, application/xml")
+
+	tests := []struct {
+		contentType   string
+		expectedType  string
+		expectedError error
+	}{
+		{contentType: "", expectedType: JSON},
+		{contentType: "application/json; charset=utf-8", expectedType: JSON},
+		{contentType: "text/xml", expectedType: XML},
+		{contentType: "text/csv", expectedError: unsupported},
+	}
+
+	for i, tt := range tests {
+		mediaType, err := ContentType(tt.contentType, JSON, XML)
+
+		assert.Equal(t, tt.expectedType, mediaType, "TEST[%d] failed: %s", i, tt.contentType)
+		assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.contentType)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	body, err := Marshal(XML, user{UserName: "waheed", UserAge: 19})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<response><data><user_name>waheed</user_name><user_age>19</user_age></data></response>`, string(body))
+
+	body, err = Marshal(MessagePack, user{UserName: "waheed", UserAge: 19})
+	assert.NoError(t, err)
+
+	var decoded map[string]map[string]any
+
+	assert.NoError(t, msgpack.Unmarshal(body, &decoded))
+	assert.Equal(t, map[string]map[string]any{"data": {"user_name": "waheed", "user_age": int8(19)}}, decoded)
+
+	_, err = Marshal(CSV, user{})
+	assert.EqualError(t, err, "cannot marshal text/csv")
+}
+
+func TestUnmarshal(t *testing.T) {
+	packed, _ := msgpack.Marshal(map[string]any{"user_name": "waheed", "user_age": 19})
+
+	tests := []struct {
+		name      string
+		mediaType string
+		body      []byte
+	}{
+		{name: "json", mediaType: JSON, body: []byte(`{"user_name":"waheed","user_age":19}`)},
+		{name: "xml", mediaType: XML, body: []byte(`<user><user_name>waheed</user_name><user_age>19</user_age></user>`)},
+		{name: "msgpack", mediaType: MessagePack, body: packed},
+	}
+
+	for i, tt := range tests {
+		var u user
+
+		err := Unmarshal(tt.mediaType, bytes.NewReader(tt.body), &u)
+
+		assert.NoError(t, err, "TEST[%d] failed: %s", i, tt.name)
+		assert.Equal(t, user{UserName: "waheed", UserAge: 19}, u, "TEST[%d] failed: %s", i, tt.name)
+	}
+
+	assert.EqualError(t, Unmarshal(CSV, strings.NewReader(""), &user{}), "cannot unmarshal text/csv")
+}
@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const staticFile = "../static/openapi.json"
+
+var update = flag.Bool("update", false, "write the document to "+staticFile)
+
+func TestStaticFile(t *testing.T) {
+	b, err := json.MarshalIndent(New(), "", "  ")
+	require.NoError(t, err)
+
+	b = append(b, '\n')
+
+	if *update {
+		require.NoError(t, os.MkdirAll("../static", 0o755))
+		require.NoError(t, os.WriteFile(staticFile, b, 0o600))
+	}
+
+	static, err := os.ReadFile(staticFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(b), string(static), "%s is out of date, run go generate ./openapi", staticFile)
+}
+
+// refs returns every $ref of the JSON value v.
+func refs(v any) []string {
+	var found []string
+
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				found = append(found, ref)
+			}
+
+			found = append(found, refs(value)...)
+		}
+	case []any:
+		for _, value := range v {
+			found = append(found, refs(value)...)
+		}
+	}
+
+	return found
+}
+
+func TestNew_References(t *testing.T) {
+	b, err := json.Marshal(New())
+	require.NoError(t, err)
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(b, &document))
+
+	components := document["components"].(map[string]any)
+
+	for _, ref := range refs(document) {
+		kind, name, ok := strings.Cut(strings.TrimPrefix(ref, "#/components/"), "/")
+		require.True(t, ok, "%s is not a reference to a component", ref)
+
+		assert.Contains(t, components[kind], name, "%s is not defined", ref)
+	}
+}
+
+func TestNew_Operations(t *testing.T) {
+	d := New()
+	ids := make(map[string]bool)
+
+	for path, item := range d.Paths {
+		for method, op := range item {
+			assert.NotEmpty(t, op.Permission, "%s %s has no permission", method, path)
+			assert.False(t, ids[op.OperationID], "%s %s reuses the id %s", method, path, op.OperationID)
+
+			ids[op.OperationID] = true
+
+			for _, param := range op.Parameters {
+				if param.Ref != "" {
+					param = d.Components.Parameters[strings.TrimPrefix(param.Ref, "#/components/parameters/")]
+				}
+
+				if param.In == "path" {
+					assert.Contains(t, path, "{"+param.Name+"}", "%s %s has no parameter %s", method, path, param.Name)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,538 @@
+// Package openapi describes the HTTP API of the users as an OpenAPI 3.1 document. The document is
+// written to static/openapi.json, which GoFr serves at /.well-known/openapi.json along with a
+// Swagger UI at /.well-known/swagger. Run go generate after changing the routes or the entities.
+//
+// The schemas of the bodies are derived from the entities by reflection, so they follow their JSON
+// encoding, including the phone_Number field of v1. The tests fail if static/openapi.json is out of date
+// or if the operations differ from the routes registered in main.go.
+package openapi
+
+//go:generate go test . -run TestStaticFile -update
+
+import (
+	"fmt"
+	"gofrProject/apiversion"
+	"gofrProject/authz"
+	"gofrProject/entities"
+	"gofrProject/events"
+	"gofrProject/negotiate"
+	"gofrProject/problem"
+	"gofrProject/service"
+	"gofrProject/validation"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// components are the named schemas, by the type they describe.
+var components = map[reflect.Type]string{
+	reflect.TypeOf(entities.Users{}):          "User",
+	reflect.TypeOf(entities.UsersPage{}):      "UsersPage",
+	reflect.TypeOf(entities.UserV2{}):         "UserV2",
+	reflect.TypeOf(entities.UsersPageV2{}):    "UsersPageV2",
+	reflect.TypeOf(entities.Rename{}):         "Rename",
+	reflect.TypeOf(entities.PurgeResult{}):    "PurgeResult",
+	reflect.TypeOf(entities.AuditEntry{}):     "AuditEntry",
+	reflect.TypeOf(entities.AuditPage{}):      "AuditPage",
+	reflect.TypeOf(entities.BatchRequest{}):   "BatchRequest",
+	reflect.TypeOf(entities.BatchOperation{}): "BatchOperation",
+	reflect.TypeOf(entities.BatchResponse{}):  "BatchResponse",
+	reflect.TypeOf(entities.BatchResult{}):    "BatchResult",
+	reflect.TypeOf(entities.ImportResult{}):   "ImportResult",
+	reflect.TypeOf(entities.ImportError{}):    "ImportError",
+	reflect.TypeOf(entities.SearchResult{}):   "SearchResult",
+	reflect.TypeOf(entities.SearchPage{}):     "SearchPage",
+	reflect.TypeOf(entities.RebuildResult{}):  "RebuildResult",
+	reflect.TypeOf(entities.UserChange{}):     "UserChange",
+	reflect.TypeOf(events.FieldChange{}):      "FieldChange",
+	reflect.TypeOf(problem.Details{}):         "Problem",
+	reflect.TypeOf(validation.FieldError{}):   "FieldError",
+}
+
+// problemResponses are the error responses, by status code. Every one has a problem details body.
+var problemResponses = map[int]string{
+	http.StatusBadRequest:           "The request is invalid, errors lists the invalid fields.",
+	http.StatusUnauthorized:         "The request carries no valid credentials.",
+	http.StatusForbidden:            "The principal lacks the permission of the operation.",
+	http.StatusNotFound:             "The user does not exist.",
+	http.StatusNotAcceptable:        "None of the media types of the Accept header can be sent.",
+	http.StatusConflict:             "The user name is taken, or the user was changed concurrently.",
+	http.StatusPreconditionFailed:   "The user is not at the version of the If-Match header.",
+	http.StatusUnsupportedMediaType: "The Content-Type of the body is not supported.",
+	http.StatusPreconditionRequired: "The If-Match header is required for writes.",
+	http.StatusServiceUnavailable:   "The store or the feature is not available.",
+}
+
+// New returns the document describing the routes registered in main.go.
+func New() *Document {
+	d := &Document{
+		OpenAPI: "3.1.0",
+		Info: Info{Title: "Users API", Version: "1.0.0", Description: "Create, read, update and delete users. " +
+			"Successful responses hold their content in a data member, errors are RFC 7807 problem details. " +
+			"The routes under /v2 are version 2 of the API, every other route is version 1, which is deprecated " +
+			"in favour of /v2/users once USER_V1_DEPRECATED_AT is set."},
+		Paths:      make(map[string]PathItem),
+		Components: newComponents(),
+		Security:   []SecurityRequirement{{"apiKey": {}}, {"basic": {}}, {"bearer": {}}},
+	}
+
+	addUserRoutes(d)
+	addBulkRoutes(d)
+	addAuditRoutes(d)
+	addV2Routes(d)
+
+	for path, item := range d.Paths {
+		for _, op := range item {
+			op.Tags = []string{tag(op)}
+			op.Responses[status(http.StatusUnauthorized)] = problemRef(http.StatusUnauthorized)
+			op.Responses[status(http.StatusForbidden)] = problemRef(http.StatusForbidden)
+
+			if apiversion.Of(path) == apiversion.V1 {
+				addDeprecationHeaders(op)
+			}
+		}
+	}
+
+	return d
+}
+
+func newComponents() Components {
+	s := schemas{names: components}
+
+	c := Components{
+		Schemas:    make(map[string]*Schema),
+		Responses:  make(map[string]Response),
+		Parameters: userParameters(),
+		SecuritySchemes: map[string]SecurityScheme{
+			"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key",
+				Description: "An API key, when AUTH_METHOD is api_key. It may also be sent as the whole Authorization header."},
+			"basic":  {Type: "http", Scheme: "basic", Description: "A user name and password, when AUTH_METHOD is basic."},
+			"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "A JWT, when AUTH_METHOD is jwt."},
+		},
+	}
+
+	for t, name := range components {
+		c.Schemas[name] = s.object(t)
+	}
+
+	describeSchemas(c.Schemas)
+
+	for code, description := range problemResponses {
+		c.Responses[problemName(code)] = Response{Description: description,
+			Content: map[string]MediaType{problem.ContentType: {Schema: ref("Problem")}}}
+	}
+
+	return c
+}
+
+// describeSchemas adds what reflection cannot tell to the schemas: descriptions, formats and the
+// rules of package validation.
+func describeSchemas(schemas map[string]*Schema) {
+	minName, maxName := validation.MinUserNameLength, validation.MaxUserNameLength
+	minAge, maxAge := validation.MinUserAge, validation.MaxUserAge
+
+	user := schemas["User"]
+	user.Description = "A user. The server assigns id on creation and deleted_at on deletion, both are ignored in bodies."
+	user.Required = []string{"user_name", "user_age", "phone_Number", "email"}
+	user.Properties["id"] = &Schema{Type: "string", Format: "uuid", ReadOnly: true}
+	user.Properties["user_name"] = &Schema{Type: "string", MinLength: &minName, MaxLength: &maxName,
+		Pattern: "^[A-Za-z0-9][A-Za-z0-9._-]*$", Description: "Unique regardless of case."}
+	user.Properties["user_age"] = &Schema{Type: "integer", Minimum: &minAge, Maximum: &maxAge}
+	user.Properties["phone_Number"] = &Schema{Type: "string", Description: "Normalised to E.164, e.g. +14155552671. " +
+		"The name has a capital N for compatibility with existing clients."}
+	user.Properties["email"] = &Schema{Type: "string", Format: "email"}
+	user.Properties["deleted_at"].ReadOnly = true
+
+	schemas["UsersPage"].Properties["next_cursor"].Description = "Cursor of the next page, absent on the last page."
+
+	userV2 := schemas["UserV2"]
+	userV2.Description = "A user, in version 2 of the API. The fields set by the server are ignored in bodies."
+	userV2.Required = []string{"user_name", "user_age", "phone_number", "email"}
+	userV2.Properties["id"] = user.Properties["id"]
+	userV2.Properties["user_name"] = user.Properties["user_name"]
+	userV2.Properties["user_age"] = user.Properties["user_age"]
+	userV2.Properties["phone_number"] = &Schema{Type: "string", Description: "Normalised to E.164, e.g. +14155552671."}
+	userV2.Properties["email"] = user.Properties["email"]
+	userV2.Properties["created_at"].ReadOnly = true
+	userV2.Properties["updated_at"].ReadOnly = true
+	userV2.Properties["deleted_at"].ReadOnly = true
+	schemas["UsersPageV2"].Properties["next_cursor"].Description = schemas["UsersPage"].Properties["next_cursor"].Description
+
+	schemas["AuditEntry"].Properties["action"].Enum = []string{entities.AuditCreate, entities.AuditUpdate,
+		entities.AuditRename, entities.AuditDelete, entities.AuditRestore, entities.AuditPurge}
+	schemas["AuditEntry"].Properties["changes"] = changesSchema()
+	schemas["BatchOperation"].Properties["op"].Enum = []string{entities.BatchCreate, entities.BatchUpdate, entities.BatchDelete}
+	schemas["BatchResult"].Properties["op"].Enum = schemas["BatchOperation"].Properties["op"].Enum
+	schemas["UserChange"].Properties["type"].Enum = []string{events.TypeUserCreated, events.TypeUserUpdated,
+		events.TypeUserDeleted, events.TypeUserPurged}
+	schemas["UserChange"].Properties["changes"] = changesSchema()
+	schemas["FieldChange"].Properties["before"] = &Schema{Description: "Value before the change, null if the field had none."}
+	schemas["FieldChange"].Properties["after"] = &Schema{Description: "Value after the change, null if the field has none."}
+	schemas["Problem"].Properties["code"].Description = "Kind of the error, e.g. not_found."
+}
+
+// changesSchema is the schema of the fields changed by a change of a user, keyed by their JSON name.
+func changesSchema() *Schema {
+	return &Schema{Type: "object", AdditionalProperties: ref("FieldChange")}
+}
+
+func userParameters() map[string]*Parameter {
+	return map[string]*Parameter{
+		"name": {Name: "name", In: "path", Required: true, Schema: &Schema{Type: "string"}, Description: "Name of the user."},
+		"id":   {Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string", Format: "uuid"}, Description: "Id of the user."},
+		"limit": {Name: "limit", In: "query", Schema: &Schema{Type: "integer"}, Description: fmt.Sprintf(
+			"Size of the page, 1 to %d, %d by default.", service.MaxUsersLimit, service.DefaultUsersLimit)},
+		"cursor":       {Name: "cursor", In: "query", Schema: &Schema{Type: "string"}, Description: "Cursor of the page, as returned with the previous page."},
+		"sort":         {Name: "sort", In: "query", Schema: &Schema{Type: "string", Enum: []string{entities.SortByUserName, entities.SortByUserAge}}},
+		"order":        {Name: "order", In: "query", Schema: &Schema{Type: "string", Enum: []string{"asc", "desc"}}},
+		"min_age":      {Name: "min_age", In: "query", Schema: &Schema{Type: "integer"}},
+		"max_age":      {Name: "max_age", In: "query", Schema: &Schema{Type: "integer"}},
+		"email_domain": {Name: "email_domain", In: "query", Schema: &Schema{Type: "string"}},
+		"include_deleted": {Name: "include_deleted", In: "query", Schema: &Schema{Type: "boolean"},
+			Description: "Also list soft-deleted users, which requires the users:read_deleted permission."},
+		"If-Match": {Name: "If-Match", In: "header", Schema: &Schema{Type: "string"},
+			Description: "ETag of the user the write is based on, * for any. Required if USER_REQUIRE_IF_MATCH is set."},
+		"If-None-Match": {Name: "If-None-Match", In: "header", Schema: &Schema{Type: "string"},
+			Description: "ETags of the user the client has, answered with 304 Not Modified if it is current."},
+		"actor": {Name: "actor", In: "query", Schema: &Schema{Type: "string"}, Description: "Principal that made the changes."},
+		"from":  {Name: "from", In: "query", Schema: &Schema{Type: "string", Format: "date-time"}},
+		"to":    {Name: "to", In: "query", Schema: &Schema{Type: "string", Format: "date-time"}},
+	}
+}
+
+func addUserRoutes(d *Document) {
+	d.add("get", "/user", &Operation{OperationID: "listUsers", Summary: "List users", Permission: perm(authz.ReadUsers),
+		Parameters: params("limit", "cursor", "sort", "order", "min_age", "max_age", "email_domain", "include_deleted"),
+		Responses: responses(http.StatusOK, Response{Description: "A page of users. As CSV the page only holds the users.",
+			Headers: map[string]Header{
+				"X-Total-Count": {Description: "Number of users matching, with CSV.", Schema: &Schema{Type: "integer"}},
+				"X-Next-Cursor": {Description: "Cursor of the next page, with CSV.", Schema: &Schema{Type: "string"}},
+			},
+			Content: withCSV(negotiated(ref("UsersPage")))}, http.StatusBadRequest, http.StatusNotAcceptable)})
+	d.add("post", "/user", &Operation{OperationID: "createUser", Summary: "Create a user", Permission: perm(authz.CreateUsers),
+		RequestBody: userBody(),
+		Responses: responses(http.StatusCreated, Response{Description: "The user was created."},
+			http.StatusBadRequest, http.StatusConflict, http.StatusUnsupportedMediaType)})
+	d.add("get", "/user/changes", &Operation{OperationID: "streamChanges", Summary: "Follow the changes of users",
+		Description: "Server-sent events, one per change, named by its type and with the change as data. Clients " +
+			"reconnecting with Last-Event-ID are sent the changes they missed, or a reset event if they are no " +
+			"longer known. A comment is sent as heartbeat.",
+		Permission: perm(authz.ReadUsers),
+		Parameters: []*Parameter{
+			{Name: "Last-Event-ID", In: "header", Schema: &Schema{Type: "string"}, Description: "Id of the last change received."},
+			lastEventID(),
+		},
+		Responses: responses(http.StatusOK, Response{Description: "A stream of changes.",
+			Content: map[string]MediaType{"text/event-stream": {Schema: &Schema{Type: "string",
+				Description: "Events holding a UserChange as data."}}}}, http.StatusServiceUnavailable)})
+	d.add("get", "/user/changes/ws", &Operation{OperationID: "streamChangesWebSocket",
+		Summary: "Follow the changes of users over a WebSocket",
+		Description: "Every message is a UserChange, {\"type\":\"reset\"} where the event stream sends a reset event, " +
+			"or {\"type\":\"heartbeat\"}.",
+		Permission: perm(authz.ReadUsers), Parameters: []*Parameter{lastEventID()},
+		Responses: responses(http.StatusSwitchingProtocols, Response{Description: "The connection is upgraded to a WebSocket."},
+			http.StatusServiceUnavailable)})
+	d.add("get", "/user/search", &Operation{OperationID: "searchUsers", Summary: "Search users",
+		Description: "Users matching every word of q by name, email or phone number, best match first. Small typos are forgiven.",
+		Permission:  perm(authz.ReadUsers),
+		Parameters: append([]*Parameter{{Name: "q", In: "query", Required: true, Schema: &Schema{Type: "string"}}},
+			params("limit")...),
+		Responses: responses(http.StatusOK, Response{Description: "The best matches.", Content: negotiated(ref("SearchPage"))},
+			http.StatusBadRequest, http.StatusNotAcceptable, http.StatusServiceUnavailable)})
+	d.add("post", "/user/search/rebuild", &Operation{OperationID: "rebuildSearchIndex", Summary: "Rebuild the search index",
+		Permission: perm(authz.ReindexUsers),
+		Responses: responses(http.StatusCreated, Response{Description: "The index was rebuilt.",
+			Content: data(ref("RebuildResult"))}, http.StatusServiceUnavailable)})
+	d.add("get", "/user/{name}", &Operation{OperationID: "getUserByName", Summary: "Get a user by name",
+		Permission: perm(authz.ReadUsers), Parameters: params("name", "If-None-Match"),
+		Responses: responses(http.StatusOK, userResponse(), http.StatusNotModified, Response{Description: "The client has the current version."},
+			http.StatusNotFound, http.StatusNotAcceptable)})
+	d.add("put", "/user/{name}", &Operation{OperationID: "updateUser", Summary: "Replace a user",
+		Description: "The user may update itself without the permission.", Permission: perm(authz.UpdateUsers),
+		Parameters: params("name", "If-Match"), RequestBody: userBody(), Responses: writeResponses(http.StatusUnsupportedMediaType)})
+	d.add("patch", "/user/{name}", &Operation{OperationID: "patchUser", Summary: "Update fields of a user",
+		Description: "The user may update itself without the permission.", Permission: perm(authz.UpdateUsers),
+		Parameters: params("name", "If-Match"), RequestBody: mergePatch(), Responses: writeResponses()})
+	d.add("delete", "/user/{name}", &Operation{OperationID: "deleteUser", Summary: "Delete a user",
+		Description: "The user is soft-deleted, it can be restored until it is purged.", Permission: perm(authz.DeleteUsers),
+		Parameters: params("name", "If-Match"),
+		Responses: responses(http.StatusNoContent, Response{Description: "The user was deleted."}, http.StatusNotFound,
+			http.StatusConflict, http.StatusPreconditionFailed, http.StatusPreconditionRequired)})
+	d.add("post", "/user/{name}/restore", &Operation{OperationID: "restoreUser", Summary: "Restore a deleted user",
+		Permission: perm(authz.RestoreUsers), Parameters: params("name"),
+		Responses: responses(http.StatusCreated, Response{Description: "The restored user.", Content: negotiated(ref("User"))},
+			http.StatusNotFound, http.StatusConflict, http.StatusNotAcceptable)})
+	d.add("post", "/user/purge", &Operation{OperationID: "purgeUsers", Summary: "Purge deleted users",
+		Description: "Permanently removes the users deleted longer than USER_DELETED_RETENTION ago.",
+		Permission:  perm(authz.PurgeUsers),
+		Responses:   responses(http.StatusCreated, Response{Description: "The users were purged.", Content: data(ref("PurgeResult"))})})
+
+	d.add("get", "/users/{id}", &Operation{OperationID: "getUserByID", Summary: "Get a user by id",
+		Permission: perm(authz.ReadUsers), Parameters: params("id", "If-None-Match"),
+		Responses: responses(http.StatusOK, userResponse(), http.StatusNotModified, Response{Description: "The client has the current version."},
+			http.StatusNotFound, http.StatusNotAcceptable)})
+	d.add("put", "/users/{id}", &Operation{OperationID: "updateUserByID", Summary: "Replace a user by id",
+		Description: "The user may update itself without the permission.", Permission: perm(authz.UpdateUsers),
+		Parameters: params("id", "If-Match"), RequestBody: userBody(), Responses: writeResponses(http.StatusUnsupportedMediaType)})
+	d.add("patch", "/users/{id}", &Operation{OperationID: "patchUserByID", Summary: "Update fields of a user by id",
+		Description: "The user may update itself without the permission.", Permission: perm(authz.UpdateUsers),
+		Parameters: params("id", "If-Match"), RequestBody: mergePatch(), Responses: writeResponses()})
+	d.add("delete", "/users/{id}", &Operation{OperationID: "deleteUserByID", Summary: "Delete a user by id",
+		Permission: perm(authz.DeleteUsers), Parameters: params("id", "If-Match"),
+		Responses: responses(http.StatusNoContent, Response{Description: "The user was deleted."}, http.StatusNotFound,
+			http.StatusConflict, http.StatusPreconditionFailed, http.StatusPreconditionRequired)})
+	d.add("post", "/users/{id}/rename", &Operation{OperationID: "renameUser", Summary: "Rename a user",
+		Description: "The id of the user stays the same.", Permission: perm(authz.UpdateUsers), Parameters: params("id"),
+		RequestBody: &RequestBody{Required: true, Content: map[string]MediaType{negotiate.JSON: {Schema: ref("Rename")}}},
+		Responses: responses(http.StatusCreated, Response{Description: "The renamed user.", Content: negotiated(ref("User"))},
+			http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusNotAcceptable)})
+}
+
+func addBulkRoutes(d *Document) {
+	d.add("get", "/user/export", &Operation{OperationID: "exportUsers", Summary: "Export users",
+		Description: "Every user matching the filters, as a CSV or NDJSON file.", Permission: perm(authz.ExportUsers),
+		Parameters: append([]*Parameter{{Name: "format", In: "query", Schema: &Schema{Type: "string", Enum: []string{"csv", "ndjson"}},
+			Description: "Format of the file, csv by default."}},
+			params("sort", "order", "min_age", "max_age", "email_domain", "include_deleted")...),
+		Responses: responses(http.StatusOK, Response{Description: "The file of the users.",
+			Headers: map[string]Header{"Content-Disposition": {Schema: &Schema{Type: "string"}}},
+			Content: files()}, http.StatusBadRequest, http.StatusServiceUnavailable)})
+	d.add("post", "/user/import", &Operation{OperationID: "importUsers", Summary: "Import users",
+		Description: "Creates the users of a CSV or NDJSON file, with a header naming the fields in CSV.",
+		Permission:  perm(authz.ImportUsers),
+		Parameters: []*Parameter{
+			{Name: "upsert", In: "query", Schema: &Schema{Type: "boolean"}, Description: "Update the users that exist."},
+			{Name: "dry_run", In: "query", Schema: &Schema{Type: "boolean"}, Description: "Only check the file."},
+		},
+		RequestBody: &RequestBody{Required: true, Content: files()},
+		Responses: responses(http.StatusCreated, Response{Description: "The outcome of the import.",
+			Content: data(ref("ImportResult"))}, http.StatusBadRequest, http.StatusUnsupportedMediaType)})
+	d.add("post", "/users:batch", &Operation{OperationID: "batchUsers", Summary: "Create, update and delete users",
+		Description: "Every operation needs the permission of the single request, its result has the status code " +
+			"the single request would have had. Atomic batches are written entirely or not at all.",
+		Permission:  perm(authz.BatchUsers),
+		RequestBody: &RequestBody{Required: true, Content: map[string]MediaType{negotiate.JSON: {Schema: ref("BatchRequest")}}},
+		Responses: responses(http.StatusCreated, Response{Description: "The result of every operation.",
+			Content: data(ref("BatchResponse"))}, http.StatusBadRequest)})
+}
+
+func addAuditRoutes(d *Document) {
+	d.add("get", "/user/{name}/history", &Operation{OperationID: "getUserHistory", Summary: "Get the history of a user",
+		Description: "The audit log of the user, newest first, across renames. The user may read its own history.",
+		Permission:  perm(authz.ReadAudit), Parameters: params("name", "limit", "cursor", "actor", "from", "to"),
+		Responses: responses(http.StatusOK, Response{Description: "A page of the history.", Content: data(ref("AuditPage"))},
+			http.StatusBadRequest, http.StatusNotFound)})
+	d.add("get", "/audit", &Operation{OperationID: "getAuditLog", Summary: "Get the audit log",
+		Description: "The changes of all users, newest first.", Permission: perm(authz.ReadAudit),
+		Parameters: params("limit", "cursor", "actor", "from", "to"),
+		Responses: responses(http.StatusOK, Response{Description: "A page of the audit log.", Content: data(ref("AuditPage"))},
+			http.StatusBadRequest)})
+}
+
+func addV2Routes(d *Document) {
+	d.add("get", "/v2/users", &Operation{OperationID: "listUsersV2", Summary: "List users", Permission: perm(authz.ReadUsers),
+		Parameters: params("limit", "cursor", "sort", "order", "min_age", "max_age", "email_domain", "include_deleted"),
+		Responses: responses(http.StatusOK, Response{Description: "A page of users.", Content: negotiated(ref("UsersPageV2"))},
+			http.StatusBadRequest, http.StatusNotAcceptable)})
+	d.add("post", "/v2/users", &Operation{OperationID: "createUserV2", Summary: "Create a user",
+		Permission: perm(authz.CreateUsers), RequestBody: userV2Body(),
+		Responses: responses(http.StatusCreated, userV2Response("The created user."), http.StatusBadRequest,
+			http.StatusConflict, http.StatusNotAcceptable, http.StatusUnsupportedMediaType)})
+	d.add("get", "/v2/users/{id}", &Operation{OperationID: "getUserV2", Summary: "Get a user",
+		Permission: perm(authz.ReadUsers), Parameters: params("id", "If-None-Match"),
+		Responses: responses(http.StatusOK, userV2Response("The user."), http.StatusNotModified,
+			Response{Description: "The client has the current version."}, http.StatusNotFound, http.StatusNotAcceptable)})
+	d.add("put", "/v2/users/{id}", &Operation{OperationID: "updateUserV2", Summary: "Replace a user",
+		Description: "The user may update itself without the permission.", Permission: perm(authz.UpdateUsers),
+		Parameters: params("id", "If-Match"), RequestBody: userV2Body(),
+		Responses: v2WriteResponses(http.StatusNotAcceptable, http.StatusUnsupportedMediaType)})
+	d.add("patch", "/v2/users/{id}", &Operation{OperationID: "patchUserV2", Summary: "Update fields of a user",
+		Description: "The user may update itself without the permission.", Permission: perm(authz.UpdateUsers),
+		Parameters: params("id", "If-Match"), RequestBody: mergePatch(),
+		Responses: v2WriteResponses(http.StatusNotAcceptable)})
+	d.add("delete", "/v2/users/{id}", &Operation{OperationID: "deleteUserV2", Summary: "Delete a user",
+		Description: "The user is soft-deleted.", Permission: perm(authz.DeleteUsers), Parameters: params("id", "If-Match"),
+		Responses: responses(http.StatusNoContent, Response{Description: "The user was deleted."}, http.StatusNotFound,
+			http.StatusConflict, http.StatusPreconditionFailed, http.StatusPreconditionRequired)})
+}
+
+// addDeprecationHeaders describes the headers of the responses of a deprecated version on the
+// successful responses of op.
+func addDeprecationHeaders(op *Operation) {
+	for code, response := range op.Responses {
+		if response.Ref != "" {
+			continue
+		}
+
+		h := map[string]Header{
+			"Deprecation": {Description: "When version 1 was deprecated, as @ and a Unix time. Sent once it is.",
+				Schema: &Schema{Type: "string"}},
+			"Sunset": {Description: "When version 1 stops being served. Sent once that is decided.",
+				Schema: &Schema{Type: "string"}},
+			"Link": {Description: "The successor-version link to /v2/users, sent with Deprecation.",
+				Schema: &Schema{Type: "string"}},
+		}
+		for name, header := range response.Headers {
+			h[name] = header
+		}
+
+		response.Headers = h
+		op.Responses[code] = response
+	}
+}
+
+// tag groups the operations by the kind of resource they are about.
+func tag(op *Operation) string {
+	switch op.Permission {
+	case perm(authz.ReadAudit):
+		return "audit"
+	case perm(authz.ExportUsers), perm(authz.ImportUsers), perm(authz.BatchUsers), perm(authz.PurgeUsers):
+		return "bulk"
+	default:
+		return "users"
+	}
+}
+
+func perm(p authz.Permission) string {
+	return string(p)
+}
+
+func lastEventID() *Parameter {
+	return &Parameter{Name: "last_event_id", In: "query", Schema: &Schema{Type: "string"},
+		Description: "Id of the last change received, for clients that cannot send Last-Event-ID."}
+}
+
+// params refers to the parameters of the components.
+func params(names ...string) []*Parameter {
+	refs := make([]*Parameter, len(names))
+	for i, name := range names {
+		refs[i] = &Parameter{Ref: "#/components/parameters/" + name}
+	}
+
+	return refs
+}
+
+// responses returns the responses of an operation: alternating status codes and responses for the
+// successful ones, followed by the status codes of the problems it may respond with.
+func responses(code int, response Response, rest ...any) map[string]Response {
+	r := map[string]Response{status(code): response}
+
+	for i := 0; i < len(rest); i++ {
+		code := rest[i].(int)
+
+		if i+1 < len(rest) {
+			if response, ok := rest[i+1].(Response); ok {
+				r[status(code)] = response
+				i++
+
+				continue
+			}
+		}
+
+		r[status(code)] = problemRef(code)
+	}
+
+	return r
+}
+
+// writeResponses are the responses of an update of a user.
+func writeResponses(codes ...int) map[string]Response {
+	r := responses(http.StatusOK, Response{Description: "The user was updated."}, http.StatusBadRequest,
+		http.StatusNotFound, http.StatusConflict, http.StatusPreconditionFailed, http.StatusPreconditionRequired)
+
+	for _, code := range codes {
+		r[status(code)] = problemRef(code)
+	}
+
+	return r
+}
+
+func status(code int) string {
+	return strconv.Itoa(code)
+}
+
+func problemName(code int) string {
+	return strings.ReplaceAll(http.StatusText(code), " ", "")
+}
+
+func problemRef(code int) Response {
+	return Response{Ref: "#/components/responses/" + problemName(code)}
+}
+
+// data is the content of a JSON response holding schema.
+func data(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{negotiate.JSON: {Schema: wrapped(schema)}}
+}
+
+// wrapped is the schema of a response holding schema in its data member.
+func wrapped(schema *Schema) *Schema {
+	return &Schema{Type: "object", Properties: map[string]*Schema{"data": schema}, Required: []string{"data"}}
+}
+
+// negotiated is the content of a response holding schema in the media type of the Accept header.
+func negotiated(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{
+		negotiate.JSON:        {Schema: wrapped(schema)},
+		negotiate.XML:         {Schema: wrapped(schema)},
+		negotiate.MessagePack: {Schema: wrapped(schema)},
+	}
+}
+
+func withCSV(content map[string]MediaType) map[string]MediaType {
+	content[negotiate.CSV] = MediaType{Schema: &Schema{Type: "string", Description: "A header and a line per user."}}
+
+	return content
+}
+
+func files() map[string]MediaType {
+	return map[string]MediaType{
+		"text/csv":             {Schema: &Schema{Type: "string", Description: "A header and a line per user."}},
+		"application/x-ndjson": {Schema: &Schema{Type: "string", Description: "A User per line."}},
+	}
+}
+
+func userResponse() Response {
+	return Response{Description: "The user.",
+		Headers: map[string]Header{"ETag": {Description: "Version of the user.", Schema: &Schema{Type: "string"}}},
+		Content: negotiated(ref("User"))}
+}
+
+func userV2Response(description string) Response {
+	return Response{Description: description,
+		Headers: map[string]Header{"ETag": {Description: "Version of the user.", Schema: &Schema{Type: "string"}}},
+		Content: negotiated(ref("UserV2"))}
+}
+
+// v2WriteResponses are the responses of an update of a user in version 2 of the API.
+func v2WriteResponses(codes ...int) map[string]Response {
+	r := writeResponses(codes...)
+	r[status(http.StatusOK)] = userV2Response("The updated user.")
+
+	return r
+}
+
+func userV2Body() *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]MediaType{
+		negotiate.JSON:        {Schema: ref("UserV2")},
+		negotiate.XML:         {Schema: ref("UserV2")},
+		negotiate.MessagePack: {Schema: ref("UserV2")},
+	}}
+}
+
+func userBody() *RequestBody {
+	return &RequestBody{Required: true, Description: "In CSV a header and a single user.", Content: map[string]MediaType{
+		negotiate.JSON:        {Schema: ref("User")},
+		negotiate.XML:         {Schema: ref("User")},
+		negotiate.CSV:         {Schema: &Schema{Type: "string"}},
+		negotiate.MessagePack: {Schema: ref("User")},
+	}}
+}
+
+func mergePatch() *RequestBody {
+	return &RequestBody{Required: true, Description: "A JSON Merge Patch (RFC 7396) of the user.",
+		Content: map[string]MediaType{
+			"application/merge-patch+json": {Schema: &Schema{Type: "object"}},
+			negotiate.JSON:                 {Schema: &Schema{Type: "object"}},
+		}}
+}
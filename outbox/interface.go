@@ -0,0 +1,12 @@
+package outbox
+
+import (
+	"gofr.dev/pkg/gofr"
+	"gofrProject/entities"
+)
+
+// Store is the outbox of a user store.
+type Store interface {
+	PendingOutbox(limit int, ctx *gofr.Context) ([]entities.OutboxMessage, error)
+	MarkOutboxSent(id int64, ctx *gofr.Context) error
+}
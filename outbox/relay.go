@@ -0,0 +1,158 @@
+// Package outbox publishes the user events the store wrote to its outbox to GoFr pub/sub.
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+	"sync"
+	"time"
+)
+
+// Defaults of the relay.
+const (
+	DefaultBatchSize  = 100
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
+// Metrics of the relay.
+const (
+	MetricLag             = "user_outbox_lag_seconds"
+	MetricPublished       = "user_outbox_published_total"
+	MetricPublishFailures = "user_outbox_publish_failures_total"
+)
+
+// Relay publishes the pending messages of an outbox in the order they were written and marks them
+// as sent. Run is meant to be scheduled as a GoFr cron job.
+//
+// A message is marked as sent only after it was published, so it is published at least once: a
+// crash between the two publishes it again on the next run. When publishing fails the run stops,
+// keeping the order of the messages, and the following runs are skipped for a backoff that doubles
+// with every failed run up to the maximum.
+type Relay struct {
+	store      Store
+	batchSize  int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	now        func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	retryAt  time.Time
+}
+
+// Option configures a Relay.
+type Option func(r *Relay)
+
+// WithBatchSize sets how many messages a run publishes at most.
+func WithBatchSize(size int) Option {
+	return func(r *Relay) {
+		r.batchSize = size
+	}
+}
+
+// WithBackoff sets the time the relay waits after the first failed run and the maximum it waits
+// after repeated failures.
+func WithBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(r *Relay) {
+		r.minBackoff, r.maxBackoff = minBackoff, maxBackoff
+	}
+}
+
+// New returns a relay for the outbox of store.
+func New(store Store, opts ...Option) *Relay {
+	r := &Relay{store: store, batchSize: DefaultBatchSize, minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff, now: time.Now}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// RegisterMetrics registers the metrics the relay records.
+func RegisterMetrics(metrics container.Metrics) {
+	metrics.NewGauge(MetricLag, "Age in seconds of the oldest user event that is not published yet.")
+	metrics.NewCounter(MetricPublished, "Number of user events published from the outbox.")
+	metrics.NewCounter(MetricPublishFailures, "Number of failed attempts to publish a user event from the outbox.")
+}
+
+// Run publishes one batch of pending messages. It returns immediately if the previous run is still
+// in progress or the relay is backing off.
+func (r *Relay) Run(ctx *gofr.Context) {
+	if !r.mu.TryLock() {
+		return
+	}
+	defer r.mu.Unlock()
+
+	if r.now().Before(r.retryAt) {
+		return
+	}
+
+	if err := r.relay(ctx); err != nil {
+		r.failures++
+		backoff := r.backoff()
+		r.retryAt = r.now().Add(backoff)
+
+		ctx.Errorf("unable to relay user events, retrying in %v: %v", backoff, err)
+
+		return
+	}
+
+	r.failures, r.retryAt = 0, time.Time{}
+}
+
+func (r *Relay) relay(ctx *gofr.Context) error {
+	messages, err := r.store.PendingOutbox(r.batchSize, ctx)
+	if err != nil {
+		return err
+	}
+
+	lag := 0.0
+	if len(messages) > 0 {
+		lag = r.now().Sub(messages[0].CreatedAt).Seconds()
+	}
+
+	ctx.Metrics().SetGauge(MetricLag, lag)
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	publisher := ctx.GetPublisher()
+	if publisher == nil {
+		return errNoPublisher
+	}
+
+	for _, message := range messages {
+		if err := publisher.Publish(ctx, message.Topic, message.Payload); err != nil {
+			ctx.Metrics().IncrementCounter(ctx, MetricPublishFailures)
+
+			return fmt.Errorf("publishing outbox message %d: %w", message.ID, err)
+		}
+
+		if err := r.store.MarkOutboxSent(message.ID, ctx); err != nil {
+			return fmt.Errorf("marking outbox message %d as sent: %w", message.ID, err)
+		}
+
+		ctx.Metrics().IncrementCounter(ctx, MetricPublished)
+	}
+
+	return nil
+}
+
+// backoff returns the time to wait after the current number of consecutive failed runs.
+func (r *Relay) backoff() time.Duration {
+	backoff := r.minBackoff
+
+	for i := 1; i < r.failures && backoff < r.maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, r.maxBackoff)
+}
+
+var errNoPublisher = errors.New("no pub/sub publisher is configured")
@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+)
+
+func TestRelay_Run(t *testing.T) {
+	now := time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC)
+	messages := []entities.OutboxMessage{
+		{ID: 1, Topic: "users", Payload: []byte(`{"type":"user.created"}`), CreatedAt: now.Add(-90 * time.Second)},
+		{ID: 2, Topic: "users", Payload: []byte(`{"type":"user.updated"}`), CreatedAt: now.Add(-30 * time.Second)},
+	}
+
+	tests := []struct {
+		name       string
+		mockExpect func(mockStore *MockStore, mocks *container.Mocks)
+	}{
+		{
+			name: "pending messages are published in order",
+			mockExpect: func(mockStore *MockStore, mocks *container.Mocks) {
+				mockStore.EXPECT().PendingOutbox(DefaultBatchSize, gomock.Any()).Return(messages, nil)
+				mocks.Metrics.EXPECT().SetGauge(MetricLag, 90.0)
+				gomock.InOrder(
+					mocks.PubSub.EXPECT().Publish(gomock.Any(), "users", messages[0].Payload).Return(nil),
+					mockStore.EXPECT().MarkOutboxSent(int64(1), gomock.Any()).Return(nil),
+					mocks.PubSub.EXPECT().Publish(gomock.Any(), "users", messages[1].Payload).Return(nil),
+					mockStore.EXPECT().MarkOutboxSent(int64(2), gomock.Any()).Return(nil),
+				)
+				mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), MetricPublished).Times(2)
+			},
+		},
+		{
+			name: "empty outbox",
+			mockExpect: func(mockStore *MockStore, mocks *container.Mocks) {
+				mockStore.EXPECT().PendingOutbox(DefaultBatchSize, gomock.Any()).Return(nil, nil)
+				mocks.Metrics.EXPECT().SetGauge(MetricLag, 0.0)
+			},
+		},
+		{
+			name: "publishing stops at the first failure",
+			mockExpect: func(mockStore *MockStore, mocks *container.Mocks) {
+				mockStore.EXPECT().PendingOutbox(DefaultBatchSize, gomock.Any()).Return(messages, nil)
+				mocks.Metrics.EXPECT().SetGauge(MetricLag, 90.0)
+				mocks.PubSub.EXPECT().Publish(gomock.Any(), "users", messages[0].Payload).
+					Return(errors.New("broker unavailable"))
+				mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), MetricPublishFailures)
+			},
+		},
+		{
+			name: "outbox unavailable",
+			mockExpect: func(mockStore *MockStore, _ *container.Mocks) {
+				mockStore.EXPECT().PendingOutbox(DefaultBatchSize, gomock.Any()).Return(nil, apperrors.Unavailable(nil))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockContainer, mocks := container.NewMockContainer(t)
+			ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+
+			mockStore := NewMockStore(gomock.NewController(t))
+			relay := New(mockStore)
+			relay.now = func() time.Time { return now }
+
+			tt.mockExpect(mockStore, mocks)
+
+			relay.Run(ctx)
+		})
+	}
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	mockContainer, mocks := container.NewMockContainer(t)
+	ctx := &gofr.Context{Context: context.Background(), Container: mockContainer}
+
+	now := time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC)
+	message := entities.OutboxMessage{ID: 1, Topic: "users", Payload: []byte(`{}`), CreatedAt: now}
+
+	mockStore := NewMockStore(gomock.NewController(t))
+	relay := New(mockStore, WithBackoff(time.Second, 3*time.Second))
+	relay.now = func() time.Time { return now }
+
+	mockStore.EXPECT().PendingOutbox(DefaultBatchSize, gomock.Any()).Return([]entities.OutboxMessage{message}, nil).
+		AnyTimes()
+	mocks.Metrics.EXPECT().SetGauge(MetricLag, gomock.Any()).AnyTimes()
+	mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), MetricPublishFailures).Times(3)
+	mocks.PubSub.EXPECT().Publish(gomock.Any(), "users", message.Payload).
+		Return(errors.New("broker unavailable")).Times(3)
+
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		relay.Run(ctx)
+		assert.Equal(t, now.Add(expected), relay.retryAt)
+
+		relay.Run(ctx)
+		assert.Equal(t, now.Add(expected), relay.retryAt, "runs are skipped while backing off")
+
+		now = now.Add(expected)
+	}
+
+	mocks.PubSub.EXPECT().Publish(gomock.Any(), "users", message.Payload).Return(nil)
+	mockStore.EXPECT().MarkOutboxSent(int64(1), gomock.Any()).Return(nil)
+	mocks.Metrics.EXPECT().IncrementCounter(gomock.Any(), MetricPublished)
+
+	relay.Run(ctx)
+	assert.Zero(t, relay.failures)
+	assert.True(t, relay.retryAt.IsZero())
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	mockMetrics := container.NewMockMetrics(gomock.NewController(t))
+
+	mockMetrics.EXPECT().NewGauge(MetricLag, gomock.Any())
+	mockMetrics.EXPECT().NewCounter(MetricPublished, gomock.Any())
+	mockMetrics.EXPECT().NewCounter(MetricPublishFailures, gomock.Any())
+
+	RegisterMetrics(mockMetrics)
+}
@@ -0,0 +1,107 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gofr.dev/pkg/gofr/http/response"
+
+	"gofrProject/apperrors"
+	"gofrProject/validation"
+)
+
+// ContentType is the media type of problem details documents.
+const ContentType = "application/problem+json"
+
+var kindStatus = map[apperrors.Kind]int{
+	apperrors.KindNotFound:      http.StatusNotFound,
+	apperrors.KindAlreadyExists: http.StatusConflict,
+	apperrors.KindValidation:    http.StatusBadRequest,
+	apperrors.KindConflict:      http.StatusConflict,
+	apperrors.KindUnavailable:   http.StatusServiceUnavailable,
+	apperrors.KindAborted:       http.StatusFailedDependency,
+
+	apperrors.KindNotAcceptable:        http.StatusNotAcceptable,
+	apperrors.KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+
+	apperrors.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	apperrors.KindPreconditionRequired: http.StatusPreconditionRequired,
+}
+
+// Details is an RFC 7807 problem details object. Code and Errors are extension members
+// carrying the domain error kind and the invalid fields of a validation error.
+type Details struct {
+	Type   string                  `json:"type"`
+	Title  string                  `json:"title"`
+	Status int                     `json:"status"`
+	Detail string                  `json:"detail,omitempty"`
+	Code   string                  `json:"code,omitempty"`
+	Errors []validation.FieldError `json:"errors,omitempty"`
+
+	err error
+}
+
+// Error returns the message of the underlying error, which may be more detailed than Detail.
+func (d *Details) Error() string {
+	return d.err.Error()
+}
+
+func (d *Details) Unwrap() error {
+	return d.err
+}
+
+// StatusCode is used by GoFr as the status of the response.
+func (d *Details) StatusCode() int {
+	return d.Status
+}
+
+// New describes err as problem details. Domain errors are mapped by their kind, errors
+// carrying their own status code keep it, and anything else is an internal error. The message
+// of an error is not disclosed unless it is a domain error or carries a client error status:
+// the server errors of GoFr, e.g. datasource.ErrorDB, hold the messages of the drivers.
+func New(err error) *Details {
+	d := &Details{Type: "about:blank", Status: http.StatusInternalServerError, Detail: "internal server error", err: err}
+
+	var statusErr interface{ StatusCode() int }
+
+	if kind := apperrors.KindOf(err); kind != "" {
+		d.Status, d.Code, d.Detail = kindStatus[kind], string(kind), err.Error()
+	} else if errors.As(err, &statusErr) {
+		d.Status = statusErr.StatusCode()
+
+		if d.Status < http.StatusInternalServerError {
+			d.Detail = err.Error()
+		}
+	}
+
+	var fieldErrs validation.Errors
+	if errors.As(err, &fieldErrs) {
+		d.Status, d.Code, d.Detail, d.Errors = http.StatusBadRequest, string(apperrors.KindValidation),
+			"request is invalid", fieldErrs
+	}
+
+	d.Title = http.StatusText(d.Status)
+
+	return d
+}
+
+// Respond returns the handler result for err: a problem+json body together with an error
+// GoFr uses to pick the status code.
+func Respond(err error) (any, error) {
+	d := New(err)
+
+	body, _ := json.Marshal(d)
+
+	return response.File{Content: body, ContentType: ContentType}, d
+}
+
+// Write renders err as problem details on a plain net/http response, for middlewares
+// running outside of GoFr handlers.
+func Write(w http.ResponseWriter, err error) {
+	d := New(err)
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(d.Status)
+	_ = json.NewEncoder(w).Encode(d)
+}
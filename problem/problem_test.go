@@ -0,0 +1,131 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gofr.dev/pkg/gofr/datasource"
+	gofrHttp "gofr.dev/pkg/gofr/http"
+	"gofr.dev/pkg/gofr/http/response"
+
+	"gofrProject/apperrors"
+	"gofrProject/validation"
+)
+
+func TestNew(t *testing.T) {
+	fieldErrs := validation.Errors{{Field: "email", Code: validation.CodeRequired, Message: "email is required"}}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected *Details
+	}{
+		{
+			name: "not found",
+			err:  apperrors.NotFound("user", "name", "waheed"),
+			expected: &Details{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound,
+				Detail: "user with name 'waheed' not found", Code: "not_found"},
+		},
+		{
+			name: "already exists",
+			err:  apperrors.AlreadyExists("user", "name", "waheed"),
+			expected: &Details{Type: "about:blank", Title: "Conflict", Status: http.StatusConflict,
+				Detail: "user with name 'waheed' already exists", Code: "already_exists"},
+		},
+		{
+			name: "conflict",
+			err:  apperrors.Conflict("user was modified concurrently", nil),
+			expected: &Details{Type: "about:blank", Title: "Conflict", Status: http.StatusConflict,
+				Detail: "user was modified concurrently", Code: "conflict"},
+		},
+		{
+			name: "precondition failed",
+			err:  apperrors.PreconditionFailed("user", "name", "waheed"),
+			expected: &Details{Type: "about:blank", Title: "Precondition Failed", Status: http.StatusPreconditionFailed,
+				Detail: "user with name 'waheed' has been modified", Code: "precondition_failed"},
+		},
+		{
+			name: "unavailable",
+			err:  fmt.Errorf("reading user: %w", apperrors.Unavailable(errors.New("connection refused"))),
+			expected: &Details{Type: "about:blank", Title: "Service Unavailable", Status: http.StatusServiceUnavailable,
+				Detail: "reading user: service temporarily unavailable", Code: "unavailable"},
+		},
+		{
+			name: "aborted",
+			err:  apperrors.Aborted("another operation of the batch failed"),
+			expected: &Details{Type: "about:blank", Title: "Failed Dependency", Status: http.StatusFailedDependency,
+				Detail: "another operation of the batch failed", Code: "aborted"},
+		},
+		{
+			name: "not acceptable",
+			err:  apperrors.NotAcceptable("Accept must allow application/json"),
+			expected: &Details{Type: "about:blank", Title: "Not Acceptable", Status: http.StatusNotAcceptable,
+				Detail: "Accept must allow application/json", Code: "not_acceptable"},
+		},
+		{
+			name: "unsupported media type",
+			err:  apperrors.UnsupportedMediaType("Content-Type must be text/csv"),
+			expected: &Details{Type: "about:blank", Title: "Unsupported Media Type", Status: http.StatusUnsupportedMediaType,
+				Detail: "Content-Type must be text/csv", Code: "unsupported_media_type"},
+		},
+		{
+			name: "validation with field errors",
+			err:  apperrors.Validation(fieldErrs),
+			expected: &Details{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: "request is invalid", Code: "validation", Errors: fieldErrs},
+		},
+		{
+			name: "error with status code",
+			err:  gofrHttp.ErrorInvalidParam{Params: []string{"limit"}},
+			expected: &Details{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: gofrHttp.ErrorInvalidParam{Params: []string{"limit"}}.Error()},
+		},
+		{
+			name: "error with a server error status code is not disclosed",
+			err:  datasource.ErrorDB{Err: errors.New("Error 1054: Unknown column 'emial'"), Message: "error from sql db"},
+			expected: &Details{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError,
+				Detail: "internal server error"},
+		},
+		{
+			name: "internal error is not disclosed",
+			err:  errors.New("dial tcp 10.0.0.1:3306: connection refused"),
+			expected: &Details{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError,
+				Detail: "internal server error"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expected.err = tt.err
+
+			d := New(tt.err)
+
+			assert.Equal(t, tt.expected, d, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expected.Status, d.StatusCode(), "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.err, errors.Unwrap(d), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestRespond(t *testing.T) {
+	res, err := Respond(apperrors.NotFound("user", "name", "waheed"))
+
+	assert.Equal(t, response.File{ContentType: ContentType, Content: []byte(`{"type":"about:blank","title":"Not Found",` +
+		`"status":404,"detail":"user with name 'waheed' not found","code":"not_found"}`)}, res)
+	assert.EqualError(t, err, "user with name 'waheed' not found")
+}
+
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	Write(w, apperrors.Unavailable(errors.New("connection refused")))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Service Unavailable","status":503,`+
+		`"detail":"service temporarily unavailable","code":"unavailable"}`, w.Body.String())
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gofrProject/openapi"
+)
+
+// permissions returns the values of the permission constants of package authz, by name.
+func permissions(t *testing.T) map[string]string {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "authz/authz.go", nil, 0)
+	require.NoError(t, err)
+
+	values := make(map[string]string)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || len(spec.Values) != len(spec.Names) {
+			return true
+		}
+
+		for i, name := range spec.Names {
+			if lit, ok := spec.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				values[name.Name], _ = strconv.Unquote(lit.Value)
+			}
+		}
+
+		return true
+	})
+
+	return values
+}
+
+// routeMethods are the methods of gofr.App registering routes.
+var routeMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "WebSocket": true}
+
+// registeredRoutes returns the routes registered in main.go, keyed to the permission they require.
+// WebSocket routes are upgraded from GET requests.
+func registeredRoutes(t *testing.T) map[openapi.Route]string {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", nil, 0)
+	require.NoError(t, err)
+
+	values := permissions(t)
+	routes := make(map[openapi.Route]string)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return true
+		}
+
+		register, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !routeMethods[register.Sel.Name] {
+			return true
+		}
+
+		if app, ok := register.X.(*ast.Ident); !ok || app.Name != "a" {
+			return true
+		}
+
+		method := strings.ToLower(register.Sel.Name)
+		if method == "websocket" {
+			method = "get"
+		}
+
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		require.True(t, ok, "the path of a route is not a literal")
+
+		path, err := strconv.Unquote(lit.Value)
+		require.NoError(t, err)
+
+		var permission string
+
+		if policy, ok := call.Args[1].(*ast.CallExpr); ok && len(policy.Args) > 0 {
+			if name, ok := policy.Args[0].(*ast.SelectorExpr); ok {
+				permission = values[name.Sel.Name]
+			}
+		}
+
+		routes[openapi.Route{Method: method, Path: path}] = permission
+
+		return true
+	})
+
+	return routes
+}
+
+func TestRoutes_OpenAPI(t *testing.T) {
+	registered := registeredRoutes(t)
+	require.NotEmpty(t, registered)
+
+	assert.Equal(t, registered, openapi.New().Routes(),
+		"the routes of main.go and openapi.New differ, describe the changed routes in package openapi")
+}
@@ -0,0 +1,16 @@
+package rpc
+
+import (
+	"gofr.dev/pkg/gofr"
+	"gofrProject/entities"
+)
+
+type UserService interface {
+	GetUsers(query entities.UsersQuery, ctx *gofr.Context) (entities.UsersPage, error)
+	GetUsersByName(name string, ctx *gofr.Context) (entities.Users, error)
+	GetUsersByID(id string, ctx *gofr.Context) (entities.Users, error)
+	AddUsers(user *entities.Users, ctx *gofr.Context) error
+	UpdateUsers(name string, version int, updateUser *entities.Users, ctx *gofr.Context) error
+	DeleteUsers(name string, version int, ctx *gofr.Context) error
+	ExportUsers(query entities.UsersQuery, fn func(user entities.Users) error, ctx *gofr.Context) error
+}
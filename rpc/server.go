@@ -0,0 +1,299 @@
+// Package rpc serves the users over gRPC, see package userpb. Like package handler does for HTTP, it
+// translates requests for service.Service and its errors for the transport, so both transports share
+// the validation and the errors of the service.
+package rpc
+
+import (
+	"context"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+	"gofrProject/apperrors"
+	"gofrProject/auth"
+	"gofrProject/authz"
+	"gofrProject/entities"
+	"gofrProject/headers"
+	"gofrProject/userpb"
+	"gofrProject/validation"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net/http"
+	"strings"
+)
+
+// Server implements userpb.UserServiceServer on top of a UserService.
+type Server struct {
+	userpb.UnimplementedUserServiceServer
+
+	// Container is injected by GoFr when the server is registered with App.RegisterService, it is
+	// the container of the gofr.Context the service is called with.
+	Container *container.Container
+
+	service        UserService
+	authenticator  auth.Authenticator
+	authorize      func(ctx *gofr.Context, permission authz.Permission) error
+	requireVersion bool
+}
+
+// Option configures a Server.
+type Option func(s *Server)
+
+// Authenticate makes every call identify its caller with authenticator, from the credentials in its
+// metadata under the names of the HTTP headers that carry them, and pass authorize for the permission
+// of the matching HTTP route.
+func Authenticate(authenticator auth.Authenticator, authorize func(ctx *gofr.Context, permission authz.Permission) error) Option {
+	return func(s *Server) {
+		s.authenticator, s.authorize = authenticator, authorize
+	}
+}
+
+// RequireVersion makes updates and deletes that do not carry the version of the user fail, like
+// handler.RequireIfMatch does for HTTP.
+func RequireVersion(required bool) Option {
+	return func(s *Server) {
+		s.requireVersion = required
+	}
+}
+
+func NewServer(userService UserService, opts ...Option) *Server {
+	s := &Server{service: userService}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// context returns the gofr.Context of a call after authorizing it for permission. The metadata of
+// the call are made available as the request headers, see package headers. A caller updating its own
+// user is authorized like the Owner rule of the HTTP API does.
+func (s *Server) context(ctx context.Context, permission authz.Permission, owner string) (*gofr.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	header := make(http.Header, len(md))
+	for key, values := range md {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	ctx = headers.With(ctx, header, http.Header{})
+
+	if s.authenticator != nil {
+		principal, err := s.authenticator.Authenticate(&http.Request{Header: header})
+		if err != nil {
+			return nil, authz.ErrUnauthenticated{}
+		}
+
+		ctx = auth.WithPrincipal(ctx, principal)
+	}
+
+	gofrCtx := &gofr.Context{Context: ctx, Container: s.Container}
+
+	if s.authorize == nil {
+		return gofrCtx, nil
+	}
+
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && owner != "" && strings.EqualFold(principal.Name, owner) {
+		return gofrCtx, nil
+	}
+
+	if err := s.authorize(gofrCtx, permission); err != nil {
+		return nil, err
+	}
+
+	return gofrCtx, nil
+}
+
+// readContext is context for a read, which needs ReadDeletedUsers too if it includes deleted users.
+func (s *Server) readContext(ctx context.Context, permission authz.Permission, filter *userpb.UsersFilter) (*gofr.Context, error) {
+	gofrCtx, err := s.context(ctx, permission, "")
+	if err != nil || !filter.GetIncludeDeleted() || s.authorize == nil {
+		return gofrCtx, err
+	}
+
+	if err := s.authorize(gofrCtx, authz.ReadDeletedUsers); err != nil {
+		return nil, err
+	}
+
+	return gofrCtx, nil
+}
+
+// GetUser returns the user with the name or id of the request.
+func (s *Server) GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.User, error) {
+	gofrCtx, err := s.context(ctx, authz.ReadUsers, "")
+	if err != nil {
+		return nil, Status(err).Err()
+	}
+
+	var user entities.Users
+
+	switch key := req.GetKey().(type) {
+	case *userpb.GetUserRequest_UserName:
+		user, err = s.service.GetUsersByName(key.UserName, gofrCtx)
+	case *userpb.GetUserRequest_Id:
+		user, err = s.service.GetUsersByID(key.Id, gofrCtx)
+	default:
+		err = apperrors.Validation(validation.Errors{{Field: "key", Code: validation.CodeRequired,
+			Message: "user_name or id is required"}})
+	}
+
+	if err != nil {
+		return nil, Status(err).Err()
+	}
+
+	return toProto(user), nil
+}
+
+// ListUsers returns one page of the users matching the filter of the request.
+func (s *Server) ListUsers(ctx context.Context, req *userpb.ListUsersRequest) (*userpb.ListUsersResponse, error) {
+	gofrCtx, err := s.readContext(ctx, authz.ReadUsers, req.GetFilter())
+	if err != nil {
+		return nil, Status(err).Err()
+	}
+
+	query := usersQuery(req.GetFilter())
+	query.Limit = int(req.GetLimit())
+
+	if req.GetCursor() != "" {
+		after, err := entities.DecodeUserCursor(req.GetCursor())
+		if err != nil {
+			return nil, Status(apperrors.Validation(validation.Errors{{Field: "cursor", Code: validation.CodeInvalidValue,
+				Message: "cursor is not valid"}})).Err()
+		}
+
+		query.After = &after
+	}
+
+	page, err := s.service.GetUsers(query, gofrCtx)
+	if err != nil {
+		return nil, Status(err).Err()
+	}
+
+	resp := &userpb.ListUsersResponse{Users: make([]*userpb.User, len(page.Users)), NextCursor: page.NextCursor,
+		TotalCount: int32(page.TotalCount)}
+	for i, user := range page.Users {
+		resp.Users[i] = toProto(user)
+	}
+
+	return resp, nil
+}
+
+// StreamUsers sends every user matching the filter of the request, like an export of the HTTP API.
+func (s *Server) StreamUsers(req *userpb.StreamUsersRequest, stream userpb.UserService_StreamUsersServer) error {
+	gofrCtx, err := s.readContext(stream.Context(), authz.ExportUsers, req.GetFilter())
+	if err != nil {
+		return Status(err).Err()
+	}
+
+	err = s.service.ExportUsers(usersQuery(req.GetFilter()), func(user entities.Users) error {
+		return stream.Send(toProto(user))
+	}, gofrCtx)
+	if err != nil {
+		return Status(err).Err()
+	}
+
+	return nil
+}
+
+// CreateUser creates the user of the request.
+func (s *Server) CreateUser(ctx context.Context, req *userpb.CreateUserRequest) (*emptypb.Empty, error) {
+	gofrCtx, err := s.context(ctx, authz.CreateUsers, "")
+	if err != nil {
+		return nil, Status(err).Err()
+	}
+
+	user := fromProto(req.GetUser())
+
+	if err := s.service.AddUsers(&user, gofrCtx); err != nil {
+		return nil, Status(err).Err()
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// UpdateUser replaces the fields of the user named in the request.
+func (s *Server) UpdateUser(ctx context.Context, req *userpb.UpdateUserRequest) (*emptypb.Empty, error) {
+	gofrCtx, err := s.context(ctx, authz.UpdateUsers, req.GetUserName())
+	if err != nil {
+		return nil, Status(err).Err()
+	}
+
+	if err := s.checkVersion(req.GetVersion()); err != nil {
+		return nil, Status(err).Err()
+	}
+
+	user := fromProto(req.GetUser())
+
+	if err := s.service.UpdateUsers(req.GetUserName(), int(req.GetVersion()), &user, gofrCtx); err != nil {
+		return nil, Status(err).Err()
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteUser soft-deletes the user named in the request.
+func (s *Server) DeleteUser(ctx context.Context, req *userpb.DeleteUserRequest) (*emptypb.Empty, error) {
+	gofrCtx, err := s.context(ctx, authz.DeleteUsers, "")
+	if err != nil {
+		return nil, Status(err).Err()
+	}
+
+	if err := s.checkVersion(req.GetVersion()); err != nil {
+		return nil, Status(err).Err()
+	}
+
+	if err := s.service.DeleteUsers(req.GetUserName(), int(req.GetVersion()), gofrCtx); err != nil {
+		return nil, Status(err).Err()
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) checkVersion(version int64) error {
+	if version == 0 && s.requireVersion {
+		return apperrors.PreconditionRequired("version is required")
+	}
+
+	return nil
+}
+
+// usersQuery returns the query of the users selected by filter.
+func usersQuery(filter *userpb.UsersFilter) entities.UsersQuery {
+	query := entities.UsersQuery{SortBy: filter.GetSort(), Descending: filter.GetDescending(),
+		EmailDomain: filter.GetEmailDomain(), IncludeDeleted: filter.GetIncludeDeleted()}
+
+	if filter == nil {
+		return query
+	}
+
+	if filter.MinAge != nil {
+		minAge := int(filter.GetMinAge())
+		query.MinAge = &minAge
+	}
+
+	if filter.MaxAge != nil {
+		maxAge := int(filter.GetMaxAge())
+		query.MaxAge = &maxAge
+	}
+
+	return query
+}
+
+func toProto(user entities.Users) *userpb.User {
+	u := &userpb.User{Id: user.ID, UserName: user.UserName, UserAge: int32(user.UserAge), PhoneNumber: user.PhoneNumber,
+		Email: user.Email, Version: int64(user.Version)}
+
+	if user.DeletedAt != nil {
+		u.DeletedAt = timestamppb.New(*user.DeletedAt)
+	}
+
+	return u
+}
+
+// fromProto returns the user of a request. The fields set by the server are left to the service.
+func fromProto(user *userpb.User) entities.Users {
+	return entities.Users{ID: user.GetId(), UserName: user.GetUserName(), UserAge: int(user.GetUserAge()),
+		PhoneNumber: user.GetPhoneNumber(), Email: user.GetEmail()}
+}
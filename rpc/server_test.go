@@ -0,0 +1,332 @@
+package rpc_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"gofrProject/apperrors"
+	"gofrProject/auth"
+	"gofrProject/authz"
+	"gofrProject/entities"
+	"gofrProject/rpc"
+	"gofrProject/userpb"
+	"gofrProject/validation"
+)
+
+const testUserID = "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f"
+
+type mockConfig map[string]string
+
+func (c mockConfig) Get(key string) string {
+	return c[key]
+}
+
+func keyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// newClient serves server on an in-process listener and returns a client of it.
+func newClient(t *testing.T, server *rpc.Server) userpb.UserServiceClient {
+	listener := bufconn.Listen(1 << 20)
+
+	s := grpc.NewServer()
+	userpb.RegisterUserServiceServer(s, server)
+
+	go func() { _ = s.Serve(listener) }()
+
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return userpb.NewUserServiceClient(conn)
+}
+
+func newServer(t *testing.T, opts ...rpc.Option) (userpb.UserServiceClient, *rpc.MockUserService) {
+	mockService := rpc.NewMockUserService(gomock.NewController(t))
+	mockContainer, _ := container.NewMockContainer(t)
+
+	server := rpc.NewServer(mockService, opts...)
+	server.Container = mockContainer
+
+	return newClient(t, server), mockService
+}
+
+func Test_GetUser(t *testing.T) {
+	client, mockService := newServer(t)
+
+	deletedAt := time.Date(2025, 1, 12, 12, 0, 0, 0, time.UTC)
+	user := entities.Users{ID: testUserID, UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "waheed@example.com", DeletedAt: &deletedAt, Version: 3}
+	expected := &userpb.User{Id: testUserID, UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "waheed@example.com", DeletedAt: timestamppb.New(deletedAt), Version: 3}
+
+	tests := []struct {
+		name         string
+		request      *userpb.GetUserRequest
+		mockExpect   func()
+		expectedUser *userpb.User
+		expectedCode codes.Code
+	}{
+		{
+			name:    "by name",
+			request: &userpb.GetUserRequest{Key: &userpb.GetUserRequest_UserName{UserName: "waheed"}},
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByName("waheed", gomock.Any()).Return(user, nil)
+			},
+			expectedUser: expected,
+			expectedCode: codes.OK,
+		},
+		{
+			name:    "by id",
+			request: &userpb.GetUserRequest{Key: &userpb.GetUserRequest_Id{Id: testUserID}},
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+			},
+			expectedUser: expected,
+			expectedCode: codes.OK,
+		},
+		{
+			name:    "not found",
+			request: &userpb.GetUserRequest{Key: &userpb.GetUserRequest_UserName{UserName: "adam"}},
+			mockExpect: func() {
+				mockService.EXPECT().GetUsersByName("adam", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "name", "adam"))
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "no key",
+			request:      &userpb.GetUserRequest{},
+			mockExpect:   func() {},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			resp, err := client.GetUser(context.Background(), tt.request)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err), "TEST[%d] failed: %s", i, tt.name)
+			assert.True(t, proto.Equal(tt.expectedUser, resp), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_ListUsers(t *testing.T) {
+	client, mockService := newServer(t)
+
+	minAge := 18
+	cursor := entities.UserCursor{UserName: "adam"}
+	page := entities.UsersPage{Users: []entities.Users{{ID: testUserID, UserName: "waheed", UserAge: 19}},
+		NextCursor: "next", TotalCount: 2}
+
+	mockService.EXPECT().GetUsers(entities.UsersQuery{Limit: 1, After: &cursor, SortBy: entities.SortByUserAge,
+		Descending: true, MinAge: &minAge}, gomock.Any()).Return(page, nil)
+
+	resp, err := client.ListUsers(context.Background(), &userpb.ListUsersRequest{
+		Filter: &userpb.UsersFilter{Sort: entities.SortByUserAge, Descending: true, MinAge: proto.Int32(18)},
+		Limit:  1, Cursor: cursor.Encode()})
+
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&userpb.ListUsersResponse{Users: []*userpb.User{{Id: testUserID, UserName: "waheed", UserAge: 19}},
+		NextCursor: "next", TotalCount: 2}, resp))
+
+	_, err = client.ListUsers(context.Background(), &userpb.ListUsersRequest{Cursor: "not a cursor"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_StreamUsers(t *testing.T) {
+	client, mockService := newServer(t)
+
+	mockService.EXPECT().ExportUsers(entities.UsersQuery{EmailDomain: "example.com"}, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ entities.UsersQuery, fn func(entities.Users) error, _ *gofr.Context) error {
+			for _, name := range []string{"adam", "waheed"} {
+				if err := fn(entities.Users{UserName: name}); err != nil {
+					return err
+				}
+			}
+
+			return apperrors.Unavailable(errors.New("connection refused"))
+		})
+
+	stream, err := client.StreamUsers(context.Background(),
+		&userpb.StreamUsersRequest{Filter: &userpb.UsersFilter{EmailDomain: "example.com"}})
+	require.NoError(t, err)
+
+	var names []string
+
+	for {
+		user, err := stream.Recv()
+		if err != nil {
+			assert.NotErrorIs(t, err, io.EOF)
+			assert.Equal(t, codes.Unavailable, status.Code(err), "error after the users read")
+
+			break
+		}
+
+		names = append(names, user.GetUserName())
+	}
+
+	assert.Equal(t, []string{"adam", "waheed"}, names)
+}
+
+func Test_Writes(t *testing.T) {
+	client, mockService := newServer(t, rpc.RequireVersion(true))
+
+	user := &userpb.User{UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671", Email: "waheed@example.com",
+		Id: testUserID, Version: 7}
+	invalid := apperrors.Validation(validation.Errors{{Field: "email", Code: validation.CodeRequired,
+		Message: "email is required"}})
+
+	mockService.EXPECT().AddUsers(&entities.Users{ID: testUserID, UserName: "waheed", UserAge: 19,
+		PhoneNumber: "+14155552671", Email: "waheed@example.com"}, gomock.Any()).Return(nil)
+	_, err := client.CreateUser(context.Background(), &userpb.CreateUserRequest{User: user})
+	assert.NoError(t, err, "create")
+
+	mockService.EXPECT().AddUsers(gomock.Any(), gomock.Any()).Return(invalid)
+	_, err = client.CreateUser(context.Background(), &userpb.CreateUserRequest{User: &userpb.User{UserName: "waheed"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "invalid user")
+	require.Len(t, status.Convert(err).Details(), 1)
+	assert.True(t, proto.Equal(&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+		{Field: "email", Description: "email is required"}}}, status.Convert(err).Details()[0].(proto.Message)))
+
+	mockService.EXPECT().UpdateUsers("waheed", 3, gomock.Any(), gomock.Any()).
+		Return(apperrors.PreconditionFailed("user", "name", "waheed"))
+	_, err = client.UpdateUser(context.Background(), &userpb.UpdateUserRequest{UserName: "waheed", User: user, Version: 3})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "update of a modified user")
+
+	_, err = client.UpdateUser(context.Background(), &userpb.UpdateUserRequest{UserName: "waheed", User: user})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "update without version")
+
+	mockService.EXPECT().DeleteUsers("waheed", 3, gomock.Any()).Return(nil)
+	_, err = client.DeleteUser(context.Background(), &userpb.DeleteUserRequest{UserName: "waheed", Version: 3})
+	assert.NoError(t, err, "delete")
+}
+
+func Test_Authorization(t *testing.T) {
+	authenticator, err := auth.NewAPIKeyAuthenticator("alice:"+keyHash("alice-key")+",carol:"+keyHash("carol-key"), time.Now)
+	require.NoError(t, err)
+
+	policy, err := authz.NewPolicy(mockConfig{"AUTHZ_ROLES": "alice:admin,carol:reader"})
+	require.NoError(t, err)
+
+	client, mockService := newServer(t, rpc.Authenticate(authenticator, policy.Authorize))
+
+	withKey := func(key string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+	}
+
+	tests := []struct {
+		name         string
+		call         func() error
+		mockExpect   func()
+		expectedCode codes.Code
+	}{
+		{
+			name: "no credentials",
+			call: func() error {
+				_, err := client.DeleteUser(context.Background(), &userpb.DeleteUserRequest{UserName: "waheed"})
+				return err
+			},
+			mockExpect:   func() {},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name: "missing permission",
+			call: func() error {
+				_, err := client.DeleteUser(withKey("carol-key"), &userpb.DeleteUserRequest{UserName: "waheed"})
+				return err
+			},
+			mockExpect:   func() {},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name: "reader listing deleted users",
+			call: func() error {
+				_, err := client.ListUsers(withKey("carol-key"),
+					&userpb.ListUsersRequest{Filter: &userpb.UsersFilter{IncludeDeleted: true}})
+				return err
+			},
+			mockExpect:   func() {},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name: "owner updating itself",
+			call: func() error {
+				_, err := client.UpdateUser(withKey("carol-key"), &userpb.UpdateUserRequest{UserName: "carol",
+					User: &userpb.User{UserName: "carol"}})
+				return err
+			},
+			mockExpect: func() {
+				mockService.EXPECT().UpdateUsers("carol", 0, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ string, _ int, _ *entities.Users, ctx *gofr.Context) error {
+						principal, _ := auth.PrincipalFromContext(ctx)
+						assert.Equal(t, "carol", principal.Name)
+
+						return nil
+					})
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "owner updating itself named in another case",
+			call: func() error {
+				_, err := client.UpdateUser(withKey("carol-key"), &userpb.UpdateUserRequest{UserName: "Carol",
+					User: &userpb.User{UserName: "Carol"}})
+				return err
+			},
+			mockExpect: func() {
+				mockService.EXPECT().UpdateUsers("Carol", 0, gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "admin",
+			call: func() error {
+				_, err := client.DeleteUser(withKey("alice-key"), &userpb.DeleteUserRequest{UserName: "waheed"})
+				return err
+			},
+			mockExpect: func() {
+				mockService.EXPECT().DeleteUsers("waheed", 0, gomock.Any()).Return(nil)
+			},
+			expectedCode: codes.OK,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			assert.Equal(t, tt.expectedCode, status.Code(tt.call()), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
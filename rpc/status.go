@@ -0,0 +1,72 @@
+package rpc
+
+import (
+	"errors"
+	"gofrProject/apperrors"
+	"gofrProject/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+var kindCode = map[apperrors.Kind]codes.Code{
+	apperrors.KindNotFound:      codes.NotFound,
+	apperrors.KindAlreadyExists: codes.AlreadyExists,
+	apperrors.KindValidation:    codes.InvalidArgument,
+	apperrors.KindConflict:      codes.Aborted,
+	apperrors.KindUnavailable:   codes.Unavailable,
+	apperrors.KindAborted:       codes.Aborted,
+
+	apperrors.KindNotAcceptable:        codes.InvalidArgument,
+	apperrors.KindUnsupportedMediaType: codes.InvalidArgument,
+
+	apperrors.KindPreconditionFailed:   codes.FailedPrecondition,
+	apperrors.KindPreconditionRequired: codes.FailedPrecondition,
+}
+
+// statusCode are the codes of the errors carrying an HTTP status code, such as those of package authz.
+var statusCode = map[int]codes.Code{
+	http.StatusBadRequest:         codes.InvalidArgument,
+	http.StatusUnauthorized:       codes.Unauthenticated,
+	http.StatusForbidden:          codes.PermissionDenied,
+	http.StatusNotFound:           codes.NotFound,
+	http.StatusConflict:           codes.Aborted,
+	http.StatusTooManyRequests:    codes.ResourceExhausted,
+	http.StatusServiceUnavailable: codes.Unavailable,
+}
+
+// Status describes err as a gRPC status, the way problem.New describes it for HTTP. Domain errors are
+// mapped by their kind, errors carrying an HTTP status code by that code, and anything else is an
+// internal error whose message is not disclosed. Validation errors carry a BadRequest detail naming
+// the invalid fields.
+func Status(err error) *status.Status {
+	code, message := codes.Internal, "internal server error"
+
+	var statusErr interface{ StatusCode() int }
+
+	if kind := apperrors.KindOf(err); kind != "" {
+		code, message = kindCode[kind], err.Error()
+	} else if errors.As(err, &statusErr) {
+		if c, ok := statusCode[statusErr.StatusCode()]; ok {
+			code, message = c, err.Error()
+		}
+	}
+
+	var fieldErrs validation.Errors
+	if !errors.As(err, &fieldErrs) {
+		return status.New(code, message)
+	}
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(fieldErrs))
+	for i, fieldErr := range fieldErrs {
+		violations[i] = &errdetails.BadRequest_FieldViolation{Field: fieldErr.Field, Description: fieldErr.Message}
+	}
+
+	s := status.New(codes.InvalidArgument, "request is invalid")
+	if detailed, err := s.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		s = detailed
+	}
+
+	return s
+}
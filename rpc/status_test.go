@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	"gofrProject/apperrors"
+	"gofrProject/authz"
+	"gofrProject/validation"
+)
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{name: "not found", err: apperrors.NotFound("user", "name", "adam"), expectedCode: codes.NotFound,
+			expectedMessage: apperrors.NotFound("user", "name", "adam").Error()},
+		{name: "already exists", err: apperrors.AlreadyExists("user", "name", "adam"), expectedCode: codes.AlreadyExists,
+			expectedMessage: apperrors.AlreadyExists("user", "name", "adam").Error()},
+		{name: "conflict", err: apperrors.Conflict("user was renamed", nil), expectedCode: codes.Aborted,
+			expectedMessage: "user was renamed"},
+		{name: "modified user", err: apperrors.PreconditionFailed("user", "name", "adam"), expectedCode: codes.FailedPrecondition,
+			expectedMessage: apperrors.PreconditionFailed("user", "name", "adam").Error()},
+		{name: "missing version", err: apperrors.PreconditionRequired("version is required"),
+			expectedCode: codes.FailedPrecondition, expectedMessage: "version is required"},
+		{name: "unavailable store", err: apperrors.Unavailable(errors.New("connection refused")), expectedCode: codes.Unavailable,
+			expectedMessage: apperrors.Unavailable(errors.New("connection refused")).Error()},
+		{name: "unauthenticated", err: authz.ErrUnauthenticated{}, expectedCode: codes.Unauthenticated,
+			expectedMessage: authz.ErrUnauthenticated{}.Error()},
+		{name: "forbidden", err: authz.ErrForbidden{Principal: "carol", Permission: authz.DeleteUsers},
+			expectedCode: codes.PermissionDenied, expectedMessage: "principal 'carol' lacks permission 'users:delete'"},
+		{name: "unknown error", err: errors.New("sql: connection is already closed"), expectedCode: codes.Internal,
+			expectedMessage: "internal server error"},
+	}
+
+	for i, tt := range tests {
+		s := Status(tt.err)
+
+		assert.Equal(t, tt.expectedCode, s.Code(), "TEST[%d] failed: %s", i, tt.name)
+		assert.Equal(t, tt.expectedMessage, s.Message(), "TEST[%d] failed: %s", i, tt.name)
+		assert.Empty(t, s.Details(), "TEST[%d] failed: %s", i, tt.name)
+	}
+}
+
+func TestStatus_Validation(t *testing.T) {
+	s := Status(apperrors.Validation(validation.Errors{
+		{Field: "userName", Code: validation.CodeRequired, Message: "userName is required"},
+		{Field: "userAge", Code: validation.CodeOutOfRange, Message: "userAge must be between 1 and 150"},
+	}))
+
+	assert.Equal(t, codes.InvalidArgument, s.Code())
+	assert.Equal(t, "request is invalid", s.Message())
+	assert.Len(t, s.Details(), 1)
+	assert.True(t, proto.Equal(&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+		{Field: "userName", Description: "userName is required"},
+		{Field: "userAge", Description: "userAge must be between 1 and 150"},
+	}}, s.Details()[0].(proto.Message)))
+}
@@ -0,0 +1,473 @@
+// Package search keeps an inverted index of the users of a store in process, so that users can be
+// searched by name, email and phone number whatever SQL dialect the store speaks.
+package search
+
+import (
+	"cmp"
+	"gofrProject/entities"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Fields of a user that are indexed.
+const (
+	fieldName = iota
+	fieldEmail
+	fieldPhone
+	fieldCount
+)
+
+// Weights of a word matching a term of a field exactly and as a prefix, and of a word matching a
+// term of the user name up to a typo.
+var (
+	exactWeights  = [fieldCount]float64{fieldName: 10, fieldEmail: 6, fieldPhone: 6}
+	prefixWeights = [fieldCount]float64{fieldName: 6, fieldEmail: 4, fieldPhone: 4}
+)
+
+const fuzzyWeight = 5
+
+// minPhoneSuffix is the length of the shortest trailing part of a phone number that is indexed, so
+// that numbers can be found without their country code.
+const minPhoneSuffix = 4
+
+// Index is an inverted index of users. Users are matched by the words of their name and email and by
+// the digits of their phone number, see Search. It is safe for concurrent use.
+type Index struct {
+	mu    sync.RWMutex
+	state *state
+	built bool
+
+	// next is the state being filled by Rebuild, removed the ids of the users removed meanwhile.
+	next    *state
+	removed map[string]bool
+
+	rebuilding sync.Mutex
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{state: newState()}
+}
+
+// Put adds user to the index or replaces the indexed version of it. Deleted users are removed instead,
+// versions older than the one indexed are ignored.
+func (x *Index) Put(user entities.Users) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.state.put(user)
+
+	if x.next != nil {
+		delete(x.removed, user.ID)
+		x.next.put(user)
+	}
+}
+
+// Remove removes the user with the given id from the index.
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.state.remove(id)
+
+	if x.next != nil {
+		x.removed[id] = true
+		x.next.remove(id)
+	}
+}
+
+// RemoveName removes the user with the given name from the index. Names are matched regardless of case.
+func (x *Index) RemoveName(name string) {
+	x.mu.RLock()
+	id, ok := x.state.names[strings.ToLower(name)]
+	x.mu.RUnlock()
+
+	if ok {
+		x.Remove(id)
+	}
+}
+
+// Built reports whether the index was filled by Rebuild.
+func (x *Index) Built() bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return x.built
+}
+
+// Rebuild replaces the users of the index with the users fill puts into it and returns how many it
+// put. Until fill returns the index keeps serving the users it had, the writes made to it meanwhile
+// are applied to the new users too, so that a user fill read before it was written is not indexed
+// stale. Rebuilds run one at a time, if fill fails the index is left as it was.
+func (x *Index) Rebuild(fill func(put func(user entities.Users)) error) (int, error) {
+	x.rebuilding.Lock()
+	defer x.rebuilding.Unlock()
+
+	x.mu.Lock()
+	x.next, x.removed = newState(), make(map[string]bool)
+	x.mu.Unlock()
+
+	indexed := 0
+
+	err := fill(func(user entities.Users) {
+		x.mu.Lock()
+		defer x.mu.Unlock()
+
+		if !x.removed[user.ID] {
+			x.next.put(user)
+			indexed++
+		}
+	})
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err == nil {
+		x.state, x.built = x.next, true
+	}
+
+	x.next, x.removed = nil, nil
+
+	return indexed, err
+}
+
+// Search returns the users matching every word of text, at most limit of them, best match first.
+// A word matches a user if it is a word of the name or email or a prefix of one, or if it differs
+// from a word of the name by a typo or two; a word of digits matches the phone number by its
+// beginning or its last digits. Exact matches rank above prefixes, prefixes above typos, and the
+// name ranks above email and phone number. Users matching equally well are ordered by name.
+func (x *Index) Search(text string, limit int) entities.SearchPage {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	results := x.state.search(queryWords(text))
+
+	slices.SortFunc(results, func(a, b entities.SearchResult) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(strings.ToLower(a.User.UserName), strings.ToLower(b.User.UserName))
+	})
+
+	page := entities.SearchPage{Results: results, TotalCount: len(results)}
+	if len(page.Results) > limit {
+		page.Results = page.Results[:limit]
+	}
+
+	return page
+}
+
+// state is the content of an index: the indexed users and the postings of their terms.
+type state struct {
+	docs   map[string]document
+	names  map[string]string
+	fields [fieldCount]postings
+}
+
+// document is an indexed user with the terms it was indexed under.
+type document struct {
+	user  entities.Users
+	terms [fieldCount][]string
+}
+
+// postings holds the ids of the users indexed under every term of a field. The terms are also kept
+// sorted, so that the terms starting with a prefix are found without looking at the others.
+type postings struct {
+	ids    map[string]map[string]bool
+	sorted []string
+}
+
+func newState() *state {
+	s := &state{docs: make(map[string]document), names: make(map[string]string)}
+
+	for f := range s.fields {
+		s.fields[f].ids = make(map[string]map[string]bool)
+	}
+
+	return s
+}
+
+func (s *state) put(user entities.Users) {
+	if user.DeletedAt != nil {
+		s.remove(user.ID)
+		return
+	}
+
+	if doc, ok := s.docs[user.ID]; ok && doc.user.Version > user.Version {
+		return
+	}
+
+	s.remove(user.ID)
+
+	doc := document{user: user, terms: [fieldCount][]string{
+		fieldName:  words(user.UserName),
+		fieldEmail: emailTerms(user.Email),
+		fieldPhone: phoneTerms(user.PhoneNumber),
+	}}
+
+	for f, terms := range doc.terms {
+		for _, term := range terms {
+			s.fields[f].add(term, user.ID)
+		}
+	}
+
+	s.docs[user.ID] = doc
+	s.names[strings.ToLower(user.UserName)] = user.ID
+}
+
+func (s *state) remove(id string) {
+	doc, ok := s.docs[id]
+	if !ok {
+		return
+	}
+
+	for f, terms := range doc.terms {
+		for _, term := range terms {
+			s.fields[f].remove(term, id)
+		}
+	}
+
+	delete(s.docs, id)
+	delete(s.names, strings.ToLower(doc.user.UserName))
+}
+
+func (p *postings) add(term, id string) {
+	ids, ok := p.ids[term]
+	if !ok {
+		ids = make(map[string]bool)
+		p.ids[term] = ids
+
+		i, _ := slices.BinarySearch(p.sorted, term)
+		p.sorted = slices.Insert(p.sorted, i, term)
+	}
+
+	ids[id] = true
+}
+
+func (p *postings) remove(term, id string) {
+	ids := p.ids[term]
+	delete(ids, id)
+
+	if len(ids) == 0 {
+		delete(p.ids, term)
+
+		if i, found := slices.BinarySearch(p.sorted, term); found {
+			p.sorted = slices.Delete(p.sorted, i, i+1)
+		}
+	}
+}
+
+// search returns the users matching every word of query with their score, in no particular order.
+func (s *state) search(query []string) []entities.SearchResult {
+	if len(query) == 0 {
+		return []entities.SearchResult{}
+	}
+
+	var scores map[string]float64
+
+	for _, word := range query {
+		wordScores := s.match(word)
+
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+
+		for id, score := range scores {
+			if wordScore, ok := wordScores[id]; ok {
+				scores[id] = score + wordScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	results := make([]entities.SearchResult, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, entities.SearchResult{User: s.docs[id].user, Score: math.Round(score*100) / 100})
+	}
+
+	return results
+}
+
+// match returns the users word matches with the score of the best of their terms it matches.
+func (s *state) match(word string) map[string]float64 {
+	scores := make(map[string]float64)
+
+	add := func(ids map[string]bool, score float64) {
+		for id := range ids {
+			scores[id] = max(scores[id], score)
+		}
+	}
+
+	for f := range s.fields {
+		p := &s.fields[f]
+		term := word
+
+		if f == fieldPhone {
+			if term = digits(word); term == "" {
+				continue
+			}
+		}
+
+		for i, _ := slices.BinarySearch(p.sorted, term); i < len(p.sorted) && strings.HasPrefix(p.sorted[i], term); i++ {
+			if p.sorted[i] == term {
+				add(p.ids[term], exactWeights[f])
+				continue
+			}
+
+			// The more of the term the prefix covers, the better it matches.
+			coverage := float64(len(term)) / float64(len(p.sorted[i]))
+			add(p.ids[p.sorted[i]], prefixWeights[f]*(1+coverage)/2)
+		}
+	}
+
+	if distance := maxTypos(word); distance > 0 {
+		for term, ids := range s.fields[fieldName].ids {
+			if d := editDistance(word, term, distance); d > 0 && d <= distance {
+				add(ids, fuzzyWeight/float64(1+d))
+			}
+		}
+	}
+
+	return scores
+}
+
+// maxTypos returns how many typos a word may have to match a name, none for short words as they
+// would match too many names.
+func maxTypos(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions of
+// adjacent characters that turn a into b, or limit+1 if it is more than limit.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > limit {
+		return limit + 1
+	}
+
+	// rows[2] is the current row of the distance matrix, rows[1] and rows[0] the two before it.
+	rows := [3][]int{make([]int, len(rb)+1), make([]int, len(rb)+1), make([]int, len(rb)+1)}
+	for j := range rows[2] {
+		rows[2][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		rows[0], rows[1], rows[2] = rows[1], rows[2], rows[0]
+		rows[2][0] = i
+		best := i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d := min(rows[1][j]+1, rows[2][j-1]+1, rows[1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d = min(d, rows[0][j-2]+1)
+			}
+
+			rows[2][j] = d
+			best = min(best, d)
+		}
+
+		if best > limit {
+			return limit + 1
+		}
+	}
+
+	return rows[2][len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// queryWords returns the words of a search. A search that only looks like a phone number is a
+// single word, however its digits are grouped.
+func queryWords(text string) []string {
+	text = strings.ToLower(strings.TrimSpace(text))
+
+	if strings.IndexFunc(text, func(r rune) bool { return !strings.ContainsRune("0123456789+-(). ", r) }) < 0 {
+		if d := digits(text); d != "" {
+			return []string{d}
+		}
+	}
+
+	return strings.Fields(text)
+}
+
+// words returns the lower-cased term of a name and the words it consists of.
+func words(s string) []string {
+	s = strings.ToLower(s)
+	if s == "" {
+		return nil
+	}
+
+	terms := []string{s}
+
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if !slices.Contains(terms, word) {
+			terms = append(terms, word)
+		}
+	}
+
+	return terms
+}
+
+// emailTerms returns the terms of an email: the address, its local part and domain, and their words.
+func emailTerms(email string) []string {
+	terms := words(email)
+
+	if local, domain, ok := strings.Cut(strings.ToLower(email), "@"); ok {
+		for _, term := range []string{local, domain} {
+			if term != "" && !slices.Contains(terms, term) {
+				terms = append(terms, term)
+			}
+		}
+	}
+
+	return terms
+}
+
+// phoneTerms returns the terms of a phone number: its digits and their trailing parts of at least
+// minPhoneSuffix digits.
+func phoneTerms(phone string) []string {
+	d := digits(phone)
+
+	var terms []string
+	for i := 0; i == 0 || i <= len(d)-minPhoneSuffix; i++ {
+		if d[i:] != "" {
+			terms = append(terms, d[i:])
+		}
+	}
+
+	return terms
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+
+		return -1
+	}, s)
+}
@@ -0,0 +1,168 @@
+package search
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gofrProject/entities"
+)
+
+func testUsers() []entities.Users {
+	return []entities.Users{
+		{ID: "1", UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671", Email: "waheed@example.com", Version: 1},
+		{ID: "2", UserName: "john_doe", UserAge: 40, PhoneNumber: "+442071838750", Email: "jd@waheed.dev", Version: 1},
+		{ID: "3", UserName: "waheeb", UserAge: 31, PhoneNumber: "+14155550000", Email: "waheeb@example.org", Version: 1},
+		{ID: "4", UserName: "alexander", UserAge: 25, PhoneNumber: "+33142685300", Email: "alex@example.fr", Version: 1},
+	}
+}
+
+func names(page entities.SearchPage) []string {
+	names := make([]string, len(page.Results))
+	for i, result := range page.Results {
+		names[i] = result.User.UserName
+	}
+
+	return names
+}
+
+func TestIndex_Search(t *testing.T) {
+	index := NewIndex()
+	for _, user := range testUsers() {
+		index.Put(user)
+	}
+
+	tests := []struct {
+		name          string
+		text          string
+		expectedNames []string
+	}{
+		{name: "exact name ranks above email and typo", text: "waheed", expectedNames: []string{"waheed", "john_doe", "waheeb"}},
+		{name: "case is ignored", text: "WAHEED", expectedNames: []string{"waheed", "john_doe", "waheeb"}},
+		{name: "prefix of a name", text: "alex", expectedNames: []string{"alexander"}},
+		{name: "word of a name", text: "doe", expectedNames: []string{"john_doe"}},
+		{name: "transposed letters", text: "alexnader", expectedNames: []string{"alexander"}},
+		{name: "short words match without typos only", text: "wab", expectedNames: []string{}},
+		{name: "email domain", text: "example.org", expectedNames: []string{"waheeb"}},
+		{name: "every word has to match", text: "john waheed", expectedNames: []string{"john_doe"}},
+		{name: "phone number with country code", text: "+1 415 555", expectedNames: []string{"waheeb", "waheed"}},
+		{name: "last digits of a phone number", text: "5552671", expectedNames: []string{"waheed"}},
+		{name: "no match", text: "zebra", expectedNames: []string{}},
+		{name: "no words", text: "  ", expectedNames: []string{}},
+	}
+
+	for i, tt := range tests {
+		page := index.Search(tt.text, 10)
+
+		assert.Equal(t, tt.expectedNames, names(page), "TEST[%d] failed: %s", i, tt.name)
+		assert.Equal(t, len(tt.expectedNames), page.TotalCount, "TEST[%d] failed: %s", i, tt.name)
+	}
+}
+
+func TestIndex_SearchLimit(t *testing.T) {
+	index := NewIndex()
+	for _, user := range testUsers() {
+		index.Put(user)
+	}
+
+	page := index.Search("waheed", 1)
+
+	assert.Equal(t, entities.SearchPage{Results: []entities.SearchResult{{User: testUsers()[0], Score: 10}}, TotalCount: 3}, page)
+}
+
+func TestIndex_Writes(t *testing.T) {
+	users := testUsers()
+	deletedAt := time.Date(2025, 1, 12, 12, 0, 0, 0, time.UTC)
+
+	index := NewIndex()
+	for _, user := range users {
+		index.Put(user)
+	}
+
+	renamed := users[0]
+	renamed.UserName, renamed.Email, renamed.Version = "walter", "walter@example.com", 2
+	index.Put(renamed)
+
+	stale := users[0]
+	index.Put(stale)
+
+	assert.Equal(t, []string{"john_doe", "waheeb"}, names(index.Search("waheed", 10)), "old name is not found")
+	assert.Equal(t, []string{"walter"}, names(index.Search("walter", 10)), "stale version is ignored")
+
+	index.RemoveName("JOHN_DOE")
+	assert.Equal(t, []string{"waheeb"}, names(index.Search("waheed", 10)))
+
+	deleted := users[2]
+	deleted.DeletedAt, deleted.Version = &deletedAt, 2
+	index.Put(deleted)
+	assert.Equal(t, []string{}, names(index.Search("waheed", 10)))
+}
+
+func TestIndex_Rebuild(t *testing.T) {
+	users := testUsers()
+
+	index := NewIndex()
+	index.Put(users[3])
+
+	indexed, err := index.Rebuild(func(put func(entities.Users)) error {
+		// Writes made while the index is rebuilt win over the users read before them.
+		updated := users[0]
+		updated.UserName, updated.Email, updated.Version = "walter", "walter@example.com", 2
+		index.Put(updated)
+		index.Remove(users[1].ID)
+
+		for _, user := range users[:3] {
+			put(user)
+		}
+
+		assert.Equal(t, []string{"alexander"}, names(index.Search("alex", 10)), "old content is searched")
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, indexed)
+	assert.True(t, index.Built())
+	assert.Equal(t, []string{"walter"}, names(index.Search("walter", 10)))
+	assert.Equal(t, []string{"waheeb"}, names(index.Search("waheed", 10)))
+	assert.Equal(t, []string{}, names(index.Search("alex", 10)))
+}
+
+func TestIndex_RebuildFailure(t *testing.T) {
+	users := testUsers()
+
+	index := NewIndex()
+	index.Put(users[3])
+
+	_, err := index.Rebuild(func(put func(entities.Users)) error {
+		put(users[0])
+
+		return errors.New("connection refused")
+	})
+
+	assert.EqualError(t, err, "connection refused")
+	assert.False(t, index.Built())
+	assert.Equal(t, []string{"alexander"}, names(index.Search("alex", 10)))
+	assert.Equal(t, []string{}, names(index.Search("waheed", 10)))
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		limit    int
+		expected int
+	}{
+		{a: "waheed", b: "waheed", limit: 2, expected: 0},
+		{a: "waheed", b: "wahed", limit: 2, expected: 1},
+		{a: "waheed", b: "awheed", limit: 2, expected: 1},
+		{a: "waheed", b: "wahid", limit: 2, expected: 2},
+		{a: "waheed", b: "john", limit: 2, expected: 3},
+		{a: "josé", b: "jose", limit: 1, expected: 1},
+	}
+
+	for i, tt := range tests {
+		assert.Equal(t, tt.expected, editDistance(tt.a, tt.b, tt.limit), "TEST[%d] failed: %s %s", i, tt.a, tt.b)
+	}
+}
@@ -0,0 +1,153 @@
+package search
+
+import (
+	"gofr.dev/pkg/gofr"
+	"gofrProject/entities"
+	"gofrProject/service"
+	"sync"
+)
+
+// Users keeps an Index of the users of a service.UserStore. Writes go to the store, and once they
+// succeed the users they changed are read back from it and indexed, so the index works the same on
+// every store. A failure to read them back is logged and leaves the index stale until it is rebuilt,
+// the index never fails a write.
+//
+// The index only sees the writes made through Users. It is filled from the store by the first search
+// or by RebuildIndex, which also picks up the writes of other processes.
+type Users struct {
+	service.UserStore
+
+	index *Index
+	build sync.Mutex
+}
+
+// New returns an index of the users of store.
+func New(store service.UserStore) *Users {
+	return &Users{UserStore: store, index: NewIndex()}
+}
+
+// SearchUsers returns the users matching query.Text, best match first, see Index.Search. The index is
+// built from the store if this is the first search.
+func (u *Users) SearchUsers(query entities.SearchQuery, ctx *gofr.Context) (entities.SearchPage, error) {
+	if err := u.ensureBuilt(ctx); err != nil {
+		return entities.SearchPage{}, err
+	}
+
+	return u.index.Search(query.Text, query.Limit), nil
+}
+
+// ensureBuilt builds the index unless it was built already. Concurrent first searches share a build.
+func (u *Users) ensureBuilt(ctx *gofr.Context) error {
+	u.build.Lock()
+	defer u.build.Unlock()
+
+	if u.index.Built() {
+		return nil
+	}
+
+	_, err := u.RebuildIndex(ctx)
+
+	return err
+}
+
+// RebuildIndex replaces the content of the index with the users of the store that are not deleted
+// and returns how many it indexed. Searches are served from the old content until it is done.
+func (u *Users) RebuildIndex(ctx *gofr.Context) (int, error) {
+	return u.index.Rebuild(func(put func(user entities.Users)) error {
+		return u.UserStore.StreamUsers(entities.UsersQuery{SortBy: entities.SortByUserName}, func(user entities.Users) error {
+			put(user)
+			return nil
+		}, ctx)
+	})
+}
+
+// AddUsers adds the user and indexes it.
+func (u *Users) AddUsers(user *entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	if err := u.UserStore.AddUsers(user, change, ctx); err != nil {
+		return err
+	}
+
+	u.refresh(ctx, user.UserName)
+
+	return nil
+}
+
+// UpdateUsers updates the user and indexes its new values.
+func (u *Users) UpdateUsers(updateUser *entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	if err := u.UserStore.UpdateUsers(updateUser, change, ctx); err != nil {
+		return err
+	}
+
+	u.refresh(ctx, updateUser.UserName)
+
+	return nil
+}
+
+// DeleteUsers deletes the user and removes it from the index.
+func (u *Users) DeleteUsers(user entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	if err := u.UserStore.DeleteUsers(user, change, ctx); err != nil {
+		return err
+	}
+
+	u.index.RemoveName(user.UserName)
+
+	return nil
+}
+
+// RestoreUsers restores the user and indexes it again.
+func (u *Users) RestoreUsers(user entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	if err := u.UserStore.RestoreUsers(user, change, ctx); err != nil {
+		return err
+	}
+
+	u.refresh(ctx, user.UserName)
+
+	return nil
+}
+
+// RenameUsers renames the user and indexes it under its new name.
+func (u *Users) RenameUsers(id, name string, version int, change *entities.Change, ctx *gofr.Context) error {
+	if err := u.UserStore.RenameUsers(id, name, version, change, ctx); err != nil {
+		return err
+	}
+
+	u.refresh(ctx, name)
+
+	return nil
+}
+
+// WriteBatch applies the writes and updates the index with the writes that succeeded.
+func (u *Users) WriteBatch(writes []entities.BatchWrite, atomic bool, ctx *gofr.Context) []error {
+	errs := u.UserStore.WriteBatch(writes, atomic, ctx)
+
+	names := make([]string, 0, len(writes))
+
+	for i := range writes {
+		switch {
+		case errs[i] != nil:
+		case writes[i].Op == entities.BatchDelete:
+			u.index.RemoveName(writes[i].User.UserName)
+		default:
+			names = append(names, writes[i].User.UserName)
+		}
+	}
+
+	if len(names) > 0 {
+		u.refresh(ctx, names...)
+	}
+
+	return errs
+}
+
+// refresh reads the users named names from the store and indexes them as they are now.
+func (u *Users) refresh(ctx *gofr.Context, names ...string) {
+	users, err := u.UserStore.GetUsersByNames(names, ctx)
+	if err != nil {
+		ctx.Errorf("refreshing search index of %v: %v", names, err)
+		return
+	}
+
+	for _, user := range users {
+		u.index.Put(user)
+	}
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+
+	"gofrProject/entities"
+	"gofrProject/service"
+	"gofrProject/store"
+)
+
+func newTestContext(t *testing.T) *gofr.Context {
+	mockContainer, _ := container.NewMockContainer(t)
+
+	return &gofr.Context{Context: context.Background(), Container: mockContainer}
+}
+
+func search(t *testing.T, u *Users, text string, ctx *gofr.Context) []string {
+	page, err := u.SearchUsers(entities.SearchQuery{Text: text, Limit: 10}, ctx)
+	require.NoError(t, err)
+
+	return names(page)
+}
+
+func TestUsers_Writes(t *testing.T) {
+	ctx := newTestContext(t)
+	memory := store.NewMemory()
+	u := New(memory)
+
+	users := testUsers()
+	for i := range users[:2] {
+		require.NoError(t, memory.AddUsers(&users[i], nil, ctx))
+	}
+
+	assert.Equal(t, []string{"waheed", "john_doe"}, search(t, u, "waheed", ctx), "first search builds the index")
+
+	require.NoError(t, u.AddUsers(&users[2], nil, ctx))
+	assert.Equal(t, []string{"waheeb"}, search(t, u, "example.org", ctx), "added user")
+
+	update := users[1]
+	update.Email = "jd@example.com"
+	require.NoError(t, u.UpdateUsers(&update, nil, ctx))
+	assert.Equal(t, []string{"waheed", "waheeb"}, search(t, u, "waheed", ctx), "updated user")
+
+	require.NoError(t, u.RenameUsers(users[0].ID, "walter", 1, nil, ctx))
+	assert.Equal(t, []string{"walter"}, search(t, u, "walter", ctx), "renamed user")
+
+	require.NoError(t, u.DeleteUsers(entities.Users{ID: users[0].ID, UserName: "walter", Version: 2}, nil, ctx))
+	assert.Equal(t, []string{}, search(t, u, "walter", ctx), "deleted user")
+
+	require.NoError(t, u.RestoreUsers(entities.Users{ID: users[0].ID, UserName: "walter", Version: 3}, nil, ctx))
+	assert.Equal(t, []string{"walter"}, search(t, u, "walter", ctx), "restored user")
+
+	errs := u.WriteBatch([]entities.BatchWrite{
+		{Op: entities.BatchCreate, User: users[3]},
+		{Op: entities.BatchDelete, User: entities.Users{ID: users[2].ID, UserName: "waheeb", Version: 1}},
+		{Op: entities.BatchDelete, User: entities.Users{ID: users[1].ID, UserName: "john_doe", Version: 1}},
+	}, false, ctx)
+
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Error(t, errs[2], "john_doe is at version 2")
+	assert.Equal(t, []string{"alexander"}, search(t, u, "alex", ctx), "user created by a batch")
+	assert.Equal(t, []string{"john_doe"}, search(t, u, "john", ctx), "user a batch failed to delete")
+	assert.Equal(t, []string{}, search(t, u, "example.org", ctx), "user deleted by a batch")
+}
+
+func TestUsers_StoreErrors(t *testing.T) {
+	ctx := newTestContext(t)
+	mockStore := service.NewMockUserStore(gomock.NewController(t))
+	u := New(mockStore)
+	user := testUsers()[0]
+
+	mockStore.EXPECT().StreamUsers(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+
+	_, err := u.SearchUsers(entities.SearchQuery{Text: "waheed", Limit: 10}, ctx)
+	assert.EqualError(t, err, "connection refused", "index cannot be built")
+
+	mockStore.EXPECT().AddUsers(&user, nil, gomock.Any()).Return(errors.New("connection refused"))
+	assert.EqualError(t, u.AddUsers(&user, nil, ctx), "connection refused", "write fails")
+
+	mockStore.EXPECT().AddUsers(&user, nil, gomock.Any()).Return(nil)
+	mockStore.EXPECT().GetUsersByNames([]string{"waheed"}, gomock.Any()).Return(nil, errors.New("connection refused"))
+	assert.NoError(t, u.AddUsers(&user, nil, ctx), "write succeeds when the user cannot be indexed")
+
+	mockStore.EXPECT().StreamUsers(entities.UsersQuery{SortBy: entities.SortByUserName}, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ entities.UsersQuery, fn func(entities.Users) error, _ *gofr.Context) error {
+			return fn(user)
+		})
+
+	indexed, err := u.RebuildIndex(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, indexed)
+	assert.Equal(t, []string{"waheed"}, search(t, u, "waheed", ctx), "rebuild repairs the index")
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"fmt"
+	"gofr.dev/pkg/gofr"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"gofrProject/validation"
+	"strings"
+)
+
+// DefaultMaxBatchSize is how many operations a batch request may hold unless WithMaxBatchSize says otherwise.
+const DefaultMaxBatchSize = 10000
+
+// WithMaxBatchSize sets how many operations a batch request may hold.
+func WithMaxBatchSize(size int) Option {
+	return func(s *Service) {
+		s.maxBatchSize = size
+	}
+}
+
+// Batch creates, updates and deletes users as requested. The operations are checked like the
+// requests for a single user, but the users they name are read with one query and the store
+// applies all writes together, inserting the created users with multi-row statements. A user may
+// only be named by one operation of the batch.
+//
+// The response has a result for every operation. If the request is atomic, nothing is written
+// unless every operation succeeds, the operations that did not fail themselves are aborted.
+func (s *Service) Batch(request entities.BatchRequest, ctx *gofr.Context) (entities.BatchResponse, error) {
+	if n := len(request.Operations); n == 0 || n > s.maxBatchSize {
+		return entities.BatchResponse{}, apperrors.Validation(validation.Errors{{Field: "operations",
+			Code: validation.CodeOutOfRange, Message: fmt.Sprintf("operations must hold 1 to %d operations", s.maxBatchSize)}})
+	}
+
+	names := make([]string, 0, len(request.Operations))
+	for _, op := range request.Operations {
+		if name := operationName(op); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	existing, err := s.existingUsers(names, ctx)
+	if err != nil {
+		return entities.BatchResponse{}, err
+	}
+
+	results := make([]entities.BatchResult, len(request.Operations))
+	writes := make([]entities.BatchWrite, 0, len(request.Operations))
+	indexes := make([]int, 0, len(request.Operations))
+	named := make(map[string]int, len(request.Operations))
+
+	for i, op := range request.Operations {
+		results[i] = entities.BatchResult{Index: i, Op: op.Op}
+
+		var write entities.BatchWrite
+
+		err := nameOnce(named, operationName(op), i, "operation")
+		if err == nil {
+			write, err = s.batchWrite(op, existing, ctx)
+		}
+
+		if err != nil {
+			results[i].Error = err
+			continue
+		}
+
+		writes = append(writes, write)
+		indexes = append(indexes, i)
+	}
+
+	if request.Atomic && len(writes) < len(results) {
+		for _, i := range indexes {
+			results[i].Error = apperrors.Aborted("another operation of the batch failed")
+		}
+
+		writes = nil
+	}
+
+	if len(writes) > 0 {
+		errs := s.store.WriteBatch(writes, request.Atomic, ctx)
+		s.notifyWrites(writes, errs)
+
+		for j, i := range indexes {
+			results[i].User, results[i].Error = batchOutcome(&writes[j], request.Operations[i], errs[j])
+		}
+	}
+
+	response := entities.BatchResponse{Atomic: request.Atomic, Results: results}
+
+	for i := range results {
+		if results[i].Error != nil {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+	}
+
+	return response, nil
+}
+
+// existingUsers reads the users with the given names, keyed by their lower-cased name as names
+// are unique regardless of case.
+func (s *Service) existingUsers(names []string, ctx *gofr.Context) (map[string]entities.Users, error) {
+	users, err := s.store.GetUsersByNames(names, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]entities.Users, len(users))
+	for _, user := range users {
+		existing[strings.ToLower(user.UserName)] = user
+	}
+
+	return existing, nil
+}
+
+// operationName returns the name of the user op operates on, "" if it names none.
+func operationName(op entities.BatchOperation) string {
+	if op.Op == entities.BatchCreate && op.User != nil {
+		return op.User.UserName
+	}
+
+	if op.Op != entities.BatchCreate {
+		return op.UserName
+	}
+
+	return ""
+}
+
+// batchWrite checks op against the existing users and returns the write the store applies for it.
+func (s *Service) batchWrite(op entities.BatchOperation, existing map[string]entities.Users,
+	ctx *gofr.Context) (entities.BatchWrite, error) {
+	if op.Op != entities.BatchCreate && op.Op != entities.BatchUpdate && op.Op != entities.BatchDelete {
+		return entities.BatchWrite{}, apperrors.Validation(validation.Errors{{Field: "op",
+			Code: validation.CodeInvalidValue, Message: "op must be create, update or delete"}})
+	}
+
+	if op.Op != entities.BatchDelete && op.User == nil {
+		return entities.BatchWrite{}, apperrors.Validation(validation.Errors{{Field: "user",
+			Code: validation.CodeRequired, Message: "user is required"}})
+	}
+
+	if op.Op == entities.BatchCreate {
+		return s.batchCreate(*op.User, existing, ctx)
+	}
+
+	if op.UserName == "" {
+		return entities.BatchWrite{}, apperrors.Validation(validation.Errors{{Field: "user_name",
+			Code: validation.CodeRequired, Message: "user_name is required"}})
+	}
+
+	if op.Op == entities.BatchUpdate {
+		return s.batchUpdate(op, *op.User, existing, ctx)
+	}
+
+	return s.batchDelete(op, existing, ctx)
+}
+
+func (s *Service) batchCreate(user entities.Users, existing map[string]entities.Users,
+	ctx *gofr.Context) (entities.BatchWrite, error) {
+	if err := validation.User(&user); err != nil {
+		return entities.BatchWrite{}, apperrors.Validation(err)
+	}
+
+	if _, ok := existing[strings.ToLower(user.UserName)]; ok {
+		return entities.BatchWrite{}, apperrors.AlreadyExists("user", "name", user.UserName)
+	}
+
+	change, err := s.create(&user, ctx)
+	if err != nil {
+		return entities.BatchWrite{}, err
+	}
+
+	return entities.BatchWrite{Op: entities.BatchCreate, User: user, Change: change}, nil
+}
+
+func (s *Service) batchUpdate(op entities.BatchOperation, updateUser entities.Users, existing map[string]entities.Users,
+	ctx *gofr.Context) (entities.BatchWrite, error) {
+	if err := validateUpdate(op.UserName, &updateUser); err != nil {
+		return entities.BatchWrite{}, err
+	}
+
+	existingUser, err := batchUser(existing, op)
+	if err != nil {
+		return entities.BatchWrite{}, err
+	}
+
+	change, err := s.update(existingUser, &updateUser, ctx)
+	if err != nil {
+		return entities.BatchWrite{}, err
+	}
+
+	return entities.BatchWrite{Op: entities.BatchUpdate, User: updateUser, Change: change}, nil
+}
+
+func (s *Service) batchDelete(op entities.BatchOperation, existing map[string]entities.Users,
+	ctx *gofr.Context) (entities.BatchWrite, error) {
+	existingUser, err := batchUser(existing, op)
+	if err != nil {
+		return entities.BatchWrite{}, err
+	}
+
+	change, err := s.delete(existingUser, ctx)
+	if err != nil {
+		return entities.BatchWrite{}, err
+	}
+
+	return entities.BatchWrite{Op: entities.BatchDelete, User: existingUser, Change: change}, nil
+}
+
+// batchUser returns the existing user an update or delete operates on.
+func batchUser(existing map[string]entities.Users, op entities.BatchOperation) (entities.Users, error) {
+	user, ok := existing[strings.ToLower(op.UserName)]
+	if !ok {
+		return entities.Users{}, apperrors.NotFound("user", "name", op.UserName)
+	}
+
+	return user, expectVersion(user, byName(op.UserName), op.Version)
+}
+
+// nameOnce records that the operation or line at index, as told by what, names a user, failing if
+// an earlier one named the same user.
+func nameOnce(named map[string]int, name string, index int, what string) error {
+	if name == "" {
+		return nil
+	}
+
+	key := strings.ToLower(name)
+
+	if first, ok := named[key]; ok {
+		return apperrors.Validation(validation.Errors{{Field: "user_name", Code: validation.CodeInvalidValue,
+			Message: fmt.Sprintf("user_name is already used by %s %d", what, first)}})
+	}
+
+	named[key] = index
+
+	return nil
+}
+
+// batchOutcome returns the user an applied write leaves behind, nil for a delete, or its error.
+func batchOutcome(write *entities.BatchWrite, op entities.BatchOperation, err error) (*entities.Users, error) {
+	if err != nil {
+		return nil, conditionalWriteError(err, byName(op.UserName), op.Version)
+	}
+
+	if write.Op == entities.BatchDelete {
+		return nil, nil
+	}
+
+	user := write.User
+	user.Version++
+
+	return &user, nil
+}
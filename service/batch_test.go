@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gofr.dev/pkg/gofr"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"gofrProject/validation"
+	"testing"
+	"time"
+)
+
+func Test_Batch(t *testing.T) {
+	adam := entities.Users{UserName: "adam", UserAge: 40, PhoneNumber: "+14155552671", Email: "adam@example.com"}
+	carol := entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", UserName: "carol", UserAge: 30,
+		PhoneNumber: "+14155552672", Email: "carol@example.com", Version: 2}
+	olderCarol := carol
+	olderCarol.UserAge = 31
+	waheed := entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0003", UserName: "waheed", UserAge: 19,
+		PhoneNumber: "+14155552673", Email: "waheed@example.com", Version: 1}
+
+	createdAdam := adam
+	createdAdam.ID, createdAdam.Version = testUserID, 1
+	aborted := apperrors.Aborted("another operation of the batch failed")
+
+	tests := []struct {
+		name             string
+		request          entities.BatchRequest
+		mockExpect       func(mockStore *MockUserStore)
+		expectedResponse entities.BatchResponse
+		expectedErr      error
+	}{
+		{
+			name: "best effort applies the operations that pass their checks",
+			request: entities.BatchRequest{Operations: []entities.BatchOperation{
+				{Op: entities.BatchCreate, User: &adam},
+				{Op: entities.BatchCreate, User: &waheed},
+				{Op: entities.BatchUpdate, UserName: "Carol", User: &entities.Users{UserAge: 31,
+					PhoneNumber: "+14155552672", Email: "carol@example.com"}},
+				{Op: entities.BatchDelete, UserName: "nobody"},
+				{Op: "upsert"},
+				{Op: entities.BatchDelete, UserName: "ADAM"},
+			}},
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().GetUsersByNames([]string{"adam", "waheed", "Carol", "nobody", "ADAM"}, gomock.Any()).
+					Return([]entities.Users{carol, waheed}, nil)
+				mockStore.EXPECT().WriteBatch(gomock.Any(), false, gomock.Any()).
+					DoAndReturn(func(writes []entities.BatchWrite, _ bool, _ *gofr.Context) []error {
+						assert.Len(t, writes, 2)
+						assert.Equal(t, createdAdam.ID, writes[0].User.ID)
+						assert.Equal(t, entities.AuditCreate, writes[0].Change.Audit.Action)
+						assert.Equal(t, 2, writes[1].User.Version, "update expects the version it read")
+
+						return []error{nil, nil}
+					})
+			},
+			expectedResponse: entities.BatchResponse{Succeeded: 2, Failed: 4, Results: []entities.BatchResult{
+				{Index: 0, Op: "create", User: &createdAdam},
+				{Index: 1, Op: "create", Error: apperrors.AlreadyExists("user", "name", "waheed")},
+				{Index: 2, Op: "update", User: &entities.Users{ID: carol.ID, UserName: "Carol", UserAge: 31,
+					PhoneNumber: "+14155552672", Email: "carol@example.com", Version: 3}},
+				{Index: 3, Op: "delete", Error: apperrors.NotFound("user", "name", "nobody")},
+				{Index: 4, Op: "upsert", Error: apperrors.Validation(validation.Errors{{Field: "op",
+					Code: validation.CodeInvalidValue, Message: "op must be create, update or delete"}})},
+				{Index: 5, Op: "delete", Error: apperrors.Validation(validation.Errors{{Field: "user_name",
+					Code: validation.CodeInvalidValue, Message: "user_name is already used by operation 0"}})},
+			}},
+		},
+		{
+			name: "atomic batch writes nothing if an operation fails its checks",
+			request: entities.BatchRequest{Atomic: true, Operations: []entities.BatchOperation{
+				{Op: entities.BatchCreate, User: &adam},
+				{Op: entities.BatchDelete, UserName: "waheed", Version: 5},
+			}},
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().GetUsersByNames([]string{"adam", "waheed"}, gomock.Any()).
+					Return([]entities.Users{waheed}, nil)
+			},
+			expectedResponse: entities.BatchResponse{Atomic: true, Failed: 2, Results: []entities.BatchResult{
+				{Index: 0, Op: "create", Error: aborted},
+				{Index: 1, Op: "delete", Error: apperrors.PreconditionFailed("user", "name", "waheed")},
+			}},
+		},
+		{
+			name: "atomic batch reports the write the store failed",
+			request: entities.BatchRequest{Atomic: true, Operations: []entities.BatchOperation{
+				{Op: entities.BatchDelete, UserName: "waheed", Version: 1},
+				{Op: entities.BatchUpdate, UserName: "carol", User: &olderCarol},
+			}},
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().GetUsersByNames([]string{"waheed", "carol"}, gomock.Any()).
+					Return([]entities.Users{waheed, carol}, nil)
+				mockStore.EXPECT().WriteBatch(gomock.Any(), true, gomock.Any()).
+					Return([]error{apperrors.Conflict("user was modified concurrently", nil), aborted})
+			},
+			expectedResponse: entities.BatchResponse{Atomic: true, Failed: 2, Results: []entities.BatchResult{
+				{Index: 0, Op: "delete", Error: apperrors.PreconditionFailed("user", "name", "waheed")},
+				{Index: 1, Op: "update", Error: aborted},
+			}},
+		},
+		{
+			name:       "empty batch",
+			request:    entities.BatchRequest{},
+			mockExpect: func(*MockUserStore) {},
+			expectedErr: apperrors.Validation(validation.Errors{{Field: "operations", Code: validation.CodeOutOfRange,
+				Message: "operations must hold 1 to 6 operations"}}),
+		},
+		{
+			name:       "batch too large",
+			request:    entities.BatchRequest{Operations: make([]entities.BatchOperation, 7)},
+			mockExpect: func(*MockUserStore) {},
+			expectedErr: apperrors.Validation(validation.Errors{{Field: "operations", Code: validation.CodeOutOfRange,
+				Message: "operations must hold 1 to 6 operations"}}),
+		},
+		{
+			name:    "users cannot be read",
+			request: entities.BatchRequest{Operations: []entities.BatchOperation{{Op: entities.BatchDelete, UserName: "a"}}},
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().GetUsersByNames([]string{"a"}, gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expectedErr: errors.New("db error"),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := NewMockUserStore(gomock.NewController(t))
+			s := NewUserService(mockStore, WithMaxBatchSize(6))
+			s.newID = func() (uuid.UUID, error) { return uuid.MustParse(testUserID), nil }
+			s.now = func() time.Time { return time.Date(2025, 1, 12, 12, 0, 0, 0, time.UTC) }
+
+			tt.mockExpect(mockStore)
+
+			response, err := s.Batch(tt.request, &gofr.Context{Context: context.Background()})
+
+			assert.Equal(t, tt.expectedResponse, response, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
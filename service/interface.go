@@ -3,12 +3,33 @@ package service
 import (
 	"gofr.dev/pkg/gofr"
 	"gofrProject/entities"
+	"time"
 )
 
 type UserStore interface {
-	GetUsers(ctx *gofr.Context) ([]entities.Users, error)
+	GetUsers(query entities.UsersQuery, ctx *gofr.Context) (entities.UsersPage, error)
+	StreamUsers(query entities.UsersQuery, fn func(user entities.Users) error, ctx *gofr.Context) error
 	GetUsersByName(name string, ctx *gofr.Context) (entities.Users, error)
-	AddUsers(user *entities.Users, ctx *gofr.Context) error
-	DeleteUsers(name string, ctx *gofr.Context) error
-	UpdateUsers(name string, updateUser *entities.Users, ctx *gofr.Context) error
+	GetUsersByID(id string, ctx *gofr.Context) (entities.Users, error)
+	GetDeletedUsersByName(name string, ctx *gofr.Context) (entities.Users, error)
+	GetUsersByNames(names []string, ctx *gofr.Context) ([]entities.Users, error)
+	AddUsers(user *entities.Users, change *entities.Change, ctx *gofr.Context) error
+	DeleteUsers(user entities.Users, change *entities.Change, ctx *gofr.Context) error
+	RestoreUsers(user entities.Users, change *entities.Change, ctx *gofr.Context) error
+	PurgeUsers(before time.Time, change func(user entities.Users) (*entities.Change, error), ctx *gofr.Context) (int64, error)
+	UpdateUsers(updateUser *entities.Users, change *entities.Change, ctx *gofr.Context) error
+	RenameUsers(id, name string, version int, change *entities.Change, ctx *gofr.Context) error
+	WriteBatch(writes []entities.BatchWrite, atomic bool, ctx *gofr.Context) []error
+	GetAuditLog(query entities.AuditQuery, ctx *gofr.Context) (entities.AuditPage, error)
+}
+
+// UserIndex searches the users of a UserStore, see package search.
+type UserIndex interface {
+	SearchUsers(query entities.SearchQuery, ctx *gofr.Context) (entities.SearchPage, error)
+	RebuildIndex(ctx *gofr.Context) (int, error)
+}
+
+// ChangeFeed is told about the changes of users written to a UserStore, see package changes.
+type ChangeFeed interface {
+	Publish(change entities.UserChange)
 }
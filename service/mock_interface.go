@@ -12,6 +12,7 @@ package service
 import (
 	entities "gofrProject/entities"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 	gofr "gofr.dev/pkg/gofr"
@@ -42,46 +43,91 @@ func (m *MockUserStore) EXPECT() *MockUserStoreMockRecorder {
 }
 
 // AddUsers mocks base method.
-func (m *MockUserStore) AddUsers(user *entities.Users, ctx *gofr.Context) error {
+func (m *MockUserStore) AddUsers(user *entities.Users, change *entities.Change, ctx *gofr.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddUsers", user, ctx)
+	ret := m.ctrl.Call(m, "AddUsers", user, change, ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddUsers indicates an expected call of AddUsers.
-func (mr *MockUserStoreMockRecorder) AddUsers(user, ctx any) *gomock.Call {
+func (mr *MockUserStoreMockRecorder) AddUsers(user, change, ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUsers", reflect.TypeOf((*MockUserStore)(nil).AddUsers), user, ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUsers", reflect.TypeOf((*MockUserStore)(nil).AddUsers), user, change, ctx)
 }
 
 // DeleteUsers mocks base method.
-func (m *MockUserStore) DeleteUsers(name string, ctx *gofr.Context) error {
+func (m *MockUserStore) DeleteUsers(user entities.Users, change *entities.Change, ctx *gofr.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUsers", name, ctx)
+	ret := m.ctrl.Call(m, "DeleteUsers", user, change, ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteUsers indicates an expected call of DeleteUsers.
-func (mr *MockUserStoreMockRecorder) DeleteUsers(name, ctx any) *gomock.Call {
+func (mr *MockUserStoreMockRecorder) DeleteUsers(user, change, ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUsers", reflect.TypeOf((*MockUserStore)(nil).DeleteUsers), name, ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUsers", reflect.TypeOf((*MockUserStore)(nil).DeleteUsers), user, change, ctx)
+}
+
+// GetAuditLog mocks base method.
+func (m *MockUserStore) GetAuditLog(query entities.AuditQuery, ctx *gofr.Context) (entities.AuditPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLog", query, ctx)
+	ret0, _ := ret[0].(entities.AuditPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockUserStoreMockRecorder) GetAuditLog(query, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockUserStore)(nil).GetAuditLog), query, ctx)
+}
+
+// GetDeletedUsersByName mocks base method.
+func (m *MockUserStore) GetDeletedUsersByName(name string, ctx *gofr.Context) (entities.Users, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedUsersByName", name, ctx)
+	ret0, _ := ret[0].(entities.Users)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedUsersByName indicates an expected call of GetDeletedUsersByName.
+func (mr *MockUserStoreMockRecorder) GetDeletedUsersByName(name, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedUsersByName", reflect.TypeOf((*MockUserStore)(nil).GetDeletedUsersByName), name, ctx)
 }
 
 // GetUsers mocks base method.
-func (m *MockUserStore) GetUsers(ctx *gofr.Context) ([]entities.Users, error) {
+func (m *MockUserStore) GetUsers(query entities.UsersQuery, ctx *gofr.Context) (entities.UsersPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUsers", ctx)
-	ret0, _ := ret[0].([]entities.Users)
+	ret := m.ctrl.Call(m, "GetUsers", query, ctx)
+	ret0, _ := ret[0].(entities.UsersPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUsers indicates an expected call of GetUsers.
-func (mr *MockUserStoreMockRecorder) GetUsers(ctx any) *gomock.Call {
+func (mr *MockUserStoreMockRecorder) GetUsers(query, ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserStore)(nil).GetUsers), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserStore)(nil).GetUsers), query, ctx)
+}
+
+// GetUsersByID mocks base method.
+func (m *MockUserStore) GetUsersByID(id string, ctx *gofr.Context) (entities.Users, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByID", id, ctx)
+	ret0, _ := ret[0].(entities.Users)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByID indicates an expected call of GetUsersByID.
+func (mr *MockUserStoreMockRecorder) GetUsersByID(id, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByID", reflect.TypeOf((*MockUserStore)(nil).GetUsersByID), id, ctx)
 }
 
 // GetUsersByName mocks base method.
@@ -99,16 +145,192 @@ func (mr *MockUserStoreMockRecorder) GetUsersByName(name, ctx any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByName", reflect.TypeOf((*MockUserStore)(nil).GetUsersByName), name, ctx)
 }
 
+// GetUsersByNames mocks base method.
+func (m *MockUserStore) GetUsersByNames(names []string, ctx *gofr.Context) ([]entities.Users, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByNames", names, ctx)
+	ret0, _ := ret[0].([]entities.Users)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByNames indicates an expected call of GetUsersByNames.
+func (mr *MockUserStoreMockRecorder) GetUsersByNames(names, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByNames", reflect.TypeOf((*MockUserStore)(nil).GetUsersByNames), names, ctx)
+}
+
+// PurgeUsers mocks base method.
+func (m *MockUserStore) PurgeUsers(before time.Time, change func(user entities.Users) (*entities.Change, error), ctx *gofr.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUsers", before, change, ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeUsers indicates an expected call of PurgeUsers.
+func (mr *MockUserStoreMockRecorder) PurgeUsers(before, change, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUsers", reflect.TypeOf((*MockUserStore)(nil).PurgeUsers), before, change, ctx)
+}
+
+// RenameUsers mocks base method.
+func (m *MockUserStore) RenameUsers(id string, name string, version int, change *entities.Change, ctx *gofr.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameUsers", id, name, version, change, ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameUsers indicates an expected call of RenameUsers.
+func (mr *MockUserStoreMockRecorder) RenameUsers(id, name, version, change, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUsers", reflect.TypeOf((*MockUserStore)(nil).RenameUsers), id, name, version, change, ctx)
+}
+
+// RestoreUsers mocks base method.
+func (m *MockUserStore) RestoreUsers(user entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUsers", user, change, ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreUsers indicates an expected call of RestoreUsers.
+func (mr *MockUserStoreMockRecorder) RestoreUsers(user, change, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUsers", reflect.TypeOf((*MockUserStore)(nil).RestoreUsers), user, change, ctx)
+}
+
+// StreamUsers mocks base method.
+func (m *MockUserStore) StreamUsers(query entities.UsersQuery, fn func(user entities.Users) error, ctx *gofr.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamUsers", query, fn, ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamUsers indicates an expected call of StreamUsers.
+func (mr *MockUserStoreMockRecorder) StreamUsers(query, fn, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamUsers", reflect.TypeOf((*MockUserStore)(nil).StreamUsers), query, fn, ctx)
+}
+
 // UpdateUsers mocks base method.
-func (m *MockUserStore) UpdateUsers(name string, updateUser *entities.Users, ctx *gofr.Context) error {
+func (m *MockUserStore) UpdateUsers(updateUser *entities.Users, change *entities.Change, ctx *gofr.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUsers", name, updateUser, ctx)
+	ret := m.ctrl.Call(m, "UpdateUsers", updateUser, change, ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateUsers indicates an expected call of UpdateUsers.
-func (mr *MockUserStoreMockRecorder) UpdateUsers(name, updateUser, ctx any) *gomock.Call {
+func (mr *MockUserStoreMockRecorder) UpdateUsers(updateUser, change, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUsers", reflect.TypeOf((*MockUserStore)(nil).UpdateUsers), updateUser, change, ctx)
+}
+
+// WriteBatch mocks base method.
+func (m *MockUserStore) WriteBatch(writes []entities.BatchWrite, atomic bool, ctx *gofr.Context) []error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteBatch", writes, atomic, ctx)
+	ret0, _ := ret[0].([]error)
+	return ret0
+}
+
+// WriteBatch indicates an expected call of WriteBatch.
+func (mr *MockUserStoreMockRecorder) WriteBatch(writes, atomic, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteBatch", reflect.TypeOf((*MockUserStore)(nil).WriteBatch), writes, atomic, ctx)
+}
+
+// MockUserIndex is a mock of UserIndex interface.
+type MockUserIndex struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserIndexMockRecorder
+	isgomock struct{}
+}
+
+// MockUserIndexMockRecorder is the mock recorder for MockUserIndex.
+type MockUserIndexMockRecorder struct {
+	mock *MockUserIndex
+}
+
+// NewMockUserIndex creates a new mock instance.
+func NewMockUserIndex(ctrl *gomock.Controller) *MockUserIndex {
+	mock := &MockUserIndex{ctrl: ctrl}
+	mock.recorder = &MockUserIndexMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserIndex) EXPECT() *MockUserIndexMockRecorder {
+	return m.recorder
+}
+
+// RebuildIndex mocks base method.
+func (m *MockUserIndex) RebuildIndex(ctx *gofr.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebuildIndex", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RebuildIndex indicates an expected call of RebuildIndex.
+func (mr *MockUserIndexMockRecorder) RebuildIndex(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildIndex", reflect.TypeOf((*MockUserIndex)(nil).RebuildIndex), ctx)
+}
+
+// SearchUsers mocks base method.
+func (m *MockUserIndex) SearchUsers(query entities.SearchQuery, ctx *gofr.Context) (entities.SearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", query, ctx)
+	ret0, _ := ret[0].(entities.SearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockUserIndexMockRecorder) SearchUsers(query, ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockUserIndex)(nil).SearchUsers), query, ctx)
+}
+
+// MockChangeFeed is a mock of ChangeFeed interface.
+type MockChangeFeed struct {
+	ctrl     *gomock.Controller
+	recorder *MockChangeFeedMockRecorder
+	isgomock struct{}
+}
+
+// MockChangeFeedMockRecorder is the mock recorder for MockChangeFeed.
+type MockChangeFeedMockRecorder struct {
+	mock *MockChangeFeed
+}
+
+// NewMockChangeFeed creates a new mock instance.
+func NewMockChangeFeed(ctrl *gomock.Controller) *MockChangeFeed {
+	mock := &MockChangeFeed{ctrl: ctrl}
+	mock.recorder = &MockChangeFeedMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChangeFeed) EXPECT() *MockChangeFeedMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockChangeFeed) Publish(change entities.UserChange) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Publish", change)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockChangeFeedMockRecorder) Publish(change any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUsers", reflect.TypeOf((*MockUserStore)(nil).UpdateUsers), name, updateUser, ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockChangeFeed)(nil).Publish), change)
 }
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+	"gofr.dev/pkg/gofr"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"gofrProject/validation"
+	"strings"
+)
+
+// errSearchDisabled is the cause of the error of searches when the service has no index.
+var errSearchDisabled = errors.New("search is not enabled")
+
+// SearchUsers returns the users best matching query.Text, applying the default page size when the
+// query does not specify one.
+func (s *Service) SearchUsers(query entities.SearchQuery, ctx *gofr.Context) (entities.SearchPage, error) {
+	if query.Limit == 0 {
+		query.Limit = DefaultUsersLimit
+	}
+
+	var errs validation.Errors
+
+	if strings.TrimSpace(query.Text) == "" {
+		errs = append(errs, validation.FieldError{Field: "q", Code: validation.CodeRequired, Message: "q is required"})
+	}
+
+	if query.Limit < 1 || query.Limit > MaxUsersLimit {
+		errs = append(errs, validation.FieldError{Field: "limit", Code: validation.CodeOutOfRange,
+			Message: "limit must be between 1 and 100"})
+	}
+
+	if len(errs) > 0 {
+		return entities.SearchPage{}, apperrors.Validation(errs)
+	}
+
+	if s.index == nil {
+		return entities.SearchPage{}, apperrors.Unavailable(errSearchDisabled)
+	}
+
+	return s.index.SearchUsers(query, ctx)
+}
+
+// RebuildSearchIndex repopulates the search index from the users of the store.
+func (s *Service) RebuildSearchIndex(ctx *gofr.Context) (entities.RebuildResult, error) {
+	if s.index == nil {
+		return entities.RebuildResult{}, apperrors.Unavailable(errSearchDisabled)
+	}
+
+	indexed, err := s.index.RebuildIndex(ctx)
+	if err != nil {
+		return entities.RebuildResult{}, err
+	}
+
+	return entities.RebuildResult{Indexed: indexed}, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gofr.dev/pkg/gofr"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"gofrProject/validation"
+	"testing"
+)
+
+func Test_SearchUsers(t *testing.T) {
+	page := entities.SearchPage{Results: []entities.SearchResult{{User: entities.Users{UserName: "waheed"}, Score: 10}},
+		TotalCount: 1}
+
+	tests := []struct {
+		name         string
+		query        entities.SearchQuery
+		mockExpect   func(mockIndex *MockUserIndex)
+		expectedPage entities.SearchPage
+		expectedErr  error
+	}{
+		{
+			name:  "default limit",
+			query: entities.SearchQuery{Text: "waheed"},
+			mockExpect: func(mockIndex *MockUserIndex) {
+				mockIndex.EXPECT().SearchUsers(entities.SearchQuery{Text: "waheed", Limit: DefaultUsersLimit}, gomock.Any()).
+					Return(page, nil)
+			},
+			expectedPage: page,
+		},
+		{
+			name:       "invalid query",
+			query:      entities.SearchQuery{Text: " ", Limit: MaxUsersLimit + 1},
+			mockExpect: func(*MockUserIndex) {},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "q", Code: validation.CodeRequired, Message: "q is required"},
+				{Field: "limit", Code: validation.CodeOutOfRange, Message: "limit must be between 1 and 100"},
+			}),
+		},
+		{
+			name:  "index error",
+			query: entities.SearchQuery{Text: "waheed", Limit: 5},
+			mockExpect: func(mockIndex *MockUserIndex) {
+				mockIndex.EXPECT().SearchUsers(gomock.Any(), gomock.Any()).Return(entities.SearchPage{}, errors.New("db error"))
+			},
+			expectedErr: errors.New("db error"),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockIndex := NewMockUserIndex(ctrl)
+			s := NewUserService(NewMockUserStore(ctrl), WithSearch(mockIndex))
+
+			tt.mockExpect(mockIndex)
+
+			result, err := s.SearchUsers(tt.query, &gofr.Context{Context: context.Background()})
+
+			assert.Equal(t, tt.expectedPage, result, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_SearchUsers_Disabled(t *testing.T) {
+	s := NewUserService(NewMockUserStore(gomock.NewController(t)))
+	ctx := &gofr.Context{Context: context.Background()}
+
+	_, err := s.SearchUsers(entities.SearchQuery{Text: "waheed"}, ctx)
+	assert.True(t, errors.Is(err, &apperrors.Error{Kind: apperrors.KindUnavailable}))
+
+	_, err = s.RebuildSearchIndex(ctx)
+	assert.True(t, errors.Is(err, &apperrors.Error{Kind: apperrors.KindUnavailable}))
+}
+
+func Test_RebuildSearchIndex(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockIndex := NewMockUserIndex(ctrl)
+	s := NewUserService(NewMockUserStore(ctrl), WithSearch(mockIndex))
+
+	mockIndex.EXPECT().RebuildIndex(gomock.Any()).Return(42, nil)
+
+	result, err := s.RebuildSearchIndex(&gofr.Context{Context: context.Background()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.RebuildResult{Indexed: 42}, result)
+}
@@ -1,36 +1,185 @@
 package service
 
 import (
-	"database/sql"
-	"fmt"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"gofr.dev/pkg/gofr"
-	"gofr.dev/pkg/gofr/datasource"
-	"gofr.dev/pkg/gofr/http"
+	"gofrProject/apperrors"
+	"gofrProject/auth"
 	"gofrProject/entities"
+	"gofrProject/events"
+	"gofrProject/headers"
+	"gofrProject/validation"
+	"strings"
+	"time"
 )
 
+// DefaultRetention is how long soft-deleted users are kept before PurgeUsers removes them.
+const DefaultRetention = 30 * 24 * time.Hour
+
 type Service struct {
-	store UserStore
+	store        UserStore
+	newID        func() (uuid.UUID, error)
+	now          func() time.Time
+	retention    time.Duration
+	eventsTopic  string
+	maxBatchSize int
+	index        UserIndex
+	feed         ChangeFeed
+}
+
+// Option configures a Service.
+type Option func(s *Service)
+
+// WithRetention sets how long soft-deleted users are kept before PurgeUsers removes them.
+func WithRetention(retention time.Duration) Option {
+	return func(s *Service) {
+		s.retention = retention
+	}
 }
 
-func NewUserService(store UserStore) *Service {
-	return &Service{store: store}
+// WithEvents records the user lifecycle events described in package events for topic. The events
+// are written to the outbox of the store together with the change they report and published by
+// the outbox relay.
+func WithEvents(topic string) Option {
+	return func(s *Service) {
+		s.eventsTopic = topic
+	}
 }
 
-func (s *Service) GetUsers(ctx *gofr.Context) ([]entities.Users, error) {
-	users, err := s.store.GetUsers(ctx)
+// WithSearch makes SearchUsers search the users in index, which has to be kept up to date with the
+// writes to the store.
+func WithSearch(index UserIndex) Option {
+	return func(s *Service) {
+		s.index = index
+	}
+}
+
+// WithChangeFeed publishes every change of a user to feed once the store has written it.
+func WithChangeFeed(feed ChangeFeed) Option {
+	return func(s *Service) {
+		s.feed = feed
+	}
+}
+
+func NewUserService(store UserStore, opts ...Option) *Service {
+	s := &Service{store: store, newID: uuid.NewV7, now: time.Now, retention: DefaultRetention,
+		maxBatchSize: DefaultMaxBatchSize}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Page size limits applied when listing users.
+const (
+	DefaultUsersLimit = 20
+	MaxUsersLimit     = 100
+)
+
+// GetUsers returns one page of users, applying the default page size and sort order
+// when the query does not specify them.
+func (s *Service) GetUsers(query entities.UsersQuery, ctx *gofr.Context) (entities.UsersPage, error) {
+	if query.Limit == 0 {
+		query.Limit = DefaultUsersLimit
+	}
+
+	if query.SortBy == "" {
+		query.SortBy = entities.SortByUserName
+	}
+
+	if errs := usersQueryErrors(query); len(errs) > 0 {
+		return entities.UsersPage{}, apperrors.Validation(errs)
+	}
+
+	page, err := s.store.GetUsers(query, ctx)
 	if err != nil {
-		return nil, err
+		return entities.UsersPage{}, err
+	}
+	return page, nil
+}
+
+// usersQueryErrors reports the query parameters holding values the store cannot serve.
+func usersQueryErrors(query entities.UsersQuery) validation.Errors {
+	var errs validation.Errors
+
+	if query.Limit < 1 || query.Limit > MaxUsersLimit {
+		errs = append(errs, validation.FieldError{Field: "limit", Code: validation.CodeOutOfRange,
+			Message: "limit must be between 1 and 100"})
+	}
+
+	return append(errs, usersFilterErrors(query)...)
+}
+
+// usersFilterErrors reports the filter and sort parameters of the query holding values the store
+// cannot serve.
+func usersFilterErrors(query entities.UsersQuery) validation.Errors {
+	var errs validation.Errors
+
+	if query.SortBy != entities.SortByUserName && query.SortBy != entities.SortByUserAge {
+		errs = append(errs, validation.FieldError{Field: "sort", Code: validation.CodeInvalidValue,
+			Message: "sort must be user_name or user_age"})
+	}
+
+	if query.MinAge != nil && query.MaxAge != nil && *query.MinAge > *query.MaxAge {
+		errs = append(errs, validation.FieldError{Field: "min_age", Code: validation.CodeOutOfRange,
+			Message: "min_age must not be greater than max_age"})
+	}
+
+	return errs
+}
+
+// GetAuditLog returns one page of the audit log entries matching the query, newest first, applying
+// the default page size when the query does not specify one.
+func (s *Service) GetAuditLog(query entities.AuditQuery, ctx *gofr.Context) (entities.AuditPage, error) {
+	if query.Limit == 0 {
+		query.Limit = DefaultUsersLimit
 	}
-	return users, nil
+
+	var errs validation.Errors
+
+	if query.Limit < 1 || query.Limit > MaxUsersLimit {
+		errs = append(errs, validation.FieldError{Field: "limit", Code: validation.CodeOutOfRange,
+			Message: "limit must be between 1 and 100"})
+	}
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		errs = append(errs, validation.FieldError{Field: "from", Code: validation.CodeOutOfRange,
+			Message: "from must be before to"})
+	}
+
+	if len(errs) > 0 {
+		return entities.AuditPage{}, apperrors.Validation(errs)
+	}
+
+	return s.store.GetAuditLog(query, ctx)
 }
 
+// GetUserHistory returns one page of the audit log of the user with the given name, newest first.
+// The history is kept by user id, so it includes the changes made before the user was renamed.
+func (s *Service) GetUserHistory(name string, query entities.AuditQuery, ctx *gofr.Context) (entities.AuditPage, error) {
+	user, err := s.GetUsersByName(name, ctx)
+	if err != nil {
+		return entities.AuditPage{}, err
+	}
+
+	query.UserID = user.ID
+
+	return s.GetAuditLog(query, ctx)
+}
+
+// GetUsersByName returns the user with the given name.
 func (s *Service) GetUsersByName(name string, ctx *gofr.Context) (entities.Users, error) {
 	user, err := s.store.GetUsersByName(name, ctx)
 	if err != nil {
-		if err == sql.ErrNoRows {
-
-			return entities.Users{}, fmt.Errorf("%w, '%s'", http.ErrorEntityNotFound{"name", "name"}, "john")
+		if apperrors.IsNotFound(err) {
+			return entities.Users{}, apperrors.NotFound("user", "name", name)
 		}
 
 		return entities.Users{}, err
@@ -39,36 +188,605 @@ func (s *Service) GetUsersByName(name string, ctx *gofr.Context) (entities.Users
 	return user, nil
 }
 
+// GetUsersByID returns the user with the given id.
+func (s *Service) GetUsersByID(id string, ctx *gofr.Context) (entities.Users, error) {
+	return s.store.GetUsersByID(id, ctx)
+}
+
+// AddUsers validates and creates a new user, rejecting names that are already taken.
+// The user is given a new UUIDv7 id, any id sent by the client is ignored.
 func (s *Service) AddUsers(user *entities.Users, ctx *gofr.Context) error {
+	if err := validation.User(user); err != nil {
+		return apperrors.Validation(err)
+	}
 
-	if user.UserName == "" || user.PhoneNumber == "" {
-		dbErr2 := datasource.ErrorDB{Message: "UserName and PhoneNumber cannot be empty"}
-		return fmt.Errorf("error:%w", dbErr2)
+	if err := s.nameAvailable(user.UserName, ctx); err != nil {
+		return err
+	}
+
+	change, err := s.create(user, ctx)
+	if err != nil {
+		return err
 	}
 
-	existingUser, err := s.store.GetUsersByName(user.UserName, ctx)
-	if err == nil && existingUser.UserName != "" {
-		var err1 = http.ErrorEntityAlreadyExist{}
-		return fmt.Errorf("%w, '%s' already exists", err1, user.UserName)
+	if err := s.store.AddUsers(user, change, ctx); err != nil {
+		return err
 	}
 
-	return s.store.AddUsers(user, ctx)
+	s.notify(change)
+
+	return nil
 }
 
-func (s *Service) DeleteUsers(name string, ctx *gofr.Context) error {
-	existingUser, err := s.store.GetUsersByName(name, ctx)
-	if err != nil || existingUser.UserName == "" {
-		return fmt.Errorf("%w", http.ErrorEntityNotFound{"name", "albert"})
+// create gives the validated user a new id and returns the change recording its creation.
+func (s *Service) create(user *entities.Users, ctx *gofr.Context) (*entities.Change, error) {
+	id, err := s.newID()
+	if err != nil {
+		return nil, err
 	}
 
-	return s.store.DeleteUsers(name, ctx)
+	user.ID = id.String()
+
+	return s.change(entities.AuditCreate, entities.Users{}, *user, ctx)
 }
 
-func (s *Service) UpdateUsers(name string, updateUser *entities.Users, ctx *gofr.Context) error {
-	existingUser, err := s.store.GetUsersByName(name, ctx)
-	if err != nil || existingUser.UserName == "" {
-		return fmt.Errorf("%w", http.ErrorEntityNotFound{"name", "albert"})
+// RenameUsers changes the name of the user with the given id and returns the renamed user.
+// The id of the user stays the same.
+func (s *Service) RenameUsers(id, name string, ctx *gofr.Context) (entities.Users, error) {
+	if err := validation.UserName(name); err != nil {
+		return entities.Users{}, apperrors.Validation(err)
+	}
+
+	user, err := s.GetUsersByID(id, ctx)
+	if err != nil {
+		return entities.Users{}, err
+	}
+
+	if user.UserName == name {
+		return user, nil
+	}
+
+	if err := s.nameAvailable(name, ctx); err != nil {
+		return entities.Users{}, err
+	}
+
+	renamed := user
+	renamed.UserName = name
+
+	change, err := s.change(entities.AuditRename, user, renamed, ctx)
+	if err != nil {
+		return entities.Users{}, err
+	}
+
+	if err := s.store.RenameUsers(id, name, user.Version, change, ctx); err != nil {
+		return entities.Users{}, err
+	}
+
+	s.notify(change)
+
+	return renamed, nil
+}
+
+// nameAvailable returns AlreadyExists if a user with the given name exists.
+func (s *Service) nameAvailable(name string, ctx *gofr.Context) error {
+	_, err := s.store.GetUsersByName(name, ctx)
+
+	switch {
+	case err == nil:
+		return apperrors.AlreadyExists("user", "name", name)
+	case !apperrors.IsNotFound(err):
+		return err
+	}
+
+	return nil
+}
+
+// DeleteUsers soft-deletes an existing user. It can be restored with RestoreUsers until it is purged.
+// A non-zero version makes the delete conditional, see UpdateUsers.
+func (s *Service) DeleteUsers(name string, version int, ctx *gofr.Context) error {
+	return s.deleteUser(byName(name), version, ctx)
+}
+
+// DeleteUsersByID is DeleteUsers for the user with the given id.
+func (s *Service) DeleteUsersByID(id string, version int, ctx *gofr.Context) error {
+	return s.deleteUser(byID(id), version, ctx)
+}
+
+func (s *Service) deleteUser(key userKey, version int, ctx *gofr.Context) error {
+	existingUser, err := s.currentUser(key, version, ctx)
+	if err != nil {
+		return err
+	}
+
+	change, err := s.delete(existingUser, ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteUsers(existingUser, change, ctx); err != nil {
+		return conditionalWriteError(err, key, version)
+	}
+
+	s.notify(change)
+
+	return nil
+}
+
+// delete returns the change recording the soft delete of existingUser.
+func (s *Service) delete(existingUser entities.Users, ctx *gofr.Context) (*entities.Change, error) {
+	deletedAt := s.now().UTC()
+	deletedUser := existingUser
+	deletedUser.DeletedAt = &deletedAt
+
+	return s.change(entities.AuditDelete, existingUser, deletedUser, ctx)
+}
+
+// RestoreUsers undoes the soft delete of a user and returns the restored user.
+func (s *Service) RestoreUsers(name string, ctx *gofr.Context) (entities.Users, error) {
+	deletedUser, err := s.store.GetDeletedUsersByName(name, ctx)
+	if err != nil {
+		return entities.Users{}, err
+	}
+
+	restoredUser := deletedUser
+	restoredUser.DeletedAt = nil
+
+	change, err := s.change(entities.AuditRestore, deletedUser, restoredUser, ctx)
+	if err != nil {
+		return entities.Users{}, err
+	}
+
+	if err := s.store.RestoreUsers(deletedUser, change, ctx); err != nil {
+		return entities.Users{}, err
+	}
+
+	s.notify(change)
+
+	return s.GetUsersByID(deletedUser.ID, ctx)
+}
+
+// PurgeUsers permanently removes the users that were soft-deleted longer than the retention ago.
+// The removal of every user is recorded like its other changes.
+func (s *Service) PurgeUsers(ctx *gofr.Context) (entities.PurgeResult, error) {
+	var changes []*entities.Change
+
+	purged, err := s.store.PurgeUsers(s.now().Add(-s.retention), func(user entities.Users) (*entities.Change, error) {
+		change, err := s.change(entities.AuditPurge, user, entities.Users{}, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, change)
+
+		return change, nil
+	}, ctx)
+	if err != nil {
+		return entities.PurgeResult{}, err
+	}
+
+	for _, change := range changes {
+		s.notify(change)
+	}
+
+	return entities.PurgeResult{Purged: purged}, nil
+}
+
+// UpdateUsers replaces all mutable fields of the user. The body may repeat the id and user name,
+// but it cannot change them; users are renamed with RenameUsers. A non-zero version is the version
+// of the user the client based the update on, the update fails with PreconditionFailed if the user
+// has been changed since.
+func (s *Service) UpdateUsers(name string, version int, updateUser *entities.Users, ctx *gofr.Context) error {
+	if err := validateUpdate(name, updateUser); err != nil {
+		return err
+	}
+
+	existingUser, err := s.currentUser(byName(name), version, ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.updateUser(existingUser, byName(name), version, updateUser, ctx)
+}
+
+// UpdateUsersByID is UpdateUsers for the user with the given id.
+func (s *Service) UpdateUsersByID(id string, version int, updateUser *entities.Users, ctx *gofr.Context) error {
+	existingUser, err := s.currentUser(byID(id), version, ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := validateUpdate(existingUser.UserName, updateUser); err != nil {
+		return err
+	}
+
+	return s.updateUser(existingUser, byID(id), version, updateUser, ctx)
+}
+
+// updateUser replaces existingUser, addressed by key, by the validated updateUser.
+func (s *Service) updateUser(existingUser entities.Users, key userKey, version int, updateUser *entities.Users,
+	ctx *gofr.Context) error {
+	change, err := s.update(existingUser, updateUser, ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.UpdateUsers(updateUser, change, ctx); err != nil {
+		return conditionalWriteError(err, key, version)
+	}
+
+	s.notify(change)
+
+	return nil
+}
+
+// validateUpdate validates updateUser, the new values of the user with the given name. It may
+// repeat the name, but not change it. Users are deleted and restored by their own requests, so it
+// cannot set deleted_at either.
+func validateUpdate(name string, updateUser *entities.Users) error {
+	if updateUser.UserName != "" && updateUser.UserName != name {
+		return apperrors.Validation(immutableFieldErrors("user_name"))
+	}
+
+	if updateUser.DeletedAt != nil {
+		return apperrors.Validation(immutableFieldErrors("deleted_at"))
+	}
+
+	updateUser.UserName = name
+
+	if err := validation.User(updateUser); err != nil {
+		return apperrors.Validation(err)
+	}
+
+	return nil
+}
+
+// update gives the validated updateUser the id and version of existingUser, failing if it tries to
+// change the id, and returns the change recording the update.
+func (s *Service) update(existingUser entities.Users, updateUser *entities.Users, ctx *gofr.Context) (*entities.Change, error) {
+	if updateUser.ID != "" && updateUser.ID != existingUser.ID {
+		return nil, apperrors.Validation(immutableFieldErrors("id"))
+	}
+
+	updateUser.ID, updateUser.Version = existingUser.ID, existingUser.Version
+
+	return s.change(entities.AuditUpdate, existingUser, *updateUser, ctx)
+}
+
+// immutableFields lists the JSON keys of entities.Users that PUT and PATCH cannot change.
+var immutableFields = []string{"id", "user_name", "deleted_at"}
+
+// PatchUsers applies a JSON Merge Patch (RFC 7396) to the stored user and persists the result.
+// Fields that are not present in the patch are left untouched, null removes a field's value.
+// A non-zero version makes the patch conditional, see UpdateUsers.
+func (s *Service) PatchUsers(name string, version int, patch map[string]any, ctx *gofr.Context) error {
+	return s.patchUser(byName(name), version, patch, ctx)
+}
+
+// PatchUsersByID is PatchUsers for the user with the given id.
+func (s *Service) PatchUsersByID(id string, version int, patch map[string]any, ctx *gofr.Context) error {
+	return s.patchUser(byID(id), version, patch, ctx)
+}
+
+func (s *Service) patchUser(key userKey, version int, patch map[string]any, ctx *gofr.Context) error {
+	existingUser, err := s.currentUser(key, version, ctx)
+	if err != nil {
+		return err
+	}
+
+	// Only users that are not deleted are patched, their deleted_at is null.
+	current := map[string]any{"id": existingUser.ID, "user_name": existingUser.UserName, "deleted_at": nil}
+
+	for _, field := range immutableFields {
+		if value, ok := patch[field]; ok && value != current[field] {
+			return apperrors.Validation(immutableFieldErrors(field))
+		}
+	}
+
+	patchedUser, err := applyMergePatch(existingUser, patch)
+	if err != nil {
+		return apperrors.Validation(err)
+	}
+
+	if err := validation.User(&patchedUser); err != nil {
+		return apperrors.Validation(err)
+	}
+
+	patchedUser.Version = existingUser.Version
+
+	change, err := s.change(entities.AuditUpdate, existingUser, patchedUser, ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.UpdateUsers(&patchedUser, change, ctx); err != nil {
+		return conditionalWriteError(err, key, version)
+	}
+
+	s.notify(change)
+
+	return nil
+}
+
+// userKey is how the client addresses the user it writes: the field "name" or "id" and its value.
+type userKey struct {
+	field, value string
+}
+
+func byName(name string) userKey {
+	return userKey{field: "name", value: name}
+}
+
+func byID(id string) userKey {
+	return userKey{field: "id", value: id}
+}
+
+// currentUser returns the user key addresses. A non-zero version is the version the client expects
+// the user to be at, it fails with PreconditionFailed if the user is at another one.
+func (s *Service) currentUser(key userKey, version int, ctx *gofr.Context) (entities.Users, error) {
+	get := s.GetUsersByName
+	if key.field == "id" {
+		get = s.GetUsersByID
+	}
+
+	user, err := get(key.value, ctx)
+	if err != nil {
+		return entities.Users{}, err
+	}
+
+	if err := expectVersion(user, key, version); err != nil {
+		return entities.Users{}, err
+	}
+
+	return user, nil
+}
+
+// expectVersion fails with PreconditionFailed if version is not zero and user, addressed by key by
+// the client, is at another version.
+func expectVersion(user entities.Users, key userKey, version int) error {
+	if version != 0 && user.Version != version {
+		return apperrors.PreconditionFailed("user", key.field, key.value)
+	}
+
+	return nil
+}
+
+// conditionalWriteError reports a write that lost the race against another write of the user as
+// PreconditionFailed if the client made it conditional.
+func conditionalWriteError(err error, key userKey, version int) error {
+	if version != 0 && apperrors.KindOf(err) == apperrors.KindConflict {
+		return apperrors.PreconditionFailed("user", key.field, key.value)
+	}
+
+	return err
+}
+
+// change returns what the store records together with a write of a user: the audit entry of action
+// with the fields that changed between before and after, and the event reporting it if events are
+// enabled.
+func (s *Service) change(action string, before, after entities.Users, ctx *gofr.Context) (*entities.Change, error) {
+	diff, err := events.Diff(before, after)
+
+	switch action {
+	case entities.AuditCreate:
+		diff, err = allFields(after, false)
+	case entities.AuditPurge:
+		diff, err = allFields(before, true)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := json.Marshal(diff)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := after
+	if action == entities.AuditDelete || action == entities.AuditPurge {
+		subject = before
+	}
+
+	now := s.now().UTC()
+	change := &entities.Change{Audit: entities.AuditEntry{Actor: actor(ctx), Action: action, UserID: subject.ID,
+		UserName: subject.UserName, Changes: changes, RequestID: requestID(ctx), Timestamp: now}}
+
+	change.Event, err = s.event(action, before, after, diff, now, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// allFields returns every field of the JSON representation of user as a change from null, the
+// changes of a new user, or to null if removed, the changes of a purged user.
+func allFields(user entities.Users, removed bool) (map[string]events.FieldChange, error) {
+	b, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]events.FieldChange, len(fields))
+	for name, value := range fields {
+		change := events.FieldChange{After: value}
+		if removed {
+			change = events.FieldChange{Before: value}
+		}
+
+		changes[name] = change
+	}
+
+	return changes, nil
+}
+
+// event returns the outbox message of the event reporting action, or nil if no events topic is
+// configured or no event reports action. The store saves the message together with the change,
+// the outbox relay publishes it afterwards.
+func (s *Service) event(action string, before, after entities.Users, diff map[string]events.FieldChange, now time.Time,
+	ctx *gofr.Context) (*entities.OutboxMessage, error) {
+	if s.eventsTopic == "" {
+		return nil, nil
+	}
+
+	var envelope events.Envelope
+
+	switch action {
+	case entities.AuditCreate:
+		envelope.Type, envelope.Payload = events.TypeUserCreated, events.UserCreated{User: after}
+	case entities.AuditUpdate, entities.AuditRename, entities.AuditRestore:
+		envelope.Type, envelope.Payload = events.TypeUserUpdated, events.UserUpdated{User: after, Changes: diff}
+	case entities.AuditDelete:
+		envelope.Type, envelope.Payload = events.TypeUserDeleted, events.UserDeleted{User: before}
+	case entities.AuditPurge:
+		envelope.Type, envelope.Payload = events.TypeUserPurged, events.UserPurged{User: before}
+	default:
+		return nil, nil
+	}
+
+	id, err := s.newID()
+	if err != nil {
+		return nil, err
+	}
+
+	envelope.ID, envelope.Version, envelope.Timestamp, envelope.Actor = id.String(), events.Version, now, actor(ctx)
+
+	message, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.OutboxMessage{Topic: s.eventsTopic, Payload: message, CreatedAt: now}, nil
+}
+
+// changeTypes are the types of the change feed notifications of the audited actions.
+var changeTypes = map[string]string{
+	entities.AuditCreate:  events.TypeUserCreated,
+	entities.AuditUpdate:  events.TypeUserUpdated,
+	entities.AuditRename:  events.TypeUserUpdated,
+	entities.AuditRestore: events.TypeUserUpdated,
+	entities.AuditDelete:  events.TypeUserDeleted,
+	entities.AuditPurge:   events.TypeUserPurged,
+}
+
+// notify publishes change, written by the store, to the change feed if there is one.
+func (s *Service) notify(change *entities.Change) {
+	if s.feed == nil || change == nil {
+		return
+	}
+
+	audit := change.Audit
+	s.feed.Publish(entities.UserChange{Type: changeTypes[audit.Action], UserID: audit.UserID, UserName: audit.UserName,
+		Changes: audit.Changes, Timestamp: audit.Timestamp})
+}
+
+// notifyWrites publishes the changes of the writes of a batch that succeeded, errs holding the
+// outcome of every write.
+func (s *Service) notifyWrites(writes []entities.BatchWrite, errs []error) {
+	for i := range writes {
+		if errs[i] == nil {
+			s.notify(writes[i].Change)
+		}
+	}
+}
+
+// actor returns the name of the authenticated principal of the request, or "" if there is none.
+func actor(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.Name
+	}
+
+	return ""
+}
+
+// requestID returns the X-Request-ID sent by the client, or else the id of the trace of the request,
+// which GoFr returns to the client as X-Correlation-ID.
+func requestID(ctx context.Context) string {
+	if id := headers.Get(ctx, "X-Request-ID"); id != "" {
+		return id
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		return spanContext.TraceID().String()
+	}
+
+	return ""
+}
+
+func immutableFieldErrors(field string) validation.Errors {
+	return validation.Errors{{Field: field, Code: validation.CodeImmutable, Message: field + " cannot be changed"}}
+}
+
+// applyMergePatch merges patch into the JSON representation of user as described by RFC 7396.
+func applyMergePatch(user entities.Users, patch map[string]any) (entities.Users, error) {
+	original, err := json.Marshal(user)
+	if err != nil {
+		return entities.Users{}, err
+	}
+
+	var document map[string]any
+	if err = json.Unmarshal(original, &document); err != nil {
+		return entities.Users{}, err
+	}
+
+	merged, err := json.Marshal(mergePatch(document, patch))
+	if err != nil {
+		return entities.Users{}, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+
+	var patchedUser entities.Users
+	if err = decoder.Decode(&patchedUser); err != nil {
+		return entities.Users{}, patchErrors(err)
+	}
+
+	return patchedUser, nil
+}
+
+// mergePatch implements the MergePatch function from RFC 7396 section 2.
+func mergePatch(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// patchErrors describes the patch field responsible for a decoding error.
+func patchErrors(err error) validation.Errors {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return validation.Errors{{Field: typeErr.Field, Code: validation.CodeInvalidType,
+			Message: typeErr.Field + " must be of type " + typeErr.Type.String()}}
+	}
+
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field = strings.Trim(field, `"`)
+
+		return validation.Errors{{Field: field, Code: validation.CodeUnknownField, Message: field + " is not a user field"}}
 	}
 
-	return s.store.UpdateUsers(name, updateUser, ctx)
+	return validation.Errors{{Field: "body", Code: validation.CodeInvalidFormat, Message: err.Error()}}
 }
@@ -1,14 +1,21 @@
 package service
 
 import (
-	"database/sql"
-	"fmt"
+	"context"
+	"errors"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"gofr.dev/pkg/gofr"
-	"gofr.dev/pkg/gofr/http"
+	"gofrProject/apperrors"
+	"gofrProject/auth"
 	"gofrProject/entities"
+	"gofrProject/headers"
+	"gofrProject/validation"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func Test_GetUsers(t *testing.T) {
@@ -18,18 +25,69 @@ func Test_GetUsers(t *testing.T) {
 	mockStore := NewMockUserStore(ctrl)
 	service := NewUserService(mockStore)
 
-	users := []entities.Users{
-		{UserName: "john", PhoneNumber: "1234"},
-		{UserName: "alice", PhoneNumber: "5678"},
+	page := entities.UsersPage{
+		Users: []entities.Users{
+			{UserName: "john", PhoneNumber: "1234"},
+			{UserName: "alice", PhoneNumber: "5678"},
+		},
+		TotalCount: 2,
 	}
 
-	mockStore.EXPECT().GetUsers(gomock.Any()).Return(users, nil).Times(1)
+	minAge, maxAge := 30, 20
 
-	ctx := &gofr.Context{}
-	result, err := service.GetUsers(ctx)
+	tests := []struct {
+		name        string
+		query       entities.UsersQuery
+		mockExpect  func()
+		expected    entities.UsersPage
+		expectedErr error
+	}{
+		{
+			name:  "Defaults Applied",
+			query: entities.UsersQuery{},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsers(entities.UsersQuery{Limit: DefaultUsersLimit, SortBy: entities.SortByUserName},
+					gomock.Any()).Return(page, nil).Times(1)
+			},
+			expected:    page,
+			expectedErr: nil,
+		},
+		{
+			name:       "Limit Too Large",
+			query:      entities.UsersQuery{Limit: MaxUsersLimit + 1},
+			mockExpect: func() {},
+			expected:   entities.UsersPage{},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "limit", Code: validation.CodeOutOfRange, Message: "limit must be between 1 and 100"},
+			}),
+		},
+		{
+			name:       "Unknown Sort And Inverted Age Range",
+			query:      entities.UsersQuery{SortBy: "email", MinAge: &minAge, MaxAge: &maxAge},
+			mockExpect: func() {},
+			expected:   entities.UsersPage{},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "sort", Code: validation.CodeInvalidValue, Message: "sort must be user_name or user_age"},
+				{Field: "min_age", Code: validation.CodeOutOfRange, Message: "min_age must not be greater than max_age"},
+			}),
+		},
+	}
 
-	assert.NoError(t, err)
-	assert.Equal(t, users, result)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			result, err := service.GetUsers(tt.query, &gofr.Context{})
+
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expected, result)
+		})
+	}
 }
 
 func Test_GetUsersByName(t *testing.T) {
@@ -56,9 +114,16 @@ func Test_GetUsersByName(t *testing.T) {
 		{
 			name:        "User Not Found",
 			mockReturn:  entities.Users{},
-			mockError:   sql.ErrNoRows,
+			mockError:   apperrors.NotFound("user", "name", "User Not Found"),
 			expected:    entities.Users{},
-			expectedErr: fmt.Errorf("%w, '%s'", http.ErrorEntityNotFound{"name", "name"}, "john"),
+			expectedErr: apperrors.NotFound("user", "name", "User Not Found"),
+		},
+		{
+			name:        "Store Unavailable",
+			mockReturn:  entities.Users{},
+			mockError:   apperrors.Unavailable(errors.New("connection refused")),
+			expected:    entities.Users{},
+			expectedErr: apperrors.Unavailable(errors.New("connection refused")),
 		},
 	}
 
@@ -71,7 +136,7 @@ func Test_GetUsersByName(t *testing.T) {
 			result, err := service.GetUsersByName(tt.name, ctx)
 
 			if tt.expectedErr != nil {
-				assert.EqualError(t, err, tt.expectedErr.Error())
+				assert.Equal(t, tt.expectedErr, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expected, result)
@@ -86,45 +151,64 @@ func Test_AddUsers(t *testing.T) {
 
 	mockStore := NewMockUserStore(ctrl)
 	service := NewUserService(mockStore)
-
-	user := &entities.Users{UserName: "john", PhoneNumber: "1234"}
+	service.newID = func() (uuid.UUID, error) {
+		return uuid.MustParse(testUserID), nil
+	}
 
 	tests := []struct {
 		name        string
-		mockReturn  entities.Users
-		mockError   error
+		user        *entities.Users
+		mockExpect  func()
 		expectedErr error
 	}{
 		{
-			name:        "Valid User",
-			mockReturn:  entities.Users{},
-			mockError:   nil,
+			name: "Valid User",
+			user: &entities.Users{UserName: "john", UserAge: 30, PhoneNumber: "+1 415 555 2671", Email: "john@example.com"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "name", "john")).Times(1)
+				mockStore.EXPECT().AddUsers(&entities.Users{
+					ID: testUserID, UserName: "john", UserAge: 30, PhoneNumber: "+14155552671", Email: "john@example.com"}, gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+			},
 			expectedErr: nil,
 		},
 		{
-			name:        "User Already Exists",
-			mockReturn:  entities.Users{UserName: "john", PhoneNumber: "1234"},
-			mockError:   nil,
-			expectedErr: fmt.Errorf("%w, '%s' already exists", http.ErrorEntityAlreadyExist{}, user.UserName),
+			name: "User Already Exists",
+			user: &entities.Users{UserName: "john", UserAge: 30, PhoneNumber: "+14155552671", Email: "john@example.com"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).
+					Return(entities.Users{UserName: "john", PhoneNumber: "+14155552671"}, nil).Times(1)
+				mockStore.EXPECT().AddUsers(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedErr: apperrors.AlreadyExists("user", "name", "john"),
+		},
+		{
+			name: "Store Unavailable",
+			user: &entities.Users{UserName: "john", UserAge: 30, PhoneNumber: "+14155552671", Email: "john@example.com"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).
+					Return(entities.Users{}, apperrors.Unavailable(errors.New("connection refused"))).Times(1)
+			},
+			expectedErr: apperrors.Unavailable(errors.New("connection refused")),
+		},
+		{
+			name:       "Invalid User",
+			user:       &entities.Users{UserName: "john", PhoneNumber: "1234"},
+			mockExpect: func() {},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "email", Code: validation.CodeRequired, Message: "email is required"},
+				{Field: "phone_Number", Code: validation.CodeMissingCountryCode,
+					Message: "phone number must start with '+' followed by the country calling code"},
+			}),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
 
-			if tt.name == "User Already Exists" {
-
-				mockStore.EXPECT().GetUsersByName(user.UserName, gomock.Any()).Return(tt.mockReturn, nil).Times(1)
-
-				mockStore.EXPECT().AddUsers(gomock.Any(), gomock.Any()).Times(0)
-			} else {
-
-				mockStore.EXPECT().GetUsersByName(user.UserName, gomock.Any()).Return(entities.Users{}, sql.ErrNoRows).Times(1)
-
-				mockStore.EXPECT().AddUsers(user, gomock.Any()).Return(nil).Times(1)
-			}
-
-			err := service.AddUsers(user, &gofr.Context{})
+			err := service.AddUsers(tt.user, &gofr.Context{Context: context.Background()})
 
 			if tt.expectedErr != nil {
 				assert.EqualError(t, err, tt.expectedErr.Error())
@@ -150,15 +234,15 @@ func Test_DeleteUsers(t *testing.T) {
 	}{
 		{
 			name:        "User Exists",
-			mockReturn:  entities.Users{UserName: "john", PhoneNumber: "1234"},
+			mockReturn:  entities.Users{UserName: "john", PhoneNumber: "1234", Version: 3},
 			mockError:   nil,
 			expectedErr: nil,
 		},
 		{
 			name:        "User Not Found",
 			mockReturn:  entities.Users{},
-			mockError:   sql.ErrNoRows,
-			expectedErr: fmt.Errorf("No entity found with name: albert"),
+			mockError:   apperrors.NotFound("user", "name", "User Not Found"),
+			expectedErr: apperrors.NotFound("user", "name", "User Not Found"),
 		},
 	}
 
@@ -168,11 +252,11 @@ func Test_DeleteUsers(t *testing.T) {
 			mockStore.EXPECT().GetUsersByName(tt.name, gomock.Any()).Return(tt.mockReturn, tt.mockError).Times(1)
 
 			if tt.expectedErr != nil {
-				err := service.DeleteUsers(tt.name, &gofr.Context{})
-				assert.EqualError(t, err, tt.expectedErr.Error())
+				err := service.DeleteUsers(tt.name, 0, &gofr.Context{Context: context.Background()})
+				assert.Equal(t, tt.expectedErr, err)
 			} else {
-				mockStore.EXPECT().DeleteUsers(tt.name, gomock.Any()).Return(nil).Times(1)
-				err := service.DeleteUsers(tt.name, &gofr.Context{})
+				mockStore.EXPECT().DeleteUsers(tt.mockReturn, gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				err := service.DeleteUsers(tt.name, 0, &gofr.Context{Context: context.Background()})
 				assert.NoError(t, err)
 			}
 		})
@@ -188,37 +272,1085 @@ func Test_UpdateUsers(t *testing.T) {
 
 	tests := []struct {
 		name        string
-		mockReturn  entities.Users
-		mockError   error
+		version     int
+		updateUser  *entities.Users
+		mockExpect  func()
 		expectedErr error
 	}{
 		{
-			name:        "User Exists",
-			mockReturn:  entities.Users{UserName: "john", PhoneNumber: "1234"},
-			mockError:   nil,
+			name:       "User Exists",
+			updateUser: &entities.Users{UserAge: 20, PhoneNumber: "+44 20 7946 0958", Email: "john@example.com"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).
+					Return(entities.Users{ID: testUserID, UserName: "john", PhoneNumber: "+14155552671", Version: 2}, nil).Times(1)
+				mockStore.EXPECT().UpdateUsers(&entities.Users{ID: testUserID, UserName: "john", UserAge: 20,
+					PhoneNumber: "+442079460958", Email: "john@example.com", Version: 2}, gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+			},
 			expectedErr: nil,
 		},
 		{
-			name:        "User Not Found",
-			mockReturn:  entities.Users{},
-			mockError:   sql.ErrNoRows,
-			expectedErr: fmt.Errorf("No entity found with name: albert"),
+			name:       "Expected Version Matches",
+			version:    2,
+			updateUser: &entities.Users{UserAge: 20, PhoneNumber: "+442079460958", Email: "john@example.com"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).
+					Return(entities.Users{ID: testUserID, UserName: "john", Version: 2}, nil).Times(1)
+				mockStore.EXPECT().UpdateUsers(&entities.Users{ID: testUserID, UserName: "john", UserAge: 20,
+					PhoneNumber: "+442079460958", Email: "john@example.com", Version: 2}, gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:       "Expected Version Is Stale",
+			version:    1,
+			updateUser: &entities.Users{UserAge: 20, PhoneNumber: "+442079460958", Email: "john@example.com"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).
+					Return(entities.Users{ID: testUserID, UserName: "john", Version: 2}, nil).Times(1)
+			},
+			expectedErr: apperrors.PreconditionFailed("user", "name", "john"),
+		},
+		{
+			name:       "Concurrent Write After Read",
+			version:    2,
+			updateUser: &entities.Users{UserAge: 20, PhoneNumber: "+442079460958", Email: "john@example.com"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).
+					Return(entities.Users{ID: testUserID, UserName: "john", Version: 2}, nil).Times(1)
+				mockStore.EXPECT().UpdateUsers(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(apperrors.Conflict("user was modified concurrently", nil)).Times(1)
+			},
+			expectedErr: apperrors.PreconditionFailed("user", "name", "john"),
+		},
+		{
+			name: "Changing Immutable ID",
+			updateUser: &entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-000000000000", PhoneNumber: "+442079460958",
+				Email: "john@example.com"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).
+					Return(entities.Users{ID: testUserID, UserName: "john", PhoneNumber: "+14155552671"}, nil).Times(1)
+			},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "id", Code: validation.CodeImmutable, Message: "id cannot be changed"},
+			}),
+		},
+		{
+			name:       "User Not Found",
+			updateUser: &entities.Users{PhoneNumber: "+442079460958", Email: "john@example.com"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "name", "john")).Times(1)
+			},
+			expectedErr: apperrors.NotFound("user", "name", "john"),
+		},
+		{
+			name:       "Changing Immutable User Name",
+			updateUser: &entities.Users{UserName: "alice", PhoneNumber: "+442079460958", Email: "john@example.com"},
+			mockExpect: func() {},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "user_name", Code: validation.CodeImmutable, Message: "user_name cannot be changed"},
+			}),
+		},
+		{
+			name: "Setting Deleted At",
+			updateUser: &entities.Users{PhoneNumber: "+442079460958", Email: "john@example.com",
+				DeletedAt: &time.Time{}},
+			mockExpect: func() {},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "deleted_at", Code: validation.CodeImmutable, Message: "deleted_at cannot be changed"},
+			}),
+		},
+		{
+			name:       "Missing Phone Number",
+			updateUser: &entities.Users{Email: "john@example.com"},
+			mockExpect: func() {},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "phone_Number", Code: validation.CodeRequired, Message: "phone number is required"},
+			}),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set up mock behavior for GetUsersByName
-			mockStore.EXPECT().GetUsersByName(tt.name, gomock.Any()).Return(tt.mockReturn, tt.mockError).Times(1)
+			tt.mockExpect()
+
+			err := service.UpdateUsers("john", tt.version, tt.updateUser, &gofr.Context{Context: context.Background()})
+
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_PatchUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockUserStore(ctrl)
+	service := NewUserService(mockStore)
+
+	existingUser := entities.Users{ID: testUserID, UserName: "john", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "john@example.com"}
+
+	tests := []struct {
+		name        string
+		patch       map[string]any
+		mockExpect  func()
+		expectedErr error
+	}{
+		{
+			name:  "Only Supplied Fields Change",
+			patch: map[string]any{"user_age": float64(20)},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(existingUser, nil).Times(1)
+				mockStore.EXPECT().UpdateUsers(&entities.Users{ID: testUserID,
+					UserName: "john", UserAge: 20, PhoneNumber: "+14155552671", Email: "john@example.com"}, gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:  "Null Resets Field",
+			patch: map[string]any{"user_age": nil},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(existingUser, nil).Times(1)
+				mockStore.EXPECT().UpdateUsers(&entities.Users{ID: testUserID,
+					UserName: "john", PhoneNumber: "+14155552671", Email: "john@example.com"}, gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:  "Patched User Is Invalid",
+			patch: map[string]any{"email": nil, "user_age": float64(200)},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(existingUser, nil).Times(1)
+			},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "user_age", Code: validation.CodeOutOfRange, Message: "user age must be between 0 and 150"},
+				{Field: "email", Code: validation.CodeRequired, Message: "email is required"},
+			}),
+		},
+		{
+			name:  "Changing Immutable User Name",
+			patch: map[string]any{"user_name": "alice"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(existingUser, nil).Times(1)
+			},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "user_name", Code: validation.CodeImmutable, Message: "user_name cannot be changed"},
+			}),
+		},
+		{
+			name:  "Changing Immutable ID",
+			patch: map[string]any{"id": "0193c6b8-7d2a-7c3e-9f41-000000000000"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(existingUser, nil).Times(1)
+			},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "id", Code: validation.CodeImmutable, Message: "id cannot be changed"},
+			}),
+		},
+		{
+			name:  "Invalid Field Type",
+			patch: map[string]any{"user_age": "twenty"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(existingUser, nil).Times(1)
+			},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "user_age", Code: validation.CodeInvalidType, Message: "user_age must be of type int"},
+			}),
+		},
+		{
+			name:  "Unknown Field",
+			patch: map[string]any{"nickname": "johnny"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(existingUser, nil).Times(1)
+			},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "nickname", Code: validation.CodeUnknownField, Message: "nickname is not a user field"},
+			}),
+		},
+		{
+			name:  "Setting Deleted At",
+			patch: map[string]any{"deleted_at": "2025-01-01T00:00:00Z"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(existingUser, nil).Times(1)
+			},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "deleted_at", Code: validation.CodeImmutable, Message: "deleted_at cannot be changed"},
+			}),
+		},
+		{
+			name:  "Null Deleted At Is Left As It Is",
+			patch: map[string]any{"deleted_at": nil, "user_age": float64(20)},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(existingUser, nil).Times(1)
+				mockStore.EXPECT().UpdateUsers(&entities.Users{ID: testUserID,
+					UserName: "john", UserAge: 20, PhoneNumber: "+14155552671", Email: "john@example.com"}, gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:  "User Not Found",
+			patch: map[string]any{"user_age": float64(20)},
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "name", "john")).Times(1)
+			},
+			expectedErr: apperrors.NotFound("user", "name", "john"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			err := service.PatchUsers("john", 0, tt.patch, &gofr.Context{Context: context.Background()})
 
 			if tt.expectedErr != nil {
-				err := service.UpdateUsers(tt.name, &entities.Users{}, &gofr.Context{})
 				assert.EqualError(t, err, tt.expectedErr.Error())
 			} else {
-				mockStore.EXPECT().UpdateUsers(tt.name, gomock.Any(), gomock.Any()).Return(nil).Times(1)
-				err := service.UpdateUsers(tt.name, &entities.Users{}, &gofr.Context{})
 				assert.NoError(t, err)
 			}
 		})
 	}
 }
+
+const testUserID = "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f"
+
+func Test_GetUsersByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockUserStore(ctrl)
+	service := NewUserService(mockStore)
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse entities.Users
+		expectedErr      error
+	}{
+		{
+			name: "User Found",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).
+					Return(entities.Users{ID: testUserID, UserName: "john"}, nil).Times(1)
+			},
+			expectedResponse: entities.Users{ID: testUserID, UserName: "john"},
+			expectedErr:      nil,
+		},
+		{
+			name: "User Not Found",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "id", testUserID)).Times(1)
+			},
+			expectedResponse: entities.Users{},
+			expectedErr:      apperrors.NotFound("user", "id", testUserID),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			user, err := service.GetUsersByID(testUserID, &gofr.Context{})
+
+			assert.Equal(t, tt.expectedResponse, user, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_WritesByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockUserStore(ctrl)
+	service := NewUserService(mockStore)
+
+	existingUser := entities.Users{ID: testUserID, UserName: "john", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "john@example.com", Version: 2}
+
+	tests := []struct {
+		name        string
+		mockExpect  func()
+		write       func(ctx *gofr.Context) error
+		expectedErr error
+	}{
+		{
+			name: "Update Keeps The Stored Name",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(existingUser, nil).Times(1)
+				mockStore.EXPECT().UpdateUsers(&entities.Users{ID: testUserID, UserName: "john", UserAge: 20,
+					PhoneNumber: "+14155552671", Email: "john@example.com", Version: 2}, gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+			},
+			write: func(ctx *gofr.Context) error {
+				return service.UpdateUsersByID(testUserID, 2, &entities.Users{UserAge: 20, PhoneNumber: "+14155552671",
+					Email: "john@example.com"}, ctx)
+			},
+		},
+		{
+			name: "Update Changing The Name",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(existingUser, nil).Times(1)
+			},
+			write: func(ctx *gofr.Context) error {
+				return service.UpdateUsersByID(testUserID, 0, &entities.Users{UserName: "alice", PhoneNumber: "+14155552671",
+					Email: "john@example.com"}, ctx)
+			},
+			expectedErr: apperrors.Validation(immutableFieldErrors("user_name")),
+		},
+		{
+			name: "Patch Of A Stale Version",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(existingUser, nil).Times(1)
+			},
+			write: func(ctx *gofr.Context) error {
+				return service.PatchUsersByID(testUserID, 1, map[string]any{"user_age": float64(20)}, ctx)
+			},
+			expectedErr: apperrors.PreconditionFailed("user", "id", testUserID),
+		},
+		{
+			name: "Delete Losing The Race",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(existingUser, nil).Times(1)
+				mockStore.EXPECT().DeleteUsers(existingUser, gomock.Any(), gomock.Any()).
+					Return(apperrors.Conflict("user was modified concurrently", nil)).Times(1)
+			},
+			write: func(ctx *gofr.Context) error {
+				return service.DeleteUsersByID(testUserID, 2, ctx)
+			},
+			expectedErr: apperrors.PreconditionFailed("user", "id", testUserID),
+		},
+		{
+			name: "Delete Of A Missing User",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "id", testUserID)).Times(1)
+			},
+			write: func(ctx *gofr.Context) error {
+				return service.DeleteUsersByID(testUserID, 0, ctx)
+			},
+			expectedErr: apperrors.NotFound("user", "id", testUserID),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			err := tt.write(&gofr.Context{Context: context.Background()})
+
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_RenameUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockUserStore(ctrl)
+	service := NewUserService(mockStore)
+
+	existingUser := entities.Users{ID: testUserID, UserName: "john", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "john@example.com"}
+	renamedUser := entities.Users{ID: testUserID, UserName: "johnny", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "john@example.com"}
+
+	tests := []struct {
+		name             string
+		newName          string
+		mockExpect       func()
+		expectedResponse entities.Users
+		expectedErr      error
+	}{
+		{
+			name:    "Renamed",
+			newName: "johnny",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(existingUser, nil).Times(1)
+				mockStore.EXPECT().GetUsersByName("johnny", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "name", "johnny")).Times(1)
+				mockStore.EXPECT().RenameUsers(testUserID, "johnny", existingUser.Version, gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			expectedResponse: renamedUser,
+		},
+		{
+			name:    "Same Name",
+			newName: "john",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(existingUser, nil).Times(1)
+			},
+			expectedResponse: existingUser,
+		},
+		{
+			name:    "Name Taken",
+			newName: "alice",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(existingUser, nil).Times(1)
+				mockStore.EXPECT().GetUsersByName("alice", gomock.Any()).
+					Return(entities.Users{UserName: "alice"}, nil).Times(1)
+			},
+			expectedErr: apperrors.AlreadyExists("user", "name", "alice"),
+		},
+		{
+			name:    "User Not Found",
+			newName: "johnny",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "id", testUserID)).Times(1)
+			},
+			expectedErr: apperrors.NotFound("user", "id", testUserID),
+		},
+		{
+			name:       "Invalid Name",
+			newName:    "jo",
+			mockExpect: func() {},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "user_name", Code: validation.CodeTooShort, Message: "user name must be at least 3 characters"},
+			}),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			user, err := service.RenameUsers(testUserID, tt.newName, &gofr.Context{Context: context.Background()})
+
+			assert.Equal(t, tt.expectedResponse, user, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_RestoreUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockUserStore(ctrl)
+	service := NewUserService(mockStore)
+
+	deletedAt := time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC)
+	deletedUser := entities.Users{ID: testUserID, UserName: "john", DeletedAt: &deletedAt, Version: 2}
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse entities.Users
+		expectedErr      error
+	}{
+		{
+			name: "Restored",
+			mockExpect: func() {
+				mockStore.EXPECT().GetDeletedUsersByName("john", gomock.Any()).Return(deletedUser, nil).Times(1)
+				mockStore.EXPECT().RestoreUsers(deletedUser, gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).
+					Return(entities.Users{ID: testUserID, UserName: "john", Version: 3}, nil).Times(1)
+			},
+			expectedResponse: entities.Users{ID: testUserID, UserName: "john", Version: 3},
+		},
+		{
+			name: "User Not Deleted",
+			mockExpect: func() {
+				mockStore.EXPECT().GetDeletedUsersByName("john", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("deleted user", "name", "john")).Times(1)
+			},
+			expectedErr: apperrors.NotFound("deleted user", "name", "john"),
+		},
+		{
+			name: "User Restored Concurrently",
+			mockExpect: func() {
+				mockStore.EXPECT().GetDeletedUsersByName("john", gomock.Any()).Return(deletedUser, nil).Times(1)
+				mockStore.EXPECT().RestoreUsers(deletedUser, gomock.Any(), gomock.Any()).
+					Return(apperrors.Conflict("user was modified concurrently", nil)).Times(1)
+			},
+			expectedErr: apperrors.Conflict("user was modified concurrently", nil),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			user, err := service.RestoreUsers("john", &gofr.Context{Context: context.Background()})
+
+			assert.Equal(t, tt.expectedResponse, user, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_PurgeUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	mockStore := NewMockUserStore(ctrl)
+	service := NewUserService(mockStore, WithRetention(7*24*time.Hour))
+	service.now = func() time.Time {
+		return now
+	}
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse entities.PurgeResult
+		expectedErr      error
+	}{
+		{
+			name: "Users Older Than Retention Purged",
+			mockExpect: func() {
+				mockStore.EXPECT().PurgeUsers(time.Date(2025, 1, 24, 12, 0, 0, 0, time.UTC), gomock.Any(), gomock.Any()).
+					Return(int64(2), nil).Times(1)
+			},
+			expectedResponse: entities.PurgeResult{Purged: 2},
+		},
+		{
+			name: "Store Unavailable",
+			mockExpect: func() {
+				mockStore.EXPECT().PurgeUsers(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(int64(0), apperrors.Unavailable(errors.New("connection refused"))).Times(1)
+			},
+			expectedErr: apperrors.Unavailable(errors.New("connection refused")),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			result, err := service.PurgeUsers(&gofr.Context{})
+
+			assert.Equal(t, tt.expectedResponse, result, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+// purgeUsers returns the DoAndReturn of a PurgeUsers of the store removing users, whose changes
+// are passed to change.
+func purgeUsers(change gomock.Matcher, users ...entities.Users) any {
+	return func(_ time.Time, purge func(user entities.Users) (*entities.Change, error), _ *gofr.Context) (int64, error) {
+		for _, user := range users {
+			c, err := purge(user)
+			if err != nil {
+				return 0, err
+			}
+
+			change.Matches(c)
+		}
+
+		return int64(len(users)), nil
+	}
+}
+
+// changeMatcher matches any change and keeps the last one it was given.
+type changeMatcher struct {
+	change *entities.Change
+}
+
+func (m *changeMatcher) Matches(x any) bool {
+	m.change, _ = x.(*entities.Change)
+
+	return true
+}
+
+func (*changeMatcher) String() string {
+	return "is a change"
+}
+
+func Test_Events(t *testing.T) {
+	user := entities.Users{ID: testUserID, UserName: "john", UserAge: 30, PhoneNumber: "+14155552671",
+		Email: "john@example.com", Version: 1}
+	userJSON := `{"id":"` + testUserID + `","user_name":"john","user_age":30,"phone_Number":"+14155552671",` +
+		`"email":"john@example.com"}`
+	envelope := func(eventType, payload string) string {
+		return `{"id":"` + testUserID + `","type":"` + eventType + `","version":1,` +
+			`"timestamp":"2024-12-30T12:00:00Z","actor":"local","payload":` + payload + `}`
+	}
+
+	tests := []struct {
+		name            string
+		mockExpect      func(mockStore *MockUserStore, event gomock.Matcher)
+		write           func(s *Service, ctx *gofr.Context) error
+		expectedMessage string
+	}{
+		{
+			name: "created",
+			mockExpect: func(mockStore *MockUserStore, event gomock.Matcher) {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(entities.Users{}, apperrors.NotFound("user", "name", "john"))
+				mockStore.EXPECT().AddUsers(gomock.Any(), event, gomock.Any()).Return(nil)
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				return s.AddUsers(&entities.Users{UserName: "john", UserAge: 30, PhoneNumber: "+14155552671",
+					Email: "john@example.com"}, ctx)
+			},
+			expectedMessage: envelope("user.created", `{"user":`+userJSON+`}`),
+		},
+		{
+			name: "updated",
+			mockExpect: func(mockStore *MockUserStore, event gomock.Matcher) {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(user, nil)
+				mockStore.EXPECT().UpdateUsers(gomock.Any(), event, gomock.Any()).Return(nil)
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				return s.UpdateUsers("john", 0, &entities.Users{UserAge: 31, PhoneNumber: "+14155552671",
+					Email: "john@example.com"}, ctx)
+			},
+			expectedMessage: envelope("user.updated", `{"user":{"id":"`+testUserID+`","user_name":"john","user_age":31,`+
+				`"phone_Number":"+14155552671","email":"john@example.com"},"changes":{"user_age":{"before":30,"after":31}}}`),
+		},
+		{
+			name: "renamed",
+			mockExpect: func(mockStore *MockUserStore, event gomock.Matcher) {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+				mockStore.EXPECT().GetUsersByName("johnny", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "name", "johnny"))
+				mockStore.EXPECT().RenameUsers(testUserID, "johnny", user.Version, event, gomock.Any()).Return(nil)
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				_, err := s.RenameUsers(testUserID, "johnny", ctx)
+
+				return err
+			},
+			expectedMessage: envelope("user.updated", `{"user":{"id":"`+testUserID+`","user_name":"johnny","user_age":30,`+
+				`"phone_Number":"+14155552671","email":"john@example.com"},`+
+				`"changes":{"user_name":{"before":"john","after":"johnny"}}}`),
+		},
+		{
+			name: "deleted",
+			mockExpect: func(mockStore *MockUserStore, event gomock.Matcher) {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(user, nil)
+				mockStore.EXPECT().DeleteUsers(user, event, gomock.Any()).Return(nil)
+			},
+			write:           func(s *Service, ctx *gofr.Context) error { return s.DeleteUsers("john", 0, ctx) },
+			expectedMessage: envelope("user.deleted", `{"user":`+userJSON+`}`),
+		},
+		{
+			name: "restored",
+			mockExpect: func(mockStore *MockUserStore, event gomock.Matcher) {
+				deletedAt := time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC)
+				deletedUser := user
+				deletedUser.DeletedAt = &deletedAt
+
+				mockStore.EXPECT().GetDeletedUsersByName("john", gomock.Any()).Return(deletedUser, nil)
+				mockStore.EXPECT().RestoreUsers(deletedUser, event, gomock.Any()).Return(nil)
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				_, err := s.RestoreUsers("john", ctx)
+
+				return err
+			},
+			expectedMessage: envelope("user.updated", `{"user":`+userJSON+`,`+
+				`"changes":{"deleted_at":{"before":"2024-12-01T12:00:00Z","after":null}}}`),
+		},
+		{
+			name: "purged",
+			mockExpect: func(mockStore *MockUserStore, event gomock.Matcher) {
+				mockStore.EXPECT().PurgeUsers(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(purgeUsers(event, user))
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				_, err := s.PurgeUsers(ctx)
+
+				return err
+			},
+			expectedMessage: envelope("user.purged", `{"user":`+userJSON+`}`),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &gofr.Context{Context: auth.WithPrincipal(context.Background(), &auth.Principal{Name: "local"})}
+
+			mockStore := NewMockUserStore(gomock.NewController(t))
+			s := NewUserService(mockStore, WithEvents("users"))
+			s.newID = func() (uuid.UUID, error) { return uuid.MustParse(testUserID), nil }
+			s.now = func() time.Time { return time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC) }
+
+			change := &changeMatcher{}
+			tt.mockExpect(mockStore, change)
+
+			require.NoError(t, tt.write(s, ctx), "TEST[%d] failed: %s", i, tt.name)
+			require.NotNil(t, change.change, "TEST[%d] failed: %s", i, tt.name)
+
+			event := change.change.Event
+			require.NotNil(t, event, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, "users", event.Topic, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC), event.CreatedAt,
+				"TEST[%d] failed: %s", i, tt.name)
+			assert.JSONEq(t, tt.expectedMessage, string(event.Payload), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_Events_Disabled(t *testing.T) {
+	mockStore := NewMockUserStore(gomock.NewController(t))
+	s := NewUserService(mockStore)
+
+	change := &changeMatcher{}
+
+	mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(entities.Users{UserName: "john", Version: 1}, nil)
+	mockStore.EXPECT().DeleteUsers(entities.Users{UserName: "john", Version: 1}, change, gomock.Any()).Return(nil)
+
+	require.NoError(t, s.DeleteUsers("john", 0, &gofr.Context{Context: context.Background()}))
+	require.NotNil(t, change.change)
+	assert.Nil(t, change.change.Event, "no outbox message is written without a topic")
+}
+
+func Test_ChangeFeed(t *testing.T) {
+	now := time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC)
+	user := entities.Users{ID: testUserID, UserName: "john", UserAge: 30, PhoneNumber: "+14155552671",
+		Email: "john@example.com", Version: 1}
+
+	tests := []struct {
+		name            string
+		mockExpect      func(mockStore *MockUserStore)
+		write           func(s *Service, ctx *gofr.Context) error
+		expectedChanges []entities.UserChange
+	}{
+		{
+			name: "update",
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(user, nil)
+				mockStore.EXPECT().UpdateUsers(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				return s.PatchUsers("john", 0, map[string]any{"user_age": 31}, ctx)
+			},
+			expectedChanges: []entities.UserChange{{Type: "user.updated", UserID: testUserID, UserName: "john",
+				Changes: []byte(`{"user_age":{"before":30,"after":31}}`), Timestamp: now}},
+		},
+		{
+			name: "failed write",
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(user, nil)
+				mockStore.EXPECT().DeleteUsers(user, gomock.Any(), gomock.Any()).
+					Return(apperrors.Conflict("user was modified concurrently", nil))
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				return s.DeleteUsers("john", 0, ctx)
+			},
+		},
+		{
+			name: "restore",
+			mockExpect: func(mockStore *MockUserStore) {
+				deletedAt := time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC)
+				deletedUser := user
+				deletedUser.DeletedAt = &deletedAt
+
+				mockStore.EXPECT().GetDeletedUsersByName("john", gomock.Any()).Return(deletedUser, nil)
+				mockStore.EXPECT().RestoreUsers(deletedUser, gomock.Any(), gomock.Any()).Return(nil)
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				_, err := s.RestoreUsers("john", ctx)
+
+				return err
+			},
+			expectedChanges: []entities.UserChange{{Type: "user.updated", UserID: testUserID, UserName: "john",
+				Changes: []byte(`{"deleted_at":{"before":"2024-12-01T12:00:00Z","after":null}}`), Timestamp: now}},
+		},
+		{
+			name: "purge reports every purged user",
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().PurgeUsers(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(purgeUsers(gomock.Any(), user, entities.Users{ID: testUserID, UserName: "jane"}))
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				_, err := s.PurgeUsers(ctx)
+
+				return err
+			},
+			expectedChanges: []entities.UserChange{
+				{Type: "user.purged", UserID: testUserID, UserName: "john", Changes: []byte(`{"id":{"before":"` + testUserID +
+					`","after":null},"user_name":{"before":"john","after":null},"user_age":{"before":30,"after":null},` +
+					`"phone_Number":{"before":"+14155552671","after":null},"email":{"before":"john@example.com","after":null}}`),
+					Timestamp: now},
+				{Type: "user.purged", UserID: testUserID, UserName: "jane", Changes: []byte(`{"id":{"before":"` + testUserID +
+					`","after":null},"user_name":{"before":"jane","after":null},"user_age":{"before":0,"after":null},` +
+					`"phone_Number":{"before":"","after":null},"email":{"before":"","after":null}}`), Timestamp: now},
+			},
+		},
+		{
+			name: "batch reports the writes that succeeded",
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().GetUsersByNames(gomock.Any(), gomock.Any()).Return([]entities.Users{user}, nil)
+				mockStore.EXPECT().WriteBatch(gomock.Len(2), false, gomock.Any()).
+					Return([]error{apperrors.AlreadyExists("user", "name", "adam"), nil})
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				_, err := s.Batch(entities.BatchRequest{Operations: []entities.BatchOperation{
+					{Op: entities.BatchCreate, User: &entities.Users{UserName: "adam", UserAge: 40,
+						PhoneNumber: "+14155552672", Email: "adam@example.com"}},
+					{Op: entities.BatchDelete, UserName: "john"},
+				}}, ctx)
+
+				return err
+			},
+			expectedChanges: []entities.UserChange{{Type: "user.deleted", UserID: testUserID, UserName: "john",
+				Changes:   []byte(`{"deleted_at":{"before":null,"after":"2024-12-30T12:00:00Z"}}`),
+				Timestamp: now}},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &gofr.Context{Context: auth.WithPrincipal(context.Background(), &auth.Principal{Name: "local"})}
+
+			ctrl := gomock.NewController(t)
+			mockStore := NewMockUserStore(ctrl)
+			mockFeed := NewMockChangeFeed(ctrl)
+			s := NewUserService(mockStore, WithChangeFeed(mockFeed))
+			s.newID = func() (uuid.UUID, error) { return uuid.MustParse(testUserID), nil }
+			s.now = func() time.Time { return now }
+
+			var published []entities.UserChange
+
+			mockFeed.EXPECT().Publish(gomock.Any()).Do(func(change entities.UserChange) {
+				published = append(published, change)
+			}).AnyTimes()
+			tt.mockExpect(mockStore)
+
+			_ = tt.write(s, ctx)
+
+			require.Len(t, published, len(tt.expectedChanges), "TEST[%d] failed: %s", i, tt.name)
+
+			for j := range published {
+				assert.JSONEq(t, string(tt.expectedChanges[j].Changes), string(published[j].Changes),
+					"TEST[%d] failed: %s", i, tt.name)
+
+				tt.expectedChanges[j].Changes, published[j].Changes = nil, nil
+			}
+
+			assert.Equal(t, tt.expectedChanges, published, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_Audit(t *testing.T) {
+	now := time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC)
+	user := entities.Users{ID: testUserID, UserName: "john", UserAge: 30, PhoneNumber: "+14155552671",
+		Email: "john@example.com", Version: 1}
+
+	tests := []struct {
+		name          string
+		mockExpect    func(mockStore *MockUserStore, change gomock.Matcher)
+		write         func(s *Service, ctx *gofr.Context) error
+		expectedAudit entities.AuditEntry
+	}{
+		{
+			name: "create",
+			mockExpect: func(mockStore *MockUserStore, change gomock.Matcher) {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(entities.Users{}, apperrors.NotFound("user", "name", "john"))
+				mockStore.EXPECT().AddUsers(gomock.Any(), change, gomock.Any()).Return(nil)
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				return s.AddUsers(&entities.Users{UserName: "john", UserAge: 30, PhoneNumber: "+14155552671",
+					Email: "john@example.com"}, ctx)
+			},
+			expectedAudit: entities.AuditEntry{Action: entities.AuditCreate, UserID: testUserID, UserName: "john",
+				Changes: []byte(`{"id":{"before":null,"after":"` + testUserID + `"},` +
+					`"user_name":{"before":null,"after":"john"},"user_age":{"before":null,"after":30},` +
+					`"phone_Number":{"before":null,"after":"+14155552671"},"email":{"before":null,"after":"john@example.com"}}`)},
+		},
+		{
+			name: "update",
+			mockExpect: func(mockStore *MockUserStore, change gomock.Matcher) {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(user, nil)
+				mockStore.EXPECT().UpdateUsers(gomock.Any(), change, gomock.Any()).Return(nil)
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				return s.PatchUsers("john", 0, map[string]any{"email": "j@example.com"}, ctx)
+			},
+			expectedAudit: entities.AuditEntry{Action: entities.AuditUpdate, UserID: testUserID, UserName: "john",
+				Changes: []byte(`{"email":{"before":"john@example.com","after":"j@example.com"}}`)},
+		},
+		{
+			name: "rename",
+			mockExpect: func(mockStore *MockUserStore, change gomock.Matcher) {
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+				mockStore.EXPECT().GetUsersByName("johnny", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "name", "johnny"))
+				mockStore.EXPECT().RenameUsers(testUserID, "johnny", user.Version, change, gomock.Any()).Return(nil)
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				_, err := s.RenameUsers(testUserID, "johnny", ctx)
+
+				return err
+			},
+			expectedAudit: entities.AuditEntry{Action: entities.AuditRename, UserID: testUserID, UserName: "johnny",
+				Changes: []byte(`{"user_name":{"before":"john","after":"johnny"}}`)},
+		},
+		{
+			name: "delete",
+			mockExpect: func(mockStore *MockUserStore, change gomock.Matcher) {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(user, nil)
+				mockStore.EXPECT().DeleteUsers(user, change, gomock.Any()).Return(nil)
+			},
+			write: func(s *Service, ctx *gofr.Context) error { return s.DeleteUsers("john", 0, ctx) },
+			expectedAudit: entities.AuditEntry{Action: entities.AuditDelete, UserID: testUserID, UserName: "john",
+				Changes: []byte(`{"deleted_at":{"before":null,"after":"2024-12-30T12:00:00Z"}}`)},
+		},
+		{
+			name: "restore",
+			mockExpect: func(mockStore *MockUserStore, change gomock.Matcher) {
+				deletedAt := time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC)
+				deletedUser := user
+				deletedUser.DeletedAt = &deletedAt
+
+				mockStore.EXPECT().GetDeletedUsersByName("john", gomock.Any()).Return(deletedUser, nil)
+				mockStore.EXPECT().RestoreUsers(deletedUser, change, gomock.Any()).Return(nil)
+				mockStore.EXPECT().GetUsersByID(testUserID, gomock.Any()).Return(user, nil)
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				_, err := s.RestoreUsers("john", ctx)
+
+				return err
+			},
+			expectedAudit: entities.AuditEntry{Action: entities.AuditRestore, UserID: testUserID, UserName: "john",
+				Changes: []byte(`{"deleted_at":{"before":"2024-12-01T12:00:00Z","after":null}}`)},
+		},
+		{
+			name: "purge",
+			mockExpect: func(mockStore *MockUserStore, change gomock.Matcher) {
+				mockStore.EXPECT().PurgeUsers(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(purgeUsers(change, user))
+			},
+			write: func(s *Service, ctx *gofr.Context) error {
+				_, err := s.PurgeUsers(ctx)
+
+				return err
+			},
+			expectedAudit: entities.AuditEntry{Action: entities.AuditPurge, UserID: testUserID, UserName: "john",
+				Changes: []byte(`{"id":{"before":"` + testUserID + `","after":null},` +
+					`"user_name":{"before":"john","after":null},"user_age":{"before":30,"after":null},` +
+					`"phone_Number":{"before":"+14155552671","after":null},"email":{"before":"john@example.com","after":null}}`)},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestHeaders := http.Header{}
+			requestHeaders.Set("X-Request-ID", "req-1")
+
+			ctx := &gofr.Context{Context: headers.With(
+				auth.WithPrincipal(context.Background(), &auth.Principal{Name: "local"}), requestHeaders, http.Header{})}
+
+			mockStore := NewMockUserStore(gomock.NewController(t))
+			s := NewUserService(mockStore)
+			s.newID = func() (uuid.UUID, error) { return uuid.MustParse(testUserID), nil }
+			s.now = func() time.Time { return now }
+
+			change := &changeMatcher{}
+			tt.mockExpect(mockStore, change)
+
+			require.NoError(t, tt.write(s, ctx), "TEST[%d] failed: %s", i, tt.name)
+			require.NotNil(t, change.change, "TEST[%d] failed: %s", i, tt.name)
+
+			audit := change.change.Audit
+			assert.JSONEq(t, string(tt.expectedAudit.Changes), string(audit.Changes), "TEST[%d] failed: %s", i, tt.name)
+
+			tt.expectedAudit.Changes, audit.Changes = nil, nil
+			tt.expectedAudit.Actor, tt.expectedAudit.RequestID, tt.expectedAudit.Timestamp = "local", "req-1", now
+			assert.Equal(t, tt.expectedAudit, audit, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_GetAuditLog(t *testing.T) {
+	mockStore := NewMockUserStore(gomock.NewController(t))
+	service := NewUserService(mockStore)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		query            entities.AuditQuery
+		mockExpect       func()
+		expectedResponse entities.AuditPage
+		expectedErr      error
+	}{
+		{
+			name:  "Default Limit",
+			query: entities.AuditQuery{Actor: "local"},
+			mockExpect: func() {
+				mockStore.EXPECT().GetAuditLog(entities.AuditQuery{Limit: DefaultUsersLimit, Actor: "local"}, gomock.Any()).
+					Return(entities.AuditPage{Entries: []entities.AuditEntry{{ID: 1, Actor: "local"}}}, nil)
+			},
+			expectedResponse: entities.AuditPage{Entries: []entities.AuditEntry{{ID: 1, Actor: "local"}}},
+		},
+		{
+			name:       "Invalid Limit And Time Range",
+			query:      entities.AuditQuery{Limit: 500, From: from, To: from},
+			mockExpect: func() {},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "limit", Code: validation.CodeOutOfRange, Message: "limit must be between 1 and 100"},
+				{Field: "from", Code: validation.CodeOutOfRange, Message: "from must be before to"},
+			}),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			page, err := service.GetAuditLog(tt.query, &gofr.Context{})
+
+			assert.Equal(t, tt.expectedResponse, page, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_GetUserHistory(t *testing.T) {
+	mockStore := NewMockUserStore(gomock.NewController(t))
+	service := NewUserService(mockStore)
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse entities.AuditPage
+		expectedErr      error
+	}{
+		{
+			name: "History Of The User Id",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).Return(entities.Users{ID: testUserID, UserName: "john"}, nil)
+				mockStore.EXPECT().GetAuditLog(entities.AuditQuery{Limit: 5, UserID: testUserID}, gomock.Any()).
+					Return(entities.AuditPage{Entries: []entities.AuditEntry{{ID: 2, UserID: testUserID}}}, nil)
+			},
+			expectedResponse: entities.AuditPage{Entries: []entities.AuditEntry{{ID: 2, UserID: testUserID}}},
+		},
+		{
+			name: "Unknown User",
+			mockExpect: func() {
+				mockStore.EXPECT().GetUsersByName("john", gomock.Any()).
+					Return(entities.Users{}, apperrors.NotFound("user", "name", "john"))
+			},
+			expectedErr: apperrors.NotFound("user", "name", "john"),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			page, err := service.GetUserHistory("john", entities.AuditQuery{Limit: 5}, &gofr.Context{})
+
+			assert.Equal(t, tt.expectedResponse, page, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
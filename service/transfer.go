@@ -0,0 +1,142 @@
+package service
+
+import (
+	"gofr.dev/pkg/gofr"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// importChunk is how many rows of an import are checked and written together.
+const importChunk = 500
+
+// ExportUsers calls fn with every user matching the filters of the query, in the requested order,
+// as the store reads them. Limit and cursor of the query are ignored, an export holds all users.
+func (s *Service) ExportUsers(query entities.UsersQuery, fn func(user entities.Users) error, ctx *gofr.Context) error {
+	if query.SortBy == "" {
+		query.SortBy = entities.SortByUserName
+	}
+
+	query.Limit, query.After = 0, nil
+
+	if errs := usersFilterErrors(query); len(errs) > 0 {
+		return apperrors.Validation(errs)
+	}
+
+	return s.store.StreamUsers(query, fn, ctx)
+}
+
+// ImportUsers creates the users of rows, or with options.Upsert updates the users they name if they
+// exist. Rows are checked like the operations of a batch and written importChunk rows at a time,
+// every row succeeds or fails on its own. Users are matched by name, ids of the rows are ignored,
+// and a user may only be named by one row. With options.DryRun the rows are checked but not written.
+func (s *Service) ImportUsers(rows iter.Seq[entities.ImportRow], options entities.ImportOptions,
+	ctx *gofr.Context) entities.ImportResult {
+	result := entities.ImportResult{DryRun: options.DryRun, Upsert: options.Upsert, Errors: []entities.ImportError{}}
+	named := make(map[string]int)
+	chunk := make([]entities.ImportRow, 0, importChunk)
+
+	for row := range rows {
+		chunk = append(chunk, row)
+
+		if len(chunk) == importChunk {
+			s.importRows(chunk, named, options, &result, ctx)
+			chunk = chunk[:0]
+		}
+	}
+
+	if len(chunk) > 0 {
+		s.importRows(chunk, named, options, &result, ctx)
+	}
+
+	return result
+}
+
+// importRows imports a chunk of rows, adding their outcome to result.
+func (s *Service) importRows(rows []entities.ImportRow, named map[string]int, options entities.ImportOptions,
+	result *entities.ImportResult, ctx *gofr.Context) {
+	// Report the errors of the chunk in the order of its lines, wherever they were found.
+	first := len(result.Errors)
+	defer func() {
+		slices.SortStableFunc(result.Errors[first:], func(a, b entities.ImportError) int {
+			return a.Line - b.Line
+		})
+	}()
+
+	names := make([]string, 0, len(rows))
+	for i := range rows {
+		if rows[i].Err == nil && rows[i].User.UserName != "" {
+			names = append(names, rows[i].User.UserName)
+		}
+	}
+
+	existing, err := s.existingUsers(names, ctx)
+	if err != nil {
+		for i := range rows {
+			importFailed(result, &rows[i], err)
+		}
+
+		return
+	}
+
+	writes := make([]entities.BatchWrite, 0, len(rows))
+	written := make([]*entities.ImportRow, 0, len(rows))
+
+	for i := range rows {
+		write, err := s.importWrite(&rows[i], named, existing, options, ctx)
+		if err != nil {
+			importFailed(result, &rows[i], err)
+			continue
+		}
+
+		writes = append(writes, write)
+		written = append(written, &rows[i])
+	}
+
+	errs := make([]error, len(writes))
+	if !options.DryRun && len(writes) > 0 {
+		errs = s.store.WriteBatch(writes, false, ctx)
+		s.notifyWrites(writes, errs)
+	}
+
+	for j, row := range written {
+		switch {
+		case errs[j] != nil:
+			importFailed(result, row, errs[j])
+		case writes[j].Op == entities.BatchCreate:
+			result.Created++
+		default:
+			result.Updated++
+		}
+	}
+}
+
+// importWrite checks row against the existing users and returns the write the store applies for it.
+func (s *Service) importWrite(row *entities.ImportRow, named map[string]int, existing map[string]entities.Users,
+	options entities.ImportOptions, ctx *gofr.Context) (entities.BatchWrite, error) {
+	if row.Err != nil {
+		return entities.BatchWrite{}, row.Err
+	}
+
+	user := row.User
+	user.ID, user.DeletedAt = "", nil
+
+	if err := nameOnce(named, user.UserName, row.Line, "line"); err != nil {
+		return entities.BatchWrite{}, err
+	}
+
+	if options.Upsert {
+		if _, ok := existing[strings.ToLower(user.UserName)]; ok {
+			return s.batchUpdate(entities.BatchOperation{Op: entities.BatchUpdate, UserName: user.UserName}, user, existing, ctx)
+		}
+	}
+
+	return s.batchCreate(user, existing, ctx)
+}
+
+func importFailed(result *entities.ImportResult, row *entities.ImportRow, err error) {
+	result.Failed++
+	result.Errors = append(result.Errors, entities.ImportError{Line: row.Line, UserName: row.User.UserName, Error: err})
+}
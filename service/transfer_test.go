@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gofr.dev/pkg/gofr"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"gofrProject/validation"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_ExportUsers(t *testing.T) {
+	minAge, maxAge := 30, 20
+	waheed := entities.Users{ID: testUserID, UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+		Email: "waheed@example.com"}
+
+	tests := []struct {
+		name          string
+		query         entities.UsersQuery
+		mockExpect    func(mockStore *MockUserStore)
+		expectedUsers []entities.Users
+		expectedErr   error
+	}{
+		{
+			name:  "all users in the default order",
+			query: entities.UsersQuery{Limit: 5, After: &entities.UserCursor{UserName: "adam"}},
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().StreamUsers(entities.UsersQuery{SortBy: entities.SortByUserName}, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ entities.UsersQuery, fn func(entities.Users) error, _ *gofr.Context) error {
+						return fn(waheed)
+					})
+			},
+			expectedUsers: []entities.Users{waheed},
+		},
+		{
+			name:       "invalid filters",
+			query:      entities.UsersQuery{SortBy: "email", MinAge: &minAge, MaxAge: &maxAge},
+			mockExpect: func(*MockUserStore) {},
+			expectedErr: apperrors.Validation(validation.Errors{
+				{Field: "sort", Code: validation.CodeInvalidValue, Message: "sort must be user_name or user_age"},
+				{Field: "min_age", Code: validation.CodeOutOfRange, Message: "min_age must not be greater than max_age"},
+			}),
+		},
+		{
+			name:  "store error",
+			query: entities.UsersQuery{},
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().StreamUsers(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+			},
+			expectedErr: errors.New("db error"),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := NewMockUserStore(gomock.NewController(t))
+			s := NewUserService(mockStore)
+
+			tt.mockExpect(mockStore)
+
+			var users []entities.Users
+
+			err := s.ExportUsers(tt.query, func(user entities.Users) error {
+				users = append(users, user)
+				return nil
+			}, &gofr.Context{Context: context.Background()})
+
+			assert.Equal(t, tt.expectedUsers, users, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_ImportUsers(t *testing.T) {
+	adam := entities.Users{ID: "ignored", UserName: "adam", UserAge: 40, PhoneNumber: "+14155552671",
+		Email: "adam@example.com"}
+	carol := entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", UserName: "carol", UserAge: 30,
+		PhoneNumber: "+14155552672", Email: "carol@example.com", Version: 2}
+	newCarol := carol
+	newCarol.ID, newCarol.UserAge = "", 31
+	unreadable := apperrors.Validation(errors.New("record has 2 fields, the header has 4"))
+
+	rows := []entities.ImportRow{
+		{Line: 2, User: adam},
+		{Line: 3, User: newCarol},
+		{Line: 4, Err: unreadable},
+		{Line: 5, User: entities.Users{UserName: "ADAM", UserAge: 41, PhoneNumber: "+14155552671", Email: "adam@example.com"}},
+	}
+	usedName := apperrors.Validation(validation.Errors{{Field: "user_name", Code: validation.CodeInvalidValue,
+		Message: "user_name is already used by line 2"}})
+
+	tests := []struct {
+		name           string
+		options        entities.ImportOptions
+		mockExpect     func(mockStore *MockUserStore)
+		expectedResult entities.ImportResult
+	}{
+		{
+			name:    "existing users fail without upsert",
+			options: entities.ImportOptions{},
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().GetUsersByNames([]string{"adam", "carol", "ADAM"}, gomock.Any()).
+					Return([]entities.Users{carol}, nil)
+				mockStore.EXPECT().WriteBatch(gomock.Any(), false, gomock.Any()).
+					DoAndReturn(func(writes []entities.BatchWrite, _ bool, _ *gofr.Context) []error {
+						assert.Len(t, writes, 1)
+						assert.Equal(t, testUserID, writes[0].User.ID, "ids of the file are ignored")
+
+						return []error{nil}
+					})
+			},
+			expectedResult: entities.ImportResult{Created: 1, Failed: 3, Errors: []entities.ImportError{
+				{Line: 3, UserName: "carol", Error: apperrors.AlreadyExists("user", "name", "carol")},
+				{Line: 4, Error: unreadable},
+				{Line: 5, UserName: "ADAM", Error: usedName},
+			}},
+		},
+		{
+			name:    "upsert updates existing users",
+			options: entities.ImportOptions{Upsert: true},
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().GetUsersByNames(gomock.Any(), gomock.Any()).Return([]entities.Users{carol}, nil)
+				mockStore.EXPECT().WriteBatch(gomock.Any(), false, gomock.Any()).
+					DoAndReturn(func(writes []entities.BatchWrite, _ bool, _ *gofr.Context) []error {
+						assert.Equal(t, entities.BatchUpdate, writes[1].Op)
+						assert.Equal(t, carol.ID, writes[1].User.ID)
+						assert.Equal(t, 2, writes[1].User.Version)
+
+						return []error{nil, apperrors.Conflict("user was modified concurrently", nil)}
+					})
+			},
+			expectedResult: entities.ImportResult{Upsert: true, Created: 1, Failed: 3, Errors: []entities.ImportError{
+				{Line: 3, UserName: "carol", Error: apperrors.Conflict("user was modified concurrently", nil)},
+				{Line: 4, Error: unreadable},
+				{Line: 5, UserName: "ADAM", Error: usedName},
+			}},
+		},
+		{
+			name:    "dry run writes nothing",
+			options: entities.ImportOptions{DryRun: true, Upsert: true},
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().GetUsersByNames(gomock.Any(), gomock.Any()).Return([]entities.Users{carol}, nil)
+			},
+			expectedResult: entities.ImportResult{DryRun: true, Upsert: true, Created: 1, Updated: 1, Failed: 2,
+				Errors: []entities.ImportError{
+					{Line: 4, Error: unreadable},
+					{Line: 5, UserName: "ADAM", Error: usedName},
+				}},
+		},
+		{
+			name:    "users cannot be read",
+			options: entities.ImportOptions{},
+			mockExpect: func(mockStore *MockUserStore) {
+				mockStore.EXPECT().GetUsersByNames(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expectedResult: entities.ImportResult{Failed: 4, Errors: []entities.ImportError{
+				{Line: 2, UserName: "adam", Error: errors.New("db error")},
+				{Line: 3, UserName: "carol", Error: errors.New("db error")},
+				{Line: 4, Error: errors.New("db error")},
+				{Line: 5, UserName: "ADAM", Error: errors.New("db error")},
+			}},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := NewMockUserStore(gomock.NewController(t))
+			s := NewUserService(mockStore)
+			s.newID = func() (uuid.UUID, error) { return uuid.MustParse(testUserID), nil }
+			s.now = func() time.Time { return time.Date(2025, 1, 12, 12, 0, 0, 0, time.UTC) }
+
+			tt.mockExpect(mockStore)
+
+			result := s.ImportUsers(slices.Values(rows), tt.options, &gofr.Context{Context: context.Background()})
+
+			assert.Equal(t, tt.expectedResult, result, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func Test_ImportUsers_Chunks(t *testing.T) {
+	rows := make([]entities.ImportRow, importChunk+1)
+	for i := range rows {
+		rows[i] = entities.ImportRow{Line: i + 2, User: entities.Users{UserName: "user" + strconv.Itoa(i), UserAge: 20,
+			PhoneNumber: "+14155552671", Email: "user@example.com"}}
+	}
+
+	mockStore := NewMockUserStore(gomock.NewController(t))
+	s := NewUserService(mockStore)
+
+	for _, size := range []int{importChunk, 1} {
+		mockStore.EXPECT().GetUsersByNames(gomock.Len(size), gomock.Any()).Return(nil, nil)
+		mockStore.EXPECT().WriteBatch(gomock.Len(size), false, gomock.Any()).Return(make([]error, size))
+	}
+
+	result := s.ImportUsers(slices.Values(rows), entities.ImportOptions{}, &gofr.Context{Context: context.Background()})
+
+	assert.Equal(t, entities.ImportResult{Created: importChunk + 1, Errors: []entities.ImportError{}}, result)
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"gofr.dev/pkg/gofr"
+	"gofrProject/entities"
+)
+
+// GetAuditLog returns one page of the audit log entries matching the query, newest first.
+func (userStore *UsersList) GetAuditLog(query entities.AuditQuery, ctx *gofr.Context) (entities.AuditPage, error) {
+	where, args := auditFilter(query)
+
+	// Fetch one extra row to find out whether there is a next page.
+	rows, err := ctx.SQL.Query(sqlFor(ctx, `SELECT ID, Actor, Action, UserID, UserName, Changes, RequestID, CreatedAt `+
+		`FROM "UserAudit"`+where+" ORDER BY ID DESC LIMIT ?"), append(args, query.Limit+1)...)
+	if err != nil {
+		return entities.AuditPage{}, mapError(err)
+	}
+	defer rows.Close()
+
+	entries := make([]entities.AuditEntry, 0, query.Limit)
+
+	for rows.Next() {
+		var (
+			entry   entities.AuditEntry
+			changes []byte
+		)
+
+		if err := rows.Scan(&entry.ID, &entry.Actor, &entry.Action, &entry.UserID, &entry.UserName, &changes,
+			&entry.RequestID, &entry.Timestamp); err != nil {
+			return entities.AuditPage{}, mapError(err)
+		}
+
+		entry.Changes = changes
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return entities.AuditPage{}, mapError(err)
+	}
+
+	page := entities.AuditPage{Entries: entries}
+
+	if len(entries) > query.Limit {
+		page.Entries = entries[:query.Limit]
+		page.NextCursor = entities.AuditCursor{ID: page.Entries[query.Limit-1].ID}.Encode()
+	}
+
+	return page, nil
+}
+
+// auditFilter builds the WHERE clause for the filters and the cursor of the query.
+func auditFilter(query entities.AuditQuery) (string, []any) {
+	var (
+		where string
+		args  []any
+	)
+
+	if query.UserID != "" {
+		where = appendCondition(where, "UserID = ?")
+		args = append(args, query.UserID)
+	}
+
+	if query.Actor != "" {
+		where = appendCondition(where, "Actor = ?")
+		args = append(args, query.Actor)
+	}
+
+	if !query.From.IsZero() {
+		where = appendCondition(where, "CreatedAt >= ?")
+		args = append(args, query.From.UTC())
+	}
+
+	if !query.To.IsZero() {
+		where = appendCondition(where, "CreatedAt < ?")
+		args = append(args, query.To.UTC())
+	}
+
+	if query.Before != nil {
+		where = appendCondition(where, "ID < ?")
+		args = append(args, query.Before.ID)
+	}
+
+	return where, args
+}
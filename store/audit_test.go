@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+	"gofr.dev/pkg/gofr/datasource"
+	"gofrProject/entities"
+)
+
+func TestGetAuditLog(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"ID", "Actor", "Action", "UserID", "UserName", "Changes", "RequestID", "CreatedAt"}
+	selectAudit := "SELECT ID, Actor, Action, UserID, UserName, Changes, RequestID, CreatedAt FROM `UserAudit`"
+
+	tests := []struct {
+		name             string
+		query            entities.AuditQuery
+		mockExpect       func()
+		expectedResponse entities.AuditPage
+		expectedError    error
+	}{
+		{
+			name:  "History of a user with a next page",
+			query: entities.AuditQuery{Limit: 1, UserID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f"},
+			mockExpect: func() {
+				mock.SQL.ExpectQuery(selectAudit+" WHERE UserID = ? ORDER BY ID DESC LIMIT ?").
+					WithArgs("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 2).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(7, "local", "update", "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", "John Doe",
+							`{"user_age":{"before":30,"after":31}}`, "req-1", from).
+						AddRow(6, "local", "create", "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", "John Doe", `{}`, "", from))
+			},
+			expectedResponse: entities.AuditPage{
+				Entries: []entities.AuditEntry{{ID: 7, Actor: "local", Action: "update",
+					UserID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", UserName: "John Doe",
+					Changes: []byte(`{"user_age":{"before":30,"after":31}}`), RequestID: "req-1", Timestamp: from}},
+				NextCursor: entities.AuditCursor{ID: 7}.Encode(),
+			},
+		},
+		{
+			name:  "Entries of an actor in a time range after a cursor",
+			query: entities.AuditQuery{Limit: 20, Actor: "local", From: from, To: to, Before: &entities.AuditCursor{ID: 7}},
+			mockExpect: func() {
+				mock.SQL.ExpectQuery(selectAudit+" WHERE Actor = ? AND CreatedAt >= ? AND CreatedAt < ? AND ID < ? "+
+					"ORDER BY ID DESC LIMIT ?").
+					WithArgs("local", from, to, int64(7), 21).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedResponse: entities.AuditPage{Entries: []entities.AuditEntry{}},
+		},
+		{
+			name:  "Error while reading the audit log",
+			query: entities.AuditQuery{Limit: 20},
+			mockExpect: func() {
+				mock.SQL.ExpectQuery(selectAudit + " ORDER BY ID DESC LIMIT ?").WithArgs(21).
+					WillReturnError(fmt.Errorf("db error"))
+			},
+			expectedError: datasource.ErrorDB{Err: fmt.Errorf("db error"), Message: "error from sql db"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			store := NewDetails()
+			page, err := store.GetAuditLog(tt.query, ctx)
+
+			assert.Equal(t, tt.expectedResponse, page, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package store
+
+import (
+	"gofr.dev/pkg/gofr"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"slices"
+	"strings"
+)
+
+// batchRows is the most rows a multi-row statement of a batch writes or reads. It keeps the number
+// of placeholders of a statement below the limits of MySQL, PostgreSQL and SQLite.
+const batchRows = 500
+
+// GetUsersByNames retrieves the users with the given names that are not deleted, in no particular
+// order. Names that match no user are left out.
+func (userStore *UsersList) GetUsersByNames(names []string, ctx *gofr.Context) ([]entities.Users, error) {
+	users := make([]entities.Users, 0, len(names))
+
+	for chunk := range slices.Chunk(names, batchRows) {
+		args := make([]any, len(chunk))
+		for i, name := range chunk {
+			args[i] = name
+		}
+
+		found, err := queryUsers(ctx.SQL, sqlFor(ctx, "SELECT "+userColumns+` FROM "User" WHERE UserName IN (`+
+			placeholders(len(chunk))+") AND "+notDeleted), args)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, found...)
+	}
+
+	return users, nil
+}
+
+// queryUsers runs query and scans the users it returns.
+func queryUsers(db execer, query string, args []any) ([]entities.Users, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	var users []entities.Users
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, mapError(err)
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, mapError(err)
+	}
+
+	return users, nil
+}
+
+// WriteBatch applies writes and returns the error of each of them, nil for the writes that were
+// applied. Created users are inserted with multi-row statements of up to batchRows users, updates
+// and deletes are guarded by the version of the user like UpdateUsers and DeleteUsers.
+//
+// If atomic is set, all writes and their changes are made in one transaction: either all of them
+// are applied, or the write that failed reports its error and all others are aborted. Otherwise
+// every multi-row insert and every update or delete is a transaction of its own, and the users of
+// a failed insert are inserted one at a time to find out which of them failed.
+func (userStore *UsersList) WriteBatch(writes []entities.BatchWrite, atomic bool, ctx *gofr.Context) []error {
+	if atomic {
+		return writeAtomic(writes, ctx)
+	}
+
+	errs := make([]error, len(writes))
+	creates, others := splitCreates(writes)
+
+	for chunk := range slices.Chunk(creates, batchRows) {
+		err := transaction(ctx, changesAt(writes, chunk), func(db execer) error {
+			return insertUsers(db, usersAt(writes, chunk), ctx)
+		})
+		if err == nil {
+			continue
+		}
+
+		for _, i := range chunk {
+			errs[i] = userStore.AddUsers(&writes[i].User, writes[i].Change, ctx)
+		}
+	}
+
+	for _, i := range others {
+		errs[i] = write(ctx, writes[i].Change, func(db execer) error {
+			return applyWrite(db, &writes[i], ctx)
+		})
+	}
+
+	return errs
+}
+
+// writeAtomic applies writes in one transaction.
+func writeAtomic(writes []entities.BatchWrite, ctx *gofr.Context) []error {
+	tx, err := ctx.SQL.Begin()
+	if err != nil {
+		return fill(len(writes), mapError(err))
+	}
+
+	failed, err := applyAll(tx, writes, ctx)
+	if err != nil {
+		_ = tx.Rollback()
+
+		if len(failed) == 0 {
+			return fill(len(writes), err)
+		}
+
+		index := failed[0]
+		if len(failed) > 1 {
+			index, err = culprit(writes, failed, err, ctx)
+		}
+
+		return abortBatch(len(writes), index, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fill(len(writes), mapError(err))
+	}
+
+	return make([]error, len(writes))
+}
+
+// applyAll runs the statements of all writes and records their changes in tx. If a statement
+// fails, it returns its error and the indexes of the writes it was run for.
+func applyAll(tx execer, writes []entities.BatchWrite, ctx *gofr.Context) ([]int, error) {
+	creates, others := splitCreates(writes)
+
+	for chunk := range slices.Chunk(creates, batchRows) {
+		if err := insertUsers(tx, usersAt(writes, chunk), ctx); err != nil {
+			return chunk, err
+		}
+	}
+
+	for _, i := range others {
+		if err := applyWrite(tx, &writes[i], ctx); err != nil {
+			return []int{i}, err
+		}
+	}
+
+	changes := make([]*entities.Change, 0, len(writes))
+	for i := range writes {
+		if writes[i].Change != nil {
+			changes = append(changes, writes[i].Change)
+		}
+	}
+
+	return nil, mapError(record(tx, changes, ctx))
+}
+
+// culprit finds the created user responsible for the error of a multi-row insert of the writes at
+// the indexes of chunk, by inserting them one at a time in a transaction that is rolled back. It
+// returns the first write of chunk and err if none of them fails on its own.
+func culprit(writes []entities.BatchWrite, chunk []int, err error, ctx *gofr.Context) (int, error) {
+	tx, beginErr := ctx.SQL.Begin()
+	if beginErr != nil {
+		return chunk[0], err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	for _, i := range chunk {
+		if insertErr := insertUsers(tx, []*entities.Users{&writes[i].User}, ctx); insertErr != nil {
+			return i, insertErr
+		}
+	}
+
+	return chunk[0], err
+}
+
+// applyWrite runs the update or delete of w.
+func applyWrite(db execer, w *entities.BatchWrite, ctx *gofr.Context) error {
+	if w.Op == entities.BatchDelete {
+		return deleteRow(db, w.User.ID, w.User.Version, ctx)
+	}
+
+	return updateRow(db, &w.User, ctx)
+}
+
+// splitCreates returns the indexes of the writes creating a user and of the other writes.
+func splitCreates(writes []entities.BatchWrite) (creates, others []int) {
+	for i := range writes {
+		if writes[i].Op == entities.BatchCreate {
+			creates = append(creates, i)
+		} else {
+			others = append(others, i)
+		}
+	}
+
+	return creates, others
+}
+
+func usersAt(writes []entities.BatchWrite, indexes []int) []*entities.Users {
+	users := make([]*entities.Users, len(indexes))
+	for j, i := range indexes {
+		users[j] = &writes[i].User
+	}
+
+	return users
+}
+
+// changesAt returns the changes of the writes at indexes, leaving out those that are nil.
+func changesAt(writes []entities.BatchWrite, indexes []int) []*entities.Change {
+	changes := make([]*entities.Change, 0, len(indexes))
+	for _, i := range indexes {
+		if writes[i].Change != nil {
+			changes = append(changes, writes[i].Change)
+		}
+	}
+
+	return changes
+}
+
+// abortBatch returns the errors of an atomic batch of n writes in which the write at index failed with err.
+func abortBatch(n, index int, err error) []error {
+	errs := fill(n, apperrors.Aborted("another operation of the batch failed"))
+	errs[index] = err
+
+	return errs
+}
+
+func fill(n int, err error) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// placeholders returns n comma separated placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// rowValues returns the VALUES list of n rows of the given number of columns.
+func rowValues(n, columns int) string {
+	row := "(" + placeholders(columns) + ")"
+
+	return strings.TrimSuffix(strings.Repeat(row+", ", n), ", ")
+}
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+	"gofr.dev/pkg/gofr/datasource"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+)
+
+func TestWriteBatch(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	createdAt := time.Date(2025, 1, 12, 12, 0, 0, 0, time.UTC)
+	change := func(action, name string) *entities.Change {
+		return &entities.Change{Audit: entities.AuditEntry{Actor: "local", Action: action, UserName: name,
+			Changes: []byte(`{}`), Timestamp: createdAt}}
+	}
+
+	adam := entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", UserName: "adam", UserAge: 40,
+		PhoneNumber: "+14155550001", Email: "adam@example.com"}
+	eve := entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", UserName: "eve", UserAge: 22,
+		PhoneNumber: "+14155550002", Email: "eve@example.com"}
+	writes := []entities.BatchWrite{
+		{Op: entities.BatchCreate, User: adam, Change: change(entities.AuditCreate, "adam")},
+		{Op: entities.BatchDelete, User: entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0003", UserName: "waheed", Version: 2},
+			Change: change(entities.AuditDelete, "waheed")},
+		{Op: entities.BatchCreate, User: eve, Change: change(entities.AuditCreate, "eve")},
+	}
+
+	insertQuery := "INSERT INTO `User` (ID, UserName, UserAge, PhoneNumber, Email, CreatedAt, UpdatedAt) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	insertTwoQuery := insertQuery + ", (?, ?, ?, ?, ?, ?, ?)"
+	adamArgs := []driver.Value{adam.ID, "adam", 40, "+14155550001", "adam@example.com", sqlmock.AnyArg(), sqlmock.AnyArg()}
+	eveArgs := []driver.Value{eve.ID, "eve", 22, "+14155550002", "eve@example.com", sqlmock.AnyArg(), sqlmock.AnyArg()}
+	deleteQuery := "UPDATE `User` SET DeletedAt = ?, UpdatedAt = ?, Version = Version + 1 WHERE ID = ? AND Version = ? AND DeletedAt IS NULL"
+	auditQuery := "INSERT INTO `UserAudit` (Actor, Action, UserID, UserName, Changes, RequestID, CreatedAt) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?)"
+	auditArgs := func(action, name string) []driver.Value {
+		return []driver.Value{"local", action, "", name, "{}", "", createdAt}
+	}
+	dbErr := datasource.ErrorDB{Err: fmt.Errorf("db error"), Message: "error from sql db"}
+	aborted := apperrors.Aborted("another operation of the batch failed")
+
+	tests := []struct {
+		name           string
+		atomic         bool
+		mockExpect     func()
+		expectedErrors []error
+	}{
+		{
+			name:   "Atomic batch inserts the created users and records all changes with one statement each",
+			atomic: true,
+			mockExpect: func() {
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectExec(insertTwoQuery).WithArgs(slices.Concat(adamArgs, eveArgs)...).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.SQL.ExpectExec(deleteQuery).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0003", 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.SQL.ExpectExec(auditQuery + ", (?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?)").
+					WithArgs(slices.Concat(auditArgs("create", "adam"), auditArgs("delete", "waheed"),
+						auditArgs("create", "eve"))...).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.SQL.ExpectCommit()
+			},
+			expectedErrors: []error{nil, nil, nil},
+		},
+		{
+			name:   "Atomic batch finds the user a failed insert was for and aborts the other writes",
+			atomic: true,
+			mockExpect: func() {
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectExec(insertTwoQuery).WithArgs(slices.Concat(adamArgs, eveArgs)...).
+					WillReturnError(fmt.Errorf("db error"))
+				mock.SQL.ExpectRollback()
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectExec(insertQuery).WithArgs(adamArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.SQL.ExpectExec(insertQuery).WithArgs(eveArgs...).WillReturnError(fmt.Errorf("db error"))
+				mock.SQL.ExpectRollback()
+			},
+			expectedErrors: []error{aborted, aborted, dbErr},
+		},
+		{
+			name:   "Best effort batch inserts the users of a failed insert one at a time",
+			atomic: false,
+			mockExpect: func() {
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectExec(insertTwoQuery).WithArgs(slices.Concat(adamArgs, eveArgs)...).
+					WillReturnError(fmt.Errorf("db error"))
+				mock.SQL.ExpectRollback()
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectExec(insertQuery).WithArgs(adamArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.SQL.ExpectExec(auditQuery).WithArgs(auditArgs("create", "adam")...).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.SQL.ExpectCommit()
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectExec(insertQuery).WithArgs(eveArgs...).WillReturnError(fmt.Errorf("db error"))
+				mock.SQL.ExpectRollback()
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectExec(deleteQuery).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0003", 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.SQL.ExpectRollback()
+			},
+			expectedErrors: []error{nil, apperrors.Conflict("user was modified concurrently", nil), dbErr},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			store := NewDetails()
+			errs := store.WriteBatch(writes, tt.atomic, ctx)
+
+			assert.Equal(t, tt.expectedErrors, errs, "TEST[%d] failed: %s", i, tt.name)
+			assert.NoError(t, mock.SQL.ExpectationsWereMet(), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestGetUsersByNames(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` "+
+		"WHERE UserName IN (?, ?) AND DeletedAt IS NULL").WithArgs("waheed", "nobody").
+		WillReturnRows(sqlmock.NewRows([]string{"ID", "UserName", "UserAge", "PhoneNumber", "Email", "DeletedAt", "CreatedAt", "UpdatedAt", "Version"}).
+			AddRow("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", "waheed", 19, "+14155552671", "waheed@example.com", nil, nil, nil, 3))
+
+	users, err := NewDetails().GetUsersByNames([]string{"waheed", "nobody"}, ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.Users{{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", UserName: "waheed", UserAge: 19,
+		PhoneNumber: "+14155552671", Email: "waheed@example.com", Version: 3}}, users)
+}
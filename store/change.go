@@ -0,0 +1,82 @@
+package store
+
+import (
+	"database/sql"
+	"gofr.dev/pkg/gofr"
+	"gofrProject/entities"
+	"slices"
+)
+
+// execer is implemented by both the database and a transaction.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// write runs the statements of fn. If change is not nil, they run in a transaction that also
+// appends the audit entry of change to the audit log and stores its event in the outbox, so that
+// both are recorded if and only if the change is. fn returns domain errors, any error makes write
+// roll the transaction back.
+func write(ctx *gofr.Context, change *entities.Change, fn func(db execer) error) error {
+	if change == nil {
+		return fn(ctx.SQL)
+	}
+
+	return transaction(ctx, []*entities.Change{change}, fn)
+}
+
+// transaction runs the statements of fn and records changes in one transaction.
+func transaction(ctx *gofr.Context, changes []*entities.Change, fn func(db execer) error) error {
+	tx, err := ctx.SQL.Begin()
+	if err != nil {
+		return mapError(err)
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+
+		return err
+	}
+
+	if err := record(tx, changes, ctx); err != nil {
+		_ = tx.Rollback()
+
+		return mapError(err)
+	}
+
+	return mapError(tx.Commit())
+}
+
+// record inserts the audit entries and the events of changes, with one statement per table for
+// up to batchRows changes.
+func record(db execer, changes []*entities.Change, ctx *gofr.Context) error {
+	for chunk := range slices.Chunk(changes, batchRows) {
+		args := make([]any, 0, len(chunk)*7)
+		events := make([]any, 0, len(chunk)*3)
+
+		for _, change := range chunk {
+			audit := change.Audit
+			args = append(args, audit.Actor, audit.Action, audit.UserID, audit.UserName, string(audit.Changes),
+				audit.RequestID, audit.Timestamp.UTC())
+
+			if event := change.Event; event != nil {
+				events = append(events, event.Topic, string(event.Payload), event.CreatedAt.UTC())
+			}
+		}
+
+		if _, err := db.Exec(sqlFor(ctx, `INSERT INTO "UserAudit" (Actor, Action, UserID, UserName, Changes, RequestID, `+
+			"CreatedAt) VALUES "+rowValues(len(chunk), 7)), args...); err != nil {
+			return err
+		}
+
+		if len(events) > 0 {
+			if _, err := db.Exec(sqlFor(ctx, `INSERT INTO "UserOutbox" (Topic, Payload, CreatedAt) VALUES `+
+				rowValues(len(events)/3, 3)), events...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+	"gofr.dev/pkg/gofr/datasource"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+)
+
+func TestDeleteUsers_Change(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	createdAt := time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC)
+	change := &entities.Change{
+		Audit: entities.AuditEntry{Actor: "local", Action: entities.AuditDelete, UserID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
+			UserName: "John Doe", Changes: []byte(`{}`), RequestID: "req-1", Timestamp: createdAt},
+		Event: &entities.OutboxMessage{Topic: "users", Payload: []byte(`{"type":"user.deleted"}`), CreatedAt: createdAt},
+	}
+
+	deleteQuery := "UPDATE `User` SET DeletedAt = ?, UpdatedAt = ?, Version = Version + 1 WHERE ID = ? AND Version = ? AND DeletedAt IS NULL"
+	auditQuery := "INSERT INTO `UserAudit` (Actor, Action, UserID, UserName, Changes, RequestID, CreatedAt) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?)"
+	outboxQuery := "INSERT INTO `UserOutbox` (Topic, Payload, CreatedAt) VALUES (?, ?, ?)"
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse error
+	}{
+		{
+			name: "Change, audit entry and event are committed together",
+			mockExpect: func() {
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectExec(deleteQuery).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 2).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.SQL.ExpectExec(auditQuery).WithArgs("local", "delete", "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
+					"John Doe", "{}", "req-1", createdAt).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.SQL.ExpectExec(outboxQuery).WithArgs("users", `{"type":"user.deleted"}`, createdAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.SQL.ExpectCommit()
+			},
+		},
+		{
+			name: "Audit entry and event are not stored when the change fails",
+			mockExpect: func() {
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectExec(deleteQuery).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.SQL.ExpectRollback()
+			},
+			expectedResponse: apperrors.Conflict("user was modified concurrently", nil),
+		},
+		{
+			name: "Change is rolled back when the event cannot be stored",
+			mockExpect: func() {
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectExec(deleteQuery).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 2).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.SQL.ExpectExec(auditQuery).WithArgs("local", "delete", "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
+					"John Doe", "{}", "req-1", createdAt).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.SQL.ExpectExec(outboxQuery).WithArgs("users", `{"type":"user.deleted"}`, createdAt).
+					WillReturnError(fmt.Errorf("db error"))
+				mock.SQL.ExpectRollback()
+			},
+			expectedResponse: datasource.ErrorDB{Err: fmt.Errorf("db error"), Message: "error from sql db"},
+		},
+		{
+			name: "Transaction cannot be started",
+			mockExpect: func() {
+				mock.SQL.ExpectBegin().WillReturnError(fmt.Errorf("db error"))
+			},
+			expectedResponse: datasource.ErrorDB{Err: fmt.Errorf("db error"), Message: "error from sql db"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			store := NewDetails()
+			err := store.DeleteUsers(entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", UserName: "John Doe", Version: 2},
+				change, ctx)
+
+			assert.Equal(t, tt.expectedResponse, err, "TEST[%d] failed: %s", i, tt.name)
+			assert.NoError(t, mock.SQL.ExpectationsWereMet(), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
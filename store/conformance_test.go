@@ -0,0 +1,573 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/config"
+	"gofr.dev/pkg/gofr/container"
+	"gofr.dev/pkg/gofr/migration"
+	"gofrProject/apperrors"
+	"gofrProject/dialect"
+	"gofrProject/entities"
+	"gofrProject/migrations"
+	"gofrProject/outbox"
+	"gofrProject/service"
+)
+
+// newStoreFunc returns an empty store and the context to call it with.
+type newStoreFunc func(t *testing.T) (service.UserStore, *gofr.Context)
+
+func TestMemoryConformance(t *testing.T) {
+	runConformance(t, func(*testing.T) (service.UserStore, *gofr.Context) {
+		return NewMemory(), &gofr.Context{Context: context.Background()}
+	})
+}
+
+// TestSQLiteConformance runs the conformance suite against a SQLite file created by the migrations
+// of the application, so it needs no database server.
+func TestSQLiteConformance(t *testing.T) {
+	c := container.NewContainer(config.NewMockConfig(map[string]string{
+		"DB_DIALECT": "sqlite",
+		"DB_NAME":    filepath.Join(t.TempDir(), "users.db"),
+	}))
+
+	all := migrations.All(dialect.SQLite)
+	versions := make([]int64, 0, len(all))
+
+	for version := range all {
+		versions = append(versions, version)
+	}
+
+	slices.Sort(versions)
+
+	for _, version := range versions {
+		require.NoError(t, all[version].UP(migration.Datasource{SQL: c.SQL}), "migration %d", version)
+	}
+
+	runConformance(t, sqlStore(c))
+}
+
+// TestSQLConformance runs the conformance suite against the database configured by the
+// USER_STORE_TEST_DB_* variables. The database must be migrated, every case deletes all its users.
+func TestSQLConformance(t *testing.T) {
+	host := os.Getenv("USER_STORE_TEST_DB_HOST")
+	if host == "" {
+		t.Skip("USER_STORE_TEST_DB_HOST is not set")
+	}
+
+	runConformance(t, sqlStore(container.NewContainer(config.NewMockConfig(map[string]string{
+		"DB_HOST":     host,
+		"DB_PORT":     os.Getenv("USER_STORE_TEST_DB_PORT"),
+		"DB_USER":     os.Getenv("USER_STORE_TEST_DB_USER"),
+		"DB_PASSWORD": os.Getenv("USER_STORE_TEST_DB_PASSWORD"),
+		"DB_NAME":     os.Getenv("USER_STORE_TEST_DB_NAME"),
+		"DB_DIALECT":  os.Getenv("USER_STORE_TEST_DB_DIALECT"),
+	}))))
+}
+
+// sqlStore returns a UsersList on the database of c, emptying its tables for every case.
+func sqlStore(c *container.Container) newStoreFunc {
+	return func(t *testing.T) (service.UserStore, *gofr.Context) {
+		for _, table := range []string{"User", "UserOutbox", "UserAudit"} {
+			_, err := c.SQL.Exec(dialect.Dialect(c.SQL.Dialect()).SQL(`DELETE FROM "` + table + `"`))
+			require.NoError(t, err)
+		}
+
+		return NewDetails(), &gofr.Context{Context: context.Background(), Container: c}
+	}
+}
+
+// runConformance checks the behavior every service.UserStore must share.
+func runConformance(t *testing.T, newStore newStoreFunc) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s service.UserStore, ctx *gofr.Context)
+	}{
+		{name: "add and get", run: conformanceAddAndGet},
+		{name: "uniqueness", run: conformanceUniqueness},
+		{name: "listing", run: conformanceListing},
+		{name: "update", run: conformanceUpdate},
+		{name: "delete, restore and purge", run: conformanceDelete},
+		{name: "rename", run: conformanceRename},
+		{name: "outbox", run: conformanceOutbox},
+		{name: "audit log", run: conformanceAudit},
+		{name: "batch", run: conformanceBatch},
+		{name: "export", run: conformanceExport},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ctx := newStore(t)
+			tt.run(t, s, ctx)
+		})
+	}
+}
+
+func conformanceUser(id, name string, age int) entities.Users {
+	return entities.Users{ID: id, UserName: name, UserAge: age, PhoneNumber: "+1415555" + id[len(id)-4:],
+		Email: name + "@example.com"}
+}
+
+func addConformanceUsers(t *testing.T, s service.UserStore, ctx *gofr.Context, users ...entities.Users) {
+	for i := range users {
+		require.NoError(t, s.AddUsers(&users[i], nil, ctx))
+	}
+}
+
+func conformanceAddAndGet(t *testing.T, s service.UserStore, ctx *gofr.Context) {
+	user := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "waheed", 19)
+	before := time.Now().Add(-time.Second)
+	require.NoError(t, s.AddUsers(&user, nil, ctx))
+
+	assert.WithinRange(t, user.CreatedAt, before, time.Now(), "creation time is set")
+	assert.Equal(t, user.CreatedAt, user.UpdatedAt)
+
+	expected := user
+	expected.Version = 1
+
+	byName, err := s.GetUsersByName("waheed", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, expected, byName)
+
+	byID, err := s.GetUsersByID(user.ID, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, expected, byID)
+
+	_, err = s.GetUsersByName("missing", ctx)
+	assert.Equal(t, apperrors.NotFound("user", "name", "missing"), err)
+
+	_, err = s.GetUsersByID("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9999", ctx)
+	assert.Equal(t, apperrors.NotFound("user", "id", "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9999"), err)
+
+	err = s.AddUsers(&entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", UserName: "nophone"}, nil, ctx)
+	assert.Equal(t, apperrors.KindValidation, apperrors.KindOf(err))
+}
+
+func conformanceUniqueness(t *testing.T, s service.UserStore, ctx *gofr.Context) {
+	addConformanceUsers(t, s, ctx, conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "waheed", 19))
+
+	tests := []struct {
+		name            string
+		user            entities.Users
+		expectedMessage string
+	}{
+		{name: "name", user: entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", UserName: "waheed",
+			PhoneNumber: "+14155550002", Email: "other@example.com"},
+			expectedMessage: "user with name 'waheed' already exists"},
+		{name: "name in another case", user: entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", UserName: "WAHEED",
+			PhoneNumber: "+14155550002", Email: "other@example.com"},
+			expectedMessage: "user with name 'WAHEED' already exists"},
+		{name: "phone number", user: entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", UserName: "other",
+			PhoneNumber: "+14155550001", Email: "other@example.com"},
+			expectedMessage: "user with phone number '+14155550001' already exists"},
+		{name: "email", user: entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", UserName: "other",
+			PhoneNumber: "+14155550002", Email: "waheed@example.com"},
+			expectedMessage: "user with email 'waheed@example.com' already exists"},
+	}
+
+	for i, tt := range tests {
+		err := s.AddUsers(&tt.user, nil, ctx)
+
+		assert.Equal(t, apperrors.KindAlreadyExists, apperrors.KindOf(err), "TEST[%d] failed: %s", i, tt.name)
+		assert.EqualError(t, err, tt.expectedMessage, "TEST[%d] failed: %s", i, tt.name)
+	}
+}
+
+func conformanceListing(t *testing.T, s service.UserStore, ctx *gofr.Context) {
+	addConformanceUsers(t, s, ctx,
+		conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "carol", 30),
+		conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", "alice", 25),
+		conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0003", "bob", 30),
+		entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0004", UserName: "dave", UserAge: 17,
+			PhoneNumber: "+14155550004", Email: "dave@example.org"},
+	)
+
+	minAge := 18
+
+	tests := []struct {
+		name          string
+		query         entities.UsersQuery
+		expectedNames []string
+		expectedTotal int
+		expectNext    bool
+	}{
+		{name: "by name", query: entities.UsersQuery{Limit: 10, SortBy: entities.SortByUserName},
+			expectedNames: []string{"alice", "bob", "carol", "dave"}, expectedTotal: 4},
+		{name: "first page", query: entities.UsersQuery{Limit: 2, SortBy: entities.SortByUserName},
+			expectedNames: []string{"alice", "bob"}, expectedTotal: 4, expectNext: true},
+		{name: "page after cursor", query: entities.UsersQuery{Limit: 2, SortBy: entities.SortByUserName,
+			After: &entities.UserCursor{UserName: "bob", UserAge: 30}},
+			expectedNames: []string{"carol", "dave"}, expectedTotal: 4},
+		{name: "by age descending", query: entities.UsersQuery{Limit: 10, SortBy: entities.SortByUserAge, Descending: true},
+			expectedNames: []string{"carol", "bob", "alice", "dave"}, expectedTotal: 4},
+		{name: "by age after tie", query: entities.UsersQuery{Limit: 10, SortBy: entities.SortByUserAge,
+			After: &entities.UserCursor{UserName: "bob", UserAge: 30}},
+			expectedNames: []string{"carol"}, expectedTotal: 4},
+		{name: "filtered", query: entities.UsersQuery{Limit: 10, SortBy: entities.SortByUserName, MinAge: &minAge,
+			EmailDomain: "example.com"},
+			expectedNames: []string{"alice", "bob", "carol"}, expectedTotal: 3},
+	}
+
+	for i, tt := range tests {
+		page, err := s.GetUsers(tt.query, ctx)
+		require.NoError(t, err, "TEST[%d] failed: %s", i, tt.name)
+
+		names := make([]string, 0, len(page.Users))
+		for _, user := range page.Users {
+			names = append(names, user.UserName)
+		}
+
+		assert.Equal(t, tt.expectedNames, names, "TEST[%d] failed: %s", i, tt.name)
+		assert.Equal(t, tt.expectedTotal, page.TotalCount, "TEST[%d] failed: %s", i, tt.name)
+		assert.Equal(t, tt.expectNext, page.NextCursor != "", "TEST[%d] failed: %s", i, tt.name)
+	}
+}
+
+func conformanceUpdate(t *testing.T, s service.UserStore, ctx *gofr.Context) {
+	user := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "waheed", 19)
+	addConformanceUsers(t, s, ctx, user)
+
+	created, err := s.GetUsersByName("waheed", ctx)
+	require.NoError(t, err)
+
+	update := entities.Users{ID: user.ID, UserAge: 20, PhoneNumber: "+14155559999", Email: "new@example.com", Version: 1}
+	require.NoError(t, s.UpdateUsers(&update, nil, ctx))
+
+	updated, err := s.GetUsersByName("waheed", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, entities.Users{ID: user.ID, UserName: "waheed", UserAge: 20, PhoneNumber: "+14155559999",
+		Email: "new@example.com", CreatedAt: created.CreatedAt, UpdatedAt: update.UpdatedAt, Version: 2}, updated)
+	assert.False(t, updated.UpdatedAt.Before(created.UpdatedAt), "update time is set")
+
+	err = s.UpdateUsers(&update, nil, ctx)
+	assert.Equal(t, apperrors.KindConflict, apperrors.KindOf(err), "stale version")
+
+	update.ID, update.Version = "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0009", 2
+	err = s.UpdateUsers(&update, nil, ctx)
+	assert.Equal(t, apperrors.KindConflict, apperrors.KindOf(err), "missing user")
+}
+
+func conformanceDelete(t *testing.T, s service.UserStore, ctx *gofr.Context) {
+	user := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "waheed", 19)
+	addConformanceUsers(t, s, ctx, user)
+
+	err := s.DeleteUsers(entities.Users{ID: user.ID, Version: 2}, nil, ctx)
+	assert.Equal(t, apperrors.KindConflict, apperrors.KindOf(err), "stale version")
+	require.NoError(t, s.DeleteUsers(entities.Users{ID: user.ID, Version: 1}, nil, ctx))
+
+	_, err = s.GetUsersByName("waheed", ctx)
+	assert.True(t, apperrors.IsNotFound(err), "deleted user is hidden")
+
+	page, err := s.GetUsers(entities.UsersQuery{Limit: 10, SortBy: entities.SortByUserName}, ctx)
+	require.NoError(t, err)
+	assert.Empty(t, page.Users)
+
+	page, err = s.GetUsers(entities.UsersQuery{Limit: 10, SortBy: entities.SortByUserName, IncludeDeleted: true}, ctx)
+	require.NoError(t, err)
+	require.Len(t, page.Users, 1)
+	assert.NotNil(t, page.Users[0].DeletedAt)
+
+	err = s.AddUsers(&entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", UserName: "waheed",
+		PhoneNumber: "+14155550002", Email: "other@example.com"}, nil, ctx)
+	assert.Equal(t, apperrors.KindAlreadyExists, apperrors.KindOf(err), "name of a deleted user is taken")
+
+	deleted, err := s.GetDeletedUsersByName("waheed", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, deleted.ID)
+	assert.NotNil(t, deleted.DeletedAt)
+
+	require.NoError(t, s.RestoreUsers(deleted, nil, ctx))
+
+	restored, err := s.GetUsersByName("waheed", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, restored.Version)
+	assert.Nil(t, restored.DeletedAt)
+
+	assert.Equal(t, apperrors.KindConflict, apperrors.KindOf(s.RestoreUsers(deleted, nil, ctx)), "user is restored already")
+
+	_, err = s.GetDeletedUsersByName("waheed", ctx)
+	assert.Equal(t, apperrors.NotFound("deleted user", "name", "waheed"), err)
+
+	require.NoError(t, s.DeleteUsers(entities.Users{ID: user.ID, Version: 3}, nil, ctx))
+
+	purge := func(user entities.Users) (*entities.Change, error) {
+		return &entities.Change{Audit: entities.AuditEntry{Action: entities.AuditPurge, UserID: user.ID,
+			UserName: user.UserName, Changes: []byte(`{}`), Timestamp: time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC)}}, nil
+	}
+
+	purged, err := s.PurgeUsers(time.Now().Add(-time.Hour), purge, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged, "recently deleted users are kept")
+
+	purged, err = s.PurgeUsers(time.Now().Add(time.Hour), purge, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	audit, err := s.GetAuditLog(entities.AuditQuery{Limit: 10, UserID: user.ID}, ctx)
+	require.NoError(t, err)
+	require.Len(t, audit.Entries, 1, "the purge of the user is recorded")
+	assert.Equal(t, entities.AuditPurge, audit.Entries[0].Action)
+	assert.Equal(t, "waheed", audit.Entries[0].UserName)
+
+	require.NoError(t, s.AddUsers(&user, nil, ctx), "name of a purged user is free")
+}
+
+func conformanceRename(t *testing.T, s service.UserStore, ctx *gofr.Context) {
+	user := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "waheed", 19)
+	addConformanceUsers(t, s, ctx, user, conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", "taken", 20))
+
+	rename := &entities.Change{Audit: entities.AuditEntry{Action: entities.AuditRename, UserID: user.ID,
+		UserName: "abdul", Changes: []byte(`{}`), Timestamp: time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC)}}
+	require.NoError(t, s.RenameUsers(user.ID, "abdul", 1, rename, ctx))
+
+	renamed, err := s.GetUsersByName("abdul", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, renamed.ID)
+	assert.Equal(t, 2, renamed.Version)
+
+	_, err = s.GetUsersByName("waheed", ctx)
+	assert.True(t, apperrors.IsNotFound(err), "old name is free")
+
+	err = s.RenameUsers(user.ID, "taken", 2, nil, ctx)
+	assert.Equal(t, apperrors.KindAlreadyExists, apperrors.KindOf(err))
+	assert.EqualError(t, err, "user with name 'taken' already exists")
+
+	err = s.RenameUsers(user.ID, "walter", 1, rename, ctx)
+	assert.Equal(t, apperrors.KindConflict, apperrors.KindOf(err), "stale version")
+
+	require.NoError(t, s.DeleteUsers(entities.Users{ID: user.ID, Version: 2}, nil, ctx))
+
+	err = s.RenameUsers(user.ID, "walter", 3, rename, ctx)
+	assert.Equal(t, apperrors.KindConflict, apperrors.KindOf(err), "deleted user")
+
+	page, err := s.GetAuditLog(entities.AuditQuery{Limit: 10, UserID: user.ID}, ctx)
+	require.NoError(t, err)
+	assert.Len(t, page.Entries, 1, "only the rename that happened is recorded")
+}
+
+func conformanceOutbox(t *testing.T, s service.UserStore, ctx *gofr.Context) {
+	store, ok := s.(outbox.Store)
+	require.True(t, ok, "store has an outbox")
+
+	createdAt := time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC)
+	event := func(payload string) *entities.Change {
+		return &entities.Change{
+			Audit: entities.AuditEntry{Action: entities.AuditUpdate, Changes: []byte(`{}`), Timestamp: createdAt},
+			Event: &entities.OutboxMessage{Topic: "users", Payload: []byte(payload), CreatedAt: createdAt},
+		}
+	}
+
+	user := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "waheed", 19)
+	require.NoError(t, s.AddUsers(&user, event("created"), ctx))
+
+	err := s.AddUsers(&user, event("duplicate"), ctx)
+	assert.Equal(t, apperrors.KindAlreadyExists, apperrors.KindOf(err))
+
+	err = s.DeleteUsers(entities.Users{ID: user.ID, Version: 5}, event("stale"), ctx)
+	assert.Equal(t, apperrors.KindConflict, apperrors.KindOf(err))
+
+	require.NoError(t, s.DeleteUsers(entities.Users{ID: user.ID, Version: 1}, event("deleted"), ctx))
+
+	pending, err := store.PendingOutbox(10, ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 2, "only the events of stored changes are pending")
+	assert.Equal(t, "created", string(pending[0].Payload))
+	assert.Equal(t, "deleted", string(pending[1].Payload))
+	assert.Less(t, pending[0].ID, pending[1].ID)
+	assert.Equal(t, "users", pending[0].Topic)
+	assert.True(t, createdAt.Equal(pending[0].CreatedAt), "created at %v", pending[0].CreatedAt)
+
+	first, err := store.PendingOutbox(1, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, pending[:1], first)
+
+	require.NoError(t, store.MarkOutboxSent(pending[0].ID, ctx))
+
+	pending, err = store.PendingOutbox(10, ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1, "sent messages are no longer pending")
+	assert.Equal(t, "deleted", string(pending[0].Payload))
+}
+
+func conformanceAudit(t *testing.T, s service.UserStore, ctx *gofr.Context) {
+	start := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	change := func(actor, action string, minutes int) *entities.Change {
+		return &entities.Change{Audit: entities.AuditEntry{Actor: actor, Action: action, UserName: "waheed",
+			UserID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", Changes: []byte(`{"user_age":{"before":19,"after":20}}`),
+			RequestID: "req-" + action, Timestamp: start.Add(time.Duration(minutes) * time.Minute)}}
+	}
+
+	user := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "waheed", 19)
+	require.NoError(t, s.AddUsers(&user, change("alice", entities.AuditCreate, 0), ctx))
+
+	user.Version, user.UserAge = 1, 20
+	require.NoError(t, s.UpdateUsers(&user, change("bob", entities.AuditUpdate, 1), ctx))
+
+	err := s.UpdateUsers(&user, change("bob", "stale", 2), ctx)
+	assert.Equal(t, apperrors.KindConflict, apperrors.KindOf(err))
+
+	require.NoError(t, s.DeleteUsers(entities.Users{ID: user.ID, Version: 2}, change("alice", entities.AuditDelete, 3), ctx))
+
+	require.NoError(t, s.RestoreUsers(entities.Users{ID: user.ID, Version: 3}, change("alice", entities.AuditRestore, 4), ctx))
+
+	page, err := s.GetAuditLog(entities.AuditQuery{Limit: 10, UserID: user.ID}, ctx)
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 4, "only the entries of stored changes are kept")
+
+	actions := make([]string, 0, len(page.Entries))
+	for _, entry := range page.Entries {
+		actions = append(actions, entry.Action)
+	}
+
+	assert.Equal(t, []string{"restore", "delete", "update", "create"}, actions, "newest first")
+	assert.Equal(t, "bob", page.Entries[2].Actor)
+	assert.Equal(t, "req-update", page.Entries[2].RequestID)
+	assert.JSONEq(t, `{"user_age":{"before":19,"after":20}}`, string(page.Entries[2].Changes))
+	assert.True(t, start.Add(time.Minute).Equal(page.Entries[2].Timestamp), "timestamp %v", page.Entries[2].Timestamp)
+	assert.Empty(t, page.NextCursor)
+
+	first, err := s.GetAuditLog(entities.AuditQuery{Limit: 3, UserID: user.ID}, ctx)
+	require.NoError(t, err)
+	require.Len(t, first.Entries, 3)
+	require.NotEmpty(t, first.NextCursor)
+
+	cursor, err := entities.DecodeAuditCursor(first.NextCursor)
+	require.NoError(t, err)
+
+	second, err := s.GetAuditLog(entities.AuditQuery{Limit: 3, UserID: user.ID, Before: &cursor}, ctx)
+	require.NoError(t, err)
+	require.Len(t, second.Entries, 1)
+	assert.Equal(t, "create", second.Entries[0].Action)
+	assert.Empty(t, second.NextCursor)
+
+	byActor, err := s.GetAuditLog(entities.AuditQuery{Limit: 10, Actor: "alice", From: start.Add(time.Minute),
+		To: start.Add(4 * time.Minute)}, ctx)
+	require.NoError(t, err)
+	require.Len(t, byActor.Entries, 1, "actor and time range filters")
+	assert.Equal(t, "delete", byActor.Entries[0].Action)
+}
+
+func conformanceBatch(t *testing.T, s service.UserStore, ctx *gofr.Context) {
+	waheed := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "waheed", 19)
+	carol := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", "carol", 30)
+	addConformanceUsers(t, s, ctx, waheed, carol)
+
+	change := func(action, name string) *entities.Change {
+		return &entities.Change{Audit: entities.AuditEntry{Action: action, UserName: name, Changes: []byte(`{}`),
+			Timestamp: time.Date(2025, 1, 12, 12, 0, 0, 0, time.UTC)}}
+	}
+
+	adam := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0003", "adam", 40)
+	eve := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0004", "eve", 22)
+	eve.PhoneNumber = carol.PhoneNumber
+	updated := waheed
+	updated.UserAge, updated.Version = 20, 1
+	staleCarol := carol
+	staleCarol.Version = 5
+
+	errs := s.WriteBatch([]entities.BatchWrite{
+		{Op: entities.BatchCreate, User: adam, Change: change(entities.AuditCreate, "adam")},
+		{Op: entities.BatchCreate, User: eve, Change: change(entities.AuditCreate, "eve")},
+		{Op: entities.BatchUpdate, User: updated, Change: change(entities.AuditUpdate, "waheed")},
+		{Op: entities.BatchDelete, User: staleCarol, Change: change(entities.AuditDelete, "carol")},
+	}, false, ctx)
+	require.Len(t, errs, 4)
+	assert.NoError(t, errs[0], "best effort: create")
+	assert.EqualError(t, errs[1], "user with phone number '"+carol.PhoneNumber+"' already exists")
+	assert.NoError(t, errs[2], "best effort: update")
+	assert.Equal(t, apperrors.KindConflict, apperrors.KindOf(errs[3]))
+
+	got, err := s.GetUsersByName("waheed", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 20, got.UserAge)
+	assert.Equal(t, 2, got.Version)
+
+	page, err := s.GetAuditLog(entities.AuditQuery{Limit: 10}, ctx)
+	require.NoError(t, err)
+	assert.Len(t, page.Entries, 2, "only the changes of applied writes are recorded")
+
+	bob := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0005", "bob", 50)
+	dan := conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0006", "dan", 60)
+	dan.Email = adam.Email
+	deleted := got
+
+	errs = s.WriteBatch([]entities.BatchWrite{
+		{Op: entities.BatchCreate, User: bob, Change: change(entities.AuditCreate, "bob")},
+		{Op: entities.BatchDelete, User: deleted, Change: change(entities.AuditDelete, "waheed")},
+		{Op: entities.BatchCreate, User: dan, Change: change(entities.AuditCreate, "dan")},
+	}, true, ctx)
+	require.Len(t, errs, 3)
+	assert.Equal(t, apperrors.KindAborted, apperrors.KindOf(errs[0]))
+	assert.Equal(t, apperrors.KindAborted, apperrors.KindOf(errs[1]))
+	assert.EqualError(t, errs[2], "user with email '"+adam.Email+"' already exists")
+
+	users, err := s.GetUsersByNames([]string{"BOB", "Waheed", "adam", "nobody"}, ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"waheed", "adam"}, userNames(users), "atomic: nothing is written")
+
+	page, err = s.GetAuditLog(entities.AuditQuery{Limit: 10}, ctx)
+	require.NoError(t, err)
+	assert.Len(t, page.Entries, 2, "atomic: nothing is recorded")
+
+	errs = s.WriteBatch([]entities.BatchWrite{
+		{Op: entities.BatchCreate, User: bob, Change: change(entities.AuditCreate, "bob")},
+		{Op: entities.BatchDelete, User: deleted, Change: change(entities.AuditDelete, "waheed")},
+	}, true, ctx)
+	assert.Equal(t, []error{nil, nil}, errs)
+
+	users, err = s.GetUsersByNames([]string{"BOB", "Waheed", "adam"}, ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"bob", "adam"}, userNames(users))
+
+	page, err = s.GetAuditLog(entities.AuditQuery{Limit: 10}, ctx)
+	require.NoError(t, err)
+	assert.Len(t, page.Entries, 4)
+}
+
+func userNames(users []entities.Users) []string {
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		names = append(names, user.UserName)
+	}
+
+	return names
+}
+
+func conformanceExport(t *testing.T, s service.UserStore, ctx *gofr.Context) {
+	addConformanceUsers(t, s, ctx,
+		conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "carol", 30),
+		conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", "alice", 25),
+		conformanceUser("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0003", "bob", 17),
+	)
+
+	minAge := 18
+	errStop := errors.New("stop")
+
+	var names []string
+
+	err := s.StreamUsers(entities.UsersQuery{SortBy: entities.SortByUserAge, Descending: true, MinAge: &minAge},
+		func(user entities.Users) error {
+			names = append(names, user.UserName)
+			return nil
+		}, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol", "alice"}, names)
+
+	names = nil
+
+	err = s.StreamUsers(entities.UsersQuery{SortBy: entities.SortByUserName}, func(user entities.Users) error {
+		names = append(names, user.UserName)
+		return errStop
+	}, ctx)
+	assert.Equal(t, errStop, err)
+	assert.Equal(t, []string{"alice"}, names, "the export stops at the first error")
+}
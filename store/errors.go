@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"gofr.dev/pkg/gofr/datasource"
+	"modernc.org/sqlite"
+
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+)
+
+// MySQL server error numbers translated into domain errors.
+const (
+	mysqlDuplicateEntry      = 1062
+	mysqlLockWaitTimeout     = 1205
+	mysqlDeadlock            = 1213
+	mysqlRowIsReferenced     = 1451
+	mysqlNoReferencedRow     = 1452
+	mysqlTooManyConnections  = 1040
+	mysqlServerShuttingDown  = 1053
+	mysqlLockDeadlockAborted = 1614
+)
+
+// PostgreSQL error codes translated into domain errors.
+const (
+	postgresUniqueViolation      pq.ErrorCode = "23505"
+	postgresForeignKeyViolation  pq.ErrorCode = "23503"
+	postgresSerializationFailure pq.ErrorCode = "40001"
+	postgresDeadlockDetected     pq.ErrorCode = "40P01"
+	postgresTooManyConnections   pq.ErrorCode = "53300"
+	postgresAdminShutdown        pq.ErrorCode = "57P01"
+)
+
+// SQLite extended result codes translated into domain errors.
+const (
+	sqliteBusy                 = 5
+	sqliteLocked               = 6
+	sqliteConstraintForeignKey = 787
+	sqliteConstraintPrimaryKey = 1555
+	sqliteConstraintUnique     = 2067
+)
+
+// mapError translates an error returned by the SQL driver into a domain error. Errors
+// without a domain meaning are returned as datasource.ErrorDB.
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if kind := driverErrorKind(err); kind != "" {
+		switch kind {
+		case apperrors.KindAlreadyExists:
+			return &apperrors.Error{Kind: apperrors.KindAlreadyExists, Message: "user already exists", Err: err}
+		case apperrors.KindConflict:
+			return apperrors.Conflict("user was modified concurrently", err)
+		default:
+			return apperrors.Unavailable(err)
+		}
+	}
+
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone), errors.Is(err, mysql.ErrInvalidConn),
+		errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr):
+		return apperrors.Unavailable(err)
+	}
+
+	return datasource.ErrorDB{Err: err, Message: "error from sql db"}
+}
+
+// driverErrorKind returns the kind of domain error a MySQL, PostgreSQL or SQLite server error stands for.
+func driverErrorKind(err error) apperrors.Kind {
+	var (
+		mysqlErr    *mysql.MySQLError
+		postgresErr *pq.Error
+		sqliteErr   *sqlite.Error
+	)
+
+	switch {
+	case errors.As(err, &mysqlErr):
+		switch mysqlErr.Number {
+		case mysqlDuplicateEntry:
+			return apperrors.KindAlreadyExists
+		case mysqlDeadlock, mysqlLockWaitTimeout, mysqlLockDeadlockAborted, mysqlRowIsReferenced, mysqlNoReferencedRow:
+			return apperrors.KindConflict
+		case mysqlTooManyConnections, mysqlServerShuttingDown:
+			return apperrors.KindUnavailable
+		}
+	case errors.As(err, &postgresErr):
+		switch postgresErr.Code {
+		case postgresUniqueViolation:
+			return apperrors.KindAlreadyExists
+		case postgresSerializationFailure, postgresDeadlockDetected, postgresForeignKeyViolation:
+			return apperrors.KindConflict
+		case postgresTooManyConnections, postgresAdminShutdown:
+			return apperrors.KindUnavailable
+		}
+	case errors.As(err, &sqliteErr):
+		switch sqliteErr.Code() {
+		case sqliteConstraintUnique, sqliteConstraintPrimaryKey:
+			return apperrors.KindAlreadyExists
+		case sqliteBusy, sqliteLocked, sqliteConstraintForeignKey:
+			return apperrors.KindConflict
+		}
+	}
+
+	return ""
+}
+
+// userConflict names the unique column of user that a duplicate entry error was raised for.
+func userConflict(err error, user *entities.Users) error {
+	if apperrors.KindOf(err) != apperrors.KindAlreadyExists {
+		return err
+	}
+
+	var conflict *apperrors.Error
+
+	// MySQL and PostgreSQL name the violated index, SQLite names the column.
+	switch message := errors.Unwrap(err).Error(); {
+	case strings.Contains(message, "idx_user_phone_number"), strings.Contains(message, "User.PhoneNumber"):
+		conflict = apperrors.AlreadyExists("user", "phone number", user.PhoneNumber)
+	case strings.Contains(message, "idx_user_email"), strings.Contains(message, "User.Email"):
+		conflict = apperrors.AlreadyExists("user", "email", user.Email)
+	default:
+		conflict = apperrors.AlreadyExists("user", "name", user.UserName)
+	}
+
+	conflict.Err = errors.Unwrap(err)
+
+	return conflict
+}
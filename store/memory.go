@@ -0,0 +1,519 @@
+package store
+
+import (
+	"fmt"
+	"gofr.dev/pkg/gofr"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory is a user store that keeps the users in memory. It behaves like UsersList on a MySQL
+// database with the default case-insensitive collation: user names, phone numbers and emails are
+// unique regardless of case, and users are ordered by their lower-cased name. It is safe for
+// concurrent use and meant for local development and tests, the users are lost when the process exits.
+type Memory struct {
+	mu     sync.RWMutex
+	users  []entities.Users
+	outbox []entities.OutboxMessage
+	audit  []entities.AuditEntry
+	now    func() time.Time
+
+	lastOutboxID, lastAuditID int64
+}
+
+// NewMemory creates an empty in-memory user store.
+func NewMemory() *Memory {
+	return &Memory{now: time.Now}
+}
+
+// GetUsers returns one page of users matching the query in the same order as UsersList.GetUsers.
+func (m *Memory) GetUsers(query entities.UsersQuery, _ *gofr.Context) (entities.UsersPage, error) {
+	matching := m.matching(query)
+
+	page := entities.UsersPage{Users: make([]entities.Users, 0, query.Limit), TotalCount: len(matching)}
+
+	for i := range matching {
+		if query.After != nil {
+			after := entities.Users{UserName: query.After.UserName, UserAge: query.After.UserAge}
+			if memoryCompare(&matching[i], &after, query) <= 0 {
+				continue
+			}
+		}
+
+		if len(page.Users) == query.Limit {
+			last := page.Users[query.Limit-1]
+			page.NextCursor = entities.UserCursor{UserName: last.UserName, UserAge: last.UserAge}.Encode()
+
+			break
+		}
+
+		page.Users = append(page.Users, matching[i])
+	}
+
+	return page, nil
+}
+
+// StreamUsers calls fn with every user matching the filters of the query, in the order of GetUsers.
+// The users are copied before fn is called, fn may use the store.
+func (m *Memory) StreamUsers(query entities.UsersQuery, fn func(user entities.Users) error, _ *gofr.Context) error {
+	for _, user := range m.matching(query) {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matching returns copies of the users matching the filters of the query, in the requested order.
+func (m *Memory) matching(query entities.UsersQuery) []entities.Users {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matching := make([]entities.Users, 0, len(m.users))
+
+	for i := range m.users {
+		if memoryFilter(&m.users[i], query) {
+			matching = append(matching, m.users[i])
+		}
+	}
+
+	sort.Slice(matching, func(i, j int) bool {
+		return memoryCompare(&matching[i], &matching[j], query) < 0
+	})
+
+	return matching
+}
+
+// memoryFilter reports whether user matches the filters of the query.
+func memoryFilter(user *entities.Users, query entities.UsersQuery) bool {
+	switch {
+	case !query.IncludeDeleted && user.DeletedAt != nil:
+		return false
+	case query.MinAge != nil && user.UserAge < *query.MinAge:
+		return false
+	case query.MaxAge != nil && user.UserAge > *query.MaxAge:
+		return false
+	case query.EmailDomain != "" && !strings.HasSuffix(strings.ToLower(user.Email), "@"+strings.ToLower(query.EmailDomain)):
+		return false
+	}
+
+	return true
+}
+
+// memoryCompare orders a before b in the requested order with the user name as tie-breaker,
+// the order UsersList.GetUsers gets from the database.
+func memoryCompare(a, b *entities.Users, query entities.UsersQuery) int {
+	result := 0
+
+	if query.SortBy == entities.SortByUserAge {
+		result = a.UserAge - b.UserAge
+	}
+
+	if result == 0 {
+		result = strings.Compare(strings.ToLower(a.UserName), strings.ToLower(b.UserName))
+	}
+
+	if query.Descending {
+		return -result
+	}
+
+	return result
+}
+
+// GetUsersByName retrieves a single user by their username.
+func (m *Memory) GetUsersByName(name string, _ *gofr.Context) (entities.Users, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if i := m.find(name); i >= 0 && m.users[i].DeletedAt == nil {
+		return m.users[i], nil
+	}
+
+	return entities.Users{}, apperrors.NotFound("user", "name", name)
+}
+
+// GetDeletedUsersByName retrieves a single soft-deleted user by their username.
+func (m *Memory) GetDeletedUsersByName(name string, _ *gofr.Context) (entities.Users, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if i := m.find(name); i >= 0 && m.users[i].DeletedAt != nil {
+		return m.users[i], nil
+	}
+
+	return entities.Users{}, apperrors.NotFound("deleted user", "name", name)
+}
+
+// GetUsersByID retrieves a single user by their id.
+func (m *Memory) GetUsersByID(id string, _ *gofr.Context) (entities.Users, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := range m.users {
+		if m.users[i].ID == id && m.users[i].DeletedAt == nil {
+			return m.users[i], nil
+		}
+	}
+
+	return entities.Users{}, apperrors.NotFound("user", "id", id)
+}
+
+// AddUsers stores a new user, recording change with it if it is not nil.
+func (m *Memory) AddUsers(user *entities.Users, change *entities.Change, _ *gofr.Context) error {
+	if user.UserName == "" || user.PhoneNumber == "" {
+		return apperrors.Validation(fmt.Errorf("UserName and PhoneNumber cannot be empty"))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.add(user, change)
+}
+
+// add stores a new user and sets its timestamps. The caller must hold the write lock.
+func (m *Memory) add(user *entities.Users, change *entities.Change) error {
+	if err := m.unique(user, -1); err != nil {
+		return err
+	}
+
+	createdAt := m.now().UTC()
+	user.CreatedAt, user.UpdatedAt = createdAt, createdAt
+
+	m.users = append(m.users, entities.Users{ID: user.ID, UserName: user.UserName, UserAge: user.UserAge,
+		PhoneNumber: user.PhoneNumber, Email: user.Email, CreatedAt: createdAt, UpdatedAt: createdAt, Version: 1})
+	m.record(change)
+
+	return nil
+}
+
+// DeleteUsers soft-deletes user, found by its id. It fails with a conflict unless the user is still
+// at user.Version.
+func (m *Memory) DeleteUsers(user entities.Users, change *entities.Change, _ *gofr.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.delete(user.ID, user.Version, change)
+}
+
+// delete soft-deletes a user. The caller must hold the write lock.
+func (m *Memory) delete(id string, version int, change *entities.Change) error {
+	i, err := m.current(m.findID(id), version)
+	if err != nil {
+		return err
+	}
+
+	deletedAt := m.now().UTC()
+	m.users[i].DeletedAt, m.users[i].UpdatedAt = &deletedAt, deletedAt
+	m.users[i].Version++
+	m.record(change)
+
+	return nil
+}
+
+// RestoreUsers clears the DeletedAt timestamp of user, a soft-deleted user found by its id. It
+// fails with a conflict unless the user is still deleted and at user.Version.
+func (m *Memory) RestoreUsers(user entities.Users, change *entities.Change, _ *gofr.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.findID(user.ID)
+	if i < 0 || m.users[i].DeletedAt == nil || m.users[i].Version != user.Version {
+		return apperrors.Conflict("user was modified concurrently", nil)
+	}
+
+	m.users[i].DeletedAt, m.users[i].UpdatedAt = nil, m.now().UTC()
+	m.users[i].Version++
+
+	m.record(change)
+
+	return nil
+}
+
+// PurgeUsers permanently removes the users soft-deleted before the given time and returns how many were removed.
+// change returns what is recorded together with the removal of a user.
+func (m *Memory) PurgeUsers(before time.Time, change func(user entities.Users) (*entities.Change, error),
+	_ *gofr.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var changes []*entities.Change
+
+	kept := make([]entities.Users, 0, len(m.users))
+
+	for i := range m.users {
+		if m.users[i].DeletedAt == nil || !m.users[i].DeletedAt.Before(before) {
+			kept = append(kept, m.users[i])
+			continue
+		}
+
+		purge, err := change(m.users[i])
+		if err != nil {
+			return 0, err
+		}
+
+		changes = append(changes, purge)
+	}
+
+	m.users = kept
+
+	for _, purge := range changes {
+		m.record(purge)
+	}
+
+	return int64(len(changes)), nil
+}
+
+// UpdateUsers replaces all mutable fields of the user with the id of updateUser. It fails with a
+// conflict unless the stored user is still at updateUser.Version.
+func (m *Memory) UpdateUsers(updateUser *entities.Users, change *entities.Change, _ *gofr.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.update(updateUser, change)
+}
+
+// update replaces all mutable fields of a user and sets the UpdatedAt of updateUser. The caller
+// must hold the write lock.
+func (m *Memory) update(updateUser *entities.Users, change *entities.Change) error {
+	i, err := m.current(m.findID(updateUser.ID), updateUser.Version)
+	if err != nil {
+		return err
+	}
+
+	updated := m.users[i]
+	updated.UserAge, updated.PhoneNumber, updated.Email = updateUser.UserAge, updateUser.PhoneNumber, updateUser.Email
+
+	if err := m.unique(&updated, i); err != nil {
+		return err
+	}
+
+	updateUser.UpdatedAt = m.now().UTC()
+	updated.UpdatedAt = updateUser.UpdatedAt
+	updated.Version++
+	m.users[i] = updated
+	m.record(change)
+
+	return nil
+}
+
+// RenameUsers changes the name of the user with the given id. It fails with a conflict unless the
+// user is still at the given version.
+func (m *Memory) RenameUsers(id, name string, version int, change *entities.Change, _ *gofr.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, err := m.current(m.findID(id), version)
+	if err != nil {
+		return err
+	}
+
+	renamed := m.users[i]
+	renamed.UserName = name
+
+	if err := m.unique(&renamed, i); err != nil {
+		return err
+	}
+
+	renamed.UpdatedAt = m.now().UTC()
+	renamed.Version++
+	m.users[i] = renamed
+	m.record(change)
+
+	return nil
+}
+
+// GetUsersByNames retrieves the users with the given names that are not deleted. Names that match
+// no user are left out.
+func (m *Memory) GetUsersByNames(names []string, _ *gofr.Context) ([]entities.Users, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]entities.Users, 0, len(names))
+
+	for _, name := range names {
+		if i := m.find(name); i >= 0 && m.users[i].DeletedAt == nil {
+			users = append(users, m.users[i])
+		}
+	}
+
+	return users, nil
+}
+
+// WriteBatch applies writes in order and returns the error of each of them, see UsersList.WriteBatch.
+// An atomic batch that fails is undone by restoring the users, audit log and outbox it started from.
+func (m *Memory) WriteBatch(writes []entities.BatchWrite, atomic bool, _ *gofr.Context) []error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var undo func()
+	if atomic {
+		undo = m.snapshot()
+	}
+
+	errs := make([]error, len(writes))
+
+	for i := range writes {
+		w := &writes[i]
+
+		switch w.Op {
+		case entities.BatchCreate:
+			errs[i] = m.add(&w.User, w.Change)
+		case entities.BatchUpdate:
+			errs[i] = m.update(&w.User, w.Change)
+		case entities.BatchDelete:
+			errs[i] = m.delete(w.User.ID, w.User.Version, w.Change)
+		}
+
+		if errs[i] != nil && atomic {
+			undo()
+
+			return abortBatch(len(writes), i, errs[i])
+		}
+	}
+
+	return errs
+}
+
+// snapshot returns a function restoring the users, audit log and outbox to their current state.
+// The caller must hold the write lock while taking and restoring the snapshot.
+func (m *Memory) snapshot() func() {
+	users, audit, outbox := slices.Clone(m.users), len(m.audit), len(m.outbox)
+	lastAuditID, lastOutboxID := m.lastAuditID, m.lastOutboxID
+
+	return func() {
+		m.users, m.audit, m.outbox = users, m.audit[:audit], m.outbox[:outbox]
+		m.lastAuditID, m.lastOutboxID = lastAuditID, lastOutboxID
+	}
+}
+
+// PendingOutbox returns up to limit messages of the outbox that were not published yet, oldest first.
+func (m *Memory) PendingOutbox(limit int, _ *gofr.Context) ([]entities.OutboxMessage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return slices.Clone(m.outbox[:min(limit, len(m.outbox))]), nil
+}
+
+// MarkOutboxSent removes the published outbox message with the given id.
+func (m *Memory) MarkOutboxSent(id int64, _ *gofr.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.outbox = slices.DeleteFunc(m.outbox, func(message entities.OutboxMessage) bool {
+		return message.ID == id
+	})
+
+	return nil
+}
+
+// GetAuditLog returns one page of the audit log entries matching the query, newest first.
+func (m *Memory) GetAuditLog(query entities.AuditQuery, _ *gofr.Context) (entities.AuditPage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	page := entities.AuditPage{Entries: make([]entities.AuditEntry, 0, query.Limit)}
+
+	for i := len(m.audit) - 1; i >= 0; i-- {
+		entry := m.audit[i]
+
+		switch {
+		case query.Before != nil && entry.ID >= query.Before.ID,
+			query.UserID != "" && entry.UserID != query.UserID,
+			query.Actor != "" && entry.Actor != query.Actor,
+			!query.From.IsZero() && entry.Timestamp.Before(query.From),
+			!query.To.IsZero() && !entry.Timestamp.Before(query.To):
+			continue
+		}
+
+		if len(page.Entries) == query.Limit {
+			page.NextCursor = entities.AuditCursor{ID: page.Entries[query.Limit-1].ID}.Encode()
+
+			break
+		}
+
+		page.Entries = append(page.Entries, entry)
+	}
+
+	return page, nil
+}
+
+// record appends the audit entry of change to the audit log and its event to the outbox. The
+// caller must hold the write lock.
+func (m *Memory) record(change *entities.Change) {
+	if change == nil {
+		return
+	}
+
+	m.lastAuditID++
+
+	entry := change.Audit
+	entry.ID = m.lastAuditID
+	m.audit = append(m.audit, entry)
+
+	if change.Event != nil {
+		m.lastOutboxID++
+
+		message := *change.Event
+		message.ID = m.lastOutboxID
+		m.outbox = append(m.outbox, message)
+	}
+}
+
+// find returns the index of the user with the given name, deleted or not, or -1.
+func (m *Memory) find(name string) int {
+	for i := range m.users {
+		if strings.EqualFold(m.users[i].UserName, name) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// findID returns the index of the user with the given id, or -1.
+func (m *Memory) findID(id string) int {
+	for i := range m.users {
+		if m.users[i].ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// current returns i if it is the index of a user that is not deleted and at the given version, the
+// rows a versioned UPDATE of UsersList matches.
+func (m *Memory) current(i, version int) (int, error) {
+	if i < 0 || m.users[i].DeletedAt != nil || m.users[i].Version != version {
+		return -1, apperrors.Conflict("user was modified concurrently", nil)
+	}
+
+	return i, nil
+}
+
+// unique returns the error of the unique index of the User table that user would violate,
+// ignoring the user stored at index self.
+func (m *Memory) unique(user *entities.Users, self int) error {
+	for i := range m.users {
+		if i == self {
+			continue
+		}
+
+		switch other := &m.users[i]; {
+		case strings.EqualFold(other.UserName, user.UserName):
+			return apperrors.AlreadyExists("user", "name", user.UserName)
+		case strings.EqualFold(other.PhoneNumber, user.PhoneNumber):
+			return apperrors.AlreadyExists("user", "phone number", user.PhoneNumber)
+		case strings.EqualFold(other.Email, user.Email):
+			return apperrors.AlreadyExists("user", "email", user.Email)
+		}
+	}
+
+	return nil
+}
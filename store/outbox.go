@@ -0,0 +1,41 @@
+package store
+
+import (
+	"gofr.dev/pkg/gofr"
+	"gofrProject/entities"
+	"time"
+)
+
+// PendingOutbox returns up to limit messages of the outbox that were not published yet, oldest first.
+func (userStore *UsersList) PendingOutbox(limit int, ctx *gofr.Context) ([]entities.OutboxMessage, error) {
+	rows, err := ctx.SQL.Query(sqlFor(ctx, `SELECT ID, Topic, Payload, CreatedAt FROM "UserOutbox" WHERE SentAt IS NULL `+
+		"ORDER BY ID LIMIT ?"), limit)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	var messages []entities.OutboxMessage
+
+	for rows.Next() {
+		var message entities.OutboxMessage
+		if err := rows.Scan(&message.ID, &message.Topic, &message.Payload, &message.CreatedAt); err != nil {
+			return nil, mapError(err)
+		}
+
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, mapError(err)
+	}
+
+	return messages, nil
+}
+
+// MarkOutboxSent records that the outbox message with the given id was published.
+func (userStore *UsersList) MarkOutboxSent(id int64, ctx *gofr.Context) error {
+	_, err := ctx.SQL.Exec(sqlFor(ctx, `UPDATE "UserOutbox" SET SentAt = ? WHERE ID = ?`), time.Now().UTC(), id)
+
+	return mapError(err)
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gofr.dev/pkg/gofr"
+	"gofr.dev/pkg/gofr/container"
+	"gofr.dev/pkg/gofr/datasource"
+	"gofrProject/entities"
+)
+
+func TestPendingOutbox(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	createdAt := time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC)
+	query := "SELECT ID, Topic, Payload, CreatedAt FROM `UserOutbox` WHERE SentAt IS NULL ORDER BY ID LIMIT ?"
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse []entities.OutboxMessage
+		expectedError    error
+	}{
+		{
+			name: "Pending messages",
+			mockExpect: func() {
+				mock.SQL.ExpectQuery(query).WithArgs(10).
+					WillReturnRows(sqlmock.NewRows([]string{"ID", "Topic", "Payload", "CreatedAt"}).
+						AddRow(3, "users", `{"type":"user.created"}`, createdAt))
+			},
+			expectedResponse: []entities.OutboxMessage{
+				{ID: 3, Topic: "users", Payload: []byte(`{"type":"user.created"}`), CreatedAt: createdAt},
+			},
+		},
+		{
+			name: "Error while reading the outbox",
+			mockExpect: func() {
+				mock.SQL.ExpectQuery(query).WithArgs(10).WillReturnError(fmt.Errorf("db error"))
+			},
+			expectedError: datasource.ErrorDB{Err: fmt.Errorf("db error"), Message: "error from sql db"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			store := NewDetails()
+			messages, err := store.PendingOutbox(10, ctx)
+
+			assert.Equal(t, tt.expectedResponse, messages, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestMarkOutboxSent(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	mock.SQL.ExpectExec("UPDATE `UserOutbox` SET SentAt = ? WHERE ID = ?").WithArgs(sqlmock.AnyArg(), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, NewDetails().MarkOutboxSent(3, ctx))
+}
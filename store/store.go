@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"gofr.dev/pkg/gofr"
-	"gofr.dev/pkg/gofr/datasource"
+	"gofrProject/apperrors"
+	"gofrProject/dialect"
 	"gofrProject/entities"
-	"log"
+	"time"
 )
 
 // UsersList is a struct that represents the user store with a connection to the database.
+// Its queries are rewritten for the dialect of the database, see package dialect.
 type UsersList struct {
 	db *sql.DB
 }
@@ -20,75 +22,410 @@ func NewDetails() *UsersList {
 	return &UsersList{}
 }
 
-// GetUsers retrieves all users from the database.
-func (userStore *UsersList) GetUsers(ctx *gofr.Context) ([]entities.Users, error) {
-	// Query the database for all users.
-	rows, err := ctx.SQL.Query("SELECT UserName, UserAge, PhoneNumber, Email FROM User")
+// userColumns are the columns of the User table selected into entities.Users by scanUser.
+const userColumns = "ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version"
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row scanner) (entities.Users, error) {
+	var (
+		user                 entities.Users
+		createdAt, updatedAt sql.NullTime
+	)
+
+	err := row.Scan(&user.ID, &user.UserName, &user.UserAge, &user.PhoneNumber, &user.Email, &user.DeletedAt,
+		&createdAt, &updatedAt, &user.Version)
+	user.CreatedAt, user.UpdatedAt = createdAt.Time, updatedAt.Time
+
+	return user, err
+}
+
+// now returns the current time at the precision of the DATETIME columns of MySQL, which would
+// otherwise round it to a different second than the one given back to the caller.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// GetUsers retrieves one page of users matching the query, ordered by the requested column
+// with UserName as tie-breaker so that the keyset cursor is stable.
+func (userStore *UsersList) GetUsers(query entities.UsersQuery, ctx *gofr.Context) (entities.UsersPage, error) {
+	where, args := usersFilter(query)
+
+	// Count every user matching the filters, regardless of the page being requested.
+	var total int
+	if err := ctx.SQL.QueryRow(sqlFor(ctx, `SELECT COUNT(*) FROM "User"`+where), args...).Scan(&total); err != nil {
+		return entities.UsersPage{}, mapError(err)
+	}
+
+	if query.After != nil {
+		keyset, keysetArgs := usersKeyset(query)
+		where, args = appendCondition(where, keyset), append(args, keysetArgs...)
+	}
+
+	// Fetch one extra row to find out whether there is a next page.
+	rows, err := ctx.SQL.Query(sqlFor(ctx, "SELECT "+userColumns+` FROM "User"`+where+usersOrder(query)+" LIMIT ?"),
+		append(args, query.Limit+1)...)
 	if err != nil {
-		// Return a custom error if the SQL query fails.
-		dbErr := datasource.ErrorDB{Err: fmt.Errorf("some db error"), Message: "error from sql db"}
-		return nil, dbErr
+		// Translate the driver error if the SQL query fails.
+		return entities.UsersPage{}, mapError(err)
 	}
 	defer rows.Close()
 
-	var users []entities.Users
+	users := make([]entities.Users, 0, query.Limit)
 	// Iterate through the rows and scan the user details into the struct.
 	for rows.Next() {
-		var user entities.Users
-		if err := rows.Scan(&user.UserName, &user.UserAge, &user.PhoneNumber, &user.Email); err != nil {
-			return nil, err
+		user, err := scanUser(rows)
+		if err != nil {
+			return entities.UsersPage{}, mapError(err)
 		}
 		users = append(users, user)
 	}
-	// Return nil if no users are found.
-	if len(users) == 0 {
-		return nil, nil
+
+	if err := rows.Err(); err != nil {
+		return entities.UsersPage{}, mapError(err)
+	}
+
+	page := entities.UsersPage{Users: users, TotalCount: total}
+
+	if len(users) > query.Limit {
+		page.Users = users[:query.Limit]
+		last := page.Users[query.Limit-1]
+		page.NextCursor = entities.UserCursor{UserName: last.UserName, UserAge: last.UserAge}.Encode()
+	}
+
+	return page, nil
+}
+
+// StreamUsers calls fn with every user matching the filters of the query, in the order of GetUsers,
+// while reading them from the database cursor. Limit and cursor of the query are ignored. It stops
+// at the first error of fn and returns it.
+func (userStore *UsersList) StreamUsers(query entities.UsersQuery, fn func(user entities.Users) error,
+	ctx *gofr.Context) error {
+	where, args := usersFilter(query)
+
+	rows, err := ctx.SQL.Query(sqlFor(ctx, "SELECT "+userColumns+` FROM "User"`+where+usersOrder(query)), args...)
+	if err != nil {
+		return mapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return mapError(err)
+		}
+
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	return mapError(rows.Err())
+}
+
+// usersFilter builds the WHERE clause for the filters of the query.
+func usersFilter(query entities.UsersQuery) (string, []any) {
+	var (
+		where string
+		args  []any
+	)
+
+	if !query.IncludeDeleted {
+		where = appendCondition(where, notDeleted)
+	}
+
+	if query.MinAge != nil {
+		where = appendCondition(where, "UserAge >= ?")
+		args = append(args, *query.MinAge)
+	}
+
+	if query.MaxAge != nil {
+		where = appendCondition(where, "UserAge <= ?")
+		args = append(args, *query.MaxAge)
+	}
+
+	if query.EmailDomain != "" {
+		where = appendCondition(where, "Email LIKE ?")
+		args = append(args, "%@"+query.EmailDomain)
 	}
 
-	return users, nil
+	return where, args
+}
+
+// usersKeyset builds the condition selecting the rows after the cursor in the requested order.
+func usersKeyset(query entities.UsersQuery) (string, []any) {
+	op := ">"
+	if query.Descending {
+		op = "<"
+	}
+
+	if query.SortBy == entities.SortByUserAge {
+		return "(UserAge " + op + " ? OR (UserAge = ? AND UserName " + op + " ?))",
+			[]any{query.After.UserAge, query.After.UserAge, query.After.UserName}
+	}
+
+	return "UserName " + op + " ?", []any{query.After.UserName}
+}
+
+// usersOrder builds the ORDER BY clause of the query.
+func usersOrder(query entities.UsersQuery) string {
+	direction := " ASC"
+	if query.Descending {
+		direction = " DESC"
+	}
+
+	if query.SortBy == entities.SortByUserAge {
+		return " ORDER BY UserAge" + direction + ", UserName" + direction
+	}
+
+	return " ORDER BY UserName" + direction
+}
+
+// notDeleted excludes soft-deleted users.
+const notDeleted = "DeletedAt IS NULL"
+
+func appendCondition(where, condition string) string {
+	if where == "" {
+		return " WHERE " + condition
+	}
+
+	return where + " AND " + condition
 }
 
 // GetUsersByName retrieves a single user by their username.
 func (userStore *UsersList) GetUsersByName(name string, ctx *gofr.Context) (entities.Users, error) {
-	var user entities.Users
 	// Query the database for a user by their username.
-	err := ctx.SQL.QueryRow("SELECT UserName, UserAge, PhoneNumber, Email FROM User WHERE Username = ?", name).
-		Scan(&user.UserName, &user.UserAge, &user.PhoneNumber, &user.Email)
+	user, err := scanUser(ctx.SQL.QueryRow(sqlFor(ctx, "SELECT "+userColumns+` FROM "User" WHERE Username = ? AND `+notDeleted), name))
 	// If no user is found, return an error.
 	if errors.Is(err, sql.ErrNoRows) {
-		return entities.Users{}, fmt.Errorf("user with name '%v'not found", name)
+		return entities.Users{}, apperrors.NotFound("user", "name", name)
+	}
+
+	if err != nil {
+		return entities.Users{}, mapError(err)
 	}
 	// Return the user if found.
 	return user, nil
 }
 
-// AddUsers inserts a new user into the database.
-func (userStore *UsersList) AddUsers(user *entities.Users, ctx *gofr.Context) error {
-	log.Printf("Inserting user: %+v", user)
+// GetUsersByID retrieves a single user by their id.
+func (userStore *UsersList) GetUsersByID(id string, ctx *gofr.Context) (entities.Users, error) {
+	user, err := scanUser(ctx.SQL.QueryRow(sqlFor(ctx, "SELECT "+userColumns+` FROM "User" WHERE ID = ? AND `+notDeleted), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Users{}, apperrors.NotFound("user", "id", id)
+	}
+
+	if err != nil {
+		return entities.Users{}, mapError(err)
+	}
+
+	return user, nil
+}
+
+// AddUsers inserts a new user into the database, recording change with it if it is not nil.
+func (userStore *UsersList) AddUsers(user *entities.Users, change *entities.Change, ctx *gofr.Context) error {
 	// Check if UserName or PhoneNumber is empty
 	if user.UserName == "" || user.PhoneNumber == "" {
-		return fmt.Errorf("UserName and PhoneNumber cannot be empty")
+		return apperrors.Validation(fmt.Errorf("UserName and PhoneNumber cannot be empty"))
 	}
-	//  Exec the database for addding the user .
-	_, err := ctx.SQL.Exec("INSERT INTO User (UserName, UserAge, PhoneNumber, Email) VALUES (?, ?, ?, ?)",
-		user.UserName, user.UserAge, user.PhoneNumber, user.Email)
-	// If unable to add user, return error
+	return write(ctx, change, func(db execer) error {
+		return insertUsers(db, []*entities.Users{user}, ctx)
+	})
+}
+
+// insertUsers inserts users with a single statement and sets their timestamps. The error of a
+// statement inserting one user names the unique field it conflicts on.
+func insertUsers(db execer, users []*entities.Users, ctx *gofr.Context) error {
+	createdAt := now()
+
+	args := make([]any, 0, len(users)*7)
+	for _, user := range users {
+		user.CreatedAt, user.UpdatedAt = createdAt, createdAt
+		args = append(args, user.ID, user.UserName, user.UserAge, user.PhoneNumber, user.Email, createdAt, createdAt)
+	}
+
+	_, err := db.Exec(sqlFor(ctx, `INSERT INTO "User" (ID, UserName, UserAge, PhoneNumber, Email, CreatedAt, UpdatedAt) VALUES `+
+		rowValues(len(users), 7)), args...)
+
+	if len(users) == 1 {
+		return userConflict(mapError(err), users[0])
+	}
+
+	return mapError(err)
+}
+
+// DeleteUsers soft-deletes user, found by its id, by setting its DeletedAt timestamp. The row is kept
+// until it is purged. It fails with a conflict unless the user is still at user.Version. change is
+// recorded with it.
+func (userStore *UsersList) DeleteUsers(user entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	return write(ctx, change, func(db execer) error {
+		return deleteRow(db, user.ID, user.Version, ctx)
+	})
+}
+
+// deleteRow runs the versioned soft delete of DeleteUsers.
+func deleteRow(db execer, id string, version int, ctx *gofr.Context) error {
+	deletedAt := now()
+
+	res, err := db.Exec(sqlFor(ctx, `UPDATE "User" SET DeletedAt = ?, UpdatedAt = ?, Version = Version + 1 `+
+		"WHERE ID = ? AND Version = ? AND "+notDeleted), deletedAt, deletedAt, id, version)
 	if err != nil {
-		dbErr := datasource.ErrorDB{Err: err, Message: "error from sql db"}
-		return dbErr
+		return mapError(err)
 	}
 
-	return nil
+	return checkVersion(res)
 }
 
-// DeleteUsers a user from the database.
-func (userStore *UsersList) DeleteUsers(name string, ctx *gofr.Context) error {
-	_, err := ctx.SQL.Exec("DELETE FROM User WHERE UserName = ?", name)
-	return err
+// GetDeletedUsersByName retrieves a single soft-deleted user by their username.
+func (userStore *UsersList) GetDeletedUsersByName(name string, ctx *gofr.Context) (entities.Users, error) {
+	user, err := scanUser(ctx.SQL.QueryRow(sqlFor(ctx, "SELECT "+userColumns+` FROM "User" WHERE UserName = ? AND DeletedAt IS NOT NULL`),
+		name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Users{}, apperrors.NotFound("deleted user", "name", name)
+	}
+
+	if err != nil {
+		return entities.Users{}, mapError(err)
+	}
+
+	return user, nil
 }
 
-// UpdateUsers a user from the database.
-func (userStore *UsersList) UpdateUsers(name string, updateUser *entities.Users, ctx *gofr.Context) error {
-	_, err := ctx.SQL.Exec("UPDATE User SET Email = ? WHERE UserName = ?", updateUser.Email, name)
-	return err
+// RestoreUsers clears the DeletedAt timestamp of user, a soft-deleted user found by its id. It fails
+// with a conflict unless the user is still deleted and at user.Version. change is recorded with it.
+func (userStore *UsersList) RestoreUsers(user entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	return write(ctx, change, func(db execer) error {
+		res, err := db.Exec(sqlFor(ctx, `UPDATE "User" SET DeletedAt = NULL, UpdatedAt = ?, Version = Version + 1 `+
+			"WHERE ID = ? AND Version = ? AND DeletedAt IS NOT NULL"), now(), user.ID, user.Version)
+		if err != nil {
+			return mapError(err)
+		}
+
+		return checkVersion(res)
+	})
+}
+
+// PurgeUsers permanently removes the users soft-deleted before the given time and returns how many were removed.
+// change returns what is recorded together with the removal of a user, in the same transaction.
+func (userStore *UsersList) PurgeUsers(before time.Time, change func(user entities.Users) (*entities.Change, error),
+	ctx *gofr.Context) (int64, error) {
+	tx, err := ctx.SQL.Begin()
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	changes, err := purgeRows(tx, before, change, ctx)
+	if err == nil {
+		err = mapError(record(tx, changes, ctx))
+	}
+
+	if err != nil {
+		_ = tx.Rollback()
+
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, mapError(err)
+	}
+
+	return int64(len(changes)), nil
+}
+
+// purgeRows removes the users soft-deleted before the given time one at a time, skipping those that
+// were restored since they were read, and returns the changes of the removed users.
+func purgeRows(db execer, before time.Time, change func(user entities.Users) (*entities.Change, error),
+	ctx *gofr.Context) ([]*entities.Change, error) {
+	users, err := queryUsers(db, sqlFor(ctx, "SELECT "+userColumns+` FROM "User" WHERE DeletedAt < ?`),
+		[]any{before.UTC()})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*entities.Change, 0, len(users))
+
+	for _, user := range users {
+		res, err := db.Exec(sqlFor(ctx, `DELETE FROM "User" WHERE ID = ? AND DeletedAt < ?`), user.ID, before.UTC())
+		if err != nil {
+			return nil, mapError(err)
+		}
+
+		purged, err := res.RowsAffected()
+		if err != nil {
+			return nil, mapError(err)
+		}
+
+		if purged == 0 {
+			continue
+		}
+
+		purge, err := change(user)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, purge)
+	}
+
+	return changes, nil
+}
+
+// UpdateUsers replaces all mutable fields of the user with the id of updateUser in the database. It
+// fails with a conflict unless the stored user is still at updateUser.Version, i.e. nobody changed it
+// since it was read. change is recorded with it.
+func (userStore *UsersList) UpdateUsers(updateUser *entities.Users, change *entities.Change, ctx *gofr.Context) error {
+	return write(ctx, change, func(db execer) error {
+		return updateRow(db, updateUser, ctx)
+	})
+}
+
+// updateRow runs the versioned UPDATE of UpdateUsers and sets the UpdatedAt of updateUser.
+func updateRow(db execer, updateUser *entities.Users, ctx *gofr.Context) error {
+	updateUser.UpdatedAt = now()
+
+	res, err := db.Exec(sqlFor(ctx, `UPDATE "User" SET UserAge = ?, PhoneNumber = ?, Email = ?, UpdatedAt = ?, `+
+		"Version = Version + 1 WHERE ID = ? AND Version = ? AND "+notDeleted),
+		updateUser.UserAge, updateUser.PhoneNumber, updateUser.Email, updateUser.UpdatedAt, updateUser.ID, updateUser.Version)
+	if err != nil {
+		return mapError(err)
+	}
+
+	return checkVersion(res)
+}
+
+// RenameUsers changes the name of the user with the given id. It fails with a conflict unless the
+// user is still at the given version. change is recorded with it.
+func (userStore *UsersList) RenameUsers(id, name string, version int, change *entities.Change, ctx *gofr.Context) error {
+	return write(ctx, change, func(db execer) error {
+		res, err := db.Exec(sqlFor(ctx, `UPDATE "User" SET UserName = ?, UpdatedAt = ?, Version = Version + 1 `+
+			"WHERE ID = ? AND Version = ? AND "+notDeleted), name, now(), id, version)
+		if err != nil {
+			return userConflict(mapError(err), &entities.Users{ID: id, UserName: name})
+		}
+
+		return checkVersion(res)
+	})
+}
+
+// sqlFor rewrites query, written with double-quoted identifiers and ? placeholders, for the dialect
+// of the database behind ctx.
+func sqlFor(ctx *gofr.Context, query string) string {
+	return dialect.Dialect(ctx.SQL.Dialect()).SQL(query)
+}
+
+// checkVersion returns a conflict if a write guarded by the version of the user matched no row.
+func checkVersion(res sql.Result) error {
+	written, err := res.RowsAffected()
+	if err != nil {
+		return mapError(err)
+	}
+
+	if written == 0 {
+		return apperrors.Conflict("user was modified concurrently", nil)
+	}
+
+	return nil
 }
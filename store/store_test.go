@@ -3,14 +3,18 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
 	"gofr.dev/pkg/gofr/datasource"
 	"golang.org/x/net/context"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"gofr.dev/pkg/gofr"
 	"gofr.dev/pkg/gofr/container"
+	"gofrProject/apperrors"
 	"gofrProject/entities"
 )
 
@@ -21,48 +25,138 @@ func TestGetUsers(t *testing.T) {
 		Container: mockContainer,
 	}
 
+	minAge := 18
+	deletedAt := time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"ID", "UserName", "UserAge", "PhoneNumber", "Email", "DeletedAt", "CreatedAt", "UpdatedAt", "Version"}
+
 	tests := []struct {
 		name             string
+		query            entities.UsersQuery
 		mockExpect       func()
 		expectedResponse interface{}
 		expectedError    error
 	}{
 		{
-			name: "Successful retrieval of users",
+			name:  "Successful retrieval of users",
+			query: entities.UsersQuery{Limit: 20, SortBy: entities.SortByUserName},
+			mockExpect: func() {
+				mock.SQL.ExpectQuery("SELECT COUNT(*) FROM `User` WHERE DeletedAt IS NULL").
+					WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` WHERE DeletedAt IS NULL ORDER BY UserName ASC LIMIT ?").
+					WithArgs(21).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", "John Doe", 30, "123-456-7890", "john@example.com", nil, nil, nil, 1))
+			},
+			expectedResponse: entities.UsersPage{
+				Users: []entities.Users{
+					{
+						ID:          "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
+						UserName:    "John Doe",
+						UserAge:     30,
+						PhoneNumber: "123-456-7890",
+						Email:       "john@example.com",
+						Version:     1,
+					},
+				},
+				TotalCount: 1,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Filtered page sorted by age with next cursor",
+			query: entities.UsersQuery{
+				Limit:       1,
+				After:       &entities.UserCursor{UserName: "Adam", UserAge: 25},
+				SortBy:      entities.SortByUserAge,
+				Descending:  true,
+				MinAge:      &minAge,
+				EmailDomain: "example.com",
+			},
 			mockExpect: func() {
-				mock.SQL.ExpectQuery("SELECT UserName, UserAge, PhoneNumber, Email FROM User").
-					WillReturnRows(sqlmock.NewRows([]string{"UserName", "UserAge", "PhoneNumber", "Email"}).
-						AddRow("John Doe", 30, "123-456-7890", "john@example.com"))
+				mock.SQL.ExpectQuery("SELECT COUNT(*) FROM `User` WHERE DeletedAt IS NULL AND UserAge >= ? AND Email LIKE ?").
+					WithArgs(18, "%@example.com").
+					WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` "+
+					"WHERE DeletedAt IS NULL AND UserAge >= ? AND Email LIKE ? AND (UserAge < ? OR (UserAge = ? AND UserName < ?)) "+
+					"ORDER BY UserAge DESC, UserName DESC LIMIT ?").
+					WithArgs(18, "%@example.com", 25, 25, "Adam", 2).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e10", "Zoe", 21, "123-456-7890", "zoe@example.com", nil, nil, nil, 1).
+						AddRow("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e11", "Bob", 19, "123-456-7891", "bob@example.com", nil, nil, nil, 1))
 			},
-			expectedResponse: []entities.Users{
-				{
-					UserName:    "John Doe",
-					UserAge:     30,
-					PhoneNumber: "123-456-7890",
-					Email:       "john@example.com",
+			expectedResponse: entities.UsersPage{
+				Users: []entities.Users{
+					{
+						ID:          "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e10",
+						UserName:    "Zoe",
+						UserAge:     21,
+						PhoneNumber: "123-456-7890",
+						Email:       "zoe@example.com",
+						Version:     1,
+					},
 				},
+				NextCursor: entities.UserCursor{UserName: "Zoe", UserAge: 21}.Encode(),
+				TotalCount: 3,
 			},
 			expectedError: nil,
 		},
 		{
-			name: "Error while fetching users",
+			name:  "Error while fetching users",
+			query: entities.UsersQuery{Limit: 20, SortBy: entities.SortByUserName},
 			mockExpect: func() {
-				mock.SQL.ExpectQuery("SELECT UserName, UserAge, PhoneNumber, Email FROM User").
+				mock.SQL.ExpectQuery("SELECT COUNT(*) FROM `User` WHERE DeletedAt IS NULL").
+					WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` WHERE DeletedAt IS NULL ORDER BY UserName ASC LIMIT ?").
+					WithArgs(21).
 					WillReturnError(fmt.Errorf("some db error"))
 			},
-			expectedResponse: []entities.Users([]entities.Users(nil)),
+			expectedResponse: entities.UsersPage{},
 			expectedError: datasource.ErrorDB{
 				Err:     fmt.Errorf("some db error"),
 				Message: "error from sql db",
 			},
 		},
 		{
-			name: "No users found",
+			name:  "Deleted users included",
+			query: entities.UsersQuery{Limit: 20, SortBy: entities.SortByUserName, IncludeDeleted: true},
+			mockExpect: func() {
+				mock.SQL.ExpectQuery("SELECT COUNT(*) FROM `User`").
+					WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` ORDER BY UserName ASC LIMIT ?").
+					WithArgs(21).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", "John Doe", 30, "123-456-7890", "john@example.com", deletedAt, createdAt, deletedAt, 1))
+			},
+			expectedResponse: entities.UsersPage{
+				Users: []entities.Users{
+					{
+						ID:          "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
+						UserName:    "John Doe",
+						UserAge:     30,
+						PhoneNumber: "123-456-7890",
+						Email:       "john@example.com",
+						DeletedAt:   &deletedAt,
+						CreatedAt:   createdAt,
+						UpdatedAt:   deletedAt,
+						Version:     1,
+					},
+				},
+				TotalCount: 1,
+			},
+			expectedError: nil,
+		},
+		{
+			name:  "No users found",
+			query: entities.UsersQuery{Limit: 20, SortBy: entities.SortByUserName},
 			mockExpect: func() {
-				mock.SQL.ExpectQuery("SELECT UserName, UserAge, PhoneNumber, Email FROM User").
-					WillReturnRows(sqlmock.NewRows([]string{"UserName", "UserAge", "PhoneNumber", "Email"}))
+				mock.SQL.ExpectQuery("SELECT COUNT(*) FROM `User` WHERE DeletedAt IS NULL").
+					WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(0))
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` WHERE DeletedAt IS NULL ORDER BY UserName ASC LIMIT ?").
+					WithArgs(21).
+					WillReturnRows(sqlmock.NewRows(columns))
 			},
-			expectedResponse: []entities.Users([]entities.Users(nil)),
+			expectedResponse: entities.UsersPage{Users: []entities.Users{}},
 			expectedError:    nil,
 		},
 	}
@@ -72,9 +166,86 @@ func TestGetUsers(t *testing.T) {
 			tt.mockExpect()
 
 			store := NewDetails()
-			users, err := store.GetUsers(ctx)
+			page, err := store.GetUsers(tt.query, ctx)
 
-			assert.Equal(t, tt.expectedResponse, users, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedResponse, page, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestStreamUsers(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	minAge := 18
+	columns := []string{"ID", "UserName", "UserAge", "PhoneNumber", "Email", "DeletedAt", "CreatedAt", "UpdatedAt", "Version"}
+	waheed := entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", UserName: "waheed", UserAge: 19,
+		PhoneNumber: "+14155552671", Email: "waheed@example.com", Version: 3}
+	errStop := fmt.Errorf("client went away")
+
+	tests := []struct {
+		name          string
+		query         entities.UsersQuery
+		fnErr         error
+		mockExpect    func()
+		expectedUsers []entities.Users
+		expectedError error
+	}{
+		{
+			name:  "Every matching user is streamed without limit",
+			query: entities.UsersQuery{Limit: 1, SortBy: entities.SortByUserAge, MinAge: &minAge},
+			mockExpect: func() {
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` " +
+					"WHERE DeletedAt IS NULL AND UserAge >= ? ORDER BY UserAge ASC, UserName ASC").
+					WithArgs(18).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(waheed.ID, "waheed", 19, "+14155552671", "waheed@example.com", nil, nil, nil, 3).
+						AddRow(waheed.ID, "waheed", 19, "+14155552671", "waheed@example.com", nil, nil, nil, 3))
+			},
+			expectedUsers: []entities.Users{waheed, waheed},
+		},
+		{
+			name:  "Streaming stops at the first error of fn",
+			query: entities.UsersQuery{SortBy: entities.SortByUserName},
+			fnErr: errStop,
+			mockExpect: func() {
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` " +
+					"WHERE DeletedAt IS NULL ORDER BY UserName ASC").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(waheed.ID, "waheed", 19, "+14155552671", "waheed@example.com", nil, nil, nil, 3).
+						AddRow(waheed.ID, "waheed", 19, "+14155552671", "waheed@example.com", nil, nil, nil, 3))
+			},
+			expectedUsers: []entities.Users{waheed},
+			expectedError: errStop,
+		},
+		{
+			name:  "Query error",
+			query: entities.UsersQuery{SortBy: entities.SortByUserName},
+			mockExpect: func() {
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` " +
+					"WHERE DeletedAt IS NULL ORDER BY UserName ASC").
+					WillReturnError(fmt.Errorf("db error"))
+			},
+			expectedError: datasource.ErrorDB{Err: fmt.Errorf("db error"), Message: "error from sql db"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			var users []entities.Users
+
+			err := NewDetails().StreamUsers(tt.query, func(user entities.Users) error {
+				users = append(users, user)
+				return tt.fnErr
+			}, ctx)
+
+			assert.Equal(t, tt.expectedUsers, users, "TEST[%d] failed: %s", i, tt.name)
 			assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
 		})
 	}
@@ -98,16 +269,18 @@ func TestGetUsersByName(t *testing.T) {
 			name:     "User found",
 			username: "John Doe",
 			mockExpect: func() {
-				mock.SQL.ExpectQuery("SELECT UserName, UserAge, PhoneNumber, Email FROM User WHERE Username = ?").
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` WHERE Username = ? AND DeletedAt IS NULL").
 					WithArgs("John Doe").
-					WillReturnRows(sqlmock.NewRows([]string{"UserName", "UserAge", "PhoneNumber", "Email"}).
-						AddRow("John Doe", 30, "123-456-7890", "john@example.com"))
+					WillReturnRows(sqlmock.NewRows([]string{"ID", "UserName", "UserAge", "PhoneNumber", "Email", "DeletedAt", "CreatedAt", "UpdatedAt", "Version"}).
+						AddRow("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", "John Doe", 30, "123-456-7890", "john@example.com", nil, nil, nil, 1))
 			},
 			expectedResponse: entities.Users{
+				ID:          "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
 				UserName:    "John Doe",
 				UserAge:     30,
 				PhoneNumber: "123-456-7890",
 				Email:       "john@example.com",
+				Version:     1,
 			},
 			expectedError: nil,
 		},
@@ -115,12 +288,23 @@ func TestGetUsersByName(t *testing.T) {
 			name:     "User not found",
 			username: "Jane Doe",
 			mockExpect: func() {
-				mock.SQL.ExpectQuery("SELECT UserName, UserAge, PhoneNumber, Email FROM User WHERE Username = ?").
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` WHERE Username = ? AND DeletedAt IS NULL").
 					WithArgs("Jane Doe").
 					WillReturnError(sql.ErrNoRows)
 			},
 			expectedResponse: entities.Users{},
-			expectedError:    fmt.Errorf("user with name 'Jane Doe'not found"),
+			expectedError:    apperrors.NotFound("user", "name", "Jane Doe"),
+		},
+		{
+			name:     "Database unavailable",
+			username: "Jane Doe",
+			mockExpect: func() {
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` WHERE Username = ? AND DeletedAt IS NULL").
+					WithArgs("Jane Doe").
+					WillReturnError(mysql.ErrInvalidConn)
+			},
+			expectedResponse: entities.Users{},
+			expectedError:    apperrors.Unavailable(mysql.ErrInvalidConn),
 		},
 	}
 
@@ -137,6 +321,64 @@ func TestGetUsersByName(t *testing.T) {
 	}
 }
 
+func TestGetUsersByID(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	id := "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f"
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse interface{}
+		expectedError    error
+	}{
+		{
+			name: "User found",
+			mockExpect: func() {
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` WHERE ID = ? AND DeletedAt IS NULL").
+					WithArgs(id).
+					WillReturnRows(sqlmock.NewRows([]string{"ID", "UserName", "UserAge", "PhoneNumber", "Email", "DeletedAt", "CreatedAt", "UpdatedAt", "Version"}).
+						AddRow(id, "John Doe", 30, "123-456-7890", "john@example.com", nil, nil, nil, 1))
+			},
+			expectedResponse: entities.Users{
+				ID:          id,
+				UserName:    "John Doe",
+				UserAge:     30,
+				PhoneNumber: "123-456-7890",
+				Email:       "john@example.com",
+				Version:     1,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "User not found",
+			mockExpect: func() {
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` WHERE ID = ? AND DeletedAt IS NULL").
+					WithArgs(id).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedResponse: entities.Users{},
+			expectedError:    apperrors.NotFound("user", "id", id),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			store := NewDetails()
+			user, err := store.GetUsersByID(id, ctx)
+
+			assert.Equal(t, tt.expectedResponse, user, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
 func TestAddUsers(t *testing.T) {
 	mockContainer, mock := container.NewMockContainer(t)
 	ctx := &gofr.Context{
@@ -153,14 +395,16 @@ func TestAddUsers(t *testing.T) {
 		{
 			name: "Successful user addition",
 			user: &entities.Users{
+				ID:          "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
 				UserName:    "John Doe",
 				UserAge:     30,
 				PhoneNumber: "123-456-7890",
 				Email:       "john@example.com",
 			},
 			mockExpect: func() {
-				mock.SQL.ExpectExec("INSERT INTO User (UserName, UserAge, PhoneNumber, Email) VALUES (?, ?, ?, ?)").
-					WithArgs("John Doe", 30, "123-456-7890", "john@example.com").
+				mock.SQL.ExpectExec("INSERT INTO `User` (ID, UserName, UserAge, PhoneNumber, Email, CreatedAt, UpdatedAt) VALUES (?, ?, ?, ?, ?, ?, ?)").
+					WithArgs("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", "John Doe", 30, "123-456-7890", "john@example.com",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			expectedResponse: nil,
@@ -174,7 +418,47 @@ func TestAddUsers(t *testing.T) {
 				Email:       "john@example.com",
 			},
 			mockExpect:       func() {},
-			expectedResponse: fmt.Errorf("UserName and PhoneNumber cannot be empty"),
+			expectedResponse: apperrors.Validation(fmt.Errorf("UserName and PhoneNumber cannot be empty")),
+		},
+		{
+			name: "Duplicate user",
+			user: &entities.Users{
+				ID:          "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
+				UserName:    "John Doe",
+				UserAge:     30,
+				PhoneNumber: "123-456-7890",
+				Email:       "john@example.com",
+			},
+			mockExpect: func() {
+				mock.SQL.ExpectExec("INSERT INTO `User` (ID, UserName, UserAge, PhoneNumber, Email, CreatedAt, UpdatedAt) VALUES (?, ?, ?, ?, ?, ?, ?)").
+					WithArgs("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", "John Doe", 30, "123-456-7890", "john@example.com",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'john@example.com' for key 'idx_user_email'"})
+			},
+			expectedResponse: &apperrors.Error{Kind: apperrors.KindAlreadyExists,
+				Message: "user with email 'john@example.com' already exists",
+				Err:     &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'john@example.com' for key 'idx_user_email'"}},
+		},
+		{
+			name: "Duplicate phone number on postgres",
+			user: &entities.Users{
+				ID:          "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
+				UserName:    "John Doe",
+				UserAge:     30,
+				PhoneNumber: "123-456-7890",
+				Email:       "john@example.com",
+			},
+			mockExpect: func() {
+				mock.SQL.ExpectExec("INSERT INTO `User` (ID, UserName, UserAge, PhoneNumber, Email, CreatedAt, UpdatedAt) VALUES (?, ?, ?, ?, ?, ?, ?)").
+					WithArgs("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", "John Doe", 30, "123-456-7890", "john@example.com",
+						sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnError(&pq.Error{Code: "23505",
+						Message: `duplicate key value violates unique constraint "idx_user_phone_number"`})
+			},
+			expectedResponse: &apperrors.Error{Kind: apperrors.KindAlreadyExists,
+				Message: "user with phone number '123-456-7890' already exists",
+				Err: &pq.Error{Code: "23505",
+					Message: `duplicate key value violates unique constraint "idx_user_phone_number"`}},
 		},
 	}
 
@@ -183,7 +467,7 @@ func TestAddUsers(t *testing.T) {
 			tt.mockExpect()
 
 			store := NewDetails()
-			err := store.AddUsers(tt.user, ctx)
+			err := store.AddUsers(tt.user, nil, ctx)
 
 			assert.Equal(t, tt.expectedResponse, err, "TEST[%d] failed: %s", i, tt.name)
 		})
@@ -199,29 +483,145 @@ func TestDeleteUsers(t *testing.T) {
 
 	tests := []struct {
 		name             string
-		username         string
 		mockExpect       func()
 		expectedResponse error
 	}{
 		{
-			name:     "Successful deletion",
-			username: "John Doe",
+			name: "Successful deletion",
 			mockExpect: func() {
-				mock.SQL.ExpectExec("DELETE FROM User WHERE UserName = ?").
-					WithArgs("John Doe").
+				mock.SQL.ExpectExec("UPDATE `User` SET DeletedAt = ?, UpdatedAt = ?, Version = Version + 1 WHERE ID = ? AND Version = ? AND DeletedAt IS NULL").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 2).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			expectedResponse: nil,
 		},
 		{
-			name:     "Error while deleting user",
-			username: "John Doe",
+			name: "Error while deleting user",
+			mockExpect: func() {
+				mock.SQL.ExpectExec("UPDATE `User` SET DeletedAt = ?, UpdatedAt = ?, Version = Version + 1 WHERE ID = ? AND Version = ? AND DeletedAt IS NULL").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 2).
+					WillReturnError(fmt.Errorf("db error"))
+			},
+			expectedResponse: datasource.ErrorDB{Err: fmt.Errorf("db error"), Message: "error from sql db"},
+		},
+		{
+			name: "User modified concurrently",
+			mockExpect: func() {
+				mock.SQL.ExpectExec("UPDATE `User` SET DeletedAt = ?, UpdatedAt = ?, Version = Version + 1 WHERE ID = ? AND Version = ? AND DeletedAt IS NULL").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedResponse: apperrors.Conflict("user was modified concurrently", nil),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			store := NewDetails()
+			err := store.DeleteUsers(entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", UserName: "John Doe", Version: 2}, nil, ctx)
+
+			assert.Equal(t, tt.expectedResponse, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestGetDeletedUsersByName(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	deletedAt := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse entities.Users
+		expectedError    error
+	}{
+		{
+			name: "Deleted user found",
 			mockExpect: func() {
-				mock.SQL.ExpectExec("DELETE FROM User WHERE UserName = ?").
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` WHERE UserName = ? AND DeletedAt IS NOT NULL").
 					WithArgs("John Doe").
+					WillReturnRows(sqlmock.NewRows([]string{"ID", "UserName", "UserAge", "PhoneNumber", "Email", "DeletedAt", "CreatedAt", "UpdatedAt", "Version"}).
+						AddRow("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", "John Doe", 30, "123-456-7890", "john@example.com", deletedAt, nil, nil, 2))
+			},
+			expectedResponse: entities.Users{
+				ID:          "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
+				UserName:    "John Doe",
+				UserAge:     30,
+				PhoneNumber: "123-456-7890",
+				Email:       "john@example.com",
+				DeletedAt:   &deletedAt,
+				Version:     2,
+			},
+		},
+		{
+			name: "User is not deleted",
+			mockExpect: func() {
+				mock.SQL.ExpectQuery("SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` WHERE UserName = ? AND DeletedAt IS NOT NULL").
+					WithArgs("John Doe").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: apperrors.NotFound("deleted user", "name", "John Doe"),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			store := NewDetails()
+			user, err := store.GetDeletedUsersByName("John Doe", ctx)
+
+			assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedResponse, user, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestRestoreUsers(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse error
+	}{
+		{
+			name: "Successful restore",
+			mockExpect: func() {
+				mock.SQL.ExpectExec("UPDATE `User` SET DeletedAt = NULL, UpdatedAt = ?, Version = Version + 1 WHERE ID = ? AND Version = ? AND DeletedAt IS NOT NULL").
+					WithArgs(sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedResponse: nil,
+		},
+		{
+			name: "Error while restoring user",
+			mockExpect: func() {
+				mock.SQL.ExpectExec("UPDATE `User` SET DeletedAt = NULL, UpdatedAt = ?, Version = Version + 1 WHERE ID = ? AND Version = ? AND DeletedAt IS NOT NULL").
+					WithArgs(sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 2).
 					WillReturnError(fmt.Errorf("db error"))
 			},
-			expectedResponse: fmt.Errorf("db error"),
+			expectedResponse: datasource.ErrorDB{Err: fmt.Errorf("db error"), Message: "error from sql db"},
+		},
+		{
+			name: "User restored concurrently",
+			mockExpect: func() {
+				mock.SQL.ExpectExec("UPDATE `User` SET DeletedAt = NULL, UpdatedAt = ?, Version = Version + 1 WHERE ID = ? AND Version = ? AND DeletedAt IS NOT NULL").
+					WithArgs(sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedResponse: apperrors.Conflict("user was modified concurrently", nil),
 		},
 	}
 
@@ -230,13 +630,134 @@ func TestDeleteUsers(t *testing.T) {
 			tt.mockExpect()
 
 			store := NewDetails()
-			err := store.DeleteUsers(tt.username, ctx)
+			err := store.RestoreUsers(entities.Users{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", UserName: "John Doe", Version: 2}, nil, ctx)
 
 			assert.Equal(t, tt.expectedResponse, err, "TEST[%d] failed: %s", i, tt.name)
 		})
 	}
 }
 
+func TestPurgeUsers(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	before := time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC)
+	deletedAt := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	selectQuery := "SELECT ID, UserName, UserAge, PhoneNumber, Email, DeletedAt, CreatedAt, UpdatedAt, Version FROM `User` " +
+		"WHERE DeletedAt < ?"
+	deleteQuery := "DELETE FROM `User` WHERE ID = ? AND DeletedAt < ?"
+	auditQuery := "INSERT INTO `UserAudit` (Actor, Action, UserID, UserName, Changes, RequestID, CreatedAt) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?)"
+	purgedRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"ID", "UserName", "UserAge", "PhoneNumber", "Email", "DeletedAt", "CreatedAt", "UpdatedAt", "Version"}).
+			AddRow("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "adam", 40, "+14155550001", "adam@example.com", deletedAt, nil, nil, 2).
+			AddRow("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", "eve", 22, "+14155550002", "eve@example.com", deletedAt, nil, nil, 2)
+	}
+	change := func(user entities.Users) (*entities.Change, error) {
+		return &entities.Change{Audit: entities.AuditEntry{Actor: "local", Action: entities.AuditPurge, UserID: user.ID,
+			UserName: user.UserName, Changes: []byte(`{}`), Timestamp: before}}, nil
+	}
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse int64
+		expectedError    error
+	}{
+		{
+			name: "Users purged and recorded together",
+			mockExpect: func() {
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectQuery(selectQuery).WithArgs(before).WillReturnRows(purgedRows())
+				mock.SQL.ExpectExec(deleteQuery).WithArgs("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", before).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.SQL.ExpectExec(deleteQuery).WithArgs("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", before).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.SQL.ExpectExec(auditQuery+", (?, ?, ?, ?, ?, ?, ?)").
+					WithArgs("local", "purge", "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", "adam", "{}", "", before,
+						"local", "purge", "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", "eve", "{}", "", before).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.SQL.ExpectCommit()
+			},
+			expectedResponse: 2,
+			expectedError:    nil,
+		},
+		{
+			name: "User restored since it was read is kept",
+			mockExpect: func() {
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectQuery(selectQuery).WithArgs(before).WillReturnRows(purgedRows())
+				mock.SQL.ExpectExec(deleteQuery).WithArgs("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", before).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.SQL.ExpectExec(deleteQuery).WithArgs("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", before).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.SQL.ExpectExec(auditQuery).
+					WithArgs("local", "purge", "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", "eve", "{}", "", before).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.SQL.ExpectCommit()
+			},
+			expectedResponse: 1,
+			expectedError:    nil,
+		},
+		{
+			name: "Nothing to purge",
+			mockExpect: func() {
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectQuery(selectQuery).WithArgs(before).
+					WillReturnRows(sqlmock.NewRows([]string{"ID", "UserName", "UserAge", "PhoneNumber", "Email", "DeletedAt",
+						"CreatedAt", "UpdatedAt", "Version"}))
+				mock.SQL.ExpectCommit()
+			},
+			expectedResponse: 0,
+			expectedError:    nil,
+		},
+		{
+			name: "Error while purging users",
+			mockExpect: func() {
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectQuery(selectQuery).WithArgs(before).WillReturnRows(purgedRows())
+				mock.SQL.ExpectExec(deleteQuery).WithArgs("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", before).
+					WillReturnError(fmt.Errorf("db error"))
+				mock.SQL.ExpectRollback()
+			},
+			expectedResponse: 0,
+			expectedError:    datasource.ErrorDB{Err: fmt.Errorf("db error"), Message: "error from sql db"},
+		},
+		{
+			name: "Purge is rolled back when it cannot be recorded",
+			mockExpect: func() {
+				mock.SQL.ExpectBegin()
+				mock.SQL.ExpectQuery(selectQuery).WithArgs(before).WillReturnRows(purgedRows())
+				mock.SQL.ExpectExec(deleteQuery).WithArgs("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0001", before).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.SQL.ExpectExec(deleteQuery).WithArgs("0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", before).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.SQL.ExpectExec(auditQuery + ", (?, ?, ?, ?, ?, ?, ?)").WillReturnError(fmt.Errorf("db error"))
+				mock.SQL.ExpectRollback()
+			},
+			expectedResponse: 0,
+			expectedError:    datasource.ErrorDB{Err: fmt.Errorf("db error"), Message: "error from sql db"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			store := NewDetails()
+			purged, err := store.PurgeUsers(before, change, ctx)
+
+			assert.Equal(t, tt.expectedResponse, purged, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedError, err, "TEST[%d] failed: %s", i, tt.name)
+			assert.NoError(t, mock.SQL.ExpectationsWereMet(), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
 // test update.
 func TestUpdateUsers(t *testing.T) {
 
@@ -246,9 +767,12 @@ func TestUpdateUsers(t *testing.T) {
 		Container: mockContainer,
 	}
 
-	name := "John Doe"
 	updateUser := &entities.Users{
-		Email: "john.new@example.com",
+		ID:          "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f",
+		UserAge:     31,
+		PhoneNumber: "123-456-7890",
+		Email:       "john.new@example.com",
+		Version:     4,
 	}
 
 	tests := []struct {
@@ -260,8 +784,9 @@ func TestUpdateUsers(t *testing.T) {
 			name: "Successful update",
 			mockExpect: func() {
 
-				mock.SQL.ExpectExec("UPDATE User SET Email = ? WHERE UserName = ?").
-					WithArgs(updateUser.Email, name).
+				mock.SQL.ExpectExec("UPDATE `User` SET UserAge = ?, PhoneNumber = ?, Email = ?, UpdatedAt = ?, Version = Version + 1 "+
+					"WHERE ID = ? AND Version = ? AND DeletedAt IS NULL").
+					WithArgs(updateUser.UserAge, updateUser.PhoneNumber, updateUser.Email, sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 4).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			expectedError: nil,
@@ -270,21 +795,23 @@ func TestUpdateUsers(t *testing.T) {
 			name: "Error while updating user",
 			mockExpect: func() {
 
-				mock.SQL.ExpectExec("UPDATE User SET Email = ? WHERE UserName = ?").
-					WithArgs(updateUser.Email, name).
+				mock.SQL.ExpectExec("UPDATE `User` SET UserAge = ?, PhoneNumber = ?, Email = ?, UpdatedAt = ?, Version = Version + 1 "+
+					"WHERE ID = ? AND Version = ? AND DeletedAt IS NULL").
+					WithArgs(updateUser.UserAge, updateUser.PhoneNumber, updateUser.Email, sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 4).
 					WillReturnError(fmt.Errorf("database error"))
 			},
-			expectedError: fmt.Errorf("database error"),
+			expectedError: datasource.ErrorDB{Err: fmt.Errorf("database error"), Message: "error from sql db"},
 		},
 		{
-			name: "No rows affected",
+			name: "User modified concurrently",
 			mockExpect: func() {
 
-				mock.SQL.ExpectExec("UPDATE User SET Email = ? WHERE UserName = ?").
-					WithArgs(updateUser.Email, name).
+				mock.SQL.ExpectExec("UPDATE `User` SET UserAge = ?, PhoneNumber = ?, Email = ?, UpdatedAt = ?, Version = Version + 1 "+
+					"WHERE ID = ? AND Version = ? AND DeletedAt IS NULL").
+					WithArgs(updateUser.UserAge, updateUser.PhoneNumber, updateUser.Email, sqlmock.AnyArg(), "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", 4).
 					WillReturnResult(sqlmock.NewResult(0, 0))
 			},
-			expectedError: nil,
+			expectedError: apperrors.Conflict("user was modified concurrently", nil),
 		},
 	}
 
@@ -294,7 +821,7 @@ func TestUpdateUsers(t *testing.T) {
 			tt.mockExpect()
 
 			store := &UsersList{}
-			err := store.UpdateUsers(name, updateUser, ctx)
+			err := store.UpdateUsers(updateUser, nil, ctx)
 
 			if tt.expectedError != nil {
 				assert.EqualError(t, err, tt.expectedError.Error(), "TEST[%d] failed: %s", i, tt.name)
@@ -306,3 +833,60 @@ func TestUpdateUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestRenameUsers(t *testing.T) {
+	mockContainer, mock := container.NewMockContainer(t)
+	ctx := &gofr.Context{
+		Context:   context.Background(),
+		Container: mockContainer,
+	}
+
+	id := "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f"
+	duplicate := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'Jane Doe' for key 'PRIMARY'"}
+
+	tests := []struct {
+		name             string
+		mockExpect       func()
+		expectedResponse error
+	}{
+		{
+			name: "Successful rename",
+			mockExpect: func() {
+				mock.SQL.ExpectExec("UPDATE `User` SET UserName = ?, UpdatedAt = ?, Version = Version + 1 WHERE ID = ? AND Version = ? AND DeletedAt IS NULL").
+					WithArgs("Jane Doe", sqlmock.AnyArg(), id, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedResponse: nil,
+		},
+		{
+			name: "User changed concurrently",
+			mockExpect: func() {
+				mock.SQL.ExpectExec("UPDATE `User` SET UserName = ?, UpdatedAt = ?, Version = Version + 1 WHERE ID = ? AND Version = ? AND DeletedAt IS NULL").
+					WithArgs("Jane Doe", sqlmock.AnyArg(), id, 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedResponse: apperrors.Conflict("user was modified concurrently", nil),
+		},
+		{
+			name: "Name already taken",
+			mockExpect: func() {
+				mock.SQL.ExpectExec("UPDATE `User` SET UserName = ?, UpdatedAt = ?, Version = Version + 1 WHERE ID = ? AND Version = ? AND DeletedAt IS NULL").
+					WithArgs("Jane Doe", sqlmock.AnyArg(), id, 2).
+					WillReturnError(duplicate)
+			},
+			expectedResponse: &apperrors.Error{Kind: apperrors.KindAlreadyExists,
+				Message: "user with name 'Jane Doe' already exists", Err: duplicate},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockExpect()
+
+			store := NewDetails()
+			err := store.RenameUsers(id, "Jane Doe", 2, nil, ctx)
+
+			assert.Equal(t, tt.expectedResponse, err, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Package stream gives GoFr handlers, which return their whole response at once, the request
+// body and response writer of the HTTP exchange they serve, for routes reading or writing more
+// data than should be held in memory.
+package stream
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+type exchangeKey struct{}
+
+type exchange struct {
+	body io.Reader
+	w    *responseWriter
+}
+
+// responseWriter discards what GoFr responds with once the handler has streamed a response itself.
+type responseWriter struct {
+	http.ResponseWriter
+
+	streamed bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.streamed {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.streamed {
+		return len(b), nil
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the writer of the server.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// streamWriter is the writer handed out by Writer.
+type streamWriter struct {
+	w *responseWriter
+}
+
+func (s streamWriter) Write(b []byte) (int, error) {
+	s.w.streamed = true
+
+	return s.w.ResponseWriter.Write(b)
+}
+
+func (s streamWriter) flush() error {
+	s.w.streamed = true
+
+	return http.NewResponseController(s.w.ResponseWriter).Flush()
+}
+
+// Middleware makes the body of every request and the writer of its response available through
+// Body and Writer.
+func Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, rw := With(r.Context(), r.Body, w)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
+
+// With returns a copy of ctx carrying the request body and the response writer, along with the
+// writer GoFr has to respond through for Writer to work.
+func With(ctx context.Context, body io.Reader, w http.ResponseWriter) (context.Context, http.ResponseWriter) {
+	rw := &responseWriter{ResponseWriter: w}
+
+	return context.WithValue(ctx, exchangeKey{}, exchange{body: body, w: rw}), rw
+}
+
+// Body returns the request body, or nil if ctx does not carry it.
+func Body(ctx context.Context) io.Reader {
+	e, ok := ctx.Value(exchangeKey{}).(exchange)
+	if !ok {
+		return nil
+	}
+
+	return e.body
+}
+
+// Writer returns a writer streaming the response body, or false if ctx does not carry the response.
+// The status code is 200 and the headers are those set before the first write. Once the handler
+// has written to it, whatever the handler returns is no longer sent, only GoFr's logs see errors.
+func Writer(ctx context.Context) (io.Writer, bool) {
+	e, ok := ctx.Value(exchangeKey{}).(exchange)
+	if !ok {
+		return nil, false
+	}
+
+	return streamWriter{w: e.w}, true
+}
+
+// Flush sends what was written to w, a writer returned by Writer, to the client right away instead
+// of when the buffer of the server is full, for responses made of events sent as they happen.
+func Flush(w io.Writer) error {
+	s, ok := w.(streamWriter)
+	if !ok {
+		return http.ErrNotSupported
+	}
+
+	return s.flush()
+}
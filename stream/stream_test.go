@@ -0,0 +1,70 @@
+package stream
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		stream         bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "streamed response", stream: true, expectedStatus: http.StatusOK, expectedBody: "waheed\nadam\n"},
+		{name: "regular response", stream: false, expectedStatus: http.StatusInternalServerError, expectedBody: "failed"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.stream {
+					body, _ := io.ReadAll(Body(r.Context()))
+					sw, _ := Writer(r.Context())
+					_, _ = sw.Write(body)
+				}
+
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte("failed"))
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/user/import", strings.NewReader("waheed\nadam\n"))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedBody, w.Body.String(), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestWithoutExchange(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := Writer(ctx)
+
+	assert.False(t, ok)
+	assert.Nil(t, Body(ctx))
+}
+
+func TestFlush(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, rw := With(context.Background(), http.NoBody, w)
+
+	sw, _ := Writer(ctx)
+
+	assert.NoError(t, Flush(sw))
+	assert.True(t, w.Flushed)
+
+	rw.WriteHeader(http.StatusInternalServerError)
+	assert.Equal(t, http.StatusOK, w.Code, "flushed response is streamed")
+
+	assert.ErrorIs(t, Flush(io.Discard), http.ErrNotSupported)
+}
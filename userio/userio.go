@@ -0,0 +1,306 @@
+// Package userio reads and writes users as CSV or NDJSON files, one user per record or line.
+// CSV files have a header naming the columns after the JSON fields of entities.Users.
+package userio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"gofrProject/validation"
+	"io"
+	"iter"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Formats of user files.
+const (
+	CSV    = "csv"
+	NDJSON = "ndjson"
+)
+
+var contentTypes = map[string]string{
+	CSV:    "text/csv",
+	NDJSON: "application/x-ndjson",
+}
+
+// ContentType returns the media type of files of format.
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// FormatOf returns the format of files of the media type contentType, or false if it is none of them.
+func FormatOf(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return CSV, true
+	case "application/x-ndjson", "application/ndjson":
+		return NDJSON, true
+	default:
+		return "", false
+	}
+}
+
+// ErrUnknownFormat is returned for a format that is neither CSV nor NDJSON.
+var ErrUnknownFormat = errors.New("format must be csv or ndjson")
+
+// columns are the columns of CSV files, the fields of entities.Users they hold are set by setField.
+var columns = []string{"id", "user_name", "user_age", "phone_Number", "email"}
+
+// Writer writes users to a file.
+type Writer interface {
+	Write(user entities.Users) error
+	// Flush writes any buffered data to the underlying writer.
+	Flush() error
+}
+
+// NewWriter returns a writer of files of format.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case CSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case NDJSON:
+		bw := bufio.NewWriter(w)
+
+		return &ndjsonWriter{w: bw, enc: json.NewEncoder(bw)}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvWriter) Write(user entities.Users) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	return c.w.Write([]string{user.ID, user.UserName, strconv.Itoa(user.UserAge), user.PhoneNumber, user.Email})
+}
+
+// Flush writes the header too if no user was written, so that an empty export is a valid file.
+func (c *csvWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	c.w.Flush()
+
+	return c.w.Error()
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+
+	c.headerWritten = true
+
+	return c.w.Write(columns)
+}
+
+type ndjsonWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(user entities.Users) error {
+	return n.enc.Encode(user)
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return n.w.Flush()
+}
+
+// maxLineLength is the longest line of an NDJSON file that is read.
+const maxLineLength = 64 * 1024
+
+// Read returns the users of the file of format read from r, one row per record or line. Rows that
+// cannot be read as a user carry a validation error, reading stops after the first error of r.
+func Read(format string, r io.Reader) iter.Seq[entities.ImportRow] {
+	switch format {
+	case CSV:
+		return readCSV(r)
+	case NDJSON:
+		return readNDJSON(r)
+	default:
+		return func(yield func(entities.ImportRow) bool) {
+			yield(entities.ImportRow{Line: 1, Err: ErrUnknownFormat})
+		}
+	}
+}
+
+func readCSV(r io.Reader) iter.Seq[entities.ImportRow] {
+	return func(yield func(entities.ImportRow) bool) {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+
+		header, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+
+		if err != nil {
+			yield(entities.ImportRow{Line: 1, Err: apperrors.Validation(fmt.Errorf("error while reading header: %v", err))})
+			return
+		}
+
+		fields, err := headerFields(header)
+		if err != nil {
+			yield(entities.ImportRow{Line: 1, Err: err})
+			return
+		}
+
+		for line := 1; ; {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = parseErr.Line
+				if !yield(entities.ImportRow{Line: parseErr.StartLine, Err: apperrors.Validation(parseErr.Err)}) {
+					return
+				}
+
+				continue
+			}
+
+			if err != nil {
+				yield(entities.ImportRow{Line: line + 1, Err: apperrors.Validation(fmt.Errorf("error while reading file: %v", err))})
+				return
+			}
+
+			start, _ := reader.FieldPos(0)
+			line, _ = reader.FieldPos(len(record) - 1)
+
+			if !yield(csvRow(start, fields, record)) {
+				return
+			}
+		}
+	}
+}
+
+// headerFields returns the column of every field of the header, -1 for the columns that are
+// ignored. Column names are matched regardless of case.
+func headerFields(header []string) ([]int, error) {
+	fields := make([]int, len(header))
+	seen := make(map[int]bool, len(columns))
+
+	for i, name := range header {
+		fields[i] = slices.IndexFunc(columns, func(column string) bool {
+			return strings.EqualFold(column, strings.TrimSpace(name))
+		})
+		if fields[i] >= 0 {
+			seen[fields[i]] = true
+		}
+	}
+
+	var errs validation.Errors
+
+	for i, column := range columns[1:] {
+		if !seen[i+1] {
+			errs = append(errs, validation.FieldError{Field: column, Code: validation.CodeRequired,
+				Message: "header has no " + column + " column"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, apperrors.Validation(errs)
+	}
+
+	return fields, nil
+}
+
+// csvRow reads the user of record, whose fields are the columns at the indexes of fields.
+func csvRow(line int, fields []int, record []string) entities.ImportRow {
+	row := entities.ImportRow{Line: line}
+
+	if len(record) != len(fields) {
+		row.Err = apperrors.Validation(fmt.Errorf("record has %d fields, the header has %d", len(record), len(fields)))
+		return row
+	}
+
+	for i, value := range record {
+		if fields[i] < 0 {
+			continue
+		}
+
+		if err := setField(&row.User, columns[fields[i]], value); err != nil {
+			row.Err = err
+			return row
+		}
+	}
+
+	return row
+}
+
+func setField(user *entities.Users, column, value string) error {
+	switch column {
+	case "id":
+		user.ID = value
+	case "user_name":
+		user.UserName = value
+	case "user_age":
+		age, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return apperrors.Validation(validation.Errors{{Field: "user_age", Code: validation.CodeInvalidType,
+				Message: "user age must be an integer"}})
+		}
+
+		user.UserAge = age
+	case "phone_Number":
+		user.PhoneNumber = value
+	case "email":
+		user.Email = value
+	}
+
+	return nil
+}
+
+func readNDJSON(r io.Reader) iter.Seq[entities.ImportRow] {
+	return func(yield func(entities.ImportRow) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineLength)
+
+		line := 0
+
+		for scanner.Scan() {
+			line++
+
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+
+			row := entities.ImportRow{Line: line}
+			if err := json.Unmarshal([]byte(text), &row.User); err != nil {
+				row.Err = apperrors.Validation(fmt.Errorf("error while reading user: %v", err))
+			}
+
+			if !yield(row) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(entities.ImportRow{Line: line + 1, Err: apperrors.Validation(fmt.Errorf("error while reading file: %v", err))})
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package userio
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gofrProject/apperrors"
+	"gofrProject/entities"
+	"gofrProject/validation"
+)
+
+func TestWriter(t *testing.T) {
+	users := []entities.Users{
+		{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f", UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671",
+			Email: "waheed@example.com", Version: 3},
+		{ID: "0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002", UserName: "o'neil", UserAge: 40, PhoneNumber: "+14155552672",
+			Email: "oneil,jr@example.com"},
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		users    []entities.Users
+		expected string
+	}{
+		{
+			name:   "csv",
+			format: CSV,
+			users:  users,
+			expected: "id,user_name,user_age,phone_Number,email\n" +
+				"0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f,waheed,19,+14155552671,waheed@example.com\n" +
+				"0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002,o'neil,40,+14155552672,\"oneil,jr@example.com\"\n",
+		},
+		{
+			name:     "csv without users",
+			format:   CSV,
+			users:    nil,
+			expected: "id,user_name,user_age,phone_Number,email\n",
+		},
+		{
+			name:   "ndjson",
+			format: NDJSON,
+			users:  users,
+			expected: `{"id":"0193c6b8-7d2a-7c3e-9f41-5a6b7c8d9e0f","user_name":"waheed","user_age":19,` +
+				`"phone_Number":"+14155552671","email":"waheed@example.com"}` + "\n" +
+				`{"id":"0193c6b8-7d2a-7c3e-9f41-5a6b7c8d0002","user_name":"o'neil","user_age":40,` +
+				`"phone_Number":"+14155552672","email":"oneil,jr@example.com"}` + "\n",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+
+			w, err := NewWriter(tt.format, &b)
+			assert.NoError(t, err, "TEST[%d] failed: %s", i, tt.name)
+
+			for _, user := range tt.users {
+				assert.NoError(t, w.Write(user), "TEST[%d] failed: %s", i, tt.name)
+			}
+
+			assert.NoError(t, w.Flush(), "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expected, b.String(), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	_, err := NewWriter("xlsx", &strings.Builder{})
+
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestRead(t *testing.T) {
+	waheed := entities.Users{UserName: "waheed", UserAge: 19, PhoneNumber: "+14155552671", Email: "waheed@example.com"}
+	adam := entities.Users{UserName: "adam", UserAge: 40, PhoneNumber: "+14155552672", Email: "adam@example.com"}
+
+	tests := []struct {
+		name     string
+		format   string
+		file     string
+		expected []entities.ImportRow
+	}{
+		{
+			name:   "csv with columns in any order",
+			format: CSV,
+			file: "Email,user_name,notes,user_age,phone_Number\n" +
+				"waheed@example.com,waheed,first,19,+14155552671\n" +
+				"\n" +
+				"adam@example.com,adam,\"two\nlines\",40,+14155552672\n",
+			expected: []entities.ImportRow{{Line: 2, User: waheed}, {Line: 4, User: adam}},
+		},
+		{
+			name:   "csv rows that cannot be read",
+			format: CSV,
+			file: "user_name,user_age,phone_Number,email\n" +
+				"waheed,nineteen,+14155552671,waheed@example.com\n" +
+				"adam,40\n" +
+				"eve,22,\"+1415\"5552673,eve@example.com\n" +
+				"adam,40,+14155552672,adam@example.com\n",
+			expected: []entities.ImportRow{
+				{Line: 2, User: entities.Users{UserName: "waheed"}, Err: apperrors.Validation(validation.Errors{{
+					Field: "user_age", Code: validation.CodeInvalidType, Message: "user age must be an integer"}})},
+				{Line: 3, Err: apperrors.Validation(errors.New("record has 2 fields, the header has 4"))},
+				{Line: 4, Err: apperrors.Validation(errors.New(`extraneous or missing " in quoted-field`))},
+				{Line: 5, User: adam},
+			},
+		},
+		{
+			name:   "csv header without required columns",
+			format: CSV,
+			file:   "user_name,email\nwaheed,waheed@example.com\n",
+			expected: []entities.ImportRow{{Line: 1, Err: apperrors.Validation(validation.Errors{
+				{Field: "user_age", Code: validation.CodeRequired, Message: "header has no user_age column"},
+				{Field: "phone_Number", Code: validation.CodeRequired, Message: "header has no phone_Number column"},
+			})}},
+		},
+		{
+			name:     "empty csv",
+			format:   CSV,
+			file:     "",
+			expected: nil,
+		},
+		{
+			name:   "ndjson",
+			format: NDJSON,
+			file: `{"user_name":"waheed","user_age":19,"phone_Number":"+14155552671","email":"waheed@example.com"}` + "\n\n" +
+				`{"user_name":"eve","user_age":"22"}` + "\n" +
+				`{"user_name":"adam","user_age":40,"phone_Number":"+14155552672","email":"adam@example.com"}`,
+			expected: []entities.ImportRow{
+				{Line: 1, User: waheed},
+				{Line: 3, User: entities.Users{UserName: "eve"}, Err: apperrors.Validation(errors.New(
+					"error while reading user: json: cannot unmarshal string into Go struct field Users.user_age of type int"))},
+				{Line: 4, User: adam},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := slices.Collect(Read(tt.format, strings.NewReader(tt.file)))
+
+			assert.Equal(t, tt.expected, rows, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		contentType    string
+		expectedFormat string
+		expectedOK     bool
+	}{
+		{contentType: "text/csv; charset=utf-8", expectedFormat: CSV, expectedOK: true},
+		{contentType: "application/x-ndjson", expectedFormat: NDJSON, expectedOK: true},
+		{contentType: "application/ndjson", expectedFormat: NDJSON, expectedOK: true},
+		{contentType: "application/json", expectedFormat: "", expectedOK: false},
+		{contentType: "", expectedFormat: "", expectedOK: false},
+	}
+
+	for i, tt := range tests {
+		format, ok := FormatOf(tt.contentType)
+
+		assert.Equal(t, tt.expectedFormat, format, "TEST[%d] failed: %s", i, tt.contentType)
+		assert.Equal(t, tt.expectedOK, ok, "TEST[%d] failed: %s", i, tt.contentType)
+	}
+}
@@ -0,0 +1,31 @@
+package validation
+
+import (
+	"net/mail"
+	"strings"
+)
+
+func email(address string) []FieldError {
+	const field = "email"
+
+	if address == "" {
+		return []FieldError{{Field: field, Code: CodeRequired, Message: "email is required"}}
+	}
+
+	if len(address) > MaxEmailLength {
+		return []FieldError{{Field: field, Code: CodeTooLong, Message: "email must be at most 254 characters"}}
+	}
+
+	// ParseAddress also accepts display names, so require the parsed address to be the whole input.
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address {
+		return []FieldError{{Field: field, Code: CodeInvalidFormat, Message: "email is not a valid address"}}
+	}
+
+	_, domain, _ := strings.Cut(address, "@")
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return []FieldError{{Field: field, Code: CodeInvalidFormat, Message: "email domain is not valid"}}
+	}
+
+	return nil
+}
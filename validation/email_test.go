@@ -0,0 +1,38 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		name         string
+		email        string
+		expectedCode string
+	}{
+		{name: "valid", email: "waheed@example.com"},
+		{name: "subdomain and plus", email: "waheed+test@mail.example.co.in"},
+		{name: "empty", email: "", expectedCode: CodeRequired},
+		{name: "missing at", email: "waheed.example.com", expectedCode: CodeInvalidFormat},
+		{name: "display name", email: "Waheed <waheed@example.com>", expectedCode: CodeInvalidFormat},
+		{name: "domain without dot", email: "waheed@localhost", expectedCode: CodeInvalidFormat},
+		{name: "domain ending in dot", email: "waheed@example.", expectedCode: CodeInvalidFormat},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := email(tt.email)
+
+			if tt.expectedCode == "" {
+				assert.Empty(t, errs, "TEST[%d] failed: %s", i, tt.name)
+				return
+			}
+
+			if assert.Len(t, errs, 1, "TEST[%d] failed: %s", i, tt.name) {
+				assert.Equal(t, tt.expectedCode, errs[0].Code, "TEST[%d] failed: %s", i, tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"gofrProject/entities"
+)
+
+// Error codes specific to phone numbers.
+const (
+	CodeMissingCountryCode = "missing_country_code"
+	CodeUnknownCountryCode = "unknown_country_code"
+	CodeInvalidLength      = "invalid_length"
+)
+
+// Lengths of an E.164 number, country calling code included. The shortest numbers in use have
+// 8 digits, e.g. those of Niue (+683).
+const (
+	minDigits = 8
+	maxDigits = 15
+)
+
+// country holds the numbering rules of a country calling code.
+type country struct {
+	name      string
+	minLength int
+	maxLength int
+}
+
+// countries maps ITU-T E.164 country calling codes to the allowed lengths of the national
+// significant number. It is not the whole numbering plan: numbers of the codes listed are checked
+// against the lengths of their country as well, numbers of other codes only against minDigits and
+// maxDigits. Calling codes are prefix free, so at most one code matches a number.
+var countries = map[string]country{
+	"1":   {name: "US/CA", minLength: 10, maxLength: 10},
+	"7":   {name: "RU/KZ", minLength: 10, maxLength: 10},
+	"20":  {name: "EG", minLength: 9, maxLength: 10},
+	"27":  {name: "ZA", minLength: 9, maxLength: 9},
+	"31":  {name: "NL", minLength: 9, maxLength: 9},
+	"32":  {name: "BE", minLength: 8, maxLength: 9},
+	"33":  {name: "FR", minLength: 9, maxLength: 9},
+	"34":  {name: "ES", minLength: 9, maxLength: 9},
+	"39":  {name: "IT", minLength: 6, maxLength: 11},
+	"41":  {name: "CH", minLength: 9, maxLength: 9},
+	"44":  {name: "GB", minLength: 10, maxLength: 10},
+	"46":  {name: "SE", minLength: 7, maxLength: 9},
+	"48":  {name: "PL", minLength: 9, maxLength: 9},
+	"49":  {name: "DE", minLength: 6, maxLength: 11},
+	"52":  {name: "MX", minLength: 10, maxLength: 10},
+	"55":  {name: "BR", minLength: 10, maxLength: 11},
+	"60":  {name: "MY", minLength: 8, maxLength: 10},
+	"61":  {name: "AU", minLength: 9, maxLength: 9},
+	"62":  {name: "ID", minLength: 8, maxLength: 12},
+	"63":  {name: "PH", minLength: 10, maxLength: 10},
+	"64":  {name: "NZ", minLength: 8, maxLength: 10},
+	"65":  {name: "SG", minLength: 8, maxLength: 8},
+	"66":  {name: "TH", minLength: 8, maxLength: 9},
+	"81":  {name: "JP", minLength: 9, maxLength: 10},
+	"82":  {name: "KR", minLength: 8, maxLength: 10},
+	"84":  {name: "VN", minLength: 9, maxLength: 10},
+	"86":  {name: "CN", minLength: 10, maxLength: 11},
+	"90":  {name: "TR", minLength: 10, maxLength: 10},
+	"91":  {name: "IN", minLength: 10, maxLength: 10},
+	"92":  {name: "PK", minLength: 10, maxLength: 10},
+	"94":  {name: "LK", minLength: 9, maxLength: 9},
+	"234": {name: "NG", minLength: 8, maxLength: 10},
+	"254": {name: "KE", minLength: 9, maxLength: 9},
+	"351": {name: "PT", minLength: 9, maxLength: 9},
+	"353": {name: "IE", minLength: 7, maxLength: 9},
+	"880": {name: "BD", minLength: 10, maxLength: 10},
+	"966": {name: "SA", minLength: 9, maxLength: 9},
+	"971": {name: "AE", minLength: 8, maxLength: 9},
+	"977": {name: "NP", minLength: 8, maxLength: 10},
+}
+
+// PhoneNumberError describes why a phone number could not be normalised.
+// It wraps entities.ErrInvalidPhoneNumber.
+type PhoneNumberError struct {
+	Code    string
+	Message string
+}
+
+func (e *PhoneNumberError) Error() string {
+	return entities.ErrInvalidPhoneNumber.Error() + ": " + e.Message
+}
+
+func (e *PhoneNumberError) Unwrap() error {
+	return entities.ErrInvalidPhoneNumber
+}
+
+// NormalizePhoneNumber converts an international phone number such as "+91 98765-43210" or
+// "0091 9876543210" to E.164 ("+919876543210"). Spaces, dashes, dots and parentheses are
+// ignored. Numbers without an international prefix are rejected, since the country cannot
+// be inferred, as are numbers of fewer than minDigits or more than maxDigits digits.
+func NormalizePhoneNumber(raw string) (string, *PhoneNumberError) {
+	if raw == "" {
+		return "", &PhoneNumberError{Code: CodeRequired, Message: "phone number is required"}
+	}
+
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+
+		return r
+	}, raw)
+
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		digits = digits[1:]
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	default:
+		return "", &PhoneNumberError{Code: CodeMissingCountryCode,
+			Message: "phone number must start with '+' followed by the country calling code"}
+	}
+
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", &PhoneNumberError{Code: CodeInvalidCharacters, Message: "phone number may only contain digits"}
+		}
+	}
+
+	if len(digits) < minDigits || len(digits) > maxDigits {
+		return "", &PhoneNumberError{Code: CodeInvalidLength,
+			Message: fmt.Sprintf("phone number must have between %d and %d digits", minDigits, maxDigits)}
+	}
+
+	// No country calling code starts with 0.
+	if digits[0] == '0' {
+		return "", &PhoneNumberError{Code: CodeUnknownCountryCode, Message: "phone number has an unknown country calling code"}
+	}
+
+	for length := 1; length <= 3 && length < len(digits); length++ {
+		c, ok := countries[digits[:length]]
+		if !ok {
+			continue
+		}
+
+		if national := len(digits) - length; national < c.minLength || national > c.maxLength {
+			return "", &PhoneNumberError{Code: CodeInvalidLength,
+				Message: "phone number has an invalid length for country " + c.name}
+		}
+
+		break
+	}
+
+	return "+" + digits, nil
+}
@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gofrProject/entities"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		name         string
+		phoneNumber  string
+		expected     string
+		expectedCode string
+	}{
+		{name: "already E.164", phoneNumber: "+14155552671", expected: "+14155552671"},
+		{name: "formatted number", phoneNumber: "+1 (415) 555-2671", expected: "+14155552671"},
+		{name: "international 00 prefix", phoneNumber: "0044 20.7946.0958", expected: "+442079460958"},
+		{name: "three digit calling code", phoneNumber: "+971 50 123 4567", expected: "+971501234567"},
+		{name: "empty", phoneNumber: "", expectedCode: CodeRequired},
+		{name: "no country code", phoneNumber: "9876543210", expectedCode: CodeMissingCountryCode},
+		{name: "letters", phoneNumber: "+91 98765 ABCDE", expectedCode: CodeInvalidCharacters},
+		{name: "too short for country", phoneNumber: "+91 98765 432", expectedCode: CodeInvalidLength},
+		{name: "too long for country", phoneNumber: "+1 415 555 26710", expectedCode: CodeInvalidLength},
+		{name: "calling code of Greece", phoneNumber: "+30 21 0123 4567", expected: "+302101234567"},
+		{name: "calling code of Austria", phoneNumber: "+43 1 234567", expected: "+431234567"},
+		{name: "calling code of Denmark", phoneNumber: "+45 32 12 34 56", expected: "+4532123456"},
+		{name: "calling code of Norway", phoneNumber: "+47 22 12 34 56", expected: "+4722123456"},
+		{name: "calling code of Finland", phoneNumber: "+358 9 1234 5678", expected: "+358912345678"},
+		{name: "calling code of Israel", phoneNumber: "+972 50 123 4567", expected: "+972501234567"},
+		{name: "too short", phoneNumber: "+45 1234", expectedCode: CodeInvalidLength},
+		{name: "too long", phoneNumber: "+45 1234 5678 9012 3456", expectedCode: CodeInvalidLength},
+		{name: "calling code starting with 0", phoneNumber: "+0 1234 5678", expectedCode: CodeUnknownCountryCode},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := NormalizePhoneNumber(tt.phoneNumber)
+
+			assert.Equal(t, tt.expected, normalized, "TEST[%d] failed: %s", i, tt.name)
+
+			if tt.expectedCode == "" {
+				assert.Nil(t, err, "TEST[%d] failed: %s", i, tt.name)
+				return
+			}
+
+			if assert.NotNil(t, err, "TEST[%d] failed: %s", i, tt.name) {
+				assert.Equal(t, tt.expectedCode, err.Code, "TEST[%d] failed: %s", i, tt.name)
+				assert.True(t, errors.Is(err, entities.ErrInvalidPhoneNumber), "TEST[%d] failed: %s", i, tt.name)
+			}
+		})
+	}
+}
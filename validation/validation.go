@@ -0,0 +1,133 @@
+package validation
+
+import (
+	"net/http"
+	"strings"
+
+	"gofrProject/entities"
+)
+
+// Error codes reported for invalid fields.
+const (
+	CodeRequired          = "required"
+	CodeInvalidFormat     = "invalid_format"
+	CodeInvalidCharacters = "invalid_characters"
+	CodeTooShort          = "too_short"
+	CodeTooLong           = "too_long"
+	CodeOutOfRange        = "out_of_range"
+	CodeInvalidValue      = "invalid_value"
+	CodeInvalidType       = "invalid_type"
+	CodeUnknownField      = "unknown_field"
+	CodeImmutable         = "immutable"
+	CodeReserved          = "reserved"
+)
+
+// Limits applied to the fields of entities.Users.
+const (
+	MinUserNameLength = 3
+	MaxUserNameLength = 32
+	MinUserAge        = 0
+	MaxUserAge        = 150
+	MaxEmailLength    = 254
+)
+
+// reservedUserNames are the names of the fixed routes below /user, which would shadow the
+// /user/{name} route of users named like them.
+var reservedUserNames = []string{"export", "changes", "search"}
+
+// FieldError describes why a single field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors holds every violation found while validating an entity.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+	}
+
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+func (Errors) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// User validates every field of user and normalises its phone number to E.164.
+// It returns nil or Errors listing all violations at once.
+func User(user *entities.Users) error {
+	var errs Errors
+
+	errs = append(errs, userName(user.UserName)...)
+	errs = append(errs, userAge(user.UserAge)...)
+	errs = append(errs, email(user.Email)...)
+
+	phoneNumber, err := NormalizePhoneNumber(user.PhoneNumber)
+	if err != nil {
+		errs = append(errs, FieldError{Field: "phone_Number", Code: err.Code, Message: err.Message})
+	} else {
+		user.PhoneNumber = phoneNumber
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// UserName validates a user name on its own, e.g. the new name of a renamed user.
+func UserName(name string) error {
+	if errs := userName(name); len(errs) > 0 {
+		return Errors(errs)
+	}
+
+	return nil
+}
+
+func userName(name string) []FieldError {
+	const field = "user_name"
+
+	switch {
+	case name == "":
+		return []FieldError{{Field: field, Code: CodeRequired, Message: "user name is required"}}
+	case len(name) < MinUserNameLength:
+		return []FieldError{{Field: field, Code: CodeTooShort, Message: "user name must be at least 3 characters"}}
+	case len(name) > MaxUserNameLength:
+		return []FieldError{{Field: field, Code: CodeTooLong, Message: "user name must be at most 32 characters"}}
+	}
+
+	for _, reserved := range reservedUserNames {
+		if strings.EqualFold(name, reserved) {
+			return []FieldError{{Field: field, Code: CodeReserved, Message: "user name " + reserved + " is reserved"}}
+		}
+	}
+
+	for i, r := range name {
+		if isAlphanumeric(r) || (i > 0 && (r == '.' || r == '_' || r == '-')) {
+			continue
+		}
+
+		return []FieldError{{Field: field, Code: CodeInvalidCharacters,
+			Message: "user name may only contain letters, digits, '.', '_' and '-' and must start with a letter or digit"}}
+	}
+
+	return nil
+}
+
+func userAge(age int) []FieldError {
+	if age < MinUserAge || age > MaxUserAge {
+		return []FieldError{{Field: "user_age", Code: CodeOutOfRange, Message: "user age must be between 0 and 150"}}
+	}
+
+	return nil
+}
+
+func isAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,112 @@
+package validation
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gofrProject/entities"
+)
+
+func TestUser(t *testing.T) {
+	tests := []struct {
+		name         string
+		user         entities.Users
+		expectedUser entities.Users
+		expectedErr  error
+	}{
+		{
+			name:         "valid user is normalised",
+			user:         entities.Users{UserName: "waheed", UserAge: 19, PhoneNumber: "+91 98765-43210", Email: "waheed@example.com"},
+			expectedUser: entities.Users{UserName: "waheed", UserAge: 19, PhoneNumber: "+919876543210", Email: "waheed@example.com"},
+			expectedErr:  nil,
+		},
+		{
+			name:         "all violations are reported",
+			user:         entities.Users{UserName: "", UserAge: 151, PhoneNumber: "12345354", Email: "waheed"},
+			expectedUser: entities.Users{UserName: "", UserAge: 151, PhoneNumber: "12345354", Email: "waheed"},
+			expectedErr: Errors{
+				{Field: "user_name", Code: CodeRequired, Message: "user name is required"},
+				{Field: "user_age", Code: CodeOutOfRange, Message: "user age must be between 0 and 150"},
+				{Field: "email", Code: CodeInvalidFormat, Message: "email is not a valid address"},
+				{Field: "phone_Number", Code: CodeMissingCountryCode,
+					Message: "phone number must start with '+' followed by the country calling code"},
+			},
+		},
+		{
+			name:         "user name too short",
+			user:         entities.Users{UserName: "wa", PhoneNumber: "+14155552671", Email: "wa@example.com"},
+			expectedUser: entities.Users{UserName: "wa", PhoneNumber: "+14155552671", Email: "wa@example.com"},
+			expectedErr: Errors{
+				{Field: "user_name", Code: CodeTooShort, Message: "user name must be at least 3 characters"},
+			},
+		},
+		{
+			name:         "user name too long",
+			user:         entities.Users{UserName: "waheed-waheed-waheed-waheed-waheed", PhoneNumber: "+14155552671", Email: "wa@example.com"},
+			expectedUser: entities.Users{UserName: "waheed-waheed-waheed-waheed-waheed", PhoneNumber: "+14155552671", Email: "wa@example.com"},
+			expectedErr: Errors{
+				{Field: "user_name", Code: CodeTooLong, Message: "user name must be at most 32 characters"},
+			},
+		},
+		{
+			name:         "reserved user name",
+			user:         entities.Users{UserName: "Search", PhoneNumber: "+14155552671", Email: "search@example.com"},
+			expectedUser: entities.Users{UserName: "Search", PhoneNumber: "+14155552671", Email: "search@example.com"},
+			expectedErr: Errors{
+				{Field: "user_name", Code: CodeReserved, Message: "user name search is reserved"},
+			},
+		},
+		{
+			name:         "user name with invalid characters",
+			user:         entities.Users{UserName: "John Doe", PhoneNumber: "+14155552671", Email: "john@example.com"},
+			expectedUser: entities.Users{UserName: "John Doe", PhoneNumber: "+14155552671", Email: "john@example.com"},
+			expectedErr: Errors{
+				{Field: "user_name", Code: CodeInvalidCharacters,
+					Message: "user name may only contain letters, digits, '.', '_' and '-' and must start with a letter or digit"},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := User(&tt.user)
+
+			assert.Equal(t, tt.expectedErr, err, "TEST[%d] failed: %s", i, tt.name)
+			assert.Equal(t, tt.expectedUser, tt.user, "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestUserName(t *testing.T) {
+	tests := []struct {
+		name        string
+		userName    string
+		expectedErr error
+	}{
+		{name: "valid", userName: "exporter", expectedErr: nil},
+		{name: "export is reserved", userName: "export",
+			expectedErr: Errors{{Field: "user_name", Code: CodeReserved, Message: "user name export is reserved"}}},
+		{name: "changes is reserved", userName: "CHANGES",
+			expectedErr: Errors{{Field: "user_name", Code: CodeReserved, Message: "user name changes is reserved"}}},
+		{name: "search is reserved", userName: "search",
+			expectedErr: Errors{{Field: "user_name", Code: CodeReserved, Message: "user name search is reserved"}}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedErr, UserName(tt.userName), "TEST[%d] failed: %s", i, tt.name)
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	errs := Errors{
+		{Field: "user_name", Code: CodeRequired, Message: "user name is required"},
+		{Field: "email", Code: CodeRequired, Message: "email is required"},
+	}
+
+	assert.Equal(t, "validation failed: user_name: user name is required; email: email is required", errs.Error())
+	assert.Equal(t, http.StatusBadRequest, errs.StatusCode())
+}